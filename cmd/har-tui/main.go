@@ -5,31 +5,56 @@ import (
 	"log"
 	"os"
 
+	"github.com/cnharrison/har-tui/internal/cli"
 	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/importer"
 	"github.com/cnharrison/har-tui/internal/ui"
 )
 
 func main() {
+	// Other tools' captures, curl commands and Postman collections open like HAR files
+	importer.Register()
+
 	if len(os.Args) < 2 {
-		fmt.Println("Usage: har-tui <file.har>")
+		cli.PrintUsage(os.Stdout)
 		fmt.Println("\n🐱 HAR TUI DELUXE - A sleek terminal interface for HAR files")
 		fmt.Println("Press ? for help when running")
 		os.Exit(1)
 	}
 
-	harFile := os.Args[1]
+	// Non-interactive subcommands for scripts and pipelines
+	if cli.IsCommand(os.Args[1]) {
+		os.Exit(cli.Run(os.Args[1:], os.Stdout, os.Stderr))
+	}
+
+	// Follow a capture that is still being written
+	if os.Args[1] == "-f" || os.Args[1] == "--follow" {
+		if len(os.Args) != 3 {
+			cli.PrintUsage(os.Stderr)
+			os.Exit(2)
+		}
+		app := ui.NewApplicationFollowing(os.Args[2])
+		if err := app.Run(); err != nil {
+			log.Fatalf("Error running application: %v", err)
+		}
+		return
+	}
+
+	// Several files open in one session, with entries tagged by source file
+	harFiles := os.Args[1:]
 	
 	// Check if we should use streaming mode (for large files or by default)
 	useStreaming := true
 	
 	if useStreaming {
 		// Start the TUI application with streaming loader
-		app := ui.NewApplicationStreaming(harFile)
+		app := ui.NewApplicationStreaming(harFiles...)
 		if err := app.Run(); err != nil {
 			log.Fatalf("Error running application: %v", err)
 		}
 	} else {
-		// Legacy mode: Load entire HAR file at once
+		// Legacy mode: Load entire HAR file at once (first file only)
+		harFile := harFiles[0]
 		data, err := har.LoadHARFile(harFile)
 		if err != nil {
 			log.Fatalf("Error loading HAR file: %v", err)
@@ -7,6 +7,7 @@ toolchain go1.24.6
 require (
 	github.com/gdamore/tcell/v2 v2.8.1
 	github.com/go-xmlfmt/xmlfmt v1.1.3
+	github.com/klauspost/compress v1.17.11
 	github.com/rivo/tview v0.0.0-20250625164341-a4a78f1e05cb
 	github.com/yosssi/gohtml v0.0.0-20201013000340-ee4748c638f4
 )
@@ -0,0 +1,378 @@
+// Package audit checks document and API responses for missing or weak
+// security headers, and every response for insecure cookies and mixed
+// content
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/severity"
+)
+
+// hstsMinAge is the shortest HSTS max-age not reported as weak, 180 days
+const hstsMinAge = 180 * 24 * 60 * 60
+
+// Issue is one problem found in a response
+type Issue struct {
+	Entry    int
+	Host     string
+	Rule     string // what is wrong, e.g. "HSTS missing"
+	Severity severity.Level
+	Detail   string // the header value, cookie name or URL involved
+}
+
+// Auditor checks entries of one capture
+type Auditor struct {
+	pages map[string]string // pageref to the URL of the page's document
+}
+
+// New returns an auditor for a capture; its documents say which pages
+// were loaded over HTTPS
+func New(entries []har.HAREntry) *Auditor {
+	a := &Auditor{pages: make(map[string]string)}
+	for _, entry := range entries {
+		if entry.Pageref == "" {
+			continue
+		}
+		if _, ok := a.pages[entry.Pageref]; !ok && har.GetRequestType(entry) == "doc" {
+			a.pages[entry.Pageref] = entry.Request.URL
+		}
+	}
+	return a
+}
+
+// Entries audits the entries at indices, most severe issues first
+func Entries(entries []har.HAREntry, indices []int) []Issue {
+	a := New(entries)
+	var issues []Issue
+	for _, idx := range indices {
+		issues = append(issues, a.Entry(idx, entries[idx])...)
+	}
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Severity > issues[j].Severity
+	})
+	return issues
+}
+
+// Entry audits one entry, numbered index in the issues. Headers are only
+// checked on successful document and API responses
+func (a *Auditor) Entry(index int, entry har.HAREntry) []Issue {
+	u, err := url.Parse(entry.Request.URL)
+	if err != nil || entry.Response.Status == 0 {
+		return nil
+	}
+	c := &checker{index: index, host: u.Hostname(), headers: responseHeaders(entry)}
+	secure := u.Scheme == "https" || u.Scheme == "wss"
+	local := har.IsLocal(c.host)
+
+	kind := har.GetRequestType(entry)
+	if (kind == "doc" || kind == "fetch") && entry.Response.Status >= 200 && entry.Response.Status < 300 {
+		if secure && !local {
+			c.hsts()
+		}
+		c.nosniff()
+		c.cors()
+		if kind == "doc" {
+			if !secure && !local {
+				c.add("page served over HTTP", severity.High, entry.Request.URL)
+			}
+			c.csp()
+			c.referrerPolicy()
+		}
+	}
+	c.cookies(entry)
+
+	// Mixed content is an insecure subresource of a secure page
+	if kind != "doc" && (u.Scheme == "http" || u.Scheme == "ws") && !local {
+		page := a.pages[entry.Pageref]
+		if page == "" {
+			page = har.HeaderValue(entry.Request.Headers, "Referer")
+		}
+		if strings.HasPrefix(page, "https://") {
+			c.add("mixed content", severity.High, entry.Request.URL)
+		}
+	}
+	return c.issues
+}
+
+// checker collects the issues of one entry
+type checker struct {
+	index   int
+	host    string
+	headers map[string]string // lower-case name to value, repeats joined
+	issues  []Issue
+}
+
+func (c *checker) add(rule string, level severity.Level, detail string) {
+	c.issues = append(c.issues, Issue{Entry: c.index, Host: c.host, Rule: rule, Severity: level, Detail: detail})
+}
+
+// hsts checks Strict-Transport-Security is set for long enough
+func (c *checker) hsts() {
+	value, ok := c.headers["strict-transport-security"]
+	if !ok {
+		c.add("HSTS missing", severity.Medium, "")
+		return
+	}
+	maxAge := -1
+	for _, directive := range strings.Split(value, ";") {
+		name, arg, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		if strings.EqualFold(name, "max-age") {
+			if n, err := strconv.Atoi(strings.Trim(arg, `" `)); err == nil {
+				maxAge = n
+			}
+		}
+	}
+	switch {
+	case maxAge <= 0:
+		c.add("HSTS disabled", severity.Medium, value)
+	case maxAge < hstsMinAge:
+		c.add("HSTS max-age under 180 days", severity.Low, value)
+	}
+}
+
+// csp checks a Content-Security-Policy is enforced and restricts scripts
+func (c *checker) csp() {
+	value, ok := c.headers["content-security-policy"]
+	if !ok {
+		if reportOnly, ok := c.headers["content-security-policy-report-only"]; ok {
+			c.add("CSP report-only", severity.Low, reportOnly)
+		} else {
+			c.add("CSP missing", severity.Medium, "")
+		}
+		return
+	}
+
+	directives := make(map[string][]string)
+	for _, directive := range strings.Split(value, ";") {
+		fields := strings.Fields(strings.ToLower(directive))
+		if len(fields) > 0 {
+			directives[fields[0]] = append(directives[fields[0]], fields[1:]...)
+		}
+	}
+	sources, ok := directives["script-src"]
+	if !ok {
+		if sources, ok = directives["default-src"]; !ok {
+			c.add("CSP doesn't restrict scripts", severity.Low, value)
+			return
+		}
+	}
+	hashed := false
+	for _, source := range sources {
+		hashed = hashed || strings.HasPrefix(source, "'nonce-") || strings.HasPrefix(source, "'sha")
+	}
+	for _, source := range sources {
+		switch source {
+		case "'unsafe-inline'":
+			// Browsers ignore it next to a nonce or hash
+			if !hashed {
+				c.add("CSP allows inline scripts", severity.Low, value)
+			}
+		case "'unsafe-eval'":
+			c.add("CSP allows eval", severity.Low, value)
+		case "*", "http:", "https:", "data:":
+			c.add("CSP allows scripts from any host", severity.Low, value)
+		}
+	}
+}
+
+// nosniff checks X-Content-Type-Options stops MIME sniffing
+func (c *checker) nosniff() {
+	value, ok := c.headers["x-content-type-options"]
+	if !ok {
+		c.add("X-Content-Type-Options missing", severity.Low, "")
+	} else if !strings.EqualFold(strings.TrimSpace(value), "nosniff") {
+		c.add("X-Content-Type-Options not nosniff", severity.Low, value)
+	}
+}
+
+// referrerPolicy checks full URLs aren't sent to other sites
+func (c *checker) referrerPolicy() {
+	value, ok := c.headers["referrer-policy"]
+	if !ok {
+		c.add("Referrer-Policy missing", severity.Low, "")
+		return
+	}
+	// The last policy in a list is the one browsers use
+	policies := strings.Split(value, ",")
+	switch strings.ToLower(strings.TrimSpace(policies[len(policies)-1])) {
+	case "unsafe-url", "no-referrer-when-downgrade":
+		c.add("Referrer-Policy leaks full URLs", severity.Low, value)
+	}
+}
+
+// cors checks credentials aren't shared with any origin
+func (c *checker) cors() {
+	if !strings.EqualFold(strings.TrimSpace(c.headers["access-control-allow-credentials"]), "true") {
+		return
+	}
+	switch origin := strings.TrimSpace(c.headers["access-control-allow-origin"]); origin {
+	case "*":
+		c.add("CORS wildcard with credentials", severity.High, origin)
+	case "null":
+		c.add("CORS null origin with credentials", severity.High, origin)
+	}
+}
+
+// cookie is a cookie set by a response, with the attributes audited
+type cookie struct {
+	name     string
+	secure   bool
+	httpOnly bool
+	sameSite string // "" when not set
+}
+
+// cookies checks cookies set by the response are Secure, HttpOnly and SameSite
+func (c *checker) cookies(entry har.HAREntry) {
+	for _, ck := range responseCookies(entry) {
+		if !ck.secure {
+			c.add("cookie without Secure", severity.Medium, ck.name)
+		}
+		if !ck.httpOnly {
+			c.add("cookie without HttpOnly", severity.Low, ck.name)
+		}
+		switch {
+		case ck.sameSite == "":
+			c.add("cookie without SameSite", severity.Low, ck.name)
+		case strings.EqualFold(ck.sameSite, "none") && !ck.secure:
+			c.add("SameSite=None cookie without Secure", severity.Medium, ck.name)
+		}
+	}
+}
+
+// responseCookies merges the parsed cookies of a response with its
+// Set-Cookie headers, which carry the SameSite attribute. Cookies being
+// deleted are left out
+func responseCookies(entry har.HAREntry) []cookie {
+	parsed := make(map[string]*http.Cookie)
+	var headerOrder []string
+	for _, header := range entry.Response.Headers {
+		if !strings.EqualFold(header.Name, "Set-Cookie") {
+			continue
+		}
+		// Some tools join several Set-Cookie headers with newlines
+		for _, line := range strings.Split(header.Value, "\n") {
+			if c, err := http.ParseSetCookie(line); err == nil {
+				if _, seen := parsed[c.Name]; !seen {
+					headerOrder = append(headerOrder, c.Name)
+				}
+				parsed[c.Name] = c
+			}
+		}
+	}
+
+	var cookies []cookie
+	seen := make(map[string]bool)
+	for _, harCookie := range entry.Response.Cookies {
+		ck := cookie{name: harCookie.Name, secure: harCookie.Secure, httpOnly: harCookie.HTTPOnly}
+		if raw, ok := harCookie.Custom["sameSite"]; ok {
+			json.Unmarshal(raw, &ck.sameSite)
+		}
+		deleted := harCookie.Value == ""
+		if c, ok := parsed[harCookie.Name]; ok {
+			ck.secure = ck.secure || c.Secure
+			ck.httpOnly = ck.httpOnly || c.HttpOnly
+			if ck.sameSite == "" {
+				ck.sameSite = sameSite(c)
+			}
+			deleted = deleted || c.MaxAge < 0
+		}
+		if !deleted && !seen[ck.name] {
+			cookies = append(cookies, ck)
+		}
+		seen[ck.name] = true
+	}
+	for _, name := range headerOrder {
+		c := parsed[name]
+		if !seen[name] && c.Value != "" && c.MaxAge >= 0 {
+			cookies = append(cookies, cookie{name: name, secure: c.Secure, httpOnly: c.HttpOnly, sameSite: sameSite(c)})
+		}
+	}
+	return cookies
+}
+
+// sameSite returns the SameSite attribute of a parsed cookie
+func sameSite(c *http.Cookie) string {
+	switch c.SameSite {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	}
+	return ""
+}
+
+// responseHeaders indexes response headers by lower-case name, joining
+// repeats with commas
+func responseHeaders(entry har.HAREntry) map[string]string {
+	headers := make(map[string]string)
+	for _, header := range entry.Response.Headers {
+		name := strings.ToLower(header.Name)
+		if value, ok := headers[name]; ok {
+			headers[name] = value + ", " + header.Value
+		} else {
+			headers[name] = header.Value
+		}
+	}
+	return headers
+}
+
+// Summary is one rule across a capture
+type Summary struct {
+	Rule      string
+	Severity  severity.Level
+	Responses int      // entries with the issue
+	Hosts     []string // hosts with the issue, sorted
+	First     int      // first entry with the issue
+}
+
+// Summarize groups issues by rule, most severe and widespread first
+func Summarize(issues []Issue) []Summary {
+	byRule := make(map[string]*Summary)
+	entries := make(map[string]map[int]bool)
+	hosts := make(map[string]map[string]bool)
+	var rules []string
+	for _, issue := range issues {
+		s, ok := byRule[issue.Rule]
+		if !ok {
+			s = &Summary{Rule: issue.Rule, Severity: issue.Severity, First: issue.Entry}
+			byRule[issue.Rule] = s
+			entries[issue.Rule] = make(map[int]bool)
+			hosts[issue.Rule] = make(map[string]bool)
+			rules = append(rules, issue.Rule)
+		}
+		if issue.Entry < s.First {
+			s.First = issue.Entry
+		}
+		if !entries[issue.Rule][issue.Entry] {
+			entries[issue.Rule][issue.Entry] = true
+			s.Responses++
+		}
+		if !hosts[issue.Rule][issue.Host] {
+			hosts[issue.Rule][issue.Host] = true
+			s.Hosts = append(s.Hosts, issue.Host)
+		}
+	}
+
+	summaries := make([]Summary, 0, len(rules))
+	for _, rule := range rules {
+		s := byRule[rule]
+		sort.Strings(s.Hosts)
+		summaries = append(summaries, *s)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		if summaries[i].Severity != summaries[j].Severity {
+			return summaries[i].Severity > summaries[j].Severity
+		}
+		return summaries[i].Responses > summaries[j].Responses
+	})
+	return summaries
+}
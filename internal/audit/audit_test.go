@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/severity"
+)
+
+func response(url, mimeType string, headers ...string) har.HAREntry {
+	entry := har.HAREntry{
+		Pageref:  "page_1",
+		Request:  har.HARRequest{Method: "GET", URL: url},
+		Response: har.HARResponse{Status: 200, Content: har.HARContent{MimeType: mimeType}},
+	}
+	entry.Response.Headers = append(entry.Response.Headers, har.HARHeader{Name: "Content-Type", Value: mimeType})
+	for i := 0; i+1 < len(headers); i += 2 {
+		entry.Response.Headers = append(entry.Response.Headers, har.HARHeader{Name: headers[i], Value: headers[i+1]})
+	}
+	return entry
+}
+
+func rules(issues []Issue, entry int) map[string]Issue {
+	found := make(map[string]Issue)
+	for _, issue := range issues {
+		if issue.Entry == entry {
+			found[issue.Rule] = issue
+		}
+	}
+	return found
+}
+
+func TestEntries(t *testing.T) {
+	sessionCookie := har.HARCookie{Name: "session", Value: "s1", Secure: true, HTTPOnly: true}
+	sessionCookie.Custom = map[string]json.RawMessage{"sameSite": json.RawMessage(`"Lax"`)}
+	page := response("https://www.example.com/", "text/html",
+		"Strict-Transport-Security", "max-age=31536000; includeSubDomains",
+		"Content-Security-Policy", "default-src 'self'; script-src 'self' 'unsafe-inline' 'nonce-abc'",
+		"X-Content-Type-Options", "nosniff",
+		"Referrer-Policy", "strict-origin-when-cross-origin",
+		"Set-Cookie", "session=s1; Secure; HttpOnly",
+		"Set-Cookie", "prefs=dark",
+		"Set-Cookie", "old=; Max-Age=0")
+	page.Response.Cookies = []har.HARCookie{sessionCookie}
+
+	entries := []har.HAREntry{
+		page,
+		response("https://api.example.com/me", "application/json",
+			"Strict-Transport-Security", "max-age=300",
+			"Access-Control-Allow-Origin", "*",
+			"Access-Control-Allow-Credentials", "true"),
+		response("http://cdn.example.com/logo.png", "image/png"),
+		response("https://legacy.example.com/", "text/html",
+			"Content-Security-Policy", "script-src * 'unsafe-eval'",
+			"X-Content-Type-Options", "sniff",
+			"Referrer-Policy", "no-referrer, unsafe-url"),
+		response("http://localhost:3000/", "text/html"),
+	}
+	entries[3].Pageref = "page_2"
+	issues := Entries(entries, []int{0, 1, 2, 3, 4})
+
+	first := rules(issues, 0)
+	if len(first) != 3 || first["cookie without Secure"].Detail != "prefs" || first["cookie without HttpOnly"].Detail != "prefs" || first["cookie without SameSite"].Detail != "prefs" {
+		t.Errorf("the page should only report the prefs cookie: %+v", first)
+	}
+
+	api := rules(issues, 1)
+	for _, rule := range []string{"HSTS max-age under 180 days", "CORS wildcard with credentials", "X-Content-Type-Options missing"} {
+		if _, ok := api[rule]; !ok {
+			t.Errorf("API response: missing %q in %+v", rule, api)
+		}
+	}
+	if _, ok := api["CSP missing"]; ok {
+		t.Error("CSP is only checked on documents")
+	}
+
+	if image := rules(issues, 2); len(image) != 1 || image["mixed content"].Host != "cdn.example.com" {
+		t.Errorf("expected mixed content only, got %+v", image)
+	}
+
+	legacy := rules(issues, 3)
+	for _, rule := range []string{"HSTS missing", "CSP allows eval", "CSP allows scripts from any host", "X-Content-Type-Options not nosniff", "Referrer-Policy leaks full URLs"} {
+		if _, ok := legacy[rule]; !ok {
+			t.Errorf("legacy page: missing %q in %+v", rule, legacy)
+		}
+	}
+
+	if local := rules(issues, 4); len(local) != 3 {
+		t.Errorf("localhost may use plain HTTP and skip HSTS, got %+v", local)
+	}
+
+	for i := 1; i < len(issues); i++ {
+		if issues[i].Severity > issues[i-1].Severity {
+			t.Fatal("issues are not sorted by severity")
+		}
+	}
+}
+
+func TestSameSiteNone(t *testing.T) {
+	entry := response("https://example.com/track", "image/gif", "Set-Cookie", "id=1; SameSite=None; HttpOnly")
+	found := rules(New(nil).Entry(0, entry), 0)
+	if len(found) != 2 || found["SameSite=None cookie without Secure"].Severity != severity.Medium {
+		t.Errorf("issues = %+v", found)
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	issues := []Issue{
+		{Entry: 4, Host: "b.example.com", Rule: "CSP missing", Severity: severity.Medium},
+		{Entry: 2, Host: "a.example.com", Rule: "CSP missing", Severity: severity.Medium},
+		{Entry: 2, Host: "a.example.com", Rule: "cookie without HttpOnly", Severity: severity.Low, Detail: "a"},
+		{Entry: 2, Host: "a.example.com", Rule: "cookie without HttpOnly", Severity: severity.Low, Detail: "b"},
+		{Entry: 7, Host: "a.example.com", Rule: "mixed content", Severity: severity.High},
+	}
+	summaries := Summarize(issues)
+	var got []string
+	for _, s := range summaries {
+		got = append(got, s.Rule+" "+strings.Join(s.Hosts, ","))
+	}
+	want := "mixed content a.example.com|CSP missing a.example.com,b.example.com|cookie without HttpOnly a.example.com"
+	if strings.Join(got, "|") != want {
+		t.Errorf("summaries = %v", got)
+	}
+	if summaries[1].Responses != 2 || summaries[1].First != 2 || summaries[2].Responses != 1 {
+		t.Errorf("counts = %+v", summaries)
+	}
+}
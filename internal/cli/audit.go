@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/audit"
+	"github.com/cnharrison/har-tui/internal/severity"
+)
+
+// auditRow is one rule in the audit report
+type auditRow struct {
+	Severity  string   `json:"severity"`
+	Rule      string   `json:"rule"`
+	Responses int      `json:"responses"`
+	Hosts     []string `json:"hosts"`
+	First     int      `json:"first"`
+}
+
+// issueRow is one issue printed by audit --entries
+type issueRow struct {
+	Index    int    `json:"index"`
+	Severity string `json:"severity"`
+	Host     string `json:"host"`
+	Rule     string `json:"rule"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+// runAudit checks the entries matching the filters for missing or weak
+// security headers, insecure cookies and mixed content
+func runAudit(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("audit", "<file.har>", stderr)
+	filters := addFilterFlags(fs)
+	entries := fs.Bool("entries", false, "list every issue instead of a summary of each rule")
+	failOn := fs.String("fail-on", failNever, "exit 1 when an issue has this severity or higher: low, medium, high, critical or none")
+	format := fs.String("format", formatTable, "output format: table, json or ndjson")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	state, err := filters.state()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	failAt := severity.Critical + 1
+	if *failOn != failNever {
+		if failAt, err = severity.Parse(*failOn); err != nil {
+			fmt.Fprintf(stderr, "Error: --fail-on: %v or %s\n", err, failNever)
+			return exitUsage
+		}
+	}
+
+	harFile, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	issues := audit.Entries(harFile.Log.Entries, state.FilterEntries(harFile.Log.Entries))
+
+	if *entries {
+		err = writeIssues(stdout, issues, *format)
+	} else {
+		err = writeAudit(stdout, audit.Summarize(issues), *format)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	for _, issue := range issues {
+		if issue.Severity >= failAt {
+			return exitError
+		}
+	}
+	return exitOK
+}
+
+// writeAudit prints one row per rule, most severe first
+func writeAudit(w io.Writer, summaries []audit.Summary, format string) error {
+	rows := []auditRow{}
+	for _, s := range summaries {
+		rows = append(rows, auditRow{Severity: s.Severity.String(), Rule: s.Rule, Responses: s.Responses, Hosts: s.Hosts, First: s.First})
+	}
+	switch format {
+	case formatJSON:
+		return writeJSON(w, rows, format)
+	case formatNDJSON:
+		for _, row := range rows {
+			if err := writeJSON(w, row, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "No issues")
+		return err
+	}
+	table := newTable(w)
+	fmt.Fprintln(table, "SEVERITY\tRULE\tRESPONSES\tFIRST\tHOSTS")
+	for _, row := range rows {
+		fmt.Fprintf(table, "%s\t%s\t%d\t#%d\t%s\n", row.Severity, row.Rule, row.Responses, row.First, strings.Join(row.Hosts, ", "))
+	}
+	return table.Flush()
+}
+
+// writeIssues prints every issue, most severe first
+func writeIssues(w io.Writer, issues []audit.Issue, format string) error {
+	rows := []issueRow{}
+	for _, issue := range issues {
+		rows = append(rows, issueRow{Index: issue.Entry, Severity: issue.Severity.String(), Host: issue.Host, Rule: issue.Rule, Detail: issue.Detail})
+	}
+	switch format {
+	case formatJSON:
+		return writeJSON(w, rows, format)
+	case formatNDJSON:
+		for _, row := range rows {
+			if err := writeJSON(w, row, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "No issues")
+		return err
+	}
+	table := newTable(w)
+	fmt.Fprintln(table, "SEVERITY\t#\tHOST\tRULE\tDETAIL")
+	for _, row := range rows {
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\n", row.Severity, row.Index, row.Host, row.Rule, row.Detail)
+	}
+	return table.Flush()
+}
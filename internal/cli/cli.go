@@ -0,0 +1,330 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/cnharrison/har-tui/internal/env"
+	"github.com/cnharrison/har-tui/internal/filter"
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// Exit codes returned by Run
+const (
+	exitOK    = 0
+	exitError = 1
+	exitUsage = 2
+)
+
+// Output formats for list, show and stats
+const (
+	formatTable  = "table"
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+)
+
+// command is a non-interactive subcommand
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+// commands returns the available subcommands in help order
+func commands() []command {
+	return []command{
+		{"list", "List entries matching the filters", runList},
+		{"show", "Print one entry, or one part of it", runShow},
+		{"stats", "Summarize the entries matching the filters", runStats},
+		{"export", "Export entries as code snippets, markdown or HAR", runExport},
+		{"diff", "Compare two HAR files request by request", runDiff},
+		{"replay", "Replay entries and check the responses match the recording", runReplay},
+		{"serve", "Serve recorded responses from a local mock server", runServe},
+		{"record", "Record traffic through a local HTTP(S) proxy", runRecord},
+		{"sanitize", "Mask secrets and personal data before sharing a HAR", runSanitize},
+		{"scan", "Find leaked secrets and personal data, failing CI on severe ones", runScan},
+		{"audit", "Check security headers, cookies and mixed content", runAudit},
+	}
+}
+
+// IsCommand reports whether name is a CLI subcommand rather than a HAR file
+func IsCommand(name string) bool {
+	for _, cmd := range commands() {
+		if cmd.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Run executes a subcommand (args[0]) and returns the process exit code
+func Run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		PrintUsage(stderr)
+		return exitUsage
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+
+	fmt.Fprintf(stderr, "Unknown command: %s\n\n", args[0])
+	PrintUsage(stderr)
+	return exitUsage
+}
+
+// PrintUsage prints the top-level usage including subcommands
+func PrintUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: har-tui <file.har> [more.har...]")
+	fmt.Fprintln(w, "       har-tui -f <capture.har|capture.ndjson>")
+	fmt.Fprintln(w, "       har-tui <command> [flags] <file.har>")
+	fmt.Fprintln(w, "\nCommands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(w, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w, "\nHAR files may be gzip, zstd or bzip2 compressed, or NDJSON with one entry per line.")
+	fmt.Fprintln(w, "mitmproxy flows, Burp XML, Charles .chlsj and Fiddler .saz captures are converted,")
+	fmt.Fprintln(w, "and so are curl commands and Postman v2.1 collections.")
+	fmt.Fprintln(w, "Use - to read from stdin, and -f to keep following a file as it is written.")
+	fmt.Fprintln(w, "Run 'har-tui <command> -h' for command flags.")
+}
+
+// filterFlags holds the filter flags shared by list, stats, export, replay, serve, sanitize, scan and audit
+type filterFlags struct {
+	requestType   string
+	errorsOnly    bool
+	search        string
+	page          string
+	slowest       bool
+	regex         bool
+	caseSensitive bool
+	scope         string
+}
+
+// addFilterFlags registers the shared filter flags on fs
+func addFilterFlags(fs *flag.FlagSet) *filterFlags {
+	f := &filterFlags{}
+	fs.StringVar(&f.requestType, "type", "all", "request type: "+strings.Join(filter.GetTypeFilters(), ", "))
+	fs.BoolVar(&f.errorsOnly, "errors", false, "only show errors (4xx/5xx and failed requests)")
+	fs.StringVar(&f.search, "search", "", "search text or query, e.g. 'status:>=400 host:*.example.com'")
+	fs.StringVar(&f.page, "page", "", "only entries belonging to this page id (pageref)")
+	fs.BoolVar(&f.slowest, "slowest", false, "sort by slowest requests first")
+	fs.BoolVar(&f.regex, "regex", false, "treat --search as an RE2 regular expression")
+	fs.BoolVar(&f.caseSensitive, "case-sensitive", false, "match --search text case-sensitively")
+	fs.StringVar(&f.scope, "scope", filter.ScopeAll, "fields plain search text matches: "+strings.Join(filter.GetSearchScopes(), ", "))
+	return f
+}
+
+// state converts the flags into a FilterState
+func (f *filterFlags) state() (*filter.FilterState, error) {
+	valid := false
+	for _, t := range filter.GetTypeFilters() {
+		if t == f.requestType {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return nil, fmt.Errorf("unknown type %q (expected one of: %s)", f.requestType, strings.Join(filter.GetTypeFilters(), ", "))
+	}
+
+	validScope := false
+	for _, scope := range filter.GetSearchScopes() {
+		if scope == f.scope {
+			validScope = true
+			break
+		}
+	}
+	if !validScope {
+		return nil, fmt.Errorf("unknown scope %q (expected one of: %s)", f.scope, strings.Join(filter.GetSearchScopes(), ", "))
+	}
+
+	state := filter.NewFilterState()
+	state.SetTypeFilter(f.requestType)
+	state.SetTextFilter(f.search)
+	state.SetPageFilter(f.page)
+	state.ShowErrorsOnly = f.errorsOnly
+	state.SortBySlowest = f.slowest
+	state.SearchRegex = f.regex
+	state.SearchCaseSensitive = f.caseSensitive
+	state.SearchScope = f.scope
+	if err := state.QueryError(); err != nil {
+		return nil, fmt.Errorf("invalid --search query: %w", err)
+	}
+	return state, nil
+}
+
+// envFlags selects an environment from the config file
+type envFlags struct {
+	name   string
+	config string
+}
+
+// addEnvFlags registers the environment flags on fs
+func addEnvFlags(fs *flag.FlagSet) *envFlags {
+	f := &envFlags{}
+	fs.StringVar(&f.name, "env", "", "environment from the config file to rewrite requests for, e.g. local")
+	fs.StringVar(&f.config, "config", "", "config file (default $"+env.ConfigEnvVar+" or har-tui/config.json in the user config directory)")
+	return f
+}
+
+// environment loads the selected environment, nil when --env isn't set
+func (f *envFlags) environment() (*env.Environment, error) {
+	if f.name == "" {
+		return nil, nil
+	}
+	var config *env.Config
+	var err error
+	if f.config != "" {
+		config, err = env.Load(f.config)
+	} else {
+		config, err = env.LoadDefault()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config.Get(f.name)
+}
+
+// parseArgs parses flags that may appear before, between or after positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		if fs.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, fs.Arg(0))
+		args = fs.Args()[1:]
+	}
+}
+
+// newFlagSet creates a flag set whose usage names the command and its arguments
+func newFlagSet(name, arguments string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: har-tui %s [flags] %s\n\nFlags:\n", name, arguments)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// loadHAR loads the HAR file named on the command line
+func loadHAR(path string) (*har.HARFile, error) {
+	harFile, err := har.LoadHARFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("loading %s: %w", path, err)
+	}
+	return harFile, nil
+}
+
+// validateFormat checks an output format flag
+func validateFormat(format string) error {
+	switch format {
+	case formatTable, formatJSON, formatNDJSON:
+		return nil
+	}
+	return fmt.Errorf("unknown format %q (expected table, json or ndjson)", format)
+}
+
+// writeJSON writes v as indented JSON (or a single line for NDJSON)
+func writeJSON(w io.Writer, v interface{}, format string) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if format != formatNDJSON {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder.Encode(v)
+}
+
+// newTable returns a tabwriter for aligned table output
+func newTable(w io.Writer) *tabwriter.Writer {
+	return tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+}
+
+// entryRow is the summary of an entry printed by list and stats
+type entryRow struct {
+	Index   int     `json:"index"`
+	Method  string  `json:"method"`
+	Status  int     `json:"status"`
+	Type    string  `json:"type"`
+	Time    float64 `json:"time"`
+	Size    int     `json:"size"`
+	URL     string  `json:"url"`
+	Pageref string  `json:"pageref,omitempty"`
+}
+
+// newEntryRow summarizes the entry at index
+func newEntryRow(entry har.HAREntry, index int) entryRow {
+	return entryRow{
+		Index:   index,
+		Method:  entry.Request.Method,
+		Status:  entry.Response.Status,
+		Type:    har.GetRequestType(entry),
+		Time:    entry.Time,
+		Size:    entry.Response.Content.Size,
+		URL:     entry.Request.URL,
+		Pageref: entry.Pageref,
+	}
+}
+
+// writeRows writes entry summaries in the requested format
+func writeRows(w io.Writer, rows []entryRow, format string) error {
+	switch format {
+	case formatJSON:
+		if rows == nil {
+			rows = []entryRow{}
+		}
+		return writeJSON(w, rows, format)
+	case formatNDJSON:
+		for _, row := range rows {
+			if err := writeJSON(w, row, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	table := newTable(w)
+	fmt.Fprintln(table, "#\tMETHOD\tSTATUS\tTYPE\tTIME\tSIZE\tURL")
+	for _, row := range rows {
+		fmt.Fprintf(table, "%d\t%s\t%d\t%s\t%.0fms\t%s\t%s\n",
+			row.Index, row.Method, row.Status, row.Type, row.Time, formatSize(row.Size), row.URL)
+	}
+	return table.Flush()
+}
+
+// formatSize formats a byte count in human readable form
+func formatSize(bytes int) string {
+	if bytes < 0 {
+		return "-"
+	}
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%dB", bytes)
+	}
+	div, exp := int64(unit), 0
+	for n := int64(bytes) / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%cB", float64(bytes)/float64(div), "KMGTPE"[exp])
+}
+
+// hostOf returns the host of a URL, or the URL itself if it doesn't parse
+func hostOf(rawURL string) string {
+	if u, err := url.Parse(rawURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return rawURL
+}
@@ -0,0 +1,441 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+const testHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "test", "version": "1.0"},
+    "pages": [
+      {"startedDateTime": "2024-05-01T10:00:00.000Z", "id": "page_1", "title": "home", "pageTimings": {}}
+    ],
+    "entries": [
+      {
+        "pageref": "page_1",
+        "startedDateTime": "2024-05-01T10:00:00.000Z",
+        "time": 50,
+        "request": {"method": "GET", "url": "https://example.com/", "httpVersion": "HTTP/1.1", "headers": [], "queryString": [], "cookies": [], "headersSize": -1, "bodySize": 0},
+        "response": {"status": 200, "statusText": "OK", "httpVersion": "HTTP/1.1", "headers": [], "cookies": [],
+          "content": {"size": 5, "mimeType": "text/html", "text": "aGVsbG8=", "encoding": "base64"},
+          "redirectURL": "", "headersSize": -1, "bodySize": 5},
+        "cache": {},
+        "timings": {"send": 1, "wait": 48, "receive": 1}
+      },
+      {
+        "startedDateTime": "2024-05-01T10:00:01.000Z",
+        "time": 300,
+        "request": {"method": "POST", "url": "https://api.example.com/login", "httpVersion": "HTTP/1.1",
+          "headers": [{"name": "Content-Type", "value": "application/json"}], "queryString": [], "cookies": [],
+          "postData": {"mimeType": "application/json", "text": "{\"user\":\"a\"}"}, "headersSize": -1, "bodySize": 12},
+        "response": {"status": 500, "statusText": "Internal Server Error", "httpVersion": "HTTP/1.1", "headers": [], "cookies": [],
+          "content": {"size": 2, "mimeType": "application/json", "text": "{}"},
+          "redirectURL": "", "headersSize": -1, "bodySize": 2},
+        "cache": {},
+        "timings": {"send": 1, "wait": 298, "receive": 1}
+      }
+    ]
+  }
+}`
+
+func writeTestHAR(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.har")
+	if err := os.WriteFile(path, []byte(testHAR), 0644); err != nil {
+		t.Fatalf("failed to write HAR: %v", err)
+	}
+	return path
+}
+
+func run(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestListFormats(t *testing.T) {
+	path := writeTestHAR(t)
+
+	out, _, code := run(t, "list", path)
+	if code != exitOK {
+		t.Fatalf("list exited %d", code)
+	}
+	if !strings.Contains(out, "https://example.com/") || !strings.Contains(out, "https://api.example.com/login") {
+		t.Errorf("table output missing entries:\n%s", out)
+	}
+
+	out, _, code = run(t, "list", "--format", "json", path, "--errors")
+	if code != exitOK {
+		t.Fatalf("list --format json exited %d", code)
+	}
+	var rows []entryRow
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(rows) != 1 || rows[0].Index != 1 || rows[0].Status != 500 {
+		t.Errorf("expected only the 500 entry at index 1, got %+v", rows)
+	}
+
+	out, _, _ = run(t, "list", "--format", "ndjson", path)
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 {
+		t.Errorf("expected 2 NDJSON lines, got %d:\n%s", len(lines), out)
+	}
+
+	out, _, _ = run(t, "list", "--format", "json", "--page", "page_1", path)
+	if err := json.Unmarshal([]byte(out), &rows); err != nil || len(rows) != 1 || rows[0].Index != 0 {
+		t.Errorf("expected only the page_1 entry, got %+v (%v)", rows, err)
+	}
+
+	// A word with a colon that isn't a field is searched as text
+	out, stderr, code := run(t, "list", "--format", "json", "--search", "localhost:8080", path)
+	if err := json.Unmarshal([]byte(out), &rows); code != exitOK || err != nil || len(rows) != 0 {
+		t.Errorf("expected no rows, got %+v (exit %d: %s)", rows, code, stderr)
+	}
+}
+
+func TestShowParts(t *testing.T) {
+	path := writeTestHAR(t)
+
+	out, _, code := run(t, "show", "0", "--part", "response-body", path)
+	if code != exitOK || out != "hello" {
+		t.Errorf("expected decoded body %q, got %q (exit %d)", "hello", out, code)
+	}
+
+	out, _, _ = run(t, "show", "1", path, "--part", "request-body")
+	if out != `{"user":"a"}` {
+		t.Errorf("unexpected request body %q", out)
+	}
+
+	out, _, _ = run(t, "show", "1", path, "--part", "curl")
+	if !strings.HasPrefix(out, "curl") || !strings.Contains(out, "api.example.com/login") {
+		t.Errorf("unexpected curl output %q", out)
+	}
+
+	if _, _, code := run(t, "show", "5", path); code != exitError {
+		t.Errorf("expected out of range index to fail, got exit %d", code)
+	}
+	if _, _, code := run(t, "show", "0", path, "--part", "bogus"); code != exitError {
+		t.Errorf("expected unknown part to fail, got exit %d", code)
+	}
+}
+
+func TestStatsJSON(t *testing.T) {
+	out, _, code := run(t, "stats", "--format", "json", writeTestHAR(t))
+	if code != exitOK {
+		t.Fatalf("stats exited %d", code)
+	}
+	var report statsReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if report.Entries != 2 || report.Errors != 1 || report.TotalBytes != 7 {
+		t.Errorf("unexpected totals: %+v", report)
+	}
+	if report.DurationMs != 1300 {
+		t.Errorf("expected duration 1300ms, got %v", report.DurationMs)
+	}
+	if len(report.SlowestFirst) != 2 || report.SlowestFirst[0].Index != 1 {
+		t.Errorf("expected the POST to be slowest, got %+v", report.SlowestFirst)
+	}
+}
+
+func TestExportHARToFile(t *testing.T) {
+	path := writeTestHAR(t)
+	output := filepath.Join(t.TempDir(), "out.har")
+
+	if _, stderr, code := run(t, "export", "--errors", "--out", output, path); code != exitOK {
+		t.Fatalf("export exited %d: %s", code, stderr)
+	}
+	out, _, code := run(t, "list", "--format", "json", output)
+	if code != exitOK {
+		t.Fatalf("exported file did not load")
+	}
+	var rows []entryRow
+	if err := json.Unmarshal([]byte(out), &rows); err != nil || len(rows) != 1 || rows[0].Status != 500 {
+		t.Errorf("expected only the error entry, got %+v (%v)", rows, err)
+	}
+}
+
+func TestExportWithEnvironment(t *testing.T) {
+	path := writeTestHAR(t)
+	config := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(config, []byte(`{"environments": {"local": {
+		"hosts": {"api.example.com": "http://localhost:8080"},
+		"headers": {"Authorization": "Bearer dev"}
+	}}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, stderr, code := run(t, "export", "--format", "curl", "--env", "local", "--config", config, path)
+	if code != exitOK {
+		t.Fatalf("export exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(out, "'http://localhost:8080/login'") || !strings.Contains(out, "'https://example.com/'") {
+		t.Errorf("expected only the api host to be rewritten:\n%s", out)
+	}
+	if strings.Count(out, "Authorization: Bearer dev") != 2 {
+		t.Errorf("expected the header override on every request:\n%s", out)
+	}
+
+	if _, _, code := run(t, "export", "--env", "prod", "--config", config, path); code != exitUsage {
+		t.Errorf("expected usage exit for an unknown environment, got %d", code)
+	}
+}
+
+func TestExportSnippet(t *testing.T) {
+	path := writeTestHAR(t)
+	out, stderr, code := run(t, "export", "--format", "python-requests", "--search", "login", path)
+	if code != exitOK {
+		t.Fatalf("export exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(out, "import requests") || strings.Count(out, "response = requests.") != 1 {
+		t.Errorf("expected one python snippet:\n%s", out)
+	}
+
+	_, stderr, code = run(t, "export", "--format", "cobol", path)
+	if code != exitUsage || !strings.Contains(stderr, "powershell") {
+		t.Errorf("expected a usage error listing the targets, got %d: %s", code, stderr)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	path := writeTestHAR(t)
+	config := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(config, []byte(`{"sanitize": {"jsonPaths": ["user"]}}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, stderr, code := run(t, "sanitize", "--config", config, "--report", "--format", "json", path)
+	if code != exitOK {
+		t.Fatalf("sanitize --report exited %d: %s", code, stderr)
+	}
+	var rows []redactionRow
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(rows) != 1 || rows[0].Index != 1 || rows[0].Location != "request body user" || rows[0].Value != "a" {
+		t.Errorf("expected the user field to be reported, got %+v", rows)
+	}
+
+	out, stderr, code = run(t, "sanitize", "--config", config, "--search", "login", path)
+	if code != exitOK {
+		t.Fatalf("sanitize exited %d: %s", code, stderr)
+	}
+	if !strings.Contains(out, `{\"user\":\"REDACTED\"}`) || strings.Contains(out, `\"a\"`) {
+		t.Errorf("expected a sanitized HAR:\n%s", out)
+	}
+
+	out, _, code = run(t, "export", "--format", "curl", "--sanitize", "--config", config, path)
+	if code != exitOK || !strings.Contains(out, `{"user":"REDACTED"}`) {
+		t.Errorf("export --sanitize should mask the body, got %d:\n%s", code, out)
+	}
+	out, _, _ = run(t, "show", "--part", "request-body", "--sanitize", "--config", config, "1", path)
+	if out != `{"user":"REDACTED"}` {
+		t.Errorf("show --sanitize printed %q", out)
+	}
+}
+
+func TestScan(t *testing.T) {
+	leaky := strings.Replace(testHAR, `"url": "https://example.com/"`, `"url": "https://example.com/?access_token=abcdef123456"`, 1)
+	path := filepath.Join(t.TempDir(), "leaky.har")
+	if err := os.WriteFile(path, []byte(leaky), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, code := run(t, "scan", path)
+	if code != exitError {
+		t.Errorf("a high finding should fail the scan, got %d", code)
+	}
+	if !strings.Contains(out, "query access_token") || !strings.Contains(out, "abcd… (12 chars)") || strings.Contains(out, "abcdef123456") {
+		t.Errorf("expected the token's location and a preview, not the value:\n%s", out)
+	}
+
+	out, _, code = run(t, "scan", "--fail-on", "critical", "--format", "json", path)
+	var rows []findingRow
+	if err := json.Unmarshal([]byte(out), &rows); err != nil || code != exitOK {
+		t.Fatalf("exit %d, invalid JSON: %v\n%s", code, err, out)
+	}
+	if len(rows) != 1 || rows[0].Index != 0 || rows[0].Severity != "high" || rows[0].Rule != "token in URL" {
+		t.Errorf("rows = %+v", rows)
+	}
+
+	if out, _, code := run(t, "scan", writeTestHAR(t)); code != exitOK || !strings.Contains(out, "No findings") {
+		t.Errorf("a clean capture should pass, got %d:\n%s", code, out)
+	}
+	if _, _, code := run(t, "scan", "--fail-on", "severe", path); code != exitUsage {
+		t.Errorf("expected a usage error, got %d", code)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	path := writeTestHAR(t)
+
+	out, stderr, code := run(t, "audit", "--format", "json", path)
+	if code != exitOK {
+		t.Fatalf("audit exited %d: %s", code, stderr)
+	}
+	var rows []auditRow
+	if err := json.Unmarshal([]byte(out), &rows); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	rules := map[string]auditRow{}
+	for _, row := range rows {
+		rules[row.Rule] = row
+	}
+	if len(rows) != 4 || rules["CSP missing"].Severity != "medium" || rules["HSTS missing"].Hosts[0] != "example.com" {
+		t.Errorf("expected the page's four missing headers, got %+v", rows)
+	}
+
+	out, _, _ = run(t, "audit", "--entries", path)
+	if !strings.Contains(out, "Referrer-Policy missing") || strings.Count(out, "example.com") != 4 {
+		t.Errorf("expected one line per issue:\n%s", out)
+	}
+	if _, _, code := run(t, "audit", "--fail-on", "medium", path); code != exitError {
+		t.Errorf("expected --fail-on medium to fail, got %d", code)
+	}
+	if out, _, code := run(t, "audit", "--search", "login", path); code != exitOK || !strings.Contains(out, "No issues") {
+		t.Errorf("the failed API call has no headers to audit, got %d:\n%s", code, out)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	before := writeTestHAR(t)
+	// The login call is fixed and slower, and a new request appears
+	fixed := strings.Replace(testHAR, `"status": 500, "statusText": "Internal Server Error"`, `"status": 200, "statusText": "OK"`, 1)
+	fixed = strings.Replace(fixed, `"time": 300`, `"time": 900`, 1)
+	fixed = strings.Replace(fixed, `"text": "{}"`, `"text": "{\"ok\":true}"`, 1)
+	fixed = strings.Replace(fixed, `"url": "https://example.com/"`, `"url": "https://example.com/?b=2&a=1"`, 1)
+	after := filepath.Join(t.TempDir(), "after.har")
+	if err := os.WriteFile(after, []byte(fixed), 0644); err != nil {
+		t.Fatalf("failed to write HAR: %v", err)
+	}
+
+	out, stderr, code := run(t, "diff", "--format", "json", before, after)
+	if code != exitOK {
+		t.Fatalf("diff exited %d: %s", code, stderr)
+	}
+	var report diffReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if report.Summary.Added != 1 || report.Summary.Removed != 1 || report.Summary.Changed != 1 || report.Summary.Regressions != 1 {
+		t.Errorf("unexpected summary: %+v", report.Summary)
+	}
+	var login diffRow
+	for _, row := range report.Entries {
+		if row.Change == "changed" {
+			login = row
+		}
+	}
+	if login.StatusBefore != 500 || login.StatusAfter != 200 || login.TimeDelta != 600 {
+		t.Errorf("unexpected login row: %+v", login)
+	}
+
+	out, _, code = run(t, "diff", "--entry", strconv.Itoa(login.Row), before, after)
+	if code != exitOK {
+		t.Fatalf("diff --entry exited %d", code)
+	}
+	if !strings.Contains(out, "Status: 500 -> 200") || !strings.Contains(out, `+  "ok": true`) {
+		t.Errorf("entry diff missing status or body change:\n%s", out)
+	}
+
+	if _, _, code := run(t, "diff", before); code != exitUsage {
+		t.Errorf("expected usage exit for a single file, got %d", code)
+	}
+}
+
+func TestReplay(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/login" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"token":"x"}`))
+			return
+		}
+		w.Header()["Content-Type"] = nil // the recording has no headers besides Date
+		w.Header().Set("Date", "Wed, 01 May 2024 10:00:00 GMT")
+		w.Write([]byte("hello"))
+	}))
+	defer server.Close()
+
+	har := strings.NewReplacer("https://example.com", server.URL, "https://api.example.com", server.URL).Replace(testHAR)
+	path := filepath.Join(t.TempDir(), "replay.har")
+	if err := os.WriteFile(path, []byte(har), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	out, _, code := run(t, "replay", "--entry", "0", path)
+	if code != exitOK || !strings.Contains(out, "PASS") {
+		t.Fatalf("expected the unchanged entry to pass (exit %d):\n%s", code, out)
+	}
+
+	out, _, code = run(t, "replay", "--format", "json", path)
+	if code != exitError {
+		t.Fatalf("expected a failing replay to exit %d, got %d", exitError, code)
+	}
+	var report replayReport
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if report.Passed != 1 || report.Failed != 1 || report.Entries[1].Report.StatusAfter != 200 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(report.Entries[1].Report.BodyChanges) != 1 || report.Entries[1].Report.BodyChanges[0].Path != "token" {
+		t.Errorf("expected the token path to be added, got %+v", report.Entries[1].Report.BodyChanges)
+	}
+
+	out, _, _ = run(t, "replay", "--entry", "0", "--no-default-ignores", path)
+	if !strings.Contains(out, "FAIL") {
+		t.Errorf("expected the Date header to fail without default ignores:\n%s", out)
+	}
+
+	// An extracted value is expected to change, so it isn't reported as a body change
+	out, _, _ = run(t, "replay", "--extract", "token=json:token", "--concurrency", "2", "--format", "json", path)
+	report = replayReport{}
+	if err := json.Unmarshal([]byte(out), &report); err != nil {
+		t.Fatalf("invalid JSON: %v\n%s", err, out)
+	}
+	if len(report.Entries[1].Extracted) != 1 || report.Entries[1].Extracted[0] != "token" {
+		t.Errorf("expected the token to be extracted, got %+v", report.Entries[1])
+	}
+	if len(report.Entries[1].Report.BodyChanges) != 0 {
+		t.Errorf("expected the extracted path to be ignored, got %+v", report.Entries[1].Report.BodyChanges)
+	}
+
+	if _, _, code := run(t, "replay", "--extract", "token", path); code != exitUsage {
+		t.Errorf("expected usage exit for a malformed extractor, got %d", code)
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	if _, _, code := run(t, "nope"); code != exitUsage {
+		t.Errorf("expected usage exit for unknown command, got %d", code)
+	}
+	if _, _, code := run(t, "list"); code != exitUsage {
+		t.Errorf("expected usage exit for missing file, got %d", code)
+	}
+	if _, _, code := run(t, "list", "--type", "bogus", "x.har"); code != exitUsage {
+		t.Errorf("expected usage exit for unknown type, got %d", code)
+	}
+	if _, _, code := run(t, "serve", "--mode", "fuzzy", "x.har"); code != exitUsage {
+		t.Errorf("expected usage exit for unknown serve mode, got %d", code)
+	}
+	if _, _, code := run(t, "serve", "--match", "method,cookies", "x.har"); code != exitUsage {
+		t.Errorf("expected usage exit for unknown match part, got %d", code)
+	}
+	if _, _, code := run(t, "record", "x.har"); code != exitUsage {
+		t.Errorf("expected usage exit for a file passed to record, got %d", code)
+	}
+	if _, _, code := run(t, "record", "--headless", "--format", "json"); code != exitUsage {
+		t.Errorf("expected usage exit for unknown record format, got %d", code)
+	}
+}
@@ -0,0 +1,569 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/export"
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// runList prints one row per entry matching the filters
+func runList(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("list", "<file.har>", stderr)
+	filters := addFilterFlags(fs)
+	format := fs.String("format", formatTable, "output format: table, json or ndjson")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	state, err := filters.state()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	harFile, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	entries := harFile.Log.Entries
+	var rows []entryRow
+	for _, idx := range state.FilterEntries(entries) {
+		rows = append(rows, newEntryRow(entries[idx], idx))
+	}
+
+	if err := writeRows(stdout, rows, *format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// showParts lists the parts accepted by show --part
+var showParts = []string{
+	"summary", "request", "request-headers", "request-body",
+	"response", "response-headers", "response-body",
+	"cookies", "timings", "raw", "curl", "markdown",
+}
+
+// runShow prints a single entry, selected by the index shown in list
+func runShow(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("show", "<index> <file.har>", stderr)
+	part := fs.String("part", "summary", "part to print: "+strings.Join(showParts, ", "))
+	format := fs.String("format", formatTable, "output format: table, json or ndjson")
+	envs := addEnvFlags(fs)
+	sanitizeFlag := fs.Bool("sanitize", false, "mask secrets and personal data, using the config file's \"sanitize\" rules")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	index, err := strconv.Atoi(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: invalid index %q\n", positional[0])
+		return exitUsage
+	}
+	environment, err := envs.environment()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	sanitizer, err := loadSanitizer(*sanitizeFlag, envs.config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	harFile, err := loadHAR(positional[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	entries := harFile.Log.Entries
+	if index < 0 || index >= len(entries) {
+		fmt.Fprintf(stderr, "Error: index %d out of range (file has %d entries)\n", index, len(entries))
+		return exitError
+	}
+
+	if err := writePart(stdout, sanitizeEntry(sanitizer, environment.Apply(entries[index])), index, *part, *format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// writePart writes one part of an entry. Bodies are always written raw so
+// they can be piped into other tools; other parts honour the format
+func writePart(w io.Writer, entry har.HAREntry, index int, part, format string) error {
+	structured := format != formatTable
+
+	switch part {
+	case "summary":
+		if structured {
+			return writeJSON(w, newEntryRow(entry, index), format)
+		}
+		return writeSummary(w, entry, index)
+	case "request":
+		if structured {
+			return writeJSON(w, entry.Request, format)
+		}
+		return writeRequest(w, entry.Request)
+	case "request-headers":
+		return writeHeaders(w, entry.Request.Headers, format)
+	case "request-body":
+		if entry.Request.PostData != nil {
+			_, err := io.WriteString(w, entry.Request.PostData.Text)
+			return err
+		}
+		return nil
+	case "response":
+		if structured {
+			return writeJSON(w, entry.Response, format)
+		}
+		return writeResponse(w, entry.Response)
+	case "response-headers":
+		return writeHeaders(w, entry.Response.Headers, format)
+	case "response-body":
+		content := entry.Response.Content
+		_, err := io.WriteString(w, har.DecodeBase64(content.Text, content.Encoding))
+		return err
+	case "cookies":
+		if structured {
+			return writeJSON(w, map[string][]har.HARCookie{
+				"request":  nonNilCookies(entry.Request.Cookies),
+				"response": nonNilCookies(entry.Response.Cookies),
+			}, format)
+		}
+		return writeCookies(w, entry)
+	case "timings":
+		if structured {
+			return writeJSON(w, entry.Timings, format)
+		}
+		return writeTimings(w, entry)
+	case "raw":
+		return writeJSON(w, entry, format)
+	case "curl":
+		_, err := fmt.Fprintln(w, export.GenerateCurlCommand(entry))
+		return err
+	case "markdown":
+		_, err := io.WriteString(w, export.GenerateMarkdownSummary(entry))
+		return err
+	}
+	return fmt.Errorf("unknown part %q (expected one of: %s)", part, strings.Join(showParts, ", "))
+}
+
+func writeSummary(w io.Writer, entry har.HAREntry, index int) error {
+	table := newTable(w)
+	fmt.Fprintf(table, "Index:\t%d\n", index)
+	fmt.Fprintf(table, "Method:\t%s\n", entry.Request.Method)
+	fmt.Fprintf(table, "URL:\t%s\n", entry.Request.URL)
+	fmt.Fprintf(table, "Status:\t%d %s\n", entry.Response.Status, entry.Response.StatusText)
+	fmt.Fprintf(table, "Type:\t%s\n", har.GetRequestType(entry))
+	fmt.Fprintf(table, "MIME Type:\t%s\n", entry.Response.Content.MimeType)
+	fmt.Fprintf(table, "Size:\t%s\n", formatSize(entry.Response.Content.Size))
+	fmt.Fprintf(table, "Time:\t%.0fms\n", entry.Time)
+	fmt.Fprintf(table, "Started:\t%s\n", entry.StartedDateTime)
+	if entry.Pageref != "" {
+		fmt.Fprintf(table, "Page:\t%s\n", entry.Pageref)
+	}
+	if entry.ServerIPAddress != "" {
+		fmt.Fprintf(table, "Server IP:\t%s\n", entry.ServerIPAddress)
+	}
+	return table.Flush()
+}
+
+func writeRequest(w io.Writer, req har.HARRequest) error {
+	fmt.Fprintf(w, "%s %s %s\n", req.Method, req.URL, req.HTTPVersion)
+	for _, h := range req.Headers {
+		fmt.Fprintf(w, "%s: %s\n", h.Name, h.Value)
+	}
+	if req.PostData != nil && req.PostData.Text != "" {
+		fmt.Fprintf(w, "\n%s\n", req.PostData.Text)
+	}
+	return nil
+}
+
+func writeResponse(w io.Writer, resp har.HARResponse) error {
+	fmt.Fprintf(w, "%s %d %s\n", resp.HTTPVersion, resp.Status, resp.StatusText)
+	for _, h := range resp.Headers {
+		fmt.Fprintf(w, "%s: %s\n", h.Name, h.Value)
+	}
+	if body := har.DecodeBase64(resp.Content.Text, resp.Content.Encoding); body != "" {
+		fmt.Fprintf(w, "\n%s\n", body)
+	}
+	return nil
+}
+
+func writeHeaders(w io.Writer, headers []har.HARHeader, format string) error {
+	switch format {
+	case formatJSON:
+		if headers == nil {
+			headers = []har.HARHeader{}
+		}
+		return writeJSON(w, headers, format)
+	case formatNDJSON:
+		for _, h := range headers {
+			if err := writeJSON(w, h, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for _, h := range headers {
+		fmt.Fprintf(w, "%s: %s\n", h.Name, h.Value)
+	}
+	return nil
+}
+
+func writeCookies(w io.Writer, entry har.HAREntry) error {
+	table := newTable(w)
+	fmt.Fprintln(table, "DIRECTION\tNAME\tVALUE\tDOMAIN\tPATH")
+	for _, c := range entry.Request.Cookies {
+		fmt.Fprintf(table, "request\t%s\t%s\t%s\t%s\n", c.Name, c.Value, c.Domain, c.Path)
+	}
+	for _, c := range entry.Response.Cookies {
+		fmt.Fprintf(table, "response\t%s\t%s\t%s\t%s\n", c.Name, c.Value, c.Domain, c.Path)
+	}
+	return table.Flush()
+}
+
+func writeTimings(w io.Writer, entry har.HAREntry) error {
+	t := entry.Timings
+	table := newTable(w)
+	for _, phase := range []struct {
+		name  string
+		value float64
+	}{
+		{"Blocked", t.Blocked}, {"DNS", t.DNS}, {"Connect", t.Connect}, {"SSL", t.SSL},
+		{"Send", t.Send}, {"Wait", t.Wait}, {"Receive", t.Receive}, {"Total", entry.Time},
+	} {
+		fmt.Fprintf(table, "%s:\t%.2fms\n", phase.name, phase.value)
+	}
+	return table.Flush()
+}
+
+func nonNilCookies(cookies []har.HARCookie) []har.HARCookie {
+	if cookies == nil {
+		return []har.HARCookie{}
+	}
+	return cookies
+}
+
+// countItem is a key and how many entries had it
+type countItem struct {
+	Key   string `json:"key"`
+	Count int    `json:"count"`
+}
+
+// statsReport is the summary printed by stats
+type statsReport struct {
+	Entries      int         `json:"entries"`
+	Errors       int         `json:"errors"`
+	TotalBytes   int64       `json:"totalBytes"`
+	TotalTime    float64     `json:"totalTime"`
+	DurationMs   float64     `json:"durationMs"`
+	ByType       []countItem `json:"byType"`
+	ByStatus     []countItem `json:"byStatus"`
+	ByMethod     []countItem `json:"byMethod"`
+	TopHosts     []countItem `json:"topHosts"`
+	SlowestFirst []entryRow  `json:"slowest"`
+}
+
+// topHostCount and slowestCount cap the lists in the stats report
+const (
+	topHostCount = 10
+	slowestCount = 5
+)
+
+// runStats summarizes the entries matching the filters
+func runStats(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("stats", "<file.har>", stderr)
+	filters := addFilterFlags(fs)
+	format := fs.String("format", formatTable, "output format: table, json or ndjson")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	state, err := filters.state()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	harFile, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	report := buildStats(harFile.Log.Entries, state.FilterEntries(harFile.Log.Entries))
+	if *format != formatTable {
+		err = writeJSON(stdout, report, *format)
+	} else {
+		err = writeStats(stdout, report)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// buildStats computes the stats report for the given entry indices
+func buildStats(entries []har.HAREntry, indices []int) statsReport {
+	report := statsReport{Entries: len(indices)}
+	byType := map[string]int{}
+	byStatus := map[string]int{}
+	byMethod := map[string]int{}
+	byHost := map[string]int{}
+	var first, last float64
+	haveRange := false
+
+	for _, idx := range indices {
+		entry := entries[idx]
+		status := entry.Response.Status
+
+		byType[har.GetRequestType(entry)]++
+		byMethod[entry.Request.Method]++
+		byHost[hostOf(entry.Request.URL)]++
+		if status == 0 {
+			byStatus["failed"]++
+		} else {
+			byStatus[fmt.Sprintf("%dxx", status/100)]++
+		}
+		if status == 0 || status >= 400 {
+			report.Errors++
+		}
+		if entry.Response.Content.Size > 0 {
+			report.TotalBytes += int64(entry.Response.Content.Size)
+		}
+		report.TotalTime += entry.Time
+
+		if started, err := har.ParseHARDateTime(entry.StartedDateTime); err == nil {
+			start := float64(started.UnixNano()) / 1e6
+			end := start + entry.Time
+			if !haveRange || start < first {
+				first = start
+			}
+			if !haveRange || end > last {
+				last = end
+			}
+			haveRange = true
+		}
+	}
+	if haveRange {
+		report.DurationMs = last - first
+	}
+
+	report.ByType = sortedCounts(byType, 0)
+	report.ByStatus = sortedCounts(byStatus, 0)
+	report.ByMethod = sortedCounts(byMethod, 0)
+	report.TopHosts = sortedCounts(byHost, topHostCount)
+
+	slowest := append([]int(nil), indices...)
+	sort.SliceStable(slowest, func(i, j int) bool {
+		return entries[slowest[i]].Time > entries[slowest[j]].Time
+	})
+	if len(slowest) > slowestCount {
+		slowest = slowest[:slowestCount]
+	}
+	report.SlowestFirst = []entryRow{}
+	for _, idx := range slowest {
+		report.SlowestFirst = append(report.SlowestFirst, newEntryRow(entries[idx], idx))
+	}
+	return report
+}
+
+// sortedCounts orders counts by count descending then key, keeping at most limit (0 = all)
+func sortedCounts(counts map[string]int, limit int) []countItem {
+	items := make([]countItem, 0, len(counts))
+	for key, count := range counts {
+		items = append(items, countItem{Key: key, Count: count})
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Count != items[j].Count {
+			return items[i].Count > items[j].Count
+		}
+		return items[i].Key < items[j].Key
+	})
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	return items
+}
+
+func writeStats(w io.Writer, report statsReport) error {
+	table := newTable(w)
+	fmt.Fprintf(table, "Entries:\t%d\n", report.Entries)
+	fmt.Fprintf(table, "Errors:\t%d\n", report.Errors)
+	fmt.Fprintf(table, "Total size:\t%s\n", formatSize(int(report.TotalBytes)))
+	fmt.Fprintf(table, "Total time:\t%.0fms\n", report.TotalTime)
+	fmt.Fprintf(table, "Duration:\t%.0fms\n", report.DurationMs)
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	for _, section := range []struct {
+		title string
+		items []countItem
+	}{
+		{"By type", report.ByType},
+		{"By status", report.ByStatus},
+		{"By method", report.ByMethod},
+		{"Top hosts", report.TopHosts},
+	} {
+		fmt.Fprintf(w, "\n%s:\n", section.title)
+		table = newTable(w)
+		for _, item := range section.items {
+			fmt.Fprintf(table, "  %s\t%d\n", item.Key, item.Count)
+		}
+		if err := table.Flush(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintln(w, "\nSlowest:")
+	return writeRows(w, report.SlowestFirst, formatTable)
+}
+
+// runExport writes the entries matching the filters as curl commands, markdown or HAR
+func runExport(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("export", "<file.har>", stderr)
+	filters := addFilterFlags(fs)
+	format := fs.String("format", "har", "export format: markdown, har or a code snippet target ("+strings.Join(export.SnippetTargetNames(), ", ")+")")
+	output := fs.String("out", "", "write to this file instead of stdout")
+	envs := addEnvFlags(fs)
+	sanitizeFlag := fs.Bool("sanitize", false, "mask secrets and personal data, using the config file's \"sanitize\" rules")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	state, err := filters.state()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	// curl, like every other snippet target, is looked up here; it keeps its
+	// own case below to print one command per line
+	var snippetTarget export.SnippetTarget
+	if *format != "markdown" && *format != "har" {
+		if snippetTarget, err = export.FindSnippetTarget(*format); err != nil {
+			fmt.Fprintf(stderr, "Error: unknown format %q (expected markdown, har or one of: %s)\n", *format, strings.Join(export.SnippetTargetNames(), ", "))
+			return exitUsage
+		}
+	}
+
+	environment, err := envs.environment()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	sanitizer, err := loadSanitizer(*sanitizeFlag, envs.config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	harFile, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	indices := state.FilterEntries(harFile.Log.Entries)
+	// Entries are rewritten for the environment, then masked
+	prepare := func(idx int) har.HAREntry {
+		return sanitizeEntry(sanitizer, environment.Apply(harFile.Log.Entries[idx]))
+	}
+
+	var buf bytes.Buffer
+	switch *format {
+	case "curl":
+		for _, idx := range indices {
+			fmt.Fprintln(&buf, export.GenerateCurlCommand(prepare(idx)))
+		}
+	case "markdown":
+		for i, idx := range indices {
+			if i > 0 {
+				buf.WriteString("\n---\n\n")
+			}
+			buf.WriteString(export.GenerateMarkdownSummary(prepare(idx)))
+		}
+	case "har":
+		if sanitizer != nil {
+			harFile = sanitizer.File(harFile, indices)
+		}
+		if *output != "" {
+			if err := har.SaveFilteredHAR(harFile, indices, *output); err != nil {
+				fmt.Fprintf(stderr, "Error: %v\n", err)
+				return exitError
+			}
+			return exitOK
+		}
+		if err := har.WriteFilteredHAR(&buf, harFile, indices); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+	default:
+		for i, idx := range indices {
+			snippet := snippetTarget.Generate(prepare(idx))
+			if i > 0 {
+				buf.WriteString("\n")
+			}
+			buf.WriteString(snippet)
+			if !strings.HasSuffix(snippet, "\n") {
+				buf.WriteString("\n")
+			}
+		}
+	}
+
+	if *output != "" {
+		err = os.WriteFile(*output, buf.Bytes(), 0644)
+	} else {
+		_, err = stdout.Write(buf.Bytes())
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,292 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/diff"
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// diffContextLines is how many unchanged body lines surround each change
+const diffContextLines = 3
+
+// diffRow is one compared request printed by diff
+type diffRow struct {
+	Row          int     `json:"row"`
+	Change       string  `json:"change"`
+	Method       string  `json:"method"`
+	URL          string  `json:"url"`
+	Before       int     `json:"before"` // entry index, -1 when added
+	After        int     `json:"after"`  // entry index, -1 when removed
+	StatusBefore int     `json:"statusBefore,omitempty"`
+	StatusAfter  int     `json:"statusAfter,omitempty"`
+	TimeBefore   float64 `json:"timeBefore,omitempty"`
+	TimeAfter    float64 `json:"timeAfter,omitempty"`
+	TimeDelta    float64 `json:"timeDelta"`
+	SizeBefore   int     `json:"sizeBefore,omitempty"`
+	SizeAfter    int     `json:"sizeAfter,omitempty"`
+	SizeDelta    int     `json:"sizeDelta"`
+}
+
+// diffReport is the JSON output of diff
+type diffReport struct {
+	Before  string       `json:"before"`
+	After   string       `json:"after"`
+	Summary diff.Summary `json:"summary"`
+	Entries []diffRow    `json:"entries"`
+}
+
+// runDiff compares two HAR files request by request
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("diff", "<before.har> <after.har>", stderr)
+	format := fs.String("format", formatTable, "output format: table, json or ndjson")
+	threshold := fs.Duration("threshold", time.Duration(diff.DefaultTimeThreshold)*time.Millisecond, "ignore timing differences smaller than this")
+	all := fs.Bool("all", false, "include unchanged requests")
+	entry := fs.Int("entry", -1, "print the header and body diff of this row")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 2 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	before, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	after, err := loadHAR(positional[1])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	opts := diff.Options{TimeThreshold: float64(*threshold) / float64(time.Millisecond)}
+	result := diff.Compare(before.Log.Entries, after.Log.Entries, opts)
+
+	if *entry >= 0 {
+		if *entry >= len(result.Entries) {
+			fmt.Fprintf(stderr, "Error: row %d out of range (diff has %d rows)\n", *entry, len(result.Entries))
+			return exitError
+		}
+		err = writeEntryDiff(stdout, result, *entry, *format)
+	} else {
+		err = writeDiff(stdout, result, positional[0], positional[1], *all, *format)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// newDiffRow summarizes one compared request
+func newDiffRow(result *diff.Result, row int) diffRow {
+	d := result.Entries[row]
+	r := diffRow{Row: row, Change: string(d.Kind), Before: d.Before, After: d.After, TimeDelta: d.TimeDelta, SizeDelta: d.SizeDelta}
+	if d.Before >= 0 {
+		entry := result.Before[d.Before]
+		r.Method, r.URL = entry.Request.Method, entry.Request.URL
+		r.StatusBefore, r.TimeBefore, r.SizeBefore = entry.Response.Status, entry.Time, entry.Response.Content.Size
+	}
+	if d.After >= 0 {
+		entry := result.After[d.After]
+		r.Method, r.URL = entry.Request.Method, entry.Request.URL
+		r.StatusAfter, r.TimeAfter, r.SizeAfter = entry.Response.Status, entry.Time, entry.Response.Content.Size
+	}
+	return r
+}
+
+func writeDiff(w io.Writer, result *diff.Result, beforeName, afterName string, all bool, format string) error {
+	var rows []diffRow
+	for i, d := range result.Entries {
+		if all || d.Kind != diff.Unchanged {
+			rows = append(rows, newDiffRow(result, i))
+		}
+	}
+
+	switch format {
+	case formatJSON:
+		if rows == nil {
+			rows = []diffRow{}
+		}
+		return writeJSON(w, diffReport{Before: beforeName, After: afterName, Summary: result.Summary(), Entries: rows}, format)
+	case formatNDJSON:
+		for _, row := range rows {
+			if err := writeJSON(w, row, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	s := result.Summary()
+	fmt.Fprintf(w, "Before: %s (%d entries)\nAfter:  %s (%d entries)\n", beforeName, len(result.Before), afterName, len(result.After))
+	fmt.Fprintf(w, "Added %d, removed %d, changed %d, unchanged %d\n", s.Added, s.Removed, s.Changed, s.Unchanged)
+	fmt.Fprintf(w, "Status changes %d, slower %d, faster %d, time %s, size %s\n\n",
+		s.StatusChanges, s.Regressions, s.Improvements, formatTimeDelta(s.TimeDelta), formatSizeDelta(s.SizeDelta))
+	if len(rows) == 0 {
+		fmt.Fprintln(w, "No differences")
+		return nil
+	}
+
+	table := newTable(w)
+	fmt.Fprintln(table, "ROW\tCHANGE\tMETHOD\tSTATUS\tTIME\tSIZE\tURL")
+	for _, r := range rows {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Row, r.Change, r.Method,
+			beforeAfter(r, strconv.Itoa(r.StatusBefore), strconv.Itoa(r.StatusAfter)),
+			beforeAfter(r, fmt.Sprintf("%.0fms", r.TimeBefore), fmt.Sprintf("%.0fms", r.TimeAfter))+timeDeltaSuffix(r),
+			beforeAfter(r, formatSize(r.SizeBefore), formatSize(r.SizeAfter)),
+			r.URL)
+	}
+	return table.Flush()
+}
+
+// beforeAfter shows one side for added or removed requests, "a -> b" when changed, or a single value
+func beforeAfter(r diffRow, before, after string) string {
+	switch {
+	case r.Before < 0:
+		return after
+	case r.After < 0:
+		return before
+	case before != after:
+		return before + " -> " + after
+	}
+	return after
+}
+
+func timeDeltaSuffix(r diffRow) string {
+	if r.Before < 0 || r.After < 0 {
+		return ""
+	}
+	return " (" + formatTimeDelta(r.TimeDelta) + ")"
+}
+
+func formatTimeDelta(ms float64) string {
+	return fmt.Sprintf("%+.0fms", ms)
+}
+
+func formatSizeDelta(bytes int) string {
+	if bytes < 0 {
+		return "-" + formatSize(-bytes)
+	}
+	return "+" + formatSize(bytes)
+}
+
+// entryDiffReport is the JSON output of diff --entry
+type entryDiffReport struct {
+	Row             diffRow             `json:"row"`
+	RequestHeaders  []diff.HeaderChange `json:"requestHeaders"`
+	ResponseHeaders []diff.HeaderChange `json:"responseHeaders"`
+	RequestBody     []string            `json:"requestBody"`  // unified diff lines
+	ResponseBody    []string            `json:"responseBody"` // unified diff lines
+}
+
+// writeEntryDiff prints the header and body differences of one compared request
+func writeEntryDiff(w io.Writer, result *diff.Result, row int, format string) error {
+	d := result.Entries[row]
+	var before, after har.HAREntry
+	if d.Before >= 0 {
+		before = result.Before[d.Before]
+	}
+	if d.After >= 0 {
+		after = result.After[d.After]
+	}
+	detail := diff.CompareEntries(before, after)
+
+	r := newDiffRow(result, row)
+	if format != formatTable {
+		return writeJSON(w, entryDiffReport{
+			Row:             r,
+			RequestHeaders:  changedHeaders(detail.RequestHeaders),
+			ResponseHeaders: changedHeaders(detail.ResponseHeaders),
+			RequestBody:     unifiedLines(detail.RequestBody),
+			ResponseBody:    unifiedLines(detail.ResponseBody),
+		}, format)
+	}
+
+	fmt.Fprintf(w, "%s %s (%s)\n", r.Method, r.URL, r.Change)
+	fmt.Fprintf(w, "Status: %s\n", beforeAfter(r, strconv.Itoa(r.StatusBefore), strconv.Itoa(r.StatusAfter)))
+	fmt.Fprintf(w, "Time:   %s%s\n", beforeAfter(r, fmt.Sprintf("%.0fms", r.TimeBefore), fmt.Sprintf("%.0fms", r.TimeAfter)), timeDeltaSuffix(r))
+	fmt.Fprintf(w, "Size:   %s\n", beforeAfter(r, formatSize(r.SizeBefore), formatSize(r.SizeAfter)))
+
+	writeHeaderChanges(w, "Request headers", detail.RequestHeaders)
+	writeHeaderChanges(w, "Response headers", detail.ResponseHeaders)
+	writeBodyDiff(w, "Request body", detail.RequestBody)
+	writeBodyDiff(w, "Response body", detail.ResponseBody)
+	return nil
+}
+
+// changedHeaders drops unchanged headers
+func changedHeaders(changes []diff.HeaderChange) []diff.HeaderChange {
+	result := []diff.HeaderChange{}
+	for _, change := range changes {
+		if change.Kind != diff.Unchanged {
+			result = append(result, change)
+		}
+	}
+	return result
+}
+
+func writeHeaderChanges(w io.Writer, title string, changes []diff.HeaderChange) {
+	changes = changedHeaders(changes)
+	fmt.Fprintf(w, "\n%s:\n", title)
+	if len(changes) == 0 {
+		fmt.Fprintln(w, "  (no changes)")
+		return
+	}
+	for _, change := range changes {
+		switch change.Kind {
+		case diff.Added:
+			fmt.Fprintf(w, "+ %s: %s\n", change.Name, change.After)
+		case diff.Removed:
+			fmt.Fprintf(w, "- %s: %s\n", change.Name, change.Before)
+		default:
+			fmt.Fprintf(w, "- %s: %s\n+ %s: %s\n", change.Name, change.Before, change.Name, change.After)
+		}
+	}
+}
+
+// unifiedLines renders a body diff as unified diff lines with folded context
+func unifiedLines(body diff.BodyDiff) []string {
+	if body.Binary {
+		if body.Changed() {
+			return []string{fmt.Sprintf("binary bodies differ (%d -> %d bytes)", len(body.Before), len(body.After))}
+		}
+		return []string{}
+	}
+	lines := []string{}
+	if !diff.HasChanges(body.Lines) {
+		return lines
+	}
+	for _, line := range diff.Fold(body.Lines, diffContextLines) {
+		if line.Op == diff.OpSkip {
+			lines = append(lines, "@@ "+line.Text+" @@")
+		} else {
+			lines = append(lines, string(line.Op)+line.Text)
+		}
+	}
+	return lines
+}
+
+func writeBodyDiff(w io.Writer, title string, body diff.BodyDiff) {
+	fmt.Fprintf(w, "\n%s:\n", title)
+	lines := unifiedLines(body)
+	if len(lines) == 0 {
+		fmt.Fprintln(w, "  (no changes)")
+		return
+	}
+	for _, line := range lines {
+		fmt.Fprintln(w, line)
+	}
+}
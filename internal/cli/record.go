@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/proxy"
+	"github.com/cnharrison/har-tui/internal/ui"
+)
+
+// runRecord runs a recording proxy, showing the captured traffic live in the
+// TUI, or logging it with --headless, and saves the session on exit
+func runRecord(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("record", "", stderr)
+	listen := fs.String("listen", "127.0.0.1:8888", "address the proxy listens on")
+	out := fs.String("out", "", "save the recorded session to this HAR file on exit")
+	caDir := fs.String("ca-dir", "", "directory holding ca.pem and ca-key.pem, created on first use (default: the har-tui config directory)")
+	insecure := fs.Bool("insecure", false, "skip TLS certificate verification of the servers proxied to")
+	timeout := fs.Duration("timeout", proxy.DefaultTimeout, "timeout for each proxied request")
+	headless := fs.Bool("headless", false, "log captured requests instead of opening the TUI")
+	format := fs.String("format", formatTable, "log format with --headless: table or ndjson")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	if *format != formatTable && *format != formatNDJSON {
+		fmt.Fprintf(stderr, "Error: unknown format %q (expected table or ndjson)\n", *format)
+		return exitUsage
+	}
+
+	dir := *caDir
+	if dir == "" {
+		if dir, err = proxy.DefaultCADir(); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+	}
+	ca, err := proxy.LoadOrCreateCA(dir)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: loading CA: %v\n", err)
+		return exitError
+	}
+	listener, err := net.Listen("tcp", *listen)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	opts := proxy.Options{Timeout: *timeout, Insecure: *insecure}
+
+	if !*headless {
+		if err := ui.NewApplicationRecording(ca, listener, opts, *out).Run(); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	var mu sync.Mutex // serializes the log
+	loader := har.NewStreamingLoader()
+	loader.StartLive(proxy.Creator())
+	loader.SetCallbacks(func(entry har.HAREntry, index int) {
+		mu.Lock()
+		defer mu.Unlock()
+		writeRecorded(stdout, newEntryRow(entry, index), *format)
+	}, nil, nil, nil)
+	p := proxy.New(ca, opts, func(entry har.HAREntry) {
+		loader.AddEntry(entry)
+	}, func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		fmt.Fprintf(stderr, "Warning: %v\n", err)
+	})
+
+	fmt.Fprintf(stderr, "Recording through the proxy on %s (Ctrl-C to stop)\n", listener.Addr())
+	fmt.Fprintf(stderr, "Trust %s, or fetch it from http://%s/ca.pem, to record HTTPS\n", filepath.Join(dir, proxy.CertFile), listener.Addr())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		p.Close()
+	}()
+	if err := p.Serve(listener); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	harFile := loader.GetHARFile()
+	fmt.Fprintf(stderr, "\nRecorded %d requests\n", len(harFile.Log.Entries))
+	if *out == "" {
+		return exitOK
+	}
+	indices := make([]int, len(harFile.Log.Entries))
+	for i := range indices {
+		indices[i] = i
+	}
+	if err := har.SaveFilteredHAR(harFile, indices, *out); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	fmt.Fprintf(stderr, "Saved to %s\n", *out)
+	return exitOK
+}
+
+// writeRecorded logs one captured exchange
+func writeRecorded(w io.Writer, row entryRow, format string) {
+	if format == formatNDJSON {
+		writeJSON(w, row, format)
+		return
+	}
+	fmt.Fprintf(w, "%d  %s  %d  %.0fms  %s  %s\n", row.Index, row.Method, row.Status, row.Time, formatSize(row.Size), row.URL)
+}
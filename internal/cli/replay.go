@@ -0,0 +1,207 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/replay"
+)
+
+// listFlag collects a flag that may be repeated or given as a comma-separated list
+type listFlag []string
+
+func (l *listFlag) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *listFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// replayRow is the outcome of replaying one entry
+type replayRow struct {
+	Index       int            `json:"index"`
+	Method      string         `json:"method"`
+	URL         string         `json:"url"`
+	Time        float64        `json:"time,omitempty"` // of the replay
+	Error       string         `json:"error,omitempty"`
+	Report      *replay.Report `json:"report,omitempty"`
+	Substituted []string       `json:"substituted,omitempty"` // variables used in the request
+	Extracted   []string       `json:"extracted,omitempty"`   // variables set from the response
+}
+
+// replayReport is the JSON output of replay
+type replayReport struct {
+	Passed  int         `json:"passed"`
+	Failed  int         `json:"failed"`
+	Entries []replayRow `json:"entries"`
+}
+
+// runReplay sends the matching entries again, in order, and checks each live
+// response against the recorded one. It exits non-zero when any of them differ
+func runReplay(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("replay", "<file.har>", stderr)
+	filters := addFilterFlags(fs)
+	format := fs.String("format", formatTable, "output format: table, json or ndjson")
+	entry := fs.Int("entry", -1, "only replay the entry with this index (see list)")
+	var ignoreHeaders, ignorePaths, extracts listFlag
+	fs.Var(&extracts, "extract", "value to carry into later requests as name=json:path, name=header:Name or name=cookie:name (repeatable)")
+	concurrency := fs.Int("concurrency", 1, "requests in flight at once")
+	rate := fs.Float64("rate", 0, "most requests started per second (0 for no limit)")
+	fs.Var(&ignoreHeaders, "ignore-header", "response header to ignore, repeatable or comma-separated (added to the defaults)")
+	fs.Var(&ignorePaths, "ignore-path", "JSON body path to ignore, e.g. data.updatedAt; covers everything below it")
+	noDefaults := fs.Bool("no-default-ignores", false, "don't ignore headers such as date and etag by default")
+	timeout := fs.Duration("timeout", replay.DefaultTimeout, "timeout for each request")
+	insecure := fs.Bool("insecure", false, "skip TLS certificate verification")
+	envs := addEnvFlags(fs)
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	state, err := filters.state()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	var extractors []replay.Extractor
+	for _, spec := range extracts {
+		extractor, err := replay.ParseExtractor(spec)
+		if err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitUsage
+		}
+		extractors = append(extractors, extractor)
+	}
+	environment, err := envs.environment()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	harFile, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	entries := harFile.Log.Entries
+
+	indices := state.FilterEntries(entries)
+	if *entry >= 0 {
+		if *entry >= len(entries) {
+			fmt.Fprintf(stderr, "Error: entry %d out of range (file has %d entries)\n", *entry, len(entries))
+			return exitError
+		}
+		indices = []int{*entry}
+	}
+	// Rewritten requests are sent; their responses are still checked against the capture
+	targets := append([]har.HAREntry(nil), entries...)
+	for _, idx := range indices {
+		targets[idx] = environment.Apply(entries[idx])
+	}
+
+	verify := replay.VerifyOptions{IgnorePaths: ignorePaths}
+	if !*noDefaults {
+		verify = replay.DefaultVerifyOptions()
+		verify.IgnorePaths = ignorePaths
+	}
+	verify.IgnoreHeaders = append(verify.IgnoreHeaders, ignoreHeaders...)
+
+	opts := replay.BatchOptions{
+		Options:     replay.Options{Timeout: *timeout, Insecure: *insecure},
+		Concurrency: *concurrency,
+		Rate:        *rate,
+		Extractors:  extractors,
+		Verify:      verify,
+	}
+	report := replayReport{Entries: []replayRow{}}
+	for _, result := range replay.RunBatch(context.Background(), targets, indices, opts, nil) {
+		recorded := targets[result.Index]
+		row := replayRow{Index: result.Index, Method: recorded.Request.Method, URL: recorded.Request.URL,
+			Substituted: result.Substituted, Extracted: result.Extracted}
+		if result.Err != nil {
+			row.Error = result.Err.Error()
+		} else {
+			row.Time, row.Report = result.Replayed.Time, &result.Report
+		}
+		if result.Passed() {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Entries = append(report.Entries, row)
+	}
+
+	if err := writeReplay(stdout, report, *format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	if report.Failed > 0 {
+		return exitError
+	}
+	return exitOK
+}
+
+func writeReplay(w io.Writer, report replayReport, format string) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, report, format)
+	case formatNDJSON:
+		for _, row := range report.Entries {
+			if err := writeJSON(w, row, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	table := newTable(w)
+	fmt.Fprintln(table, "#\tMETHOD\tSTATUS\tTIME\tVARS\tVERDICT\tURL")
+	for _, row := range report.Entries {
+		status, verdict := "-", "ERROR: "+row.Error
+		if row.Report != nil {
+			status = fmt.Sprintf("%d", row.Report.StatusAfter)
+			if row.Report.StatusBefore != row.Report.StatusAfter {
+				status = fmt.Sprintf("%d -> %d", row.Report.StatusBefore, row.Report.StatusAfter)
+			}
+			verdict = row.Report.Verdict()
+		}
+		fmt.Fprintf(table, "%d\t%s\t%s\t%.0fms\t%s\t%s\t%s\n", row.Index, row.Method, status, row.Time, formatVars(row), verdict, row.URL)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\nPassed %d, failed %d\n", report.Passed, report.Failed)
+	return err
+}
+
+// formatVars shows the variables a row used and set, e.g. "<token >csrf"
+func formatVars(row replayRow) string {
+	var parts []string
+	for _, name := range row.Substituted {
+		parts = append(parts, "<"+name)
+	}
+	for _, name := range row.Extracted {
+		parts = append(parts, ">"+name)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
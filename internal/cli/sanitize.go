@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/sanitize"
+)
+
+// redactionRow is one masked value printed by sanitize --report
+type redactionRow struct {
+	Index    int    `json:"index"`
+	Location string `json:"location"`
+	Rule     string `json:"rule"`
+	Value    string `json:"value"`
+}
+
+// runSanitize writes the entries matching the filters as a HAR with
+// secrets and personal data masked, or reports what it would mask
+func runSanitize(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("sanitize", "<file.har>", stderr)
+	filters := addFilterFlags(fs)
+	output := fs.String("out", "", "write to this file instead of stdout")
+	config := fs.String("config", "", "config file with a \"sanitize\" section of extra rules (default the har-tui config file)")
+	report := fs.Bool("report", false, "list what would be masked instead of writing the HAR")
+	format := fs.String("format", formatTable, "report format: table, json or ndjson")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	state, err := filters.state()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	sanitizer, err := sanitize.Load(*config)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	harFile, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	indices := state.FilterEntries(harFile.Log.Entries)
+
+	if *report {
+		rows := []redactionRow{}
+		for _, idx := range indices {
+			_, redactions := sanitizer.Entry(harFile.Log.Entries[idx])
+			for _, r := range redactions {
+				rows = append(rows, redactionRow{Index: idx, Location: r.Location, Rule: r.Rule, Value: r.Value})
+			}
+		}
+		if err := writeRedactions(stdout, rows, *format); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+
+	sanitized := sanitizer.File(harFile, indices)
+	if *output != "" {
+		err = har.SaveFilteredHAR(sanitized, indices, *output)
+	} else {
+		err = har.WriteFilteredHAR(stdout, sanitized, indices)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// writeRedactions prints the values sanitize would mask
+func writeRedactions(w io.Writer, rows []redactionRow, format string) error {
+	switch format {
+	case formatJSON:
+		return writeJSON(w, rows, format)
+	case formatNDJSON:
+		for _, row := range rows {
+			if err := writeJSON(w, row, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "Nothing to mask")
+		return err
+	}
+	table := newTable(w)
+	fmt.Fprintln(table, "#\tLOCATION\tRULE\tVALUE")
+	for _, row := range rows {
+		fmt.Fprintf(table, "%d\t%s\t%s\t%q\n", row.Index, row.Location, row.Rule, row.Value)
+	}
+	return table.Flush()
+}
+
+// loadSanitizer loads the sanitize rules when --sanitize is set, nil otherwise
+func loadSanitizer(on bool, config string) (*sanitize.Sanitizer, error) {
+	if !on {
+		return nil, nil
+	}
+	return sanitize.Load(config)
+}
+
+// sanitizeEntry masks an entry when s is set
+func sanitizeEntry(s *sanitize.Sanitizer, entry har.HAREntry) har.HAREntry {
+	if s == nil {
+		return entry
+	}
+	entry, _ = s.Entry(entry)
+	return entry
+}
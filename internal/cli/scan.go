@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/cnharrison/har-tui/internal/scan"
+	"github.com/cnharrison/har-tui/internal/severity"
+)
+
+// failNever is the --fail-on value that always exits 0
+const failNever = "none"
+
+// findingRow is one finding printed by scan. The value itself is never
+// printed, so reports are safe to keep in CI logs
+type findingRow struct {
+	Index    int    `json:"index"`
+	Severity string `json:"severity"`
+	Location string `json:"location"`
+	Rule     string `json:"rule"`
+	Preview  string `json:"preview"`
+}
+
+// runScan reports leaked secrets and personal data in the entries matching
+// the filters, and fails when any reach --fail-on
+func runScan(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("scan", "<file.har>", stderr)
+	filters := addFilterFlags(fs)
+	minSeverity := fs.String("min-severity", "low", "report findings of this severity or higher: low, medium, high or critical")
+	failOn := fs.String("fail-on", "high", "exit 1 when a finding has this severity or higher, or none")
+	format := fs.String("format", formatTable, "output format: table, json or ndjson")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if err := validateFormat(*format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	state, err := filters.state()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	least, err := severity.Parse(*minSeverity)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: --min-severity: %v\n", err)
+		return exitUsage
+	}
+	failAt := severity.Critical + 1
+	if *failOn != failNever {
+		if failAt, err = severity.Parse(*failOn); err != nil {
+			fmt.Fprintf(stderr, "Error: --fail-on: %v or %s\n", err, failNever)
+			return exitUsage
+		}
+	}
+
+	harFile, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	var findings []scan.Finding
+	failed := false
+	for _, f := range scan.Entries(harFile.Log.Entries, state.FilterEntries(harFile.Log.Entries)) {
+		if f.Severity >= least {
+			findings = append(findings, f)
+			failed = failed || f.Severity >= failAt
+		}
+	}
+
+	if err := writeFindings(stdout, findings, *format); err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	if failed {
+		return exitError
+	}
+	return exitOK
+}
+
+// writeFindings prints findings, most severe first, with a count of each
+// severity in table output
+func writeFindings(w io.Writer, findings []scan.Finding, format string) error {
+	rows := []findingRow{}
+	for _, f := range findings {
+		rows = append(rows, findingRow{Index: f.Entry, Severity: f.Severity.String(), Location: f.Location, Rule: f.Rule, Preview: f.Preview()})
+	}
+	switch format {
+	case formatJSON:
+		return writeJSON(w, rows, format)
+	case formatNDJSON:
+		for _, row := range rows {
+			if err := writeJSON(w, row, format); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(rows) == 0 {
+		_, err := fmt.Fprintln(w, "No findings")
+		return err
+	}
+	table := newTable(w)
+	fmt.Fprintln(table, "SEVERITY\t#\tLOCATION\tRULE\tVALUE")
+	for _, row := range rows {
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\t%s\n", row.Severity, row.Index, row.Location, row.Rule, row.Preview)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+	counts := scan.Counts(findings)
+	_, err := fmt.Fprintf(w, "\n%d findings: %d critical, %d high, %d medium, %d low\n",
+		len(findings), counts[severity.Critical], counts[severity.High], counts[severity.Medium], counts[severity.Low])
+	return err
+}
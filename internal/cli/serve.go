@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/cnharrison/har-tui/internal/mock"
+)
+
+// runServe answers HTTP requests with the recorded responses of the matching
+// entries until interrupted, logging every hit
+func runServe(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("serve", "<file.har>", stderr)
+	filters := addFilterFlags(fs)
+	format := fs.String("format", formatTable, "hit log format: table or ndjson")
+	host := fs.String("host", "127.0.0.1", "address to listen on")
+	port := fs.Int("port", 8080, "port to listen on")
+	match := fs.String("match", strings.Join(mock.DefaultMatch, ","), "request parts to match: method, host, path, query, body")
+	mode := fs.String("mode", "loose", "strict: every matched part must be equal; loose: serve the closest query and body")
+	cycle := fs.Bool("cycle", false, "serve repeated requests from the first response again after the last")
+	cors := fs.Bool("cors", false, "allow cross-origin requests, answering preflights")
+
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return exitUsage
+	}
+	if len(positional) != 1 {
+		fs.Usage()
+		return exitUsage
+	}
+	if *format != formatTable && *format != formatNDJSON {
+		fmt.Fprintf(stderr, "Error: unknown format %q (expected table or ndjson)\n", *format)
+		return exitUsage
+	}
+	if *mode != "strict" && *mode != "loose" {
+		fmt.Fprintf(stderr, "Error: unknown mode %q (expected strict or loose)\n", *mode)
+		return exitUsage
+	}
+	parts, err := mock.ParseMatch(*match)
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+	state, err := filters.state()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitUsage
+	}
+
+	harFile, err := loadHAR(positional[0])
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+	entries := harFile.Log.Entries
+	indices := state.FilterEntries(entries)
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(*host, strconv.Itoa(*port)))
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	var mu sync.Mutex // serializes the log
+	served := 0
+	unmatched := make(map[string]int)
+	opts := mock.Options{Match: parts, Strict: *mode == "strict", Cycle: *cycle, CORS: *cors}
+	handler := mock.NewServer(entries, indices, opts, func(hit mock.Hit) {
+		mu.Lock()
+		defer mu.Unlock()
+		served++
+		recordedURL := ""
+		if hit.Matched() {
+			recordedURL = entries[hit.Entry].Request.URL
+		} else {
+			unmatched[hit.Method+" "+hit.URL]++
+		}
+		writeHit(stdout, recordedURL, hit, *format)
+	})
+
+	fmt.Fprintf(stderr, "Serving %d recorded responses from %s on http://%s (Ctrl-C to stop)\n",
+		len(indices), positional[0], listener.Addr())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	server := &http.Server{Handler: handler}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "Error: %v\n", err)
+		return exitError
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	writeUnmatched(stderr, served, unmatched)
+	return exitOK
+}
+
+// writeHit logs one request the mock server answered
+func writeHit(w io.Writer, recordedURL string, hit mock.Hit, format string) {
+	if format == formatNDJSON {
+		writeJSON(w, hit, format)
+		return
+	}
+	served := "UNMATCHED"
+	if hit.Matched() {
+		served = fmt.Sprintf("entry %d", hit.Entry)
+		if hit.Matches > 1 {
+			served += fmt.Sprintf(" (%d of %d)", hit.Sequence, hit.Matches)
+		}
+		served += "  " + recordedURL
+	}
+	fmt.Fprintf(w, "%s  %d  %-7s %s  → %s\n", hit.Time.Format("15:04:05"), hit.Status, hit.Method, hit.URL, served)
+}
+
+// writeUnmatched summarizes the session, listing requests no entry answered
+func writeUnmatched(w io.Writer, served int, unmatched map[string]int) {
+	total := 0
+	requests := make([]string, 0, len(unmatched))
+	for request, count := range unmatched {
+		requests = append(requests, request)
+		total += count
+	}
+	fmt.Fprintf(w, "\nServed %d requests, %d unmatched\n", served, total)
+	sort.Strings(requests)
+	for _, request := range requests {
+		fmt.Fprintf(w, "  %3dx %s\n", unmatched[request], request)
+	}
+}
@@ -0,0 +1,187 @@
+package diff
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// Kind says how an entry or header differs between the two sides
+type Kind string
+
+const (
+	Added     Kind = "added"
+	Removed   Kind = "removed"
+	Changed   Kind = "changed"
+	Unchanged Kind = "unchanged"
+)
+
+// DefaultTimeThreshold is how much slower or faster (in ms) a request must be
+// before its timing counts as a change
+const DefaultTimeThreshold = 100.0
+
+// Options tune how entries are compared
+type Options struct {
+	TimeThreshold float64 // ms; smaller timing differences are ignored
+}
+
+// EntryDiff pairs an entry of the before HAR with its match in the after HAR
+type EntryDiff struct {
+	Kind   Kind
+	Key    string // method and normalized URL the entries were matched on
+	Before int    // index into the before entries, -1 when added
+	After  int    // index into the after entries, -1 when removed
+
+	StatusChanged bool
+	TimeDelta     float64 // after minus before, in ms
+	SizeDelta     int     // after minus before, in bytes of response content
+}
+
+// Regressed reports whether the request got slower by more than threshold ms
+func (d EntryDiff) Regressed(threshold float64) bool {
+	return d.Kind == Changed && d.TimeDelta > threshold
+}
+
+// Result is the comparison of two lists of entries
+type Result struct {
+	Before  []har.HAREntry
+	After   []har.HAREntry
+	Entries []EntryDiff // in after order, removed entries placed where they used to be
+	Options Options
+}
+
+// Summary counts the differences in a Result
+type Summary struct {
+	Added         int     `json:"added"`
+	Removed       int     `json:"removed"`
+	Changed       int     `json:"changed"`
+	Unchanged     int     `json:"unchanged"`
+	StatusChanges int     `json:"statusChanges"`
+	Regressions   int     `json:"regressions"`
+	Improvements  int     `json:"improvements"`
+	TimeDelta     float64 `json:"timeDelta"` // total over matched entries, in ms
+	SizeDelta     int     `json:"sizeDelta"` // total over matched entries, in bytes
+}
+
+// Summary counts the added, removed and changed entries
+func (r *Result) Summary() Summary {
+	var s Summary
+	threshold := r.Options.TimeThreshold
+	for _, d := range r.Entries {
+		switch d.Kind {
+		case Added:
+			s.Added++
+			continue
+		case Removed:
+			s.Removed++
+			continue
+		case Changed:
+			s.Changed++
+		case Unchanged:
+			s.Unchanged++
+		}
+		if d.StatusChanged {
+			s.StatusChanges++
+		}
+		if d.TimeDelta > threshold {
+			s.Regressions++
+		} else if d.TimeDelta < -threshold {
+			s.Improvements++
+		}
+		s.TimeDelta += d.TimeDelta
+		s.SizeDelta += d.SizeDelta
+	}
+	return s
+}
+
+// Compare matches the entries of two HARs by method, normalized URL and
+// order: the nth request with a given key before is paired with the nth one
+// after. Unpaired entries are reported as added or removed
+func Compare(before, after []har.HAREntry, opts Options) *Result {
+	result := &Result{Before: before, After: after, Options: opts}
+
+	// Queue the before entries under each key, in order
+	pending := make(map[string][]int)
+	for i, entry := range before {
+		key := MatchKey(entry)
+		pending[key] = append(pending[key], i)
+	}
+
+	matched := make([]bool, len(before))
+	nextRemoved := 0
+	// flushRemoved emits unmatched before entries that come before index limit
+	flushRemoved := func(limit int) {
+		for ; nextRemoved < limit; nextRemoved++ {
+			if !matched[nextRemoved] {
+				result.Entries = append(result.Entries, EntryDiff{
+					Kind:   Removed,
+					Key:    MatchKey(before[nextRemoved]),
+					Before: nextRemoved,
+					After:  -1,
+				})
+			}
+		}
+	}
+
+	for i, entry := range after {
+		key := MatchKey(entry)
+		queue := pending[key]
+		if len(queue) == 0 {
+			result.Entries = append(result.Entries, EntryDiff{Kind: Added, Key: key, Before: -1, After: i})
+			continue
+		}
+		b := queue[0]
+		pending[key] = queue[1:]
+		matched[b] = true
+		flushRemoved(b)
+		result.Entries = append(result.Entries, compareMatched(before[b], entry, key, b, i, opts))
+	}
+	flushRemoved(len(before))
+	return result
+}
+
+// compareMatched diffs the summary fields of a matched pair
+func compareMatched(before, after har.HAREntry, key string, b, a int, opts Options) EntryDiff {
+	d := EntryDiff{
+		Kind:          Unchanged,
+		Key:           key,
+		Before:        b,
+		After:         a,
+		StatusChanged: before.Response.Status != after.Response.Status,
+		TimeDelta:     after.Time - before.Time,
+		SizeDelta:     after.Response.Content.Size - before.Response.Content.Size,
+	}
+	if d.StatusChanged || d.SizeDelta != 0 || d.TimeDelta > opts.TimeThreshold || d.TimeDelta < -opts.TimeThreshold {
+		d.Kind = Changed
+	}
+	return d
+}
+
+// MatchKey returns the method and normalized URL entries are matched on
+func MatchKey(entry har.HAREntry) string {
+	return strings.ToUpper(entry.Request.Method) + " " + NormalizeURL(entry.Request.URL)
+}
+
+// NormalizeURL makes equivalent URLs compare equal: the scheme and host are
+// lowercased, default ports and fragments dropped and query parameters sorted
+func NormalizeURL(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if (u.Scheme == "http" && strings.HasSuffix(u.Host, ":80")) || (u.Scheme == "https" && strings.HasSuffix(u.Host, ":443")) {
+		u.Host = u.Host[:strings.LastIndex(u.Host, ":")]
+	}
+	if u.Path == "" && u.Host != "" {
+		u.Path = "/"
+	}
+	u.Fragment, u.RawFragment = "", ""
+	if u.RawQuery != "" {
+		// Encode sorts by key and keeps the order of repeated keys
+		u.RawQuery = u.Query().Encode()
+	}
+	return u.String()
+}
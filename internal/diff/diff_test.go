@@ -0,0 +1,160 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func testEntry(method, url string, status int, time float64, size int) har.HAREntry {
+	entry := har.HAREntry{Time: time}
+	entry.Request.Method = method
+	entry.Request.URL = url
+	entry.Response.Status = status
+	entry.Response.Content.Size = size
+	return entry
+}
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		a, b string
+	}{
+		{"https://Example.com:443/a?b=2&a=1#top", "https://example.com/a?a=1&b=2"},
+		{"http://example.com:80", "http://example.com/"},
+		{"https://example.com/a?x=1&x=2", "https://example.com/a?x=1&x=2"},
+	}
+	for _, tt := range tests {
+		if NormalizeURL(tt.a) != NormalizeURL(tt.b) {
+			t.Errorf("expected %q and %q to normalize the same, got %q and %q", tt.a, tt.b, NormalizeURL(tt.a), NormalizeURL(tt.b))
+		}
+	}
+	if NormalizeURL("https://example.com/a?x=2&x=1") == NormalizeURL("https://example.com/a?x=1&x=2") {
+		t.Error("expected the order of repeated query keys to matter")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	before := []har.HAREntry{
+		testEntry("GET", "https://example.com/", 200, 100, 1000),
+		testEntry("GET", "https://example.com/api/items?page=1", 200, 50, 200),
+		testEntry("GET", "https://example.com/old.js", 200, 10, 300),
+		testEntry("GET", "https://example.com/api/items?page=1", 200, 50, 200),
+		testEntry("POST", "https://example.com/api/save", 200, 80, 10),
+	}
+	after := []har.HAREntry{
+		testEntry("GET", "https://example.com/", 200, 120, 1000),
+		testEntry("GET", "https://example.com/api/items?page=1", 200, 400, 200),
+		testEntry("GET", "https://example.com/api/items?page=1", 200, 50, 250),
+		testEntry("GET", "https://example.com/new.js", 200, 10, 300),
+		testEntry("POST", "https://example.com/api/save", 500, 80, 10),
+	}
+
+	result := Compare(before, after, Options{TimeThreshold: DefaultTimeThreshold})
+	want := []struct {
+		kind          Kind
+		before, after int
+	}{
+		{Unchanged, 0, 0}, // 20ms slower is under the threshold
+		{Changed, 1, 1},   // slower
+		{Removed, 2, -1},  // old.js, placed before the entry that followed it
+		{Changed, 3, 2},   // second items request matched by order, bigger
+		{Added, -1, 3},
+		{Changed, 4, 4}, // status change
+	}
+	if len(result.Entries) != len(want) {
+		t.Fatalf("expected %d diffs, got %+v", len(want), result.Entries)
+	}
+	for i, w := range want {
+		got := result.Entries[i]
+		if got.Kind != w.kind || got.Before != w.before || got.After != w.after {
+			t.Errorf("diff %d: expected %s %d->%d, got %s %d->%d", i, w.kind, w.before, w.after, got.Kind, got.Before, got.After)
+		}
+	}
+	if !result.Entries[1].Regressed(DefaultTimeThreshold) || result.Entries[3].SizeDelta != 50 || !result.Entries[5].StatusChanged {
+		t.Errorf("unexpected deltas: %+v", result.Entries)
+	}
+
+	summary := result.Summary()
+	if summary.Added != 1 || summary.Removed != 1 || summary.Changed != 3 || summary.Unchanged != 1 {
+		t.Errorf("unexpected counts: %+v", summary)
+	}
+	if summary.StatusChanges != 1 || summary.Regressions != 1 || summary.SizeDelta != 50 {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	before := []har.HARHeader{
+		{Name: "Content-Type", Value: "text/html"},
+		{Name: "Set-Cookie", Value: "a=1"},
+		{Name: "X-Old", Value: "1"},
+	}
+	after := []har.HARHeader{
+		{Name: "content-type", Value: "text/html"},
+		{Name: "Set-Cookie", Value: "a=1"},
+		{Name: "Set-Cookie", Value: "b=2"},
+		{Name: "X-New", Value: "1"},
+	}
+
+	kinds := map[string]Kind{}
+	for _, change := range Headers(before, after) {
+		kinds[change.Name] = change.Kind
+	}
+	want := map[string]Kind{"content-type": Unchanged, "Set-Cookie": Changed, "X-Old": Removed, "X-New": Added}
+	for name, kind := range want {
+		if kinds[name] != kind {
+			t.Errorf("header %s: expected %s, got %q (all: %v)", name, kind, kinds[name], kinds)
+		}
+	}
+}
+
+func TestCompareEntriesPrettyPrintsJSON(t *testing.T) {
+	before := testEntry("GET", "https://example.com/api", 200, 10, 10)
+	after := before
+	before.Response.Content.Text = `{"id":1,"name":"a"}`
+	after.Response.Content.Text = `{"id":1,"name":"b"}`
+
+	detail := CompareEntries(before, after)
+	if !detail.ResponseBody.Changed() || detail.RequestBody.Changed() {
+		t.Fatalf("expected only the response body to change: %+v", detail)
+	}
+	var changed []string
+	for _, line := range detail.ResponseBody.Lines {
+		if line.Op != OpEqual {
+			changed = append(changed, string(line.Op)+line.Text)
+		}
+	}
+	if len(changed) != 2 || changed[0] != `-  "name": "a"` || changed[1] != `+  "name": "b"` {
+		t.Errorf("expected just the name line to change, got %q", changed)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	before := `{"id": 1, "name": "a", "tags": ["x", "y"], "meta": {"v": 1.0, "old": true}}`
+	after := `{"id": 1, "name": "b", "tags": ["x"], "meta": {"v": 1, "new": null}, "extra": [1]}`
+
+	changes, ok := JSON(before, after)
+	if !ok {
+		t.Fatal("expected valid JSON to compare")
+	}
+	got := map[string]Kind{}
+	for _, change := range changes {
+		got[change.Path] = change.Kind
+	}
+	want := map[string]Kind{"name": Changed, "tags[1]": Removed, "meta.old": Removed, "meta.new": Added, "extra": Added}
+	if len(got) != len(want) {
+		t.Errorf("expected %d changes, got %v", len(want), got)
+	}
+	for path, kind := range want {
+		if got[path] != kind {
+			t.Errorf("path %s: expected %s, got %q", path, kind, got[path])
+		}
+	}
+
+	if changes, _ := JSON(`{"a": [1]}`, `{"a": {"0": 1}}`); len(changes) != 1 || changes[0].Kind != Changed || changes[0].Path != "a" {
+		t.Errorf("expected an array replaced by an object to be one change, got %+v", changes)
+	}
+	if _, ok := JSON(`{"a":`, `{}`); ok {
+		t.Error("expected invalid JSON to be rejected")
+	}
+}
@@ -0,0 +1,147 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// HeaderChange is one header name compared across two entries. Repeated
+// headers are joined with ", "
+type HeaderChange struct {
+	Kind   Kind   `json:"kind"`
+	Name   string `json:"name"`
+	Before string `json:"before,omitempty"`
+	After  string `json:"after,omitempty"`
+}
+
+// Headers compares two header lists as sets keyed by case-insensitive name,
+// sorted by name
+func Headers(before, after []har.HARHeader) []HeaderChange {
+	beforeValues, names := headerValues(before)
+	afterValues, afterNames := headerValues(after)
+	for key, name := range afterNames {
+		names[key] = name
+	}
+
+	keys := make([]string, 0, len(names))
+	for key := range names {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	changes := make([]HeaderChange, 0, len(keys))
+	for _, key := range keys {
+		b, inBefore := beforeValues[key]
+		a, inAfter := afterValues[key]
+		change := HeaderChange{Name: names[key], Before: b, After: a}
+		switch {
+		case !inBefore:
+			change.Kind = Added
+		case !inAfter:
+			change.Kind = Removed
+		case a != b:
+			change.Kind = Changed
+		default:
+			change.Kind = Unchanged
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// headerValues groups header values by lowercased name, remembering how each name was written
+func headerValues(headers []har.HARHeader) (values map[string]string, names map[string]string) {
+	values = make(map[string]string)
+	names = make(map[string]string)
+	for _, header := range headers {
+		key := strings.ToLower(header.Name)
+		if existing, ok := values[key]; ok {
+			values[key] = existing + ", " + header.Value
+		} else {
+			values[key] = header.Value
+			names[key] = header.Name
+		}
+	}
+	return values, names
+}
+
+// BodyDiff is the line diff of a request or response body
+type BodyDiff struct {
+	Before string // body text as diffed (JSON is pretty-printed)
+	After  string
+	Binary bool // a side isn't text, so no line diff was made
+	Lines  []Line
+
+	// Structural differences, when both bodies are JSON
+	IsJSON      bool
+	JSONChanges []PathChange
+}
+
+// Changed reports whether the bodies differ
+func (b BodyDiff) Changed() bool {
+	if b.Binary {
+		return b.Before != b.After
+	}
+	return HasChanges(b.Lines)
+}
+
+// EntryDetail is the header and body diff of a matched pair of entries
+type EntryDetail struct {
+	RequestHeaders  []HeaderChange
+	ResponseHeaders []HeaderChange
+	RequestBody     BodyDiff
+	ResponseBody    BodyDiff
+}
+
+// CompareEntries diffs the headers and bodies of two entries
+func CompareEntries(before, after har.HAREntry) EntryDetail {
+	return EntryDetail{
+		RequestHeaders:  Headers(before.Request.Headers, after.Request.Headers),
+		ResponseHeaders: Headers(before.Response.Headers, after.Response.Headers),
+		RequestBody:     compareBodies(RequestBody(before), RequestBody(after)),
+		ResponseBody:    compareBodies(ResponseBody(before), ResponseBody(after)),
+	}
+}
+
+func compareBodies(before, after string) BodyDiff {
+	body := BodyDiff{Before: before, After: after}
+	if !utf8.ValidString(before) || !utf8.ValidString(after) {
+		body.Binary = true
+		return body
+	}
+	body.Lines = Lines(before, after)
+	body.JSONChanges, body.IsJSON = JSON(before, after)
+	return body
+}
+
+// RequestBody returns the request body as it is diffed
+func RequestBody(entry har.HAREntry) string {
+	if entry.Request.PostData == nil {
+		return ""
+	}
+	return normalizeBody(entry.Request.PostData.Text)
+}
+
+// ResponseBody returns the decoded response body as it is diffed
+func ResponseBody(entry har.HAREntry) string {
+	content := entry.Response.Content
+	return normalizeBody(har.DecodeBase64(content.Text, content.Encoding))
+}
+
+// normalizeBody pretty-prints JSON so that changes line up with their fields
+func normalizeBody(text string) string {
+	trimmed := strings.TrimSpace(text)
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return text
+	}
+	var pretty bytes.Buffer
+	if err := json.Indent(&pretty, []byte(trimmed), "", "  "); err != nil {
+		return text
+	}
+	return pretty.String()
+}
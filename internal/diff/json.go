@@ -0,0 +1,81 @@
+package diff
+
+import (
+	"github.com/cnharrison/har-tui/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+// PathChange is a value added, removed or changed at a JSON path. Paths use
+// the same notation as the JSON path copied from the Body tab, e.g. "items[0].id"
+type PathChange struct {
+	Kind   Kind   `json:"kind"`
+	Path   string `json:"path"`
+	Before string `json:"before,omitempty"` // raw JSON
+	After  string `json:"after,omitempty"`  // raw JSON
+}
+
+// JSON compares two JSON documents structurally. ok is false when either side
+// isn't valid JSON, in which case a line diff is the better tool
+func JSON(before, after string) (changes []PathChange, ok bool) {
+	if !gjson.Valid(before) || !gjson.Valid(after) {
+		return nil, false
+	}
+	return compareJSON(gjson.Parse(before), gjson.Parse(after), "", nil), true
+}
+
+func compareJSON(before, after gjson.Result, path string, changes []PathChange) []PathChange {
+	switch {
+	case before.IsObject() && after.IsObject():
+		afterFields := objectFields(after)
+		seen := make(map[string]bool)
+		before.ForEach(func(key, value gjson.Result) bool {
+			name := key.String()
+			seen[name] = true
+			keyPath := util.JSONKeyPath(path, name)
+			if other, ok := afterFields[name]; ok {
+				changes = compareJSON(value, other, keyPath, changes)
+			} else {
+				changes = append(changes, PathChange{Kind: Removed, Path: keyPath, Before: value.Raw})
+			}
+			return true
+		})
+		after.ForEach(func(key, value gjson.Result) bool {
+			if name := key.String(); !seen[name] {
+				changes = append(changes, PathChange{Kind: Added, Path: util.JSONKeyPath(path, name), After: value.Raw})
+			}
+			return true
+		})
+		return changes
+
+	case before.IsArray() && after.IsArray():
+		beforeItems, afterItems := before.Array(), after.Array()
+		for i := 0; i < len(beforeItems) || i < len(afterItems); i++ {
+			indexPath := util.JSONIndexPath(path, i)
+			switch {
+			case i >= len(afterItems):
+				changes = append(changes, PathChange{Kind: Removed, Path: indexPath, Before: beforeItems[i].Raw})
+			case i >= len(beforeItems):
+				changes = append(changes, PathChange{Kind: Added, Path: indexPath, After: afterItems[i].Raw})
+			default:
+				changes = compareJSON(beforeItems[i], afterItems[i], indexPath, changes)
+			}
+		}
+		return changes
+	}
+
+	// Both JSON here means an object on one side and an array on the other
+	if before.Type != after.Type || before.Type == gjson.JSON || before.Value() != after.Value() {
+		changes = append(changes, PathChange{Kind: Changed, Path: path, Before: before.Raw, After: after.Raw})
+	}
+	return changes
+}
+
+// objectFields indexes an object's members by key; the last of repeated keys wins
+func objectFields(object gjson.Result) map[string]gjson.Result {
+	fields := make(map[string]gjson.Result)
+	object.ForEach(func(key, value gjson.Result) bool {
+		fields[key.String()] = value
+		return true
+	})
+	return fields
+}
@@ -0,0 +1,210 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is the edit a diff line represents
+type Op byte
+
+const (
+	OpEqual  Op = ' '
+	OpInsert Op = '+'
+	OpDelete Op = '-'
+	OpSkip   Op = '@' // a run of equal lines folded away by Fold
+)
+
+// Line is one line of a line diff. Before and After are 0-based line numbers
+// on each side, -1 when the line isn't on that side
+type Line struct {
+	Op     Op
+	Text   string
+	Before int
+	After  int
+}
+
+// maxEdits bounds the diff search; beyond it the differing middle of the two
+// texts is reported as deleted and re-inserted in full
+const maxEdits = 2000
+
+// Lines returns a minimal line diff turning before into after
+func Lines(before, after string) []Line {
+	a, b := splitLines(before), splitLines(after)
+
+	// Common prefix and suffix don't need the search
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	var lines []Line
+	for i := 0; i < prefix; i++ {
+		lines = append(lines, Line{Op: OpEqual, Text: a[i], Before: i, After: i})
+	}
+	for _, line := range myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if line.Before >= 0 {
+			line.Before += prefix
+		}
+		if line.After >= 0 {
+			line.After += prefix
+		}
+		lines = append(lines, line)
+	}
+	for i := suffix; i > 0; i-- {
+		lines = append(lines, Line{Op: OpEqual, Text: a[len(a)-i], Before: len(a) - i, After: len(b) - i})
+	}
+	return lines
+}
+
+// splitLines splits text into lines, without a trailing empty line
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// myers finds a shortest edit script with Myers' O(ND) algorithm. Only the
+// diagonals reachable at each step are kept, so memory grows with D² rather
+// than D·(N+M)
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	maxD := n + m
+	if maxD > maxEdits {
+		maxD = maxEdits
+	}
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+
+	for d := 0; d <= maxD; d++ {
+		// Keep the furthest x reached on each diagonal after step d-1
+		low, high := offset-d+1, offset+d
+		if low > high {
+			low = high
+		}
+		trace = append(trace, append([]int(nil), v[low:high]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1]
+			} else {
+				x = v[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				return backtrack(trace, a, b)
+			}
+		}
+	}
+	return replaceAll(a, b)
+}
+
+// backtrack walks the saved diagonals back from the end to recover the edits
+func backtrack(trace [][]int, a, b []string) []Line {
+	x, y := len(a), len(b)
+	var reversed []Line
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d] // diagonal k of step d-1 is at prev[k+d-1]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && prev[k-1+d-1] < prev[k+1+d-1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := prev[prevK+d-1]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, Line{Op: OpEqual, Text: a[x], Before: x, After: y})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, Line{Op: OpInsert, Text: b[y], Before: -1, After: y})
+		} else {
+			x--
+			reversed = append(reversed, Line{Op: OpDelete, Text: a[x], Before: x, After: -1})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, Line{Op: OpEqual, Text: a[x], Before: x, After: y})
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// replaceAll reports every line of a as deleted and every line of b as inserted
+func replaceAll(a, b []string) []Line {
+	lines := make([]Line, 0, len(a)+len(b))
+	for i, text := range a {
+		lines = append(lines, Line{Op: OpDelete, Text: text, Before: i, After: -1})
+	}
+	for i, text := range b {
+		lines = append(lines, Line{Op: OpInsert, Text: text, Before: -1, After: i})
+	}
+	return lines
+}
+
+// HasChanges reports whether a line diff contains any insertions or deletions
+func HasChanges(lines []Line) bool {
+	for _, line := range lines {
+		if line.Op == OpInsert || line.Op == OpDelete {
+			return true
+		}
+	}
+	return false
+}
+
+// Fold keeps context equal lines around each change and replaces longer
+// runs of equal lines with a single OpSkip line
+func Fold(lines []Line, context int) []Line {
+	keep := make([]bool, len(lines))
+	for i, line := range lines {
+		if line.Op == OpEqual {
+			continue
+		}
+		for j := i - context; j <= i+context; j++ {
+			if j >= 0 && j < len(lines) {
+				keep[j] = true
+			}
+		}
+	}
+
+	var folded []Line
+	for i := 0; i < len(lines); {
+		if keep[i] {
+			folded = append(folded, lines[i])
+			i++
+			continue
+		}
+		start := i
+		for i < len(lines) && !keep[i] {
+			i++
+		}
+		folded = append(folded, Line{
+			Op:     OpSkip,
+			Text:   fmt.Sprintf("%d unchanged lines", i-start),
+			Before: lines[start].Before,
+			After:  lines[start].After,
+		})
+	}
+	return folded
+}
@@ -0,0 +1,87 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+// sides rebuilds both texts from a line diff
+func sides(lines []Line) (before, after string) {
+	var b, a []string
+	for _, line := range lines {
+		if line.Op != OpInsert {
+			b = append(b, line.Text)
+		}
+		if line.Op != OpDelete {
+			a = append(a, line.Text)
+		}
+	}
+	return strings.Join(b, "\n"), strings.Join(a, "\n")
+}
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name          string
+		before, after string
+		edits         int
+	}{
+		{"identical", "a\nb\nc", "a\nb\nc", 0},
+		{"empty to text", "", "a\nb", 2},
+		{"text to empty", "a\nb\n", "", 2},
+		{"insert in middle", "a\nb\nc", "a\nb\nx\nc", 1},
+		{"replace", "a\nb\nc", "a\nx\nc", 2},
+		{"reorder", "a\nb\nc\nd", "b\na\nd\nc", 4},
+		{"scattered", "1\n2\n3\n4\n5\n6\n7", "0\n1\n3\n4\n5\n6x\n7", 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := Lines(tt.before, tt.after)
+			before, after := sides(lines)
+			if before != strings.TrimSuffix(tt.before, "\n") || after != strings.TrimSuffix(tt.after, "\n") {
+				t.Fatalf("diff doesn't rebuild the inputs: %q / %q", before, after)
+			}
+			edits := 0
+			for _, line := range lines {
+				if line.Op != OpEqual {
+					edits++
+				}
+			}
+			if edits != tt.edits {
+				t.Errorf("expected %d edits, got %d: %+v", tt.edits, edits, lines)
+			}
+		})
+	}
+}
+
+func TestLinesLineNumbers(t *testing.T) {
+	for _, line := range Lines("a\nb\nc\nd", "a\nx\nc\nd\ne") {
+		if line.Op != OpInsert && line.Text != strings.Split("a\nb\nc\nd", "\n")[line.Before] {
+			t.Errorf("before line number %d wrong for %q", line.Before, line.Text)
+		}
+		if line.Op != OpDelete && line.Text != strings.Split("a\nx\nc\nd\ne", "\n")[line.After] {
+			t.Errorf("after line number %d wrong for %q", line.After, line.Text)
+		}
+	}
+}
+
+func TestFold(t *testing.T) {
+	var before, after []string
+	for i := 0; i < 20; i++ {
+		before = append(before, string(rune('a'+i)))
+	}
+	after = append(after, before...)
+	after[10] = "changed"
+
+	folded := Fold(Lines(strings.Join(before, "\n"), strings.Join(after, "\n")), 2)
+	var ops []string
+	for _, line := range folded {
+		ops = append(ops, string(line.Op))
+	}
+	// 8 skipped, 2 context, change, 2 context, 7 skipped
+	if got := strings.Join(ops, ""); got != "@  -+  @" {
+		t.Errorf("unexpected folding %q: %+v", got, folded)
+	}
+	if folded[0].Text != "8 unchanged lines" {
+		t.Errorf("unexpected skip text %q", folded[0].Text)
+	}
+}
@@ -0,0 +1,285 @@
+// Package env rewrites captured requests for another deployment, so a
+// production capture can be replayed or exported against staging or localhost
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// ConfigEnvVar names the environment variable that overrides the config path
+const ConfigEnvVar = "HAR_TUI_CONFIG"
+
+// Environment describes how requests change for one deployment
+type Environment struct {
+	Name string `json:"-"`
+	// Hosts maps captured hosts, optionally globs such as *.example.com, to
+	// host[:port] or scheme://host[:port]
+	Hosts map[string]string `json:"hosts,omitempty"`
+	// Scheme, when set, is used for every request
+	Scheme string `json:"scheme,omitempty"`
+	// Headers are set on every request, replacing any header of the same
+	// name; an empty value removes the header
+	Headers map[string]string `json:"headers,omitempty"`
+	// Variables fill {{name}} placeholders in the URL, headers, cookies and body
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// Config is the har-tui config file
+type Config struct {
+	Default      string                  `json:"defaultEnvironment,omitempty"`
+	Environments map[string]*Environment `json:"environments,omitempty"`
+}
+
+// DefaultPath returns $HAR_TUI_CONFIG, or config.json in the user's config directory
+func DefaultPath() (string, error) {
+	if p := os.Getenv(ConfigEnvVar); p != "" {
+		return p, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "har-tui", "config.json"), nil
+}
+
+// LoadDefault loads the config at DefaultPath. A missing file is an empty config
+func LoadDefault() (*Config, error) {
+	p, err := DefaultPath()
+	if err != nil {
+		return &Config{}, nil
+	}
+	config, err := Load(p)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	return config, err
+}
+
+// Load reads and validates a config file
+func Load(p string) (*Config, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return nil, err
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("%s: %w", p, err)
+	}
+	for name, e := range config.Environments {
+		if e == nil {
+			e = &Environment{}
+			config.Environments[name] = e
+		}
+		e.Name = name
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("%s: environment %q: %w", p, name, err)
+		}
+	}
+	if config.Default != "" && config.Environments[config.Default] == nil {
+		return nil, fmt.Errorf("%s: default environment %q is not defined", p, config.Default)
+	}
+	return &config, nil
+}
+
+// Names returns the environment names, sorted
+func (c *Config) Names() []string {
+	names := make([]string, 0, len(c.Environments))
+	for name := range c.Environments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the named environment
+func (c *Config) Get(name string) (*Environment, error) {
+	if e := c.Environments[name]; e != nil {
+		return e, nil
+	}
+	if len(c.Environments) == 0 {
+		return nil, fmt.Errorf("unknown environment %q (no environments are configured)", name)
+	}
+	return nil, fmt.Errorf("unknown environment %q (expected one of %s)", name, strings.Join(c.Names(), ", "))
+}
+
+// validate checks the schemes and host rewrites
+func (e *Environment) validate() error {
+	if e.Scheme != "" && e.Scheme != "http" && e.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https, got %q", e.Scheme)
+	}
+	for pattern, target := range e.Hosts {
+		if _, err := path.Match(strings.ToLower(pattern), ""); err != nil {
+			return fmt.Errorf("host %q: %w", pattern, err)
+		}
+		scheme, host := splitTarget(target)
+		if host == "" || strings.ContainsAny(host, "/?#") {
+			return fmt.Errorf("host %q: target %q must be host[:port] or scheme://host[:port]", pattern, target)
+		}
+		if scheme != "" && scheme != "http" && scheme != "https" {
+			return fmt.Errorf("host %q: scheme must be http or https, got %q", pattern, scheme)
+		}
+	}
+	return nil
+}
+
+// splitTarget splits a host rewrite target into its optional scheme and host
+func splitTarget(target string) (scheme, host string) {
+	if s, h, ok := strings.Cut(target, "://"); ok {
+		return strings.ToLower(s), strings.TrimSuffix(h, "/")
+	}
+	return "", target
+}
+
+// rewriteHost returns the host rewrite for a URL's host, matched with and
+// without its port. Exact names win over globs
+func (e *Environment) rewriteHost(u *url.URL) (scheme, host string, ok bool) {
+	candidates := []string{strings.ToLower(u.Host), strings.ToLower(u.Hostname())}
+	for _, candidate := range candidates {
+		for pattern, target := range e.Hosts {
+			if strings.ToLower(pattern) == candidate {
+				scheme, host = splitTarget(target)
+				return scheme, host, true
+			}
+		}
+	}
+	patterns := make([]string, 0, len(e.Hosts))
+	for pattern := range e.Hosts {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, candidate := range candidates {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(strings.ToLower(pattern), candidate); matched {
+				scheme, host = splitTarget(e.Hosts[pattern])
+				return scheme, host, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// RewriteURL applies the variables, host rewrites and scheme to a URL.
+// URLs that don't parse only have their variables replaced
+func (e *Environment) RewriteURL(raw string) string {
+	if e == nil {
+		return raw
+	}
+	raw = e.expand(raw)
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return raw
+	}
+	host, scheme := u.Host, u.Scheme
+	if newScheme, newHost, ok := e.rewriteHost(u); ok {
+		u.Host = newHost
+		if newScheme != "" {
+			u.Scheme = newScheme
+		}
+	}
+	if e.Scheme != "" {
+		u.Scheme = e.Scheme
+	}
+	if u.Host == host && u.Scheme == scheme {
+		return raw
+	}
+	return u.String()
+}
+
+// expand replaces {{name}} placeholders with the environment's variables
+func (e *Environment) expand(text string) string {
+	if len(e.Variables) == 0 || !strings.Contains(text, "{{") {
+		return text
+	}
+	for name, value := range e.Variables {
+		text = strings.ReplaceAll(text, "{{"+name+"}}", value)
+	}
+	return text
+}
+
+// Apply returns a copy of entry whose request targets the environment. The
+// Host header follows the host rewrite. A nil environment changes nothing
+func (e *Environment) Apply(entry har.HAREntry) har.HAREntry {
+	if e == nil {
+		return entry
+	}
+	request := entry.Request
+	request.URL = e.RewriteURL(request.URL)
+	// The Host header only changes when the host was rewritten
+	host := ""
+	if before, err := url.Parse(e.expand(entry.Request.URL)); err == nil {
+		if after, err := url.Parse(request.URL); err == nil && after.Host != before.Host {
+			host = after.Host
+		}
+	}
+
+	overrides := make(map[string]string, len(e.Headers))
+	for name, value := range e.Headers {
+		overrides[strings.ToLower(name)] = e.expand(value)
+	}
+	// Overrides replace the first header of the same name in place
+	applied := make(map[string]bool, len(overrides))
+	request.Headers = make([]har.HARHeader, 0, len(entry.Request.Headers)+len(overrides))
+	for _, header := range entry.Request.Headers {
+		name := strings.ToLower(header.Name)
+		if value, ok := overrides[name]; ok {
+			if applied[name] || value == "" {
+				continue
+			}
+			applied[name] = true
+			header.Value = value
+		} else {
+			header.Value = e.expand(header.Value)
+		}
+		if (name == "host" || name == ":authority") && host != "" {
+			header.Value = host
+		}
+		request.Headers = append(request.Headers, header)
+	}
+	names := make([]string, 0, len(e.Headers))
+	for name := range e.Headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if value := overrides[strings.ToLower(name)]; value != "" && !applied[strings.ToLower(name)] {
+			request.Headers = append(request.Headers, har.HARHeader{Name: name, Value: value})
+		}
+	}
+
+	request.Cookies = make([]har.HARCookie, 0, len(entry.Request.Cookies))
+	// Overriding or removing the Cookie header drops the captured cookies too
+	if _, ok := overrides["cookie"]; !ok {
+		for _, cookie := range entry.Request.Cookies {
+			cookie.Value = e.expand(cookie.Value)
+			request.Cookies = append(request.Cookies, cookie)
+		}
+	}
+	request.QueryString = make([]har.HARQueryParam, len(entry.Request.QueryString))
+	for i, param := range entry.Request.QueryString {
+		param.Value = e.expand(param.Value)
+		request.QueryString[i] = param
+	}
+	if entry.Request.PostData != nil {
+		postData := *entry.Request.PostData
+		postData.Text = e.expand(postData.Text)
+		postData.Params = make([]har.HARParam, len(entry.Request.PostData.Params))
+		for i, param := range entry.Request.PostData.Params {
+			param.Value = e.expand(param.Value)
+			postData.Params[i] = param
+		}
+		request.PostData = &postData
+	}
+	entry.Request = request
+	return entry
+}
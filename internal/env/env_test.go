@@ -0,0 +1,132 @@
+package env
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+const testConfig = `{
+  "defaultEnvironment": "local",
+  "environments": {
+    "local": {
+      "hosts": {
+        "api.prod.example.com": "http://localhost:8080",
+        "*.cdn.example.com": "cdn.staging.example.com"
+      },
+      "headers": {"Authorization": "Bearer {{token}}", "X-Debug": "1", "Cookie": ""},
+      "variables": {"token": "dev-token", "user": "42"}
+    },
+    "staging": {"scheme": "http"}
+  }
+}`
+
+func loadTestConfig(t *testing.T) *Config {
+	t.Helper()
+	p := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(p, []byte(testConfig), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return config
+}
+
+func TestLoad(t *testing.T) {
+	config := loadTestConfig(t)
+	if config.Default != "local" || strings.Join(config.Names(), ",") != "local,staging" {
+		t.Errorf("unexpected config: %+v", config)
+	}
+	if e, err := config.Get("staging"); err != nil || e.Name != "staging" {
+		t.Errorf("Get(staging) = %+v, %v", e, err)
+	}
+	if _, err := config.Get("prod"); err == nil || !strings.Contains(err.Error(), "local, staging") {
+		t.Errorf("expected an error listing the environments, got %v", err)
+	}
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"scheme":  `{"environments": {"x": {"scheme": "ftp"}}}`,
+		"target":  `{"environments": {"x": {"hosts": {"a.com": "http://b.com/path"}}}}`,
+		"default": `{"defaultEnvironment": "y", "environments": {"x": {}}}`,
+		"json":    `{"environments": [`,
+	} {
+		p := filepath.Join(dir, name+".json")
+		os.WriteFile(p, []byte(content), 0644)
+		if _, err := Load(p); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	t.Setenv(ConfigEnvVar, filepath.Join(dir, "missing.json"))
+	if config, err := LoadDefault(); err != nil || len(config.Environments) != 0 {
+		t.Errorf("expected a missing config to be empty, got %+v, %v", config, err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	config := loadTestConfig(t)
+	local, _ := config.Get("local")
+	entry := har.HAREntry{Request: har.HARRequest{
+		Method: "POST",
+		URL:    "https://api.prod.example.com/users/{{user}}?q=1",
+		Headers: []har.HARHeader{
+			{Name: "Host", Value: "api.prod.example.com"},
+			{Name: "authorization", Value: "Bearer prod-token"},
+			{Name: "Cookie", Value: "session=abc"},
+			{Name: "Accept", Value: "application/json"},
+		},
+		Cookies:     []har.HARCookie{{Name: "session", Value: "abc"}},
+		QueryString: []har.HARQueryParam{{Name: "q", Value: "1"}},
+		PostData:    &har.HARPostData{MimeType: "application/json", Text: `{"user":"{{user}}"}`},
+	}}
+
+	got := local.Apply(entry)
+	if got.Request.URL != "http://localhost:8080/users/42?q=1" {
+		t.Errorf("URL = %q", got.Request.URL)
+	}
+	want := []har.HARHeader{
+		{Name: "Host", Value: "localhost:8080"},
+		{Name: "authorization", Value: "Bearer dev-token"},
+		{Name: "Accept", Value: "application/json"},
+		{Name: "X-Debug", Value: "1"},
+	}
+	if len(got.Request.Headers) != len(want) {
+		t.Fatalf("headers = %+v", got.Request.Headers)
+	}
+	for i, header := range want {
+		if h := got.Request.Headers[i]; h.Name != header.Name || h.Value != header.Value {
+			t.Errorf("header %d = %+v, want %+v", i, got.Request.Headers[i], header)
+		}
+	}
+	if len(got.Request.Cookies) != 0 {
+		t.Errorf("expected the removed Cookie header to drop the cookies, got %+v", got.Request.Cookies)
+	}
+	if got.Request.PostData.Text != `{"user":"42"}` {
+		t.Errorf("body = %q", got.Request.PostData.Text)
+	}
+	if entry.Request.Headers[1].Value != "Bearer prod-token" || entry.Request.PostData.Text != `{"user":"{{user}}"}` {
+		t.Error("Apply modified the original entry")
+	}
+
+	if url := local.RewriteURL("https://img.cdn.example.com/a.png"); url != "https://cdn.staging.example.com/a.png" {
+		t.Errorf("glob rewrite = %q", url)
+	}
+	if url := local.RewriteURL("https://other.example.com/"); url != "https://other.example.com/" {
+		t.Errorf("unmatched host rewritten to %q", url)
+	}
+	staging, _ := config.Get("staging")
+	if url := staging.RewriteURL("https://api.example.com:8443/x"); url != "http://api.example.com:8443/x" {
+		t.Errorf("scheme rewrite = %q", url)
+	}
+
+	var none *Environment
+	if got := none.Apply(entry); got.Request.URL != entry.Request.URL {
+		t.Errorf("nil environment changed the URL to %q", got.Request.URL)
+	}
+}
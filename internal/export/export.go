@@ -10,41 +10,203 @@ import (
 	"github.com/cnharrison/har-tui/internal/har"
 )
 
-// GenerateCurlCommand generates a curl command from a HAR entry
+// CurlShell is a shell curl commands can be quoted for
+type CurlShell int
+
+const (
+	// CurlPOSIX quotes for sh, bash and zsh
+	CurlPOSIX CurlShell = iota
+	// CurlCmd quotes for the Windows command prompt
+	CurlCmd
+)
+
+// curlBodyFile is where a binary body is written for curl to read
+const curlBodyFile = "request-body.bin"
+
+// GenerateCurlCommand generates a curl command from a HAR entry, quoted for a POSIX shell
 func GenerateCurlCommand(entry har.HAREntry) string {
+	return GenerateCurlCommandFor(entry, CurlPOSIX)
+}
+
+// GenerateCurlCommandFor generates a curl command quoted for the given shell.
+// A binary body can't be passed as an argument, so it is first decoded into
+// a file the command reads
+func GenerateCurlCommandFor(entry har.HAREntry, shell CurlShell) string {
+	sr := newSnippetRequest(entry)
 	var cmd strings.Builder
-	cmd.WriteString(fmt.Sprintf("curl -X %s '%s'", entry.Request.Method, entry.Request.URL))
-	
-	for _, header := range entry.Request.Headers {
-		if strings.ToLower(header.Name) != "host" {
-			cmd.WriteString(fmt.Sprintf(" -H '%s: %s'", header.Name, header.Value))
+	if sr.binary() && !sr.multipart() {
+		switch shell {
+		case CurlCmd:
+			fmt.Fprintf(&cmd, "(echo %s)> request-body.b64\r\n", sr.base64Body())
+			fmt.Fprintf(&cmd, "certutil -f -decode request-body.b64 %s >nul\r\n", curlBodyFile)
+		default:
+			fmt.Fprintf(&cmd, "echo %s | base64 -d > %s\n", shellQuote(sr.base64Body()), curlBodyFile)
 		}
 	}
-	
-	if entry.Request.PostData != nil && entry.Request.PostData.Text != "" {
-		cmd.WriteString(fmt.Sprintf(" -d '%s'", strings.ReplaceAll(entry.Request.PostData.Text, "'", "'\\''")))
+	cmd.WriteString("curl")
+	for _, arg := range curlArgs(sr, entry.Request.HTTPVersion, "@"+curlBodyFile) {
+		cmd.WriteString(" ")
+		switch {
+		case arg.option:
+			cmd.WriteString(arg.value)
+		case shell == CurlCmd:
+			cmd.WriteString(cmdQuote(arg.value))
+		default:
+			cmd.WriteString(singleQuote(arg.value))
+		}
 	}
-	
 	return cmd.String()
 }
 
-// GenerateCurlArgs generates secure curl arguments from a HAR entry
+// GenerateCurlArgs generates secure curl arguments from a HAR entry; a
+// binary body is read from standard input
 func GenerateCurlArgs(entry har.HAREntry) []string {
-	args := []string{"-X", entry.Request.Method, entry.Request.URL}
-	
-	for _, header := range entry.Request.Headers {
-		if strings.ToLower(header.Name) != "host" {
-			args = append(args, "-H", fmt.Sprintf("%s: %s", header.Name, header.Value))
+	var args []string
+	for _, arg := range curlArgs(newSnippetRequest(entry), entry.Request.HTTPVersion, "@-") {
+		args = append(args, arg.value)
+	}
+	return args
+}
+
+// curlArg is a curl argument; options are written as they are and values quoted
+type curlArg struct {
+	value  string
+	option bool
+}
+
+// curlArgs builds the arguments sending a request. A binary body is read
+// from binaryBody, a curl "@file" reference
+func curlArgs(sr snippetRequest, httpVersion, binaryBody string) []curlArg {
+	var args []curlArg
+	option := func(name string, values ...string) {
+		args = append(args, curlArg{value: name, option: true})
+		for _, value := range values {
+			args = append(args, curlArg{value: value})
 		}
 	}
-	
-	if entry.Request.PostData != nil && entry.Request.PostData.Text != "" {
-		args = append(args, "-d", entry.Request.PostData.Text)
+
+	// curl sends GET, or POST when there is a body, unless told otherwise
+	hasBody := len(sr.body) > 0
+	switch {
+	case sr.method == "HEAD" && !hasBody:
+		option("--head")
+	case hasBody && sr.method != "POST", !hasBody && sr.method != "GET":
+		option("-X", sr.method)
+	}
+	args = append(args, curlArg{value: sr.url})
+
+	switch strings.ToUpper(httpVersion) {
+	case "HTTP/2", "HTTP/2.0", "H2":
+		option("--http2")
+	case "HTTP/3", "HTTP/3.0", "H3":
+		option("--http3")
+	case "HTTP/1.0":
+		option("--http1.0")
+	}
+
+	compressed := false
+	for _, header := range sr.headersForBody() {
+		switch {
+		case strings.EqualFold(header.Name, "Accept-Encoding"):
+			// --compressed asks for the encodings curl can decode, and decodes
+			// the response instead of printing it compressed
+			compressed = compressed || header.Value != "identity"
+		case header.Value == "":
+			// "Name:" would remove the header rather than send it empty
+			option("-H", header.Name+";")
+		default:
+			option("-H", header.Name+": "+header.Value)
+		}
+	}
+	if len(sr.cookies) > 0 {
+		option("-b", sr.cookieHeader())
+	}
+	if compressed {
+		option("--compressed")
+	}
+
+	switch {
+	case sr.multipart():
+		for _, field := range sr.fields {
+			if field.fileName == "" {
+				// Unlike -F, --form-string never reads a file for a leading @ or <
+				option("--form-string", field.name+"="+field.value)
+				continue
+			}
+			file := field.fileName
+			if strings.ContainsAny(file, `;,"`) {
+				file = `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(file) + `"`
+			}
+			if field.contentType != "" {
+				file += ";type=" + field.contentType
+			}
+			option("-F", field.name+"=@"+file)
+		}
+	case sr.binary():
+		option("--data-binary", binaryBody)
+	case hasBody:
+		// --data-raw sends the body as it is, where -d would strip newlines
+		// from a file and read one for a leading @
+		option("--data-raw", string(sr.body))
 	}
-	
 	return args
 }
 
+// cmdQuote quotes an argument for the Windows command prompt. Quotes and
+// backslashes are escaped for the program's own argument parsing, then the
+// whole argument is left unquoted to cmd with ^ escaping what it treats
+// specially, as a quoted string can't escape a newline or %
+func cmdQuote(arg string) string {
+	var quoted strings.Builder
+	backslashes := 0
+	for _, c := range arg {
+		switch c {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			quoted.WriteString(strings.Repeat(`\`, 2*backslashes+1))
+		default:
+			quoted.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		quoted.WriteRune(c)
+		backslashes = 0
+	}
+	quoted.WriteString(strings.Repeat(`\`, 2*backslashes))
+
+	var out strings.Builder
+	out.WriteString(`^"`)
+	text := quoted.String()
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\r' && i+1 < len(text) && text[i+1] == '\n':
+		case c == '\n':
+			// A caret before a line break escapes it, and the blank line
+			// after it is the newline kept
+			out.WriteString("^\n\n")
+		case c == '%':
+			// %^NAME% stops cmd expanding a variable
+			out.WriteString("^%")
+			if i+1 < len(text) && isWordByte(text[i+1]) {
+				out.WriteString("^")
+			}
+		case strings.IndexByte(`^&|<>()!"`, c) >= 0:
+			out.WriteByte('^')
+			out.WriteByte(c)
+		default:
+			out.WriteByte(c)
+		}
+	}
+	out.WriteString(`^"`)
+	return out.String()
+}
+
+// isWordByte reports whether c may be part of a cmd variable name
+func isWordByte(c byte) bool {
+	return c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
 // OpenInEditor opens content in the user's preferred editor
 func OpenInEditor(content, extension string) (string, error) {
 	editor := os.Getenv("EDITOR")
@@ -0,0 +1,70 @@
+package export
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func TestGenerateCurlCommand(t *testing.T) {
+	entry := jsonEntry()
+	entry.Request.HTTPVersion = "h2"
+	entry.Request.Headers = append(entry.Request.Headers, har.HARHeader{Name: "Accept-Encoding", Value: "gzip, br"}, har.HARHeader{Name: "X-Empty"})
+	got := GenerateCurlCommand(entry)
+	for _, part := range []string{
+		"curl 'https://api.example.com/items?q=1' --http2",
+		"-H 'Accept: application/json' -H 'Accept: text/plain'",
+		"-H 'X-Empty;'",
+		"-b 'session=abc; theme=dark' --compressed",
+		`--data-raw '{"name":"it'\''s \"new\""}'`,
+	} {
+		if !strings.Contains(got, part) {
+			t.Errorf("missing %q in\n%s", part, got)
+		}
+	}
+	for _, dropped := range []string{"Host:", "Cookie:", "Content-Length:", "Accept-Encoding:", "-X"} {
+		if strings.Contains(got, dropped) {
+			t.Errorf("unexpected %q in\n%s", dropped, got)
+		}
+	}
+
+	if got := GenerateCurlCommand(multipartEntry()); got != "curl 'https://example.com/upload' --form-string 'title=Holiday' -F 'photo=@beach.png;type=image/png'" {
+		t.Errorf("unexpected multipart command:\n%s", got)
+	}
+
+	got = GenerateCurlCommand(binaryEntry())
+	if !strings.HasPrefix(got, "echo AAH/ | base64 -d > request-body.bin\ncurl -X 'PUT' ") || !strings.HasSuffix(got, "--data-binary '@request-body.bin'") {
+		t.Errorf("unexpected binary command:\n%s", got)
+	}
+	if args := GenerateCurlArgs(binaryEntry()); args[len(args)-1] != "@-" {
+		t.Errorf("expected the binary body on stdin: %q", args)
+	}
+
+	head := har.HAREntry{Request: har.HARRequest{Method: "HEAD", URL: "https://example.com/"}}
+	if got := GenerateCurlCommand(head); got != "curl --head 'https://example.com/'" {
+		t.Errorf("unexpected HEAD command: %s", got)
+	}
+}
+
+func TestCmdQuote(t *testing.T) {
+	tests := map[string]string{
+		"plain":            `^"plain^"`,
+		`say "hi"`:         `^"say \^"hi\^"^"`,
+		`C:\dir\`:          `^"C:\dir\\^"`,
+		`\"`:               `^"\\\^"^"`,
+		"a&b|c<d>e^f":      `^"a^&b^|c^<d^>e^^f^"`,
+		"%PATH% 50%":       `^"^%^PATH^% 50^%^"`,
+		"line1\r\nline2\n": "^\"line1^\n\nline2^\n\n^\"",
+	}
+	for in, want := range tests {
+		if got := cmdQuote(in); got != want {
+			t.Errorf("cmdQuote(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	got := GenerateCurlCommandFor(binaryEntry(), CurlCmd)
+	if !strings.HasPrefix(got, "(echo AAH/)> request-body.b64\r\ncertutil -f -decode request-body.b64 request-body.bin >nul\r\ncurl -X ^\"PUT^\"") {
+		t.Errorf("unexpected cmd command:\n%s", got)
+	}
+}
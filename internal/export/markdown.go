@@ -7,10 +7,13 @@ import (
 	"time"
 
 	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/sanitize"
 )
 
-// GenerateMarkdownSummary generates a detailed markdown report for support/debugging
+// GenerateMarkdownSummary generates a detailed markdown report for support/debugging.
+// Summaries end up in tickets, so they are always sanitized with the built-in rules
 func GenerateMarkdownSummary(entry har.HAREntry) string {
+	entry, _ = sanitize.Default().Entry(entry)
 	u, _ := url.Parse(entry.Request.URL)
 	
 	// Parse timestamp for better date display
@@ -59,14 +62,7 @@ func GenerateMarkdownSummary(entry har.HAREntry) string {
 	if len(reqHeaders) > 0 {
 		summary.WriteString("## Key Request Headers\n\n")
 		for _, header := range reqHeaders {
-			// Redact sensitive information
-			value := header.Value
-			if strings.ToLower(header.Name) == "authorization" || strings.Contains(strings.ToLower(header.Name), "auth") {
-				if len(value) > 10 {
-					value = value[:10] + "..." + value[len(value)-4:] + " (redacted)"
-				}
-			}
-			summary.WriteString(fmt.Sprintf("- **%s:** `%s`\n", header.Name, value))
+			summary.WriteString(fmt.Sprintf("- **%s:** `%s`\n", header.Name, header.Value))
 		}
 		summary.WriteString("\n")
 	}
@@ -0,0 +1,359 @@
+package export
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// SnippetTarget is a language or HTTP client requests can be exported to as code
+type SnippetTarget struct {
+	Name     string // command line name, e.g. "python-requests"
+	Title    string // shown in menus
+	Generate func(entry har.HAREntry) string
+}
+
+// snippetTargets are the registered targets, in menu order
+var snippetTargets []SnippetTarget
+
+func init() {
+	RegisterSnippetTarget(SnippetTarget{Name: "curl", Title: "cURL", Generate: GenerateCurlCommand})
+	RegisterSnippetTarget(SnippetTarget{Name: "curl-cmd", Title: "cURL (Windows cmd)", Generate: func(entry har.HAREntry) string {
+		return GenerateCurlCommandFor(entry, CurlCmd)
+	}})
+	RegisterSnippetTarget(SnippetTarget{Name: "fetch", Title: "JavaScript fetch", Generate: generateFetch})
+	RegisterSnippetTarget(SnippetTarget{Name: "axios", Title: "JavaScript axios", Generate: generateAxios})
+	RegisterSnippetTarget(SnippetTarget{Name: "python-requests", Title: "Python requests", Generate: generatePythonRequests})
+	RegisterSnippetTarget(SnippetTarget{Name: "python-httpx", Title: "Python httpx", Generate: generatePythonHTTPX})
+	RegisterSnippetTarget(SnippetTarget{Name: "go", Title: "Go net/http", Generate: generateGo})
+	RegisterSnippetTarget(SnippetTarget{Name: "httpie", Title: "HTTPie", Generate: generateHTTPie})
+	RegisterSnippetTarget(SnippetTarget{Name: "wget", Title: "wget", Generate: generateWget})
+	RegisterSnippetTarget(SnippetTarget{Name: "powershell", Title: "PowerShell Invoke-WebRequest", Generate: generatePowerShell})
+	RegisterSnippetTarget(SnippetTarget{Name: "http", Title: "Raw HTTP/1.1", Generate: generateRawHTTP})
+}
+
+// RegisterSnippetTarget adds a target to those GenerateSnippet and the copy menu offer
+func RegisterSnippetTarget(target SnippetTarget) {
+	snippetTargets = append(snippetTargets, target)
+}
+
+// SnippetTargets returns the registered targets in menu order
+func SnippetTargets() []SnippetTarget {
+	return append([]SnippetTarget(nil), snippetTargets...)
+}
+
+// SnippetTargetNames returns the command line names of the registered targets
+func SnippetTargetNames() []string {
+	names := make([]string, len(snippetTargets))
+	for i, target := range snippetTargets {
+		names[i] = target.Name
+	}
+	return names
+}
+
+// FindSnippetTarget returns the target with the given command line name
+func FindSnippetTarget(name string) (SnippetTarget, error) {
+	for _, target := range snippetTargets {
+		if target.Name == name {
+			return target, nil
+		}
+	}
+	return SnippetTarget{}, fmt.Errorf("unknown snippet target %q (expected one of: %s)", name, strings.Join(SnippetTargetNames(), ", "))
+}
+
+// GenerateSnippet renders an entry's request as code for the named target
+func GenerateSnippet(name string, entry har.HAREntry) (string, error) {
+	target, err := FindSnippetTarget(name)
+	if err != nil {
+		return "", err
+	}
+	return target.Generate(entry), nil
+}
+
+// snippetRequest is a request the way snippet targets need it: headers a
+// client sets itself are dropped, cookies are split out of the Cookie
+// header, and the body is either raw bytes or multipart fields
+type snippetRequest struct {
+	method   string
+	url      string
+	headers  []har.HARHeader // without Host, Cookie, Content-Length and pseudo-headers
+	cookies  []har.HARCookie
+	body     []byte // the body as sent; for multipart requests, the encoded form
+	mimeType string
+	fields   []snippetField // set for multipart bodies
+}
+
+// snippetField is a multipart form field; file fields refer to a local
+// file by name, since a capture doesn't keep uploaded files
+type snippetField struct {
+	name        string
+	value       string
+	fileName    string
+	contentType string
+}
+
+// clientManagedHeaders are set by HTTP clients from the request itself
+var clientManagedHeaders = map[string]bool{
+	"host":              true,
+	"cookie":            true,
+	"content-length":    true,
+	"connection":        true,
+	"keep-alive":        true,
+	"transfer-encoding": true,
+	"proxy-connection":  true,
+	"upgrade":           true,
+	"te":                true,
+}
+
+// newSnippetRequest prepares an entry's request for the snippet targets
+func newSnippetRequest(entry har.HAREntry) snippetRequest {
+	request := entry.Request
+	sr := snippetRequest{method: request.Method, url: request.URL}
+	if sr.method == "" {
+		sr.method = "GET"
+	}
+
+	cookieHeader := false
+	for _, header := range request.Headers {
+		name := strings.ToLower(header.Name)
+		if name == "cookie" {
+			cookieHeader = true
+			if cookies, err := http.ParseCookie(header.Value); err == nil {
+				for _, cookie := range cookies {
+					sr.cookies = append(sr.cookies, har.HARCookie{Name: cookie.Name, Value: cookie.Value})
+				}
+			}
+		}
+		if strings.HasPrefix(name, ":") || clientManagedHeaders[name] {
+			continue
+		}
+		sr.headers = append(sr.headers, har.HARHeader{Name: header.Name, Value: header.Value})
+	}
+	if !cookieHeader {
+		for _, cookie := range request.Cookies {
+			sr.cookies = append(sr.cookies, har.HARCookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+
+	postData := request.PostData
+	if postData == nil {
+		return sr
+	}
+	sr.mimeType = postData.MimeType
+	if sr.mimeType == "" {
+		sr.mimeType = har.HeaderValue(sr.headers, "Content-Type")
+	} else if har.HeaderValue(sr.headers, "Content-Type") == "" {
+		sr.headers = append(sr.headers, har.HARHeader{Name: "Content-Type", Value: sr.mimeType})
+	}
+	sr.body = []byte(postData.Text)
+	mediaType, params, _ := mime.ParseMediaType(sr.mimeType)
+	switch {
+	case mediaType == "multipart/form-data":
+		sr.fields = multipartFields(postData, params["boundary"])
+		if len(sr.body) == 0 && len(sr.fields) > 0 {
+			sr.body = encodeMultipart(sr.fields, params["boundary"])
+		}
+	case len(sr.body) == 0 && len(postData.Params) > 0:
+		form := url.Values{}
+		for _, param := range postData.Params {
+			form.Add(param.Name, param.Value)
+		}
+		sr.body = []byte(form.Encode())
+	}
+	return sr
+}
+
+// multipartFields reads the fields of a multipart body from its recorded
+// params, or else by parsing its text
+func multipartFields(postData *har.HARPostData, boundary string) []snippetField {
+	var fields []snippetField
+	if len(postData.Params) > 0 {
+		for _, param := range postData.Params {
+			fields = append(fields, snippetField{name: param.Name, value: param.Value, fileName: param.FileName, contentType: param.ContentType})
+		}
+		return fields
+	}
+	if boundary == "" {
+		return nil
+	}
+	reader := multipart.NewReader(strings.NewReader(postData.Text), boundary)
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			// A body that doesn't parse is sent as it is
+			if err != io.EOF {
+				return nil
+			}
+			return fields
+		}
+		value, err := io.ReadAll(part)
+		if err != nil {
+			return nil
+		}
+		field := snippetField{name: part.FormName(), fileName: part.FileName()}
+		if field.fileName != "" {
+			field.contentType = part.Header.Get("Content-Type")
+		} else {
+			field.value = string(value)
+		}
+		fields = append(fields, field)
+	}
+}
+
+// encodeMultipart encodes fields recorded only as params, leaving files empty
+func encodeMultipart(fields []snippetField, boundary string) []byte {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	if boundary != "" {
+		writer.SetBoundary(boundary)
+	}
+	for _, field := range fields {
+		if field.fileName != "" {
+			writer.CreateFormFile(field.name, field.fileName)
+			continue
+		}
+		writer.WriteField(field.name, field.value)
+	}
+	writer.Close()
+	return buf.Bytes()
+}
+
+// binary reports whether the body can't be written as text
+func (sr snippetRequest) binary() bool {
+	if !utf8.Valid(sr.body) {
+		return true
+	}
+	for _, c := range sr.body {
+		if c < 0x20 && c != '\t' && c != '\n' && c != '\r' {
+			return true
+		}
+	}
+	return false
+}
+
+// multipart reports whether the body is a form the target should build itself
+func (sr snippetRequest) multipart() bool {
+	return len(sr.fields) > 0
+}
+
+// headersForBody returns the headers to send with the body a target
+// builds; multipart clients set their own Content-Type with a new boundary
+func (sr snippetRequest) headersForBody() []har.HARHeader {
+	if !sr.multipart() {
+		return sr.headers
+	}
+	var headers []har.HARHeader
+	for _, header := range sr.headers {
+		if !strings.EqualFold(header.Name, "Content-Type") {
+			headers = append(headers, header)
+		}
+	}
+	return headers
+}
+
+// cookieHeader joins the cookies into a Cookie header value
+func (sr snippetRequest) cookieHeader() string {
+	pairs := make([]string, len(sr.cookies))
+	for i, cookie := range sr.cookies {
+		pairs[i] = cookie.Name + "=" + cookie.Value
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// headersWithCookie returns the headers to send with the cookies folded
+// back into a Cookie header, for targets without a cookie option
+func (sr snippetRequest) headersWithCookie() []har.HARHeader {
+	headers := sr.headersForBody()
+	if len(sr.cookies) > 0 {
+		headers = append(append([]har.HARHeader(nil), headers...), har.HARHeader{Name: "Cookie", Value: sr.cookieHeader()})
+	}
+	return headers
+}
+
+// mergedHeaders folds repeated headers into one, for targets that take
+// headers as a map; values are joined with ", " as HTTP allows
+func mergedHeaders(headers []har.HARHeader) []har.HARHeader {
+	var merged []har.HARHeader
+	index := map[string]int{}
+	for _, header := range headers {
+		key := strings.ToLower(header.Name)
+		if i, ok := index[key]; ok {
+			merged[i].Value += ", " + header.Value
+			continue
+		}
+		index[key] = len(merged)
+		merged = append(merged, har.HARHeader{Name: header.Name, Value: header.Value})
+	}
+	return merged
+}
+
+// jsonString quotes text as a JSON string, which JavaScript and Python
+// read as a string literal too
+func jsonString(text string) string {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(text)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// shellQuote quotes a word for a POSIX shell, leaving words that need no
+// quoting as they are
+func shellQuote(word string) string {
+	if word != "" && strings.Trim(word, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./:=@%+,") == "" {
+		return word
+	}
+	return singleQuote(word)
+}
+
+// singleQuote single-quotes a word for a POSIX shell; an embedded quote
+// closes the string, is escaped, and reopens it
+func singleQuote(word string) string {
+	return "'" + strings.ReplaceAll(word, "'", `'\''`) + "'"
+}
+
+// base64Body encodes a binary body
+func (sr snippetRequest) base64Body() string {
+	return base64.StdEncoding.EncodeToString(sr.body)
+}
+
+// generateRawHTTP renders the request as HTTP/1.1 on the wire
+func generateRawHTTP(entry har.HAREntry) string {
+	sr := newSnippetRequest(entry)
+	target, host := sr.url, ""
+	if u, err := url.Parse(sr.url); err == nil && u.Host != "" {
+		target, host = u.RequestURI(), u.Host
+	}
+	for _, header := range entry.Request.Headers {
+		if name := strings.ToLower(header.Name); name == "host" || name == ":authority" {
+			host = header.Value
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s HTTP/1.1\r\n", sr.method, target)
+	fmt.Fprintf(&b, "Host: %s\r\n", host)
+	// The body is sent as recorded, so a multipart body keeps its boundary
+	for _, header := range sr.headers {
+		fmt.Fprintf(&b, "%s: %s\r\n", header.Name, header.Value)
+	}
+	if len(sr.cookies) > 0 {
+		fmt.Fprintf(&b, "Cookie: %s\r\n", sr.cookieHeader())
+	}
+	if len(sr.body) > 0 {
+		fmt.Fprintf(&b, "Content-Length: %d\r\n", len(sr.body))
+	}
+	b.WriteString("\r\n")
+	b.Write(sr.body)
+	return b.String()
+}
@@ -0,0 +1,99 @@
+package export
+
+import (
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// generateGo renders the request as a Go program using net/http
+func generateGo(entry har.HAREntry) string {
+	sr := newSnippetRequest(entry)
+	imports := map[string]bool{"fmt": true, "io": true, "net/http": true}
+	var b strings.Builder
+
+	bodyArg := "nil"
+	switch {
+	case sr.multipart():
+		imports["bytes"], imports["mime/multipart"] = true, true
+		b.WriteString("\tvar body bytes.Buffer\n\tform := multipart.NewWriter(&body)\n")
+		for _, field := range sr.fields {
+			if field.fileName == "" {
+				fmt.Fprintf(&b, "\tif err := form.WriteField(%s, %s); err != nil {\n\t\tpanic(err)\n\t}\n", goString(field.name), goString(field.value))
+				continue
+			}
+			imports["os"] = true
+			b.WriteString("\t{\n")
+			fmt.Fprintf(&b, "\t\tfile, err := os.Open(%s)\n\t\tif err != nil {\n\t\t\tpanic(err)\n\t\t}\n\t\tdefer file.Close()\n", goString(field.fileName))
+			if field.contentType == "" {
+				fmt.Fprintf(&b, "\t\tpart, err := form.CreateFormFile(%s, %s)\n", goString(field.name), goString(field.fileName))
+			} else {
+				imports["net/textproto"] = true
+				disposition := fmt.Sprintf("form-data; name=%s; filename=%s", strconv.Quote(field.name), strconv.Quote(field.fileName))
+				fmt.Fprintf(&b, "\t\theader := make(textproto.MIMEHeader)\n\t\theader.Set(\"Content-Disposition\", %s)\n\t\theader.Set(\"Content-Type\", %s)\n", goString(disposition), goString(field.contentType))
+				b.WriteString("\t\tpart, err := form.CreatePart(header)\n")
+			}
+			b.WriteString("\t\tif err != nil {\n\t\t\tpanic(err)\n\t\t}\n\t\tif _, err := io.Copy(part, file); err != nil {\n\t\t\tpanic(err)\n\t\t}\n\t}\n")
+		}
+		b.WriteString("\tif err := form.Close(); err != nil {\n\t\tpanic(err)\n\t}\n\n")
+		bodyArg = "&body"
+	case sr.binary():
+		imports["bytes"] = true
+		fmt.Fprintf(&b, "\tbody := bytes.NewReader([]byte(%s))\n", strconv.Quote(string(sr.body)))
+		bodyArg = "body"
+	case len(sr.body) > 0:
+		imports["strings"] = true
+		fmt.Fprintf(&b, "\tbody := strings.NewReader(%s)\n", goString(string(sr.body)))
+		bodyArg = "body"
+	}
+
+	fmt.Fprintf(&b, "\treq, err := http.NewRequest(%s, %s, %s)\n\tif err != nil {\n\t\tpanic(err)\n\t}\n", goString(sr.method), goString(sr.url), bodyArg)
+	for _, header := range sr.headersForBody() {
+		fmt.Fprintf(&b, "\treq.Header.Add(%s, %s)\n", goString(header.Name), goString(header.Value))
+	}
+	if sr.multipart() {
+		b.WriteString("\treq.Header.Set(\"Content-Type\", form.FormDataContentType())\n")
+	}
+	for _, cookie := range sr.cookies {
+		fmt.Fprintf(&b, "\treq.AddCookie(&http.Cookie{Name: %s, Value: %s})\n", goString(cookie.Name), goString(cookie.Value))
+	}
+	b.WriteString(`
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(resp.Status)
+	fmt.Println(string(data))
+}
+`)
+
+	var program strings.Builder
+	program.WriteString("package main\n\nimport (\n")
+	for path := range imports {
+		fmt.Fprintf(&program, "\t%q\n", path)
+	}
+	program.WriteString(")\n\nfunc main() {\n")
+	program.WriteString(b.String())
+	// gofmt sorts the imports
+	if formatted, err := format.Source([]byte(program.String())); err == nil {
+		return string(formatted)
+	}
+	return program.String()
+}
+
+// goString quotes text as a Go string literal, raw when that reads better
+func goString(text string) string {
+	if strings.Contains(text, "\n") && !strings.ContainsAny(text, "`\r") && strconv.CanBackquote(strings.ReplaceAll(text, "\n", "")) {
+		return "`" + text + "`"
+	}
+	return strconv.Quote(text)
+}
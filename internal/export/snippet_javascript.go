@@ -0,0 +1,114 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// jsHeaders writes headers as the members of a JavaScript object literal
+func jsHeaders(b *strings.Builder, headers []har.HARHeader, indent string) {
+	merged := mergedHeaders(headers)
+	b.WriteString("{\n")
+	for i, header := range merged {
+		fmt.Fprintf(b, "%s  %s: %s", indent, jsonString(header.Name), jsonString(header.Value))
+		if i < len(merged)-1 {
+			b.WriteString(",")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + "}")
+}
+
+// jsForm writes the statements building a FormData for a multipart body
+func jsForm(b *strings.Builder, sr snippetRequest) {
+	b.WriteString("const form = new FormData();\n")
+	for _, field := range sr.fields {
+		if field.fileName == "" {
+			fmt.Fprintf(b, "form.append(%s, %s);\n", jsonString(field.name), jsonString(field.value))
+			continue
+		}
+		options := ""
+		if field.contentType != "" {
+			options = fmt.Sprintf(", { type: %s }", jsonString(field.contentType))
+		}
+		fmt.Fprintf(b, "form.append(%s, await fs.openAsBlob(%s%s), %s);\n",
+			jsonString(field.name), jsonString(field.fileName), options, jsonString(field.fileName))
+	}
+	b.WriteString("\n")
+}
+
+// jsImports writes the imports a snippet needs; uploaded files are read
+// with Node's fs
+func jsImports(b *strings.Builder, sr snippetRequest, imports ...string) {
+	for _, field := range sr.fields {
+		if field.fileName != "" {
+			imports = append(imports, `import fs from "node:fs";`)
+			break
+		}
+	}
+	for _, line := range imports {
+		b.WriteString(line + "\n")
+	}
+	if len(imports) > 0 {
+		b.WriteString("\n")
+	}
+}
+
+// jsBody returns the expression for the request body
+func jsBody(sr snippetRequest) string {
+	switch {
+	case sr.multipart():
+		return "form"
+	case sr.binary():
+		return fmt.Sprintf("Uint8Array.from(atob(%s), (c) => c.charCodeAt(0))", jsonString(sr.base64Body()))
+	}
+	return jsonString(string(sr.body))
+}
+
+// generateFetch renders the request with the Fetch API
+func generateFetch(entry har.HAREntry) string {
+	sr := newSnippetRequest(entry)
+	var b strings.Builder
+	jsImports(&b, sr)
+	if sr.multipart() {
+		jsForm(&b, sr)
+	}
+	fmt.Fprintf(&b, "const response = await fetch(%s, {\n", jsonString(sr.url))
+	fmt.Fprintf(&b, "  method: %s", jsonString(sr.method))
+	if headers := sr.headersWithCookie(); len(headers) > 0 {
+		b.WriteString(",\n  headers: ")
+		jsHeaders(&b, headers, "  ")
+	}
+	if len(sr.body) > 0 {
+		fmt.Fprintf(&b, ",\n  body: %s", jsBody(sr))
+	}
+	b.WriteString("\n});\n\n")
+	b.WriteString("console.log(response.status, await response.text());\n")
+	return b.String()
+}
+
+// generateAxios renders the request with axios
+func generateAxios(entry har.HAREntry) string {
+	sr := newSnippetRequest(entry)
+	var b strings.Builder
+	jsImports(&b, sr, `import axios from "axios";`)
+	if sr.multipart() {
+		jsForm(&b, sr)
+	}
+	b.WriteString("const response = await axios({\n")
+	fmt.Fprintf(&b, "  method: %s,\n", jsonString(strings.ToLower(sr.method)))
+	fmt.Fprintf(&b, "  url: %s", jsonString(sr.url))
+	if headers := sr.headersWithCookie(); len(headers) > 0 {
+		b.WriteString(",\n  headers: ")
+		jsHeaders(&b, headers, "  ")
+	}
+	if len(sr.body) > 0 {
+		fmt.Fprintf(&b, ",\n  data: %s", jsBody(sr))
+	}
+	// Keep the body as text instead of having axios parse JSON
+	b.WriteString(",\n  responseType: \"text\"\n});\n\n")
+	b.WriteString("console.log(response.status, response.data);\n")
+	return b.String()
+}
@@ -0,0 +1,119 @@
+package export
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// powerShellMethods are the values Invoke-WebRequest's -Method accepts;
+// other methods need -CustomMethod
+var powerShellMethods = map[string]string{
+	"GET": "Get", "HEAD": "Head", "POST": "Post", "PUT": "Put", "DELETE": "Delete",
+	"TRACE": "Trace", "OPTIONS": "Options", "MERGE": "Merge", "PATCH": "Patch",
+}
+
+// powerShellString quotes text as a verbatim PowerShell string
+func powerShellString(text string) string {
+	return "'" + strings.ReplaceAll(text, "'", "''") + "'"
+}
+
+// generatePowerShell renders the request with Invoke-WebRequest
+func generatePowerShell(entry har.HAREntry) string {
+	sr := newSnippetRequest(entry)
+	var b strings.Builder
+	args := []string{"-Uri " + powerShellString(sr.url)}
+	if method, ok := powerShellMethods[sr.method]; ok {
+		args = append(args, "-Method "+method)
+	} else {
+		args = append(args, "-CustomMethod "+powerShellString(sr.method))
+	}
+
+	// User-Agent and Content-Type are restricted headers with options of
+	// their own
+	var headers []har.HARHeader
+	userAgent, contentType := "", ""
+	for _, header := range mergedHeaders(sr.headersForBody()) {
+		switch strings.ToLower(header.Name) {
+		case "user-agent":
+			userAgent = header.Value
+		case "content-type":
+			contentType = header.Value
+		default:
+			headers = append(headers, header)
+		}
+	}
+
+	if len(sr.cookies) > 0 || userAgent != "" {
+		b.WriteString("$session = New-Object Microsoft.PowerShell.Commands.WebRequestSession\n")
+		if userAgent != "" {
+			fmt.Fprintf(&b, "$session.UserAgent = %s\n", powerShellString(userAgent))
+		}
+		domain := ""
+		if u, err := url.Parse(sr.url); err == nil {
+			domain = u.Hostname()
+		}
+		for _, cookie := range sr.cookies {
+			fmt.Fprintf(&b, "$session.Cookies.Add((New-Object System.Net.Cookie(%s, %s, '/', %s)))\n",
+				powerShellString(cookie.Name), powerShellString(cookie.Value), powerShellString(domain))
+		}
+		args = append(args, "-WebSession $session")
+	}
+	if len(headers) > 0 {
+		b.WriteString("$headers = @{\n")
+		for _, header := range headers {
+			fmt.Fprintf(&b, "    %s = %s\n", powerShellString(header.Name), powerShellString(header.Value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "-Headers $headers")
+	}
+
+	switch {
+	case sr.multipart():
+		// -Form needs PowerShell 7; a repeated field takes an array
+		var names []string
+		values := map[string][]string{}
+		for _, field := range sr.fields {
+			value := powerShellString(field.value)
+			if field.fileName != "" {
+				value = "(Get-Item -Path " + powerShellString(field.fileName) + ")"
+			}
+			if _, ok := values[field.name]; !ok {
+				names = append(names, field.name)
+			}
+			values[field.name] = append(values[field.name], value)
+		}
+		b.WriteString("$form = [ordered]@{\n")
+		for _, name := range names {
+			value := values[name][0]
+			if len(values[name]) > 1 {
+				value = "@(" + strings.Join(values[name], ", ") + ")"
+			}
+			fmt.Fprintf(&b, "    %s = %s\n", powerShellString(name), value)
+		}
+		b.WriteString("}\n")
+		args = append(args, "-Form $form")
+	case sr.binary():
+		if contentType != "" {
+			args = append(args, "-ContentType "+powerShellString(contentType))
+		}
+		fmt.Fprintf(&b, "$body = [Convert]::FromBase64String(%s)\n", powerShellString(sr.base64Body()))
+		args = append(args, "-Body $body")
+	case len(sr.body) > 0:
+		if contentType != "" {
+			args = append(args, "-ContentType "+powerShellString(contentType))
+		}
+		fmt.Fprintf(&b, "$body = %s\n", powerShellString(string(sr.body)))
+		args = append(args, "-Body $body")
+	}
+
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "$response = Invoke-WebRequest %s\n", strings.Join(args, " `\n    "))
+	b.WriteString("$response.StatusCode\n")
+	b.WriteString("$response.Content\n")
+	return b.String()
+}
@@ -0,0 +1,139 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// pythonClient is a Python HTTP library the request can be written for
+type pythonClient struct {
+	module string
+	// bodyArgument is the keyword raw bodies are passed as
+	bodyArgument string
+	// cookiesArgument is whether cookies are passed on their own rather than
+	// in the Cookie header
+	cookiesArgument bool
+	// bodyMethods are the method functions taking a body, nil for all
+	bodyMethods map[string]bool
+}
+
+// pythonMethods are the methods both libraries have a function for; others
+// go through request()
+var pythonMethods = map[string]bool{"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true, "HEAD": true, "OPTIONS": true}
+
+var (
+	pythonRequests = pythonClient{
+		module:          "requests",
+		bodyArgument:    "data",
+		cookiesArgument: true,
+	}
+	// httpx deprecates per-request cookies, and only its post, put and
+	// patch functions take a body
+	pythonHTTPX = pythonClient{
+		module:       "httpx",
+		bodyArgument: "content",
+		bodyMethods:  map[string]bool{"POST": true, "PUT": true, "PATCH": true},
+	}
+)
+
+// generatePythonRequests renders the request with requests
+func generatePythonRequests(entry har.HAREntry) string {
+	return pythonRequests.generate(entry)
+}
+
+// generatePythonHTTPX renders the request with httpx
+func generatePythonHTTPX(entry har.HAREntry) string {
+	return pythonHTTPX.generate(entry)
+}
+
+func (client pythonClient) generate(entry har.HAREntry) string {
+	sr := newSnippetRequest(entry)
+	var b strings.Builder
+	fmt.Fprintf(&b, "import %s\n\n", client.module)
+	fmt.Fprintf(&b, "url = %s\n", jsonString(sr.url))
+	args := []string{"url"}
+
+	headers := sr.headersWithCookie()
+	if client.cookiesArgument {
+		headers = sr.headersForBody()
+	}
+	if len(headers) > 0 {
+		b.WriteString("headers = {\n")
+		for _, header := range mergedHeaders(headers) {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsonString(header.Name), jsonString(header.Value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "headers=headers")
+	}
+	if client.cookiesArgument && len(sr.cookies) > 0 {
+		b.WriteString("cookies = {\n")
+		for _, cookie := range sr.cookies {
+			fmt.Fprintf(&b, "    %s: %s,\n", jsonString(cookie.Name), jsonString(cookie.Value))
+		}
+		b.WriteString("}\n")
+		args = append(args, "cookies=cookies")
+	}
+
+	switch {
+	case sr.multipart():
+		// A list keeps the fields in order, and (None, value) sends text fields
+		// as form fields rather than files
+		b.WriteString("files = [\n")
+		for _, field := range sr.fields {
+			if field.fileName == "" {
+				fmt.Fprintf(&b, "    (%s, (None, %s)),\n", jsonString(field.name), jsonString(field.value))
+				continue
+			}
+			file := fmt.Sprintf("(%s, open(%s, \"rb\")", jsonString(field.fileName), jsonString(field.fileName))
+			if field.contentType != "" {
+				file += ", " + jsonString(field.contentType)
+			}
+			fmt.Fprintf(&b, "    (%s, %s)),\n", jsonString(field.name), file)
+		}
+		b.WriteString("]\n")
+		args = append(args, "files=files")
+	case sr.binary():
+		fmt.Fprintf(&b, "body = %s\n", pythonBytes(sr.body))
+		args = append(args, client.bodyArgument+"=body")
+	case len(sr.body) > 0:
+		// Encoded explicitly: a str body is sent as Latin-1 by requests
+		fmt.Fprintf(&b, "body = %s.encode()\n", jsonString(string(sr.body)))
+		args = append(args, client.bodyArgument+"=body")
+	}
+
+	call := fmt.Sprintf("%s.request(%s, ", client.module, jsonString(sr.method))
+	if pythonMethods[sr.method] && (len(sr.body) == 0 || client.bodyMethods == nil || client.bodyMethods[sr.method]) {
+		call = fmt.Sprintf("%s.%s(", client.module, strings.ToLower(sr.method))
+	}
+	fmt.Fprintf(&b, "\nresponse = %s%s)\n", call, strings.Join(args, ", "))
+	b.WriteString("print(response.status_code)\n")
+	b.WriteString("print(response.text)\n")
+	return b.String()
+}
+
+// pythonBytes writes data as a Python bytes literal
+func pythonBytes(data []byte) string {
+	var b strings.Builder
+	b.WriteString(`b"`)
+	for _, c := range data {
+		switch {
+		case c == '\\' || c == '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c == '\n':
+			b.WriteString(`\n`)
+		case c == '\r':
+			b.WriteString(`\r`)
+		case c == '\t':
+			b.WriteString(`\t`)
+		case c >= 0x20 && c < 0x7f:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, `\x%02x`, c)
+		}
+	}
+	b.WriteString(`"`)
+	return b.String()
+}
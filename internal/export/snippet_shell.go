@@ -0,0 +1,93 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// shellCommand joins words into a command line, one option per line
+func shellCommand(words []string) string {
+	return strings.Join(words, " \\\n  ") + "\n"
+}
+
+// base64Pipe returns the pipeline feeding a binary body to a command on
+// standard input
+func base64Pipe(sr snippetRequest) string {
+	return fmt.Sprintf("echo %s | base64 -d | ", shellQuote(sr.base64Body()))
+}
+
+// httpieItem writes a request item, escaping separators HTTPie would
+// otherwise find in the key or at the start of the value
+func httpieItem(key, separator, value string) string {
+	for _, c := range []string{`\`, "=", ":", "@", ";"} {
+		key = strings.ReplaceAll(key, c, `\`+c)
+	}
+	if strings.HasPrefix(value, "=") || strings.HasPrefix(value, "@") {
+		value = `\` + value
+	}
+	return shellQuote(key + separator + value)
+}
+
+// generateHTTPie renders the request as an HTTPie command
+func generateHTTPie(entry har.HAREntry) string {
+	sr := newSnippetRequest(entry)
+	prefix := ""
+	words := []string{"http"}
+	switch {
+	case sr.multipart():
+		words = append(words, "--multipart")
+	case sr.binary():
+		// HTTPie sends standard input as the body
+		prefix = base64Pipe(sr)
+	case len(sr.body) > 0:
+		words = append(words, "--raw", shellQuote(string(sr.body)))
+	}
+	words = append(words, shellQuote(sr.method), shellQuote(sr.url))
+	for _, header := range sr.headersWithCookie() {
+		if header.Value == "" {
+			// "Name:" would unset the header instead of sending it empty
+			words = append(words, httpieItem(header.Name, ";", ""))
+			continue
+		}
+		words = append(words, httpieItem(header.Name, ":", header.Value))
+	}
+	for _, field := range sr.fields {
+		if field.fileName == "" {
+			words = append(words, httpieItem(field.name, "=", field.value))
+			continue
+		}
+		file := field.fileName
+		if field.contentType != "" {
+			file += ";type=" + field.contentType
+		}
+		words = append(words, httpieItem(field.name, "@", file))
+	}
+	return prefix + shellCommand(words)
+}
+
+// generateWget renders the request as a wget command
+func generateWget(entry har.HAREntry) string {
+	sr := newSnippetRequest(entry)
+	prefix := ""
+	words := []string{"wget", "--quiet", "--output-document=-", "--content-on-error", "--method=" + shellQuote(sr.method)}
+	// wget can't build a form, so a multipart body is sent as recorded,
+	// boundary and all
+	headers := sr.headers
+	if len(sr.cookies) > 0 {
+		headers = append(append([]har.HARHeader(nil), headers...), har.HARHeader{Name: "Cookie", Value: sr.cookieHeader()})
+	}
+	for _, header := range headers {
+		words = append(words, "--header="+shellQuote(header.Name+": "+header.Value))
+	}
+	switch {
+	case sr.binary():
+		prefix = base64Pipe(sr)
+		words = append(words, "--body-file=/dev/stdin")
+	case len(sr.body) > 0:
+		words = append(words, "--body-data="+shellQuote(string(sr.body)))
+	}
+	words = append(words, shellQuote(sr.url))
+	return prefix + shellCommand(words)
+}
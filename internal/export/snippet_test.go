@@ -0,0 +1,163 @@
+package export
+
+import (
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func jsonEntry() har.HAREntry {
+	return har.HAREntry{Request: har.HARRequest{
+		Method: "POST",
+		URL:    "https://api.example.com/items?q=1",
+		Headers: []har.HARHeader{
+			{Name: "Host", Value: "api.example.com"},
+			{Name: "Accept", Value: "application/json"},
+			{Name: "Accept", Value: "text/plain"},
+			{Name: "Cookie", Value: "session=abc; theme=dark"},
+			{Name: "Content-Length", Value: "22"},
+			{Name: "User-Agent", Value: "test"},
+		},
+		PostData: &har.HARPostData{MimeType: "application/json", Text: `{"name":"it's \"new\""}`},
+	}}
+}
+
+func multipartEntry() har.HAREntry {
+	return har.HAREntry{Request: har.HARRequest{
+		Method:  "POST",
+		URL:     "https://example.com/upload",
+		Headers: []har.HARHeader{{Name: "Content-Type", Value: "multipart/form-data; boundary=XyZ"}},
+		PostData: &har.HARPostData{
+			MimeType: "multipart/form-data; boundary=XyZ",
+			Text: "--XyZ\r\nContent-Disposition: form-data; name=\"title\"\r\n\r\nHoliday\r\n" +
+				"--XyZ\r\nContent-Disposition: form-data; name=\"photo\"; filename=\"beach.png\"\r\nContent-Type: image/png\r\n\r\n\x89PNG\r\n" +
+				"--XyZ--\r\n",
+		},
+	}}
+}
+
+func binaryEntry() har.HAREntry {
+	return har.HAREntry{Request: har.HARRequest{
+		Method:   "PUT",
+		URL:      "https://example.com/blob",
+		PostData: &har.HARPostData{MimeType: "application/octet-stream", Text: "\x00\x01\xff"},
+	}}
+}
+
+func TestSnippetRequest(t *testing.T) {
+	sr := newSnippetRequest(jsonEntry())
+	for _, header := range sr.headers {
+		if name := strings.ToLower(header.Name); name == "host" || name == "cookie" || name == "content-length" {
+			t.Errorf("client-managed header %s kept", header.Name)
+		}
+	}
+	if len(sr.cookies) != 2 || sr.cookieHeader() != "session=abc; theme=dark" {
+		t.Errorf("cookies = %+v", sr.cookies)
+	}
+	if har.HeaderValue(sr.headers, "content-type") != "application/json" {
+		t.Errorf("missing Content-Type from the mime type: %+v", sr.headers)
+	}
+
+	sr = newSnippetRequest(multipartEntry())
+	if len(sr.fields) != 2 || sr.fields[0].value != "Holiday" || sr.fields[1].fileName != "beach.png" || sr.fields[1].contentType != "image/png" {
+		t.Errorf("fields = %+v", sr.fields)
+	}
+	if har.HeaderValue(sr.headersForBody(), "Content-Type") != "" {
+		t.Error("multipart targets should set their own Content-Type")
+	}
+
+	params := har.HAREntry{Request: har.HARRequest{Method: "POST", URL: "https://example.com/", PostData: &har.HARPostData{
+		MimeType: "application/x-www-form-urlencoded",
+		Params:   []har.HARParam{{Name: "a", Value: "1 2"}, {Name: "b", Value: "&"}},
+	}}}
+	if body := string(newSnippetRequest(params).body); body != "a=1+2&b=%26" {
+		t.Errorf("form body = %q", body)
+	}
+}
+
+func TestSnippetTargets(t *testing.T) {
+	entries := map[string]har.HAREntry{"json": jsonEntry(), "multipart": multipartEntry(), "binary": binaryEntry()}
+	for _, target := range SnippetTargets() {
+		for kind, entry := range entries {
+			code := target.Generate(entry)
+			if !strings.Contains(code, "example.com") {
+				t.Errorf("%s %s: no URL in\n%s", target.Name, kind, code)
+			}
+		}
+	}
+
+	want := map[string][]string{
+		"fetch":           {`"Accept": "application/json, text/plain"`, `"Cookie": "session=abc; theme=dark"`, `body: "{\"name\":\"it's \\\"new\\\"\"}"`},
+		"axios":           {`import axios from "axios";`, `method: "post"`, `data: "{\"name\"`},
+		"python-requests": {`"session": "abc"`, "cookies=cookies", "requests.post(url", ".encode()"},
+		"python-httpx":    {`"Cookie": "session=abc; theme=dark"`, "content=body", "httpx.post(url"},
+		"go":              {`req.AddCookie(&http.Cookie{Name: "session", Value: "abc"})`, `req.Header.Add("Accept", "text/plain")`},
+		"httpie":          {"--raw \\\n  '{\"name\":\"it'\\''s", "Accept:application/json", "'Cookie:session=abc; theme=dark'"},
+		"wget":            {"--method=POST", `--header='Cookie: session=abc; theme=dark'`},
+		"powershell":      {"$session.UserAgent = 'test'", "-ContentType 'application/json'", `$body = '{"name":"it''s \"new\""}'`, "System.Net.Cookie('session', 'abc', '/', 'api.example.com')"},
+		"http":            {"POST /items?q=1 HTTP/1.1\r\nHost: api.example.com\r\n", "Content-Length: 23\r\n\r\n{"},
+	}
+	for name, parts := range want {
+		code, err := GenerateSnippet(name, jsonEntry())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, part := range parts {
+			if !strings.Contains(code, part) {
+				t.Errorf("%s: missing %q in\n%s", name, part, code)
+			}
+		}
+	}
+
+	if _, err := GenerateSnippet("cobol", jsonEntry()); err == nil || !strings.Contains(err.Error(), "python-httpx") {
+		t.Errorf("expected an error listing the targets, got %v", err)
+	}
+}
+
+func TestSnippetBodies(t *testing.T) {
+	want := map[string][]string{
+		"fetch":           {`await fs.openAsBlob("beach.png", { type: "image/png" }), "beach.png"`, `form.append("title", "Holiday")`},
+		"python-requests": {`("title", (None, "Holiday"))`, `("photo", ("beach.png", open("beach.png", "rb"), "image/png"))`},
+		"go":              {"form.WriteField(\"title\", \"Holiday\")", "form.FormDataContentType()", `os.Open("beach.png")`},
+		"httpie":          {"--multipart", "title=Holiday", "'photo@beach.png;type=image/png'"},
+		"wget":            {"boundary=XyZ", "--body-file=/dev/stdin"},
+		"powershell":      {"'photo' = (Get-Item -Path 'beach.png')", "-Form $form"},
+	}
+	for name, parts := range want {
+		code, _ := GenerateSnippet(name, multipartEntry())
+		for _, part := range parts {
+			if !strings.Contains(code, part) {
+				t.Errorf("%s multipart: missing %q in\n%s", name, part, code)
+			}
+		}
+	}
+
+	want = map[string][]string{
+		"fetch":           {`atob("AAH/")`},
+		"python-requests": {`b"\x00\x01\xff"`},
+		"go":              {`bytes.NewReader([]byte("\x00\x01\xff"))`},
+		"httpie":          {"echo AAH/ | base64 -d | http"},
+		"wget":            {"--body-file=/dev/stdin"},
+		"powershell":      {"[Convert]::FromBase64String('AAH/')"},
+	}
+	for name, parts := range want {
+		code, _ := GenerateSnippet(name, binaryEntry())
+		for _, part := range parts {
+			if !strings.Contains(code, part) {
+				t.Errorf("%s binary: missing %q in\n%s", name, part, code)
+			}
+		}
+	}
+}
+
+func TestGoSnippetParses(t *testing.T) {
+	for _, entry := range []har.HAREntry{jsonEntry(), multipartEntry(), binaryEntry(), {Request: har.HARRequest{URL: "https://example.com/"}}} {
+		code := generateGo(entry)
+		if _, err := parser.ParseFile(token.NewFileSet(), "main.go", code, 0); err != nil {
+			t.Errorf("%v in\n%s", err, code)
+		}
+	}
+}
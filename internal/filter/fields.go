@@ -0,0 +1,128 @@
+package filter
+
+import (
+	"net/url"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// FieldKind says which part of an entry a Field holds
+type FieldKind int
+
+// Field kinds, in the order WalkFields visits them
+const (
+	FieldURL        FieldKind = iota // the whole URL, outside the all scope
+	FieldHost                        // the URL's host
+	FieldPath                        // the URL's path
+	FieldQuery                       // the URL's raw query string
+	FieldMethod                      // the request method
+	FieldHeader                      // a header; Name is set
+	FieldStatusText                  // the response status text
+	FieldMimeType                    // the response content type
+	FieldBody                        // a body, response bodies decoded
+	FieldCookie                      // a parsed cookie; Name is set
+)
+
+// Field is one searchable value of an entry
+type Field struct {
+	Kind     FieldKind
+	Response bool   // the value comes from the response
+	Name     string // header or cookie name
+	Value    string
+	MimeType string // type of a body
+}
+
+// Location describes where a field is, e.g. "request header Accept"
+func (f Field) Location() string {
+	side := "request"
+	if f.Response {
+		side = "response"
+	}
+	switch f.Kind {
+	case FieldURL, FieldHost, FieldPath, FieldQuery:
+		return "request URL"
+	case FieldMethod:
+		return "request method"
+	case FieldHeader:
+		return side + " header " + f.Name
+	case FieldStatusText:
+		return "response status"
+	case FieldMimeType:
+		return "response content type"
+	case FieldBody:
+		return side + " body"
+	case FieldCookie:
+		return side + " cookie " + f.Name
+	}
+	return side
+}
+
+// WalkFields calls visit with the fields of an entry that are searched in
+// scope, stopping at the first that returns true. It reports whether one
+// did. Bodies are only visited when bodies is set
+func WalkFields(entry har.HAREntry, scope string, bodies bool, visit func(Field) bool) bool {
+	all := scope == ScopeAll
+
+	// 1. URL (host, path, query parameters)
+	if all {
+		if u, err := url.Parse(entry.Request.URL); err == nil {
+			if visit(Field{Kind: FieldHost, Value: u.Host}) ||
+				visit(Field{Kind: FieldPath, Value: u.Path}) ||
+				visit(Field{Kind: FieldQuery, Value: u.RawQuery}) {
+				return true
+			}
+		}
+	} else if scope == ScopeURL && visit(Field{Kind: FieldURL, Value: entry.Request.URL}) {
+		return true
+	}
+
+	// 2. Request method
+	if all && visit(Field{Kind: FieldMethod, Value: entry.Request.Method}) {
+		return true
+	}
+
+	// 3. Request and response headers
+	if all || scope == ScopeHeaders {
+		for i, headers := range [][]har.HARHeader{entry.Request.Headers, entry.Response.Headers} {
+			for _, header := range headers {
+				if visit(Field{Kind: FieldHeader, Response: i == 1, Name: header.Name, Value: header.Value}) {
+					return true
+				}
+			}
+		}
+	}
+
+	// 4. Response status text and content type
+	if all && (visit(Field{Kind: FieldStatusText, Response: true, Value: entry.Response.StatusText}) ||
+		visit(Field{Kind: FieldMimeType, Response: true, Value: entry.Response.Content.MimeType})) {
+		return true
+	}
+
+	// 5. Request body and decoded response body
+	if bodies && (all || scope == ScopeBody) {
+		if postData := entry.Request.PostData; postData != nil && postData.Text != "" {
+			if visit(Field{Kind: FieldBody, Value: postData.Text, MimeType: postData.MimeType}) {
+				return true
+			}
+		}
+		content := entry.Response.Content
+		if content.Text != "" {
+			if visit(Field{Kind: FieldBody, Response: true, Value: har.DecodeBase64(content.Text, content.Encoding), MimeType: content.MimeType}) {
+				return true
+			}
+		}
+	}
+
+	// 6. Cookies
+	if all {
+		for i, cookies := range [][]har.HARCookie{entry.Request.Cookies, entry.Response.Cookies} {
+			for _, cookie := range cookies {
+				if visit(Field{Kind: FieldCookie, Response: i == 1, Name: cookie.Name, Value: cookie.Value}) {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
@@ -2,7 +2,6 @@ package filter
 
 import (
 	"fmt"
-	"net/url"
 	"regexp"
 	"sort"
 	"strings"
@@ -18,6 +17,19 @@ type FilterState struct {
 	ShowErrorsOnly   bool
 	SortBySlowest    bool
 	ActiveTypeFilter string
+	ActivePage       string // pageref to show, or "" for all pages
+	ActiveSource     string // source file to show, or "" for all files
+	
+	// Search modes for free text in FilterText
+	SearchRegex         bool   // match FilterText as a single RE2 pattern
+	SearchCaseSensitive bool
+	SearchScope         string // one of GetSearchScopes, "" means all fields
+	
+	// FilterText parsed as a query, cached until the text or modes change
+	queryText  string
+	queryMode  searchMode
+	query      QueryNode
+	queryError error
 }
 
 // NewFilterState creates a new filter state
@@ -27,12 +39,16 @@ func NewFilterState() *FilterState {
 		ShowErrorsOnly:   false,
 		SortBySlowest:    false,
 		ActiveTypeFilter: "all",
+		ActivePage:       "",
+		ActiveSource:     "",
+		SearchScope:      ScopeAll,
 	}
 }
 
 // FilterEntries filters HAR entries based on current filter state (legacy O(n) method)
 func (f *FilterState) FilterEntries(entries []har.HAREntry) []int {
 	var filteredEntries []int
+	query := f.compileQuery()
 	
 	for i, entry := range entries {
 		// Apply error filter
@@ -41,10 +57,8 @@ func (f *FilterState) FilterEntries(entries []har.HAREntry) []int {
 		}
 		
 		// Apply text filter
-		if f.FilterText != "" {
-			if !f.matchesTextSearch(entry, strings.ToLower(f.FilterText)) {
-				continue
-			}
+		if query != nil && !query.Match(entry) {
+			continue
 		}
 		
 		// Apply type filter
@@ -55,6 +69,16 @@ func (f *FilterState) FilterEntries(entries []har.HAREntry) []int {
 			}
 		}
 		
+		// Apply page filter
+		if f.ActivePage != "" && entry.Pageref != f.ActivePage {
+			continue
+		}
+		
+		// Apply source filter
+		if f.ActiveSource != "" && entry.Source != f.ActiveSource {
+			continue
+		}
+		
 		filteredEntries = append(filteredEntries, i)
 	}
 	
@@ -87,10 +111,21 @@ func (f *FilterState) FilterEntriesWithIndex(entries []har.HAREntry, index *har.
 		result = util.IntersectIndices(result, errorIndices)
 	}
 	
-	// Apply text filter (still O(n) but only on filtered set)
-	if f.FilterText != "" {
-		textIndices := index.FilterByText(entries, f.FilterText)
-		result = util.IntersectIndices(result, textIndices)
+	// Apply page filter using index
+	if f.ActivePage != "" {
+		pageIndices := index.GetByPage(f.ActivePage)
+		result = util.IntersectIndices(result, pageIndices)
+	}
+	
+	// Apply source filter using index
+	if f.ActiveSource != "" {
+		sourceIndices := index.GetBySource(f.ActiveSource)
+		result = util.IntersectIndices(result, sourceIndices)
+	}
+	
+	// Apply text filter, using index lookups for the query terms the index covers
+	if query := f.compileQuery(); query != nil {
+		result = EvaluateQuery(query, entries, index, result)
 	}
 	
 	// Sort if requested
@@ -104,12 +139,14 @@ func (f *FilterState) FilterEntriesWithIndex(entries []har.HAREntry, index *har.
 }
 
 
-// Reset resets all filters to their default state
+// Reset resets all filters to their default state. Search modes are kept
 func (f *FilterState) Reset() {
 	f.FilterText = ""
 	f.ShowErrorsOnly = false
 	f.SortBySlowest = false
 	f.ActiveTypeFilter = "all"
+	f.ActivePage = ""
+	f.ActiveSource = ""
 }
 
 // ToggleErrorsOnly toggles the errors-only filter
@@ -132,91 +169,74 @@ func (f *FilterState) SetTypeFilter(filterType string) {
 	f.ActiveTypeFilter = filterType
 }
 
-// GetTypeFilters returns available type filters
-func GetTypeFilters() []string {
-	return []string{"all", "fetch", "doc", "css", "js", "img", "media", "manifest", "cors", "ws", "wasm", "other"}
+// SetPageFilter sets the page filter ("" shows every page)
+func (f *FilterState) SetPageFilter(pageref string) {
+	f.ActivePage = pageref
 }
 
-// matchesTextSearch performs comprehensive text matching across all request/response fields
-func (f *FilterState) matchesTextSearch(entry har.HAREntry, searchText string) bool {
-	// 1. Search URL (host, path, query parameters)
-	if u, err := url.Parse(entry.Request.URL); err == nil {
-		if strings.Contains(strings.ToLower(u.Host), searchText) ||
-		   strings.Contains(strings.ToLower(u.Path), searchText) ||
-		   strings.Contains(strings.ToLower(u.RawQuery), searchText) {
-			return true
-		}
-	}
-	
-	// 2. Search request method
-	if strings.Contains(strings.ToLower(entry.Request.Method), searchText) {
-		return true
+// SetSourceFilter sets the source file filter ("" shows every file)
+func (f *FilterState) SetSourceFilter(source string) {
+	f.ActiveSource = source
+}
+
+// QueryError returns why FilterText failed to parse as a query (or regex), or nil.
+// Searches that don't parse fall back to a plain substring search
+func (f *FilterState) QueryError() error {
+	f.compileQuery()
+	return f.queryError
+}
+
+// compileQuery parses FilterText into a query, reusing the last parse if the text and modes are unchanged
+func (f *FilterState) compileQuery() QueryNode {
+	mode := f.currentSearchMode()
+	if f.FilterText == f.queryText && mode == f.queryMode && (f.query != nil || f.queryError != nil || f.FilterText == "") {
+		return f.query
 	}
 	
-	// 3. Search request headers (names and values)
-	for _, header := range entry.Request.Headers {
-		if strings.Contains(strings.ToLower(header.Name), searchText) ||
-		   strings.Contains(strings.ToLower(header.Value), searchText) {
-			return true
-		}
+	f.queryText = f.FilterText
+	f.queryMode = mode
+	f.query, f.queryError = nil, nil
+	if f.FilterText == "" {
+		return nil
 	}
 	
-	// 4. Search response headers (names and values)
-	for _, header := range entry.Response.Headers {
-		if strings.Contains(strings.ToLower(header.Name), searchText) ||
-		   strings.Contains(strings.ToLower(header.Value), searchText) {
-			return true
+	if mode.regex {
+		match, err := newRegexMatcher(f.FilterText, mode.caseSensitive)
+		if err == nil {
+			f.query = &textNode{text: f.FilterText, regex: true, scope: mode.scope, match: match}
+			return f.query
 		}
+		f.queryError = regexError(err, f.FilterText)
+	} else {
+		f.query, f.queryError = parseQuery(f.FilterText, mode)
 	}
-	
-	// 5. Search response status text
-	if strings.Contains(strings.ToLower(entry.Response.StatusText), searchText) {
-		return true
+	if f.queryError != nil {
+		f.query = newTextNode(f.FilterText, mode)
 	}
-	
-	// 6. Search response content type
-	if strings.Contains(strings.ToLower(entry.Response.Content.MimeType), searchText) {
+	return f.query
+}
+
+// GetTypeFilters returns available type filters
+func GetTypeFilters() []string {
+	return []string{"all", "fetch", "doc", "css", "js", "img", "media", "manifest", "cors", "ws", "wasm", "other"}
+}
+
+// matchesTextSearch performs comprehensive text matching across the request/response fields in scope.
+// Bodies are only searched when searchBodies is set (the body index says they may match)
+func matchesTextSearch(entry har.HAREntry, scope string, match textMatcher, searchBodies bool) bool {
+	return WalkFields(entry, scope, searchBodies, func(field Field) bool {
+		// Header and cookie names match as well as their values
+		return (field.Name != "" && match(field.Name)) || match(field.Value)
+	})
+}
+
+// matchesBodies searches the request body and decoded response body
+func matchesBodies(entry har.HAREntry, match textMatcher) bool {
+	if entry.Request.PostData != nil && entry.Request.PostData.Text != "" && match(entry.Request.PostData.Text) {
 		return true
 	}
-	
-	// 7. Search request body (if present and not too large)
-	if entry.Request.PostData != nil && entry.Request.PostData.Text != "" {
-		// Limit body search to reasonable size for performance
-		bodyText := entry.Request.PostData.Text
-		if len(bodyText) <= 10000 { // 10KB limit for body search
-			if strings.Contains(strings.ToLower(bodyText), searchText) {
-				return true
-			}
-		}
-	}
-	
-	// 8. Search response body (if present and not too large)
-	if entry.Response.Content.Text != "" {
-		// Decode and search response body
-		bodyText := har.DecodeBase64(entry.Response.Content.Text, entry.Response.Content.Encoding)
-		if len(bodyText) <= 10000 { // 10KB limit for body search
-			if strings.Contains(strings.ToLower(bodyText), searchText) {
-				return true
-			}
-		}
-	}
-	
-	// 9. Search cookies (names and values)
-	for _, cookie := range entry.Request.Cookies {
-		if strings.Contains(strings.ToLower(cookie.Name), searchText) ||
-		   strings.Contains(strings.ToLower(cookie.Value), searchText) {
-			return true
-		}
-	}
-	
-	for _, cookie := range entry.Response.Cookies {
-		if strings.Contains(strings.ToLower(cookie.Name), searchText) ||
-		   strings.Contains(strings.ToLower(cookie.Value), searchText) {
-			return true
-		}
-	}
-	
-	return false
+	content := entry.Response.Content
+	return content.Text != "" && match(har.DecodeBase64(content.Text, content.Encoding))
 }
 
 // GenerateFilteredFilename creates a descriptive filename based on current filters
@@ -224,8 +244,14 @@ func (f *FilterState) GenerateFilteredFilename(originalFilename string) string {
 	// Get timestamp for uniqueness
 	timestamp := time.Now().Format("20060102_150405")
 	
-	// Remove extension from original filename
+	// Remove compression and HAR extensions from original filename
 	baseName := originalFilename
+	if baseName == har.StdinPath {
+		baseName = "stdin.har"
+	}
+	for _, ext := range []string{".gz", ".zst", ".bz2"} {
+		baseName = strings.TrimSuffix(baseName, ext)
+	}
 	if lastDot := strings.LastIndex(baseName, "."); lastDot != -1 {
 		baseName = baseName[:lastDot]
 	}
@@ -238,6 +264,18 @@ func (f *FilterState) GenerateFilteredFilename(originalFilename string) string {
 		filterParts = append(filterParts, f.ActiveTypeFilter)
 	}
 	
+	// Add page filter
+	if f.ActivePage != "" {
+		cleanedPage := regexp.MustCompile(`[^\w\-_.]`).ReplaceAllString(f.ActivePage, "_")
+		filterParts = append(filterParts, "page_" + cleanedPage)
+	}
+	
+	// Add source filter
+	if f.ActiveSource != "" {
+		cleanedSource := regexp.MustCompile(`[^\w\-_.]`).ReplaceAllString(f.ActiveSource, "_")
+		filterParts = append(filterParts, "source_" + cleanedSource)
+	}
+	
 	// Add text filter (cleaned for filename)
 	if f.FilterText != "" {
 		cleanedText := regexp.MustCompile(`[^\w\-_.]`).ReplaceAllString(f.FilterText, "_")
@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/cnharrison/har-tui/internal/har"
@@ -200,7 +201,7 @@ func TestFilterState_TypeFilter(t *testing.T) {
 func TestGetTypeFilters(t *testing.T) {
 	filters := GetTypeFilters()
 	
-	expected := []string{"all", "fetch", "doc", "css", "js", "img", "media", "manifest", "ws", "wasm", "other"}
+	expected := []string{"all", "fetch", "doc", "css", "js", "img", "media", "manifest", "cors", "ws", "wasm", "other"}
 	
 	if len(filters) != len(expected) {
 		t.Errorf("Expected %d type filters, got %d", len(expected), len(filters))
@@ -212,4 +213,114 @@ func TestGetTypeFilters(t *testing.T) {
 			t.Errorf("Expected filter %q at position %d, got %q", expectedFilter, i, filters[i])
 		}
 	}
-}
\ No newline at end of file
+}
+
+func TestFilterState_PageFilter(t *testing.T) {
+	entries := []har.HAREntry{
+		createTestEntry("GET", "https://example.com/", 200, "text/html"),
+		createTestEntry("GET", "https://api.example.com/users", 200, "application/json"),
+		createTestEntry("GET", "https://example.com/next", 200, "text/html"),
+		createTestEntry("GET", "https://api.example.com/beacon", 204, ""),
+	}
+	entries[0].Pageref = "page_1"
+	entries[1].Pageref = "page_1"
+	entries[2].Pageref = "page_2"
+	
+	index := har.NewEntryIndex()
+	for i, entry := range entries {
+		index.AddEntry(entry, i)
+	}
+	
+	fs := NewFilterState()
+	fs.SetPageFilter("page_1")
+	
+	for name, result := range map[string][]int{
+		"FilterEntries":          fs.FilterEntries(entries),
+		"FilterEntriesWithIndex": fs.FilterEntriesWithIndex(entries, index),
+	} {
+		if len(result) != 2 || result[0] != 0 || result[1] != 1 {
+			t.Errorf("%s: expected [0 1] for page_1, got %v", name, result)
+		}
+	}
+	
+	fs.SetTypeFilter("fetch")
+	if result := fs.FilterEntriesWithIndex(entries, index); len(result) != 1 || result[0] != 1 {
+		t.Errorf("Expected [1] for fetch on page_1, got %v", result)
+	}
+	
+	fs.Reset()
+	if fs.ActivePage != "" {
+		t.Errorf("Expected empty ActivePage after reset, got %q", fs.ActivePage)
+	}
+	if result := fs.FilterEntries(entries); len(result) != 4 {
+		t.Errorf("Expected all 4 entries after reset, got %v", result)
+	}
+}
+
+func TestFilterState_SourceFilter(t *testing.T) {
+	entries := []har.HAREntry{
+		createTestEntry("GET", "https://example.com/", 200, "text/html"),
+		createTestEntry("GET", "https://example.com/", 500, "text/html"),
+		createTestEntry("GET", "https://api.example.com/users", 200, "application/json"),
+	}
+	entries[0].Source = "before.har"
+	entries[1].Source = "after.har"
+	entries[2].Source = "after.har"
+	
+	index := har.NewEntryIndex()
+	for i, entry := range entries {
+		index.AddEntry(entry, i)
+	}
+	
+	fs := NewFilterState()
+	fs.SetSourceFilter("after.har")
+	
+	for name, result := range map[string][]int{
+		"FilterEntries":          fs.FilterEntries(entries),
+		"FilterEntriesWithIndex": fs.FilterEntriesWithIndex(entries, index),
+	} {
+		if len(result) != 2 || result[0] != 1 || result[1] != 2 {
+			t.Errorf("%s: expected [1 2] for after.har, got %v", name, result)
+		}
+	}
+	
+	if filename := fs.GenerateFilteredFilename("before.har"); !strings.Contains(filename, "source_after.har") {
+		t.Errorf("Expected source in filename, got %q", filename)
+	}
+	
+	fs.Reset()
+	if fs.ActiveSource != "" {
+		t.Errorf("Expected empty ActiveSource after reset, got %q", fs.ActiveSource)
+	}
+}
+
+func TestWalkFields(t *testing.T) {
+	entry := createTestEntry("POST", "https://api.example.com/login?next=%2Fhome", 200, "application/json")
+	entry.Request.PostData = &har.HARPostData{MimeType: "application/json", Text: `{"user":"a"}`}
+	entry.Response.Cookies = []har.HARCookie{{Name: "session", Value: "s1"}}
+	
+	var locations []string
+	WalkFields(entry, ScopeAll, true, func(field Field) bool {
+		locations = append(locations, field.Location()+"="+field.Value)
+		return false
+	})
+	want := []string{
+		"request URL=api.example.com", "request URL=/login", "request URL=next=%2Fhome",
+		"request method=POST", "response header Content-Type=application/json",
+		"response status=", "response content type=application/json",
+		`request body={"user":"a"}`, "response cookie session=s1",
+	}
+	if strings.Join(locations, "\n") != strings.Join(want, "\n") {
+		t.Errorf("fields:\n%s\nwant:\n%s", strings.Join(locations, "\n"), strings.Join(want, "\n"))
+	}
+	
+	// Scopes limit the fields, and the walk stops at the first match
+	var visited int
+	found := WalkFields(entry, ScopeHeaders, true, func(field Field) bool {
+		visited++
+		return field.Kind == FieldHeader
+	})
+	if !found || visited != 1 {
+		t.Errorf("expected to stop at the header, found %v after %d fields", found, visited)
+	}
+}
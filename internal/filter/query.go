@@ -0,0 +1,821 @@
+package filter
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// Query language used by the search box and --search, for example:
+//
+//	status:>=400 method:POST host:*.api.example.com size:>1mb time:>500ms
+//	type:fetch header:x-request-id body:"timeout" -host:cdn.*
+//	(method:GET OR method:HEAD) AND NOT status:2xx
+//
+// Terms next to each other are ANDed. NOT binds tighter than AND, which
+// binds tighter than OR. A word without a field prefix searches every
+// field. Text without field prefixes, quotes, parentheses or AND/OR/NOT
+// is searched as a whole, the same way the plain text search always has,
+// so "-webkit" and "not found" still match as substrings.
+
+// QueryFields lists the field prefixes understood by the query language
+var QueryFields = []string{"status", "method", "host", "url", "path", "type", "mime", "size", "time", "header", "body", "page"}
+
+// QueryNode is a node of a parsed filter query
+type QueryNode interface {
+	// Match reports whether a single entry satisfies the node
+	Match(entry har.HAREntry) bool
+	// String returns the node in normalized query syntax
+	String() string
+}
+
+// indexedNode is a QueryNode that can be answered from an EntryIndex
+type indexedNode interface {
+	lookup(index *har.EntryIndex) []int
+}
+
+// QueryError describes a syntax error in a filter query
+type QueryError struct {
+	Pos int // byte offset into the query
+	Msg string
+}
+
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("col %d: %s", e.Pos+1, e.Msg)
+}
+
+// ParseQuery parses a filter query. An empty query returns a nil node
+func ParseQuery(query string) (QueryNode, error) {
+	return parseQuery(query, searchMode{scope: ScopeAll})
+}
+
+// parseQuery parses a filter query whose free text terms are matched according to mode
+func parseQuery(query string, mode searchMode) (QueryNode, error) {
+	tokens, err := lexQuery(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	if isPlainText(tokens) {
+		return newTextNode(query, mode), nil
+	}
+
+	p := &queryParser{tokens: tokens, end: len(query), mode: mode}
+	node, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok != nil {
+		if tok.kind == tokRParen {
+			return nil, &QueryError{tok.pos, "unexpected ')'"}
+		}
+		return nil, &QueryError{tok.pos, fmt.Sprintf("unexpected %q", tok.text)}
+	}
+	return node, nil
+}
+
+// EvaluateQuery returns the candidates (in order) that match node, using
+// index lookups for the parts of the query that the index covers
+func EvaluateQuery(node QueryNode, entries []har.HAREntry, index *har.EntryIndex, candidates []int) []int {
+	if node == nil {
+		return candidates
+	}
+
+	if index != nil {
+		if n, ok := node.(indexedNode); ok {
+			return keepIndices(candidates, n.lookup(index), true)
+		}
+	}
+
+	switch n := node.(type) {
+	case *andNode:
+		// Narrow with the indexed terms first so the rest scan fewer entries
+		result := candidates
+		for _, child := range n.children {
+			if _, ok := child.(indexedNode); ok && index != nil {
+				result = EvaluateQuery(child, entries, index, result)
+			}
+		}
+		for _, child := range n.children {
+			if _, ok := child.(indexedNode); !ok || index == nil {
+				result = EvaluateQuery(child, entries, index, result)
+			}
+		}
+		return result
+	case *orNode:
+		var matched []int
+		for _, child := range n.children {
+			matched = append(matched, EvaluateQuery(child, entries, index, candidates)...)
+		}
+		return keepIndices(candidates, matched, true)
+	case *notNode:
+		return keepIndices(candidates, EvaluateQuery(n.child, entries, index, candidates), false)
+	case *textNode:
+		if index != nil && !n.regex {
+			bodies := index.BodyFilter(n.text)
+			return filterCandidates(candidates, func(i int) bool {
+				return matchesTextSearch(entries[i], n.scope, n.match, bodies.MayContain(i))
+			})
+		}
+	case *bodyNode:
+		if index != nil {
+			bodies := index.BodyFilter(n.text)
+			return filterCandidates(candidates, func(i int) bool {
+				return bodies.MayContain(i) && n.Match(entries[i])
+			})
+		}
+	}
+
+	return filterCandidates(candidates, func(i int) bool { return node.Match(entries[i]) })
+}
+
+// filterCandidates returns the candidates for which keep is true, preserving order
+func filterCandidates(candidates []int, keep func(int) bool) []int {
+	var result []int
+	for _, i := range candidates {
+		if keep(i) {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// keepIndices returns the candidates that are (keep) or aren't (!keep) in set, preserving order
+func keepIndices(candidates, set []int, keep bool) []int {
+	inSet := make(map[int]bool, len(set))
+	for _, i := range set {
+		inSet[i] = true
+	}
+	result := []int{}
+	for _, i := range candidates {
+		if inSet[i] == keep {
+			result = append(result, i)
+		}
+	}
+	return result
+}
+
+// Boolean nodes
+
+type andNode struct{ children []QueryNode }
+
+func (n *andNode) Match(entry har.HAREntry) bool {
+	for _, child := range n.children {
+		if !child.Match(entry) {
+			return false
+		}
+	}
+	return true
+}
+
+func (n *andNode) String() string { return joinNodes(n.children, " ") }
+
+type orNode struct{ children []QueryNode }
+
+func (n *orNode) Match(entry har.HAREntry) bool {
+	for _, child := range n.children {
+		if child.Match(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+func (n *orNode) String() string { return "(" + joinNodes(n.children, " OR ") + ")" }
+
+type notNode struct{ child QueryNode }
+
+func (n *notNode) Match(entry har.HAREntry) bool { return !n.child.Match(entry) }
+
+func (n *notNode) String() string { return "-" + n.child.String() }
+
+func joinNodes(nodes []QueryNode, sep string) string {
+	parts := make([]string, len(nodes))
+	for i, node := range nodes {
+		parts[i] = node.String()
+	}
+	return strings.Join(parts, sep)
+}
+
+// Field nodes
+
+// textNode searches the fields in scope, like the plain search box
+type textNode struct {
+	text  string
+	regex bool
+	scope string
+	match textMatcher
+}
+
+func newTextNode(text string, mode searchMode) *textNode {
+	return &textNode{text: text, scope: mode.scope, match: newSubstringMatcher(text, mode.caseSensitive)}
+}
+
+func (n *textNode) Match(entry har.HAREntry) bool {
+	return matchesTextSearch(entry, n.scope, n.match, true)
+}
+
+func (n *textNode) String() string {
+	if n.regex {
+		return "/" + n.text + "/"
+	}
+	return strconv.Quote(n.text)
+}
+
+type statusNode struct {
+	raw string
+	cmp comparison
+}
+
+func (n *statusNode) Match(entry har.HAREntry) bool {
+	return n.cmp.matches(float64(entry.Response.Status))
+}
+
+func (n *statusNode) String() string { return "status:" + n.raw }
+
+func (n *statusNode) lookup(index *har.EntryIndex) []int {
+	var result []int
+	for _, status := range index.Statuses() {
+		if n.cmp.matches(float64(status)) {
+			result = append(result, index.GetByStatus(status)...)
+		}
+	}
+	return result
+}
+
+type methodNode struct{ method string }
+
+func (n *methodNode) Match(entry har.HAREntry) bool {
+	return strings.EqualFold(entry.Request.Method, n.method)
+}
+
+func (n *methodNode) String() string { return "method:" + n.method }
+
+func (n *methodNode) lookup(index *har.EntryIndex) []int {
+	var result []int
+	for _, method := range index.Methods() {
+		if strings.EqualFold(method, n.method) {
+			result = append(result, index.GetByMethod(method)...)
+		}
+	}
+	return result
+}
+
+// hostNode matches the request host against a glob such as *.example.com
+type hostNode struct{ pattern string }
+
+func (n *hostNode) Match(entry har.HAREntry) bool {
+	u, err := url.Parse(entry.Request.URL)
+	return err == nil && n.matchHost(u.Host)
+}
+
+func (n *hostNode) matchHost(host string) bool {
+	host = strings.ToLower(host)
+	if matchGlob(n.pattern, host) {
+		return true
+	}
+	// Let host:example.com match example.com:8443 too
+	if h, _, found := strings.Cut(host, ":"); found {
+		return matchGlob(n.pattern, h)
+	}
+	return false
+}
+
+func (n *hostNode) String() string { return "host:" + n.pattern }
+
+func (n *hostNode) lookup(index *har.EntryIndex) []int {
+	var result []int
+	for _, host := range index.Hosts() {
+		if n.matchHost(host) {
+			result = append(result, index.GetByHost(host)...)
+		}
+	}
+	return result
+}
+
+// urlNode matches the full URL, or just its path, by substring or glob
+type urlNode struct {
+	field   string
+	pattern string
+}
+
+func (n *urlNode) Match(entry har.HAREntry) bool {
+	value := entry.Request.URL
+	if n.field == "path" {
+		u, err := url.Parse(entry.Request.URL)
+		if err != nil {
+			return false
+		}
+		value = u.Path
+	}
+	value = strings.ToLower(value)
+	if strings.ContainsAny(n.pattern, "*?") {
+		return matchGlob(n.pattern, value)
+	}
+	return strings.Contains(value, n.pattern)
+}
+
+func (n *urlNode) String() string { return n.field + ":" + quoteValue(n.pattern) }
+
+type typeNode struct{ requestType string }
+
+func (n *typeNode) Match(entry har.HAREntry) bool { return har.GetRequestType(entry) == n.requestType }
+
+func (n *typeNode) String() string { return "type:" + n.requestType }
+
+func (n *typeNode) lookup(index *har.EntryIndex) []int { return index.GetByType(n.requestType) }
+
+type mimeNode struct{ mimeType string }
+
+func (n *mimeNode) Match(entry har.HAREntry) bool {
+	return strings.Contains(strings.ToLower(entry.Response.Content.MimeType), n.mimeType)
+}
+
+func (n *mimeNode) String() string { return "mime:" + quoteValue(n.mimeType) }
+
+type sizeNode struct {
+	raw string
+	cmp comparison
+}
+
+func (n *sizeNode) Match(entry har.HAREntry) bool {
+	return n.cmp.matches(float64(entry.Response.Content.Size))
+}
+
+func (n *sizeNode) String() string { return "size:" + n.raw }
+
+type timeNode struct {
+	raw string
+	cmp comparison
+}
+
+func (n *timeNode) Match(entry har.HAREntry) bool { return n.cmp.matches(entry.Time) }
+
+func (n *timeNode) String() string { return "time:" + n.raw }
+
+// headerNode matches a request or response header by name, and optionally by value substring
+type headerNode struct {
+	name  string
+	value string
+}
+
+func (n *headerNode) Match(entry har.HAREntry) bool {
+	for _, headers := range [][]har.HARHeader{entry.Request.Headers, entry.Response.Headers} {
+		for _, h := range headers {
+			if strings.EqualFold(h.Name, n.name) &&
+				(n.value == "" || strings.Contains(strings.ToLower(h.Value), n.value)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (n *headerNode) String() string {
+	if n.value == "" {
+		return "header:" + quoteValue(n.name)
+	}
+	return "header:" + quoteValue(n.name+"="+n.value)
+}
+
+// bodyNode searches the request and response bodies
+type bodyNode struct {
+	text  string
+	match textMatcher
+}
+
+func (n *bodyNode) Match(entry har.HAREntry) bool { return matchesBodies(entry, n.match) }
+
+func (n *bodyNode) String() string { return "body:" + quoteValue(n.text) }
+
+type pageNode struct{ pageref string }
+
+func (n *pageNode) Match(entry har.HAREntry) bool { return entry.Pageref == n.pageref }
+
+func (n *pageNode) String() string { return "page:" + quoteValue(n.pageref) }
+
+func (n *pageNode) lookup(index *har.EntryIndex) []int { return index.GetByPage(n.pageref) }
+
+func quoteValue(value string) string {
+	if strings.ContainsAny(value, " \t()\"") {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// matchGlob matches a lowercased value against a glob where * spans any characters
+func matchGlob(pattern, value string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			for i := len(value); i >= 0; i-- {
+				if matchGlob(pattern[1:], value[i:]) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if value == "" {
+				return false
+			}
+		default:
+			if value == "" || value[0] != pattern[0] {
+				return false
+			}
+		}
+		pattern, value = pattern[1:], value[1:]
+	}
+	return value == ""
+}
+
+// comparison is a numeric test such as >=400 or a status class such as 4xx
+type comparison struct {
+	op    string
+	value float64
+	upper float64 // inclusive upper bound for status classes
+}
+
+func (c comparison) matches(x float64) bool {
+	switch c.op {
+	case ">":
+		return x > c.value
+	case ">=":
+		return x >= c.value
+	case "<":
+		return x < c.value
+	case "<=":
+		return x <= c.value
+	case "range":
+		return x >= c.value && x <= c.upper
+	}
+	return x == c.value
+}
+
+// splitOperator splits a leading comparison operator from a value
+func splitOperator(value string) (string, string) {
+	for _, op := range []string{">=", "<=", ">", "<", "="} {
+		if strings.HasPrefix(value, op) {
+			return op, value[len(op):]
+		}
+	}
+	return "=", value
+}
+
+func parseStatus(value string) (comparison, error) {
+	op, rest := splitOperator(value)
+	lower := strings.ToLower(rest)
+	if len(lower) == 3 && strings.HasSuffix(lower, "xx") && lower[0] >= '1' && lower[0] <= '5' {
+		if op != "=" {
+			return comparison{}, fmt.Errorf("status class %s can't be used with %s", rest, op)
+		}
+		base := float64(lower[0]-'0') * 100
+		return comparison{op: "range", value: base, upper: base + 99}, nil
+	}
+	n, err := strconv.Atoi(rest)
+	if err != nil {
+		return comparison{}, fmt.Errorf("invalid status %q (expected a number like 404 or a class like 4xx)", rest)
+	}
+	return comparison{op: op, value: float64(n)}, nil
+}
+
+// sizeUnits maps size suffixes to bytes
+var sizeUnits = map[string]float64{
+	"": 1, "b": 1,
+	"k": 1 << 10, "kb": 1 << 10,
+	"m": 1 << 20, "mb": 1 << 20,
+	"g": 1 << 30, "gb": 1 << 30,
+}
+
+// timeUnits maps duration suffixes to milliseconds
+var timeUnits = map[string]float64{
+	"": 1, "ms": 1,
+	"s": 1000,
+	"m": 60 * 1000,
+}
+
+// parseQuantity parses a comparison such as >1.5mb using the given units
+func parseQuantity(value string, units map[string]float64, what string) (comparison, error) {
+	op, rest := splitOperator(value)
+	rest = strings.ToLower(rest)
+	end := 0
+	for end < len(rest) && (rest[end] >= '0' && rest[end] <= '9' || rest[end] == '.') {
+		end++
+	}
+	if end == 0 {
+		return comparison{}, fmt.Errorf("invalid %s %q (expected a number)", what, rest)
+	}
+	n, err := strconv.ParseFloat(rest[:end], 64)
+	if err != nil {
+		return comparison{}, fmt.Errorf("invalid %s %q", what, rest)
+	}
+	multiplier, ok := units[rest[end:]]
+	if !ok {
+		return comparison{}, fmt.Errorf("unknown %s unit %q", what, rest[end:])
+	}
+	return comparison{op: op, value: n * multiplier}, nil
+}
+
+// newFieldNode builds the node for field:value
+func newFieldNode(field, value string) (QueryNode, error) {
+	if value == "" {
+		return nil, fmt.Errorf("missing value for %s:", field)
+	}
+	lower := strings.ToLower(value)
+
+	switch field {
+	case "status":
+		cmp, err := parseStatus(value)
+		if err != nil {
+			return nil, err
+		}
+		return &statusNode{raw: value, cmp: cmp}, nil
+	case "method":
+		return &methodNode{method: strings.ToUpper(value)}, nil
+	case "host":
+		return &hostNode{pattern: lower}, nil
+	case "url", "path":
+		return &urlNode{field: field, pattern: lower}, nil
+	case "type":
+		for _, t := range GetTypeFilters() {
+			if t == lower && t != "all" {
+				return &typeNode{requestType: t}, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown type %q", value)
+	case "mime":
+		return &mimeNode{mimeType: lower}, nil
+	case "size":
+		cmp, err := parseQuantity(value, sizeUnits, "size")
+		if err != nil {
+			return nil, err
+		}
+		return &sizeNode{raw: lower, cmp: cmp}, nil
+	case "time":
+		cmp, err := parseQuantity(value, timeUnits, "time")
+		if err != nil {
+			return nil, err
+		}
+		return &timeNode{raw: lower, cmp: cmp}, nil
+	case "header":
+		name, headerValue, _ := strings.Cut(value, "=")
+		if name == "" {
+			return nil, fmt.Errorf("missing header name")
+		}
+		return &headerNode{name: name, value: strings.ToLower(headerValue)}, nil
+	case "body":
+		return &bodyNode{text: lower, match: newSubstringMatcher(lower, false)}, nil
+	case "page":
+		return &pageNode{pageref: value}, nil
+	}
+	return nil, fmt.Errorf("unknown field %q", field)
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokTerm tokenKind = iota
+	tokLParen
+	tokRParen
+	tokAnd
+	tokOr
+	tokNot
+)
+
+type queryToken struct {
+	kind  tokenKind
+	text  string // raw text as typed
+	field string // for terms with a known field prefix
+	value string // unquoted value
+	pos   int
+}
+
+func lexQuery(query string) ([]queryToken, error) {
+	var tokens []queryToken
+	i := 0
+	for i < len(query) {
+		c := query[i]
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '(':
+			tokens = append(tokens, queryToken{kind: tokLParen, text: "(", pos: i})
+			i++
+		case c == ')':
+			tokens = append(tokens, queryToken{kind: tokRParen, text: ")", pos: i})
+			i++
+		case c == '-' && i+1 < len(query) && query[i+1] != ' ' && query[i+1] != ')':
+			tokens = append(tokens, queryToken{kind: tokNot, text: "-", pos: i})
+			i++
+		default:
+			tok, next, err := lexTerm(query, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		}
+	}
+	return tokens, nil
+}
+
+// lexTerm reads a word, field:value or quoted phrase starting at start
+func lexTerm(query string, start int) (queryToken, int, error) {
+	var value strings.Builder
+	i := start
+	for i < len(query) {
+		c := query[i]
+		if c == ' ' || c == '\t' || c == '(' || c == ')' {
+			break
+		}
+		if c != '"' {
+			value.WriteByte(c)
+			i++
+			continue
+		}
+
+		// Quoted section, possibly after a field prefix
+		quoteStart := i
+		i++
+		closed := false
+		for i < len(query) {
+			if query[i] == '\\' && i+1 < len(query) {
+				value.WriteByte(query[i+1])
+				i += 2
+				continue
+			}
+			if query[i] == '"' {
+				closed = true
+				i++
+				break
+			}
+			value.WriteByte(query[i])
+			i++
+		}
+		if !closed {
+			return queryToken{}, 0, &QueryError{quoteStart, "unterminated quote"}
+		}
+	}
+
+	raw := query[start:i]
+	tok := queryToken{kind: tokTerm, text: raw, value: value.String(), pos: start}
+
+	switch raw {
+	case "AND":
+		tok.kind = tokAnd
+		return tok, i, nil
+	case "OR":
+		tok.kind = tokOr
+		return tok, i, nil
+	case "NOT":
+		tok.kind = tokNot
+		return tok, i, nil
+	}
+
+	// field:value, where the prefix is written without quotes. Other words
+	// with a colon, like localhost:8080, are searched as text
+	if colon := strings.IndexByte(raw, ':'); colon > 0 && !strings.Contains(raw[:colon], "\"") {
+		prefix := strings.ToLower(raw[:colon])
+		if isQueryField(prefix) {
+			tok.field = prefix
+			tok.value = tok.value[colon+1:]
+		}
+	}
+	return tok, i, nil
+}
+
+// isPlainText reports whether tokens are only bare words, each possibly
+// after a '-', which are searched as one phrase rather than as a query
+func isPlainText(tokens []queryToken) bool {
+	for _, tok := range tokens {
+		switch {
+		case tok.kind == tokNot && tok.text == "-":
+		case tok.kind == tokTerm && tok.field == "" && !strings.Contains(tok.text, "\""):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func isQueryField(name string) bool {
+	for _, field := range QueryFields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Parser
+
+type queryParser struct {
+	tokens []queryToken
+	pos    int
+	end    int        // length of the query, for errors at the end
+	mode   searchMode // how free text terms are matched
+}
+
+func (p *queryParser) peek() *queryToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+func (p *queryParser) next() *queryToken {
+	tok := p.peek()
+	if tok != nil {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr: and ("OR" and)*
+func (p *queryParser) parseOr() (QueryNode, error) {
+	first, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	children := []QueryNode{first}
+	for tok := p.peek(); tok != nil && tok.kind == tokOr; tok = p.peek() {
+		p.next()
+		child, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &orNode{children: children}, nil
+}
+
+// parseAnd: unary (["AND"] unary)*
+func (p *queryParser) parseAnd() (QueryNode, error) {
+	first, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	children := []QueryNode{first}
+	for tok := p.peek(); tok != nil && tok.kind != tokOr && tok.kind != tokRParen; tok = p.peek() {
+		if tok.kind == tokAnd {
+			p.next()
+		}
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		children = append(children, child)
+	}
+	if len(children) == 1 {
+		return first, nil
+	}
+	return &andNode{children: children}, nil
+}
+
+// parseUnary: ("NOT" | "-") unary | "(" or ")" | term
+func (p *queryParser) parseUnary() (QueryNode, error) {
+	tok := p.next()
+	if tok == nil {
+		return nil, &QueryError{p.end, "expected a search term"}
+	}
+
+	switch tok.kind {
+	case tokNot:
+		child, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{child: child}, nil
+	case tokLParen:
+		if next := p.peek(); next != nil && next.kind == tokRParen {
+			return nil, &QueryError{next.pos, "empty parentheses"}
+		}
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing == nil || closing.kind != tokRParen {
+			return nil, &QueryError{tok.pos, "missing ')'"}
+		}
+		return node, nil
+	case tokTerm:
+		if tok.field == "" {
+			return newTextNode(tok.value, p.mode), nil
+		}
+		node, err := newFieldNode(tok.field, tok.value)
+		if err != nil {
+			return nil, &QueryError{tok.pos, err.Error()}
+		}
+		return node, nil
+	}
+	return nil, &QueryError{tok.pos, fmt.Sprintf("expected a search term before %q", tok.text)}
+}
@@ -0,0 +1,193 @@
+package filter
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func queryTestEntries() []har.HAREntry {
+	entries := []har.HAREntry{
+		createTestEntry("GET", "https://api.example.com/users", 200, "application/json"),
+		createTestEntry("POST", "https://api.example.com/login", 500, "application/json"),
+		createTestEntry("GET", "https://cdn.example.com/app.js", 200, "application/javascript"),
+		createTestEntry("GET", "https://eu.api.example.com:8443/slow", 404, "text/html"),
+	}
+	entries[1].Request.PostData = &har.HARPostData{Text: `{"error":"Timeout"}`}
+	entries[1].Request.Headers = []har.HARHeader{{Name: "X-Request-Id", Value: "abc-123"}}
+	entries[1].Response.StatusText = "Server (Error"
+	entries[2].Response.Content.Size = 2 * 1024 * 1024
+	entries[3].Time = 1500
+	entries[3].Pageref = "page_2"
+	return entries
+}
+
+func TestParseQueryMatches(t *testing.T) {
+	entries := queryTestEntries()
+	index := har.NewEntryIndex()
+	for i, entry := range entries {
+		index.AddEntry(entry, i)
+	}
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"status:>=400", []int{1, 3}},
+		{"status:2xx", []int{0, 2}},
+		{"method:post", []int{1}},
+		{"host:*.api.example.com", []int{3}},
+		{"host:api.example.com", []int{0, 1}},
+		{"-host:cdn.*", []int{0, 1, 3}},
+		{"size:>1mb", []int{2}},
+		{"time:>1s", []int{3}},
+		{"type:js", []int{2}},
+		{"header:x-request-id", []int{1}},
+		{"header:x-request-id=ABC", []int{1}},
+		{`body:"timeout"`, []int{1}},
+		{"page:page_2", []int{3}},
+		{"path:/users", []int{0}},
+		{"url:*example.com/*.js", []int{2}},
+		{"mime:json", []int{0, 1}},
+		{"method:GET status:200", []int{0, 2}},
+		{"method:GET AND NOT status:200", []int{3}},
+		{"status:500 OR status:404", []int{1, 3}},
+		{"(status:500 OR status:404) -method:POST", []int{3}},
+		{"cdn", []int{2}},
+		{`"server (error"`, []int{1}},
+		{"example.com:8443", []int{3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) failed: %v", tt.query, err)
+			}
+
+			var scanned []int
+			for i, entry := range entries {
+				if node.Match(entry) {
+					scanned = append(scanned, i)
+				}
+			}
+			if !reflect.DeepEqual(scanned, tt.expected) {
+				t.Errorf("Match: expected %v, got %v", tt.expected, scanned)
+			}
+
+			indexed := EvaluateQuery(node, entries, index, []int{0, 1, 2, 3})
+			if !reflect.DeepEqual(indexed, tt.expected) {
+				t.Errorf("EvaluateQuery: expected %v, got %v", tt.expected, indexed)
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	tests := []struct {
+		query string
+		pos   int
+		msg   string
+	}{
+		{"status:abc", 0, "invalid status"},
+		{"method:GET (status:500", 11, "missing ')'"},
+		{"status:500)", 10, "unexpected ')'"},
+		{"status:500 OR", 13, "expected a search term"},
+		{"()", 1, "empty parentheses"},
+		{`body:"oops`, 5, "unterminated quote"},
+		{"size:>1tb", 0, "unknown size unit"},
+		{"type:bogus", 0, "unknown type"},
+		{"status:>4xx", 0, "can't be used"},
+		{"host:", 0, "missing value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			_, err := ParseQuery(tt.query)
+			queryErr, ok := err.(*QueryError)
+			if !ok {
+				t.Fatalf("expected a QueryError, got %v", err)
+			}
+			if queryErr.Pos != tt.pos || !strings.Contains(queryErr.Msg, tt.msg) {
+				t.Errorf("expected %q at %d, got %q at %d", tt.msg, tt.pos, queryErr.Msg, queryErr.Pos)
+			}
+		})
+	}
+}
+
+func TestParseQueryUnknownFieldIsText(t *testing.T) {
+	for _, query := range []string{"localhost:8080", "error:", "a:b", "stauts:500"} {
+		node, err := ParseQuery(query)
+		if err != nil {
+			t.Errorf("ParseQuery(%q) failed: %v", query, err)
+			continue
+		}
+		if got := node.String(); got != strconv.Quote(query) {
+			t.Errorf("ParseQuery(%q) = %s, want a text search", query, got)
+		}
+	}
+}
+
+func TestParseQueryPlainText(t *testing.T) {
+	entries := queryTestEntries()
+	entries[0].Response.StatusText = "Not Found"
+	entries[2].Response.Headers = append(entries[2].Response.Headers, har.HARHeader{Name: "X-Style", Value: "-webkit-box"})
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"-webkit", []int{2}},
+		{"not found", []int{0}},
+		{"abc -123", nil},
+		{"NOT found", []int{1, 2, 3}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			node, err := ParseQuery(tt.query)
+			if err != nil {
+				t.Fatalf("ParseQuery(%q) failed: %v", tt.query, err)
+			}
+			var got []int
+			for i, entry := range entries {
+				if node.Match(entry) {
+					got = append(got, i)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFilterState_QueryFallback(t *testing.T) {
+	entries := queryTestEntries()
+	fs := NewFilterState()
+
+	fs.SetTextFilter("status:>=400 method:GET")
+	if fs.QueryError() != nil {
+		t.Fatalf("unexpected error: %v", fs.QueryError())
+	}
+	if got := fs.FilterEntries(entries); !reflect.DeepEqual(got, []int{3}) {
+		t.Errorf("expected [3], got %v", got)
+	}
+
+	// A query that doesn't parse still searches as plain text
+	fs.SetTextFilter("server (error")
+	if fs.QueryError() == nil {
+		t.Fatal("expected a query error")
+	}
+	if got := fs.FilterEntries(entries); !reflect.DeepEqual(got, []int{1}) {
+		t.Errorf("expected plain text fallback to match [1], got %v", got)
+	}
+
+	fs.SetTextFilter("")
+	if fs.QueryError() != nil || len(fs.FilterEntries(entries)) != len(entries) {
+		t.Error("expected an empty query to match everything")
+	}
+}
@@ -0,0 +1,114 @@
+package filter
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+)
+
+// Search scopes limit which fields free text is matched against
+const (
+	ScopeAll     = "all"
+	ScopeURL     = "url"
+	ScopeHeaders = "headers"
+	ScopeBody    = "body"
+)
+
+// GetSearchScopes returns the available search scopes in cycling order
+func GetSearchScopes() []string {
+	return []string{ScopeAll, ScopeURL, ScopeHeaders, ScopeBody}
+}
+
+// textMatcher reports whether a field value matches the search text
+type textMatcher func(value string) bool
+
+// searchMode is how FilterText is interpreted, compared to decide when to re-parse it
+type searchMode struct {
+	regex         bool
+	caseSensitive bool
+	scope         string
+}
+
+// newSubstringMatcher matches values containing text
+func newSubstringMatcher(text string, caseSensitive bool) textMatcher {
+	if caseSensitive {
+		return func(value string) bool { return strings.Contains(value, text) }
+	}
+	text = strings.ToLower(text)
+	return func(value string) bool { return strings.Contains(strings.ToLower(value), text) }
+}
+
+// newRegexMatcher compiles an RE2 pattern, case-insensitive unless caseSensitive
+func newRegexMatcher(pattern string, caseSensitive bool) (textMatcher, error) {
+	if !caseSensitive {
+		pattern = "(?i)" + pattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString, nil
+}
+
+// regexError turns a regexp compile error into a QueryError pointing into pattern
+func regexError(err error, pattern string) *QueryError {
+	syntaxErr, ok := err.(*syntax.Error)
+	if !ok {
+		return &QueryError{Pos: 0, Msg: err.Error()}
+	}
+	pos := 0
+	if i := strings.Index(pattern, syntaxErr.Expr); i >= 0 {
+		pos = i
+	}
+	return &QueryError{Pos: pos, Msg: fmt.Sprintf("invalid regex: %s: `%s`", syntaxErr.Code, syntaxErr.Expr)}
+}
+
+// ToggleSearchRegex toggles matching the search text as a regular expression
+func (f *FilterState) ToggleSearchRegex() {
+	f.SearchRegex = !f.SearchRegex
+}
+
+// ToggleSearchCaseSensitive toggles case-sensitive search
+func (f *FilterState) ToggleSearchCaseSensitive() {
+	f.SearchCaseSensitive = !f.SearchCaseSensitive
+}
+
+// CycleSearchScope moves to the next search scope
+func (f *FilterState) CycleSearchScope() {
+	scopes := GetSearchScopes()
+	for i, scope := range scopes {
+		if scope == f.searchScope() {
+			f.SearchScope = scopes[(i+1)%len(scopes)]
+			return
+		}
+	}
+	f.SearchScope = ScopeAll
+}
+
+// SearchModeLabel describes the non-default search modes, e.g. "regex, case, url only"
+func (f *FilterState) SearchModeLabel() string {
+	var modes []string
+	if f.SearchRegex {
+		modes = append(modes, "regex")
+	}
+	if f.SearchCaseSensitive {
+		modes = append(modes, "case")
+	}
+	if scope := f.searchScope(); scope != ScopeAll {
+		modes = append(modes, scope+" only")
+	}
+	return strings.Join(modes, ", ")
+}
+
+// searchScope returns the active scope, treating "" as all fields
+func (f *FilterState) searchScope() string {
+	if f.SearchScope == "" {
+		return ScopeAll
+	}
+	return f.SearchScope
+}
+
+func (f *FilterState) currentSearchMode() searchMode {
+	return searchMode{regex: f.SearchRegex, caseSensitive: f.SearchCaseSensitive, scope: f.searchScope()}
+}
@@ -0,0 +1,143 @@
+package filter
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func TestFilterState_SearchModes(t *testing.T) {
+	entries := queryTestEntries()
+	entries[0].Response.Content.Text = "User list"
+
+	tests := []struct {
+		name     string
+		text     string
+		setup    func(*FilterState)
+		expected []int
+	}{
+		{"default is case-insensitive", "USER", func(fs *FilterState) {}, []int{0}},
+		{"case-sensitive", "USER", func(fs *FilterState) { fs.SearchCaseSensitive = true }, []int{}},
+		{"case-sensitive match", "User", func(fs *FilterState) { fs.SearchCaseSensitive = true }, []int{0}},
+		{"regex", `^/(users|login)$`, func(fs *FilterState) { fs.SearchRegex = true }, []int{0, 1}},
+		{"regex is case-insensitive by default", `X-REQUEST-\w+`, func(fs *FilterState) { fs.SearchRegex = true }, []int{1}},
+		{"url scope skips bodies", "user", func(fs *FilterState) { fs.SearchScope = ScopeURL }, []int{0}},
+		{"body scope skips URLs", "users", func(fs *FilterState) { fs.SearchScope = ScopeBody }, []int{}},
+		{"body scope", "timeout", func(fs *FilterState) { fs.SearchScope = ScopeBody }, []int{1}},
+		{"headers scope", "abc-123", func(fs *FilterState) { fs.SearchScope = ScopeHeaders }, []int{1}},
+		{"scope applies inside queries", "method:GET list", func(fs *FilterState) { fs.SearchScope = ScopeBody }, []int{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fs := NewFilterState()
+			tt.setup(fs)
+			fs.SetTextFilter(tt.text)
+			if err := fs.QueryError(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got := fs.FilterEntries(entries)
+			if got == nil {
+				got = []int{}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("FilterEntries: expected %v, got %v", tt.expected, got)
+			}
+
+			index := har.NewEntryIndex()
+			for i, entry := range entries {
+				index.AddEntry(entry, i)
+			}
+			got = fs.FilterEntriesWithIndex(entries, index)
+			if got == nil {
+				got = []int{}
+			}
+			if !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("FilterEntriesWithIndex: expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestFilterState_InvalidRegex(t *testing.T) {
+	fs := NewFilterState()
+	fs.SearchRegex = true
+	fs.SetTextFilter("users(")
+
+	err := fs.QueryError()
+	if err == nil || !strings.Contains(err.Error(), "invalid regex") {
+		t.Fatalf("expected an invalid regex error, got %v", err)
+	}
+
+	// Toggling regex off re-parses the same text as a query
+	fs.ToggleSearchRegex()
+	if err := fs.QueryError(); err == nil || strings.Contains(err.Error(), "regex") {
+		t.Errorf("expected a query error after leaving regex mode, got %v", err)
+	}
+}
+
+func TestFilterState_SearchModeLabel(t *testing.T) {
+	fs := NewFilterState()
+	if label := fs.SearchModeLabel(); label != "" {
+		t.Errorf("expected no label by default, got %q", label)
+	}
+
+	fs.ToggleSearchRegex()
+	fs.ToggleSearchCaseSensitive()
+	fs.CycleSearchScope()
+	if label := fs.SearchModeLabel(); label != "regex, case, url only" {
+		t.Errorf("unexpected label %q", label)
+	}
+
+	for range GetSearchScopes()[1:] {
+		fs.CycleSearchScope()
+	}
+	if fs.SearchScope != ScopeAll {
+		t.Errorf("expected scope to cycle back to all, got %q", fs.SearchScope)
+	}
+}
+
+func TestFilterState_SearchesLargeBodies(t *testing.T) {
+	entries := queryTestEntries()
+	entries[2].Response.Content.Text = strings.Repeat("x", 50000) + "deep-token"
+
+	index := har.NewEntryIndex()
+	for i, entry := range entries {
+		index.AddEntry(entry, i)
+	}
+	index.WaitForBodies()
+
+	for _, text := range []string{"deep-token", "body:deep-token"} {
+		fs := NewFilterState()
+		fs.SetTextFilter(text)
+		if got := fs.FilterEntriesWithIndex(entries, index); !reflect.DeepEqual(got, []int{2}) {
+			t.Errorf("%q with index: expected [2], got %v", text, got)
+		}
+		if got := fs.FilterEntries(entries); !reflect.DeepEqual(got, []int{2}) {
+			t.Errorf("%q without index: expected [2], got %v", text, got)
+		}
+	}
+}
+
+func TestFilterState_SearchesEncodedBodies(t *testing.T) {
+	entries := queryTestEntries()
+	entries[0].Response.Content.Text = base64.StdEncoding.EncodeToString([]byte("encoded deep-token"))
+	entries[0].Response.Content.Encoding = "base64"
+	entries[3].Request.PostData = &har.HARPostData{Text: "form=deep-token"}
+
+	index := har.NewEntryIndex()
+	for i, entry := range entries {
+		index.AddEntry(entry, i)
+	}
+	index.WaitForBodies()
+
+	fs := NewFilterState()
+	fs.SetTextFilter("DEEP-TOKEN")
+	if got := fs.FilterEntriesWithIndex(entries, index); !reflect.DeepEqual(got, []int{0, 3}) {
+		t.Errorf("expected the encoded and request bodies to match, got %v", got)
+	}
+}
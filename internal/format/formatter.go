@@ -896,6 +896,26 @@ func (f *ContentFormatter) extractHexContent(fullOutput string) string {
 	return fullOutput
 }
 
+// HighlightLines syntax highlights already formatted content line by line, so
+// a diff can show any line of it. It always returns one string per line
+func (f *ContentFormatter) HighlightLines(content, contentType string) []string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	switch contentType {
+	case "json", "html", "xml", "javascript", "css":
+	default:
+		return lines
+	}
+	
+	highlighted := strings.Split(strings.TrimSuffix(f.formatWithChroma(content, contentType), "\n"), "\n")
+	if len(highlighted) != len(lines) {
+		return lines // The highlighter changed the line structure
+	}
+	for i, line := range highlighted {
+		highlighted[i] = f.ensureColorReset(line)
+	}
+	return highlighted
+}
+
 // GetImageDisplayer returns the internal image displayer for testing
 func (f *ContentFormatter) GetImageDisplayer() *ImageDisplayer {
 	return f.getImageDisplayer()
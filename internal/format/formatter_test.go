@@ -284,6 +284,24 @@ func TestContentFormatter_PrettyJSON(t *testing.T) {
 	}
 }
 
+func TestContentFormatter_HighlightLines(t *testing.T) {
+	formatter := NewContentFormatter()
+	content := "{\n  \"name\": \"a\",\n  \"ids\": [\n    1\n  ]\n}"
+
+	lines := formatter.HighlightLines(content, "json")
+	if len(lines) != 6 {
+		t.Fatalf("Expected one highlighted line per input line, got %d: %q", len(lines), lines)
+	}
+	if !strings.Contains(lines[1], "name") || !strings.Contains(lines[1], "[") {
+		t.Errorf("Expected a highlighted name line, got: %q", lines[1])
+	}
+
+	plain := formatter.HighlightLines("a\nb\n", "text")
+	if len(plain) != 2 || plain[0] != "a" || plain[1] != "b" {
+		t.Errorf("Expected plain lines back, got: %q", plain)
+	}
+}
+
 func BenchmarkContentFormatter_FormatContent(b *testing.B) {
 	formatter := NewContentFormatter()
 	jsContent := `
@@ -23,4 +23,9 @@ func ParseHARDateTime(dateTime string) (time.Time, error) {
 		}
 	}
 	return time.Time{}, fmt.Errorf("unable to parse datetime: %s", dateTime)
-}
\ No newline at end of file
+}
+
+// FormatHARDateTime formats a time the way HAR writers do, in UTC with milliseconds
+func FormatHARDateTime(t time.Time) string {
+	return t.UTC().Format(supportedTimeFormats[0])
+}
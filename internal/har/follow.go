@@ -0,0 +1,126 @@
+package har
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// FollowInterval is how often a followed file is checked for new data
+var FollowInterval = 250 * time.Millisecond
+
+// errRewritten reports a followed file that shrank, so it was rewritten
+// rather than appended to
+var errRewritten = errors.New("file was rewritten")
+
+// FollowHARFile loads a HAR or NDJSON file like LoadHARFileStreaming, then
+// keeps adding entries as they are written to it until StopFollowing. A HAR
+// document still being written is read as far as it goes; once complete or
+// rewritten it is read again, adding only the entries beyond those loaded
+func (sl *StreamingLoader) FollowHARFile(filePath string) {
+	stop := make(chan struct{})
+	sl.mutex.Lock()
+	sl.stop = stop
+	sl.mutex.Unlock()
+
+	go func() {
+		if err := sl.follow(filePath, stop); err != nil {
+			if sl.onError != nil {
+				sl.onError(err)
+			}
+			return
+		}
+		if sl.onComplete != nil {
+			sl.onComplete()
+		}
+	}()
+}
+
+// StopFollowing stops following the file, completing the load
+func (sl *StreamingLoader) StopFollowing() {
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+	if sl.stop != nil {
+		close(sl.stop)
+		sl.stop = nil
+	}
+}
+
+// follow reads the file until stop is closed, reading it again whenever it
+// is complete and changes, or is rewritten
+func (sl *StreamingLoader) follow(filePath string, stop <-chan struct{}) error {
+	for {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		sl.mutex.Lock()
+		sl.skip = len(sl.entries)
+		sl.mutex.Unlock()
+
+		err = sl.parse(&followReader{file: file, stop: stop})
+		info, statErr := file.Stat()
+		file.Close()
+
+		select {
+		case <-stop:
+			// Whatever was cut short was still being written
+			return nil
+		default:
+		}
+		switch {
+		case errors.Is(err, errRewritten):
+			continue
+		case err != nil:
+			return err
+		case statErr != nil:
+			return statErr
+		}
+		if !waitForChange(filePath, info, stop) {
+			return nil
+		}
+	}
+}
+
+// waitForChange waits until the file's size or modification time differ
+// from info, returning false if stopped first
+func waitForChange(filePath string, info os.FileInfo, stop <-chan struct{}) bool {
+	for {
+		select {
+		case <-stop:
+			return false
+		case <-time.After(FollowInterval):
+		}
+		current, err := os.Stat(filePath)
+		if err == nil && (current.Size() != info.Size() || !current.ModTime().Equal(info.ModTime())) {
+			return true
+		}
+	}
+}
+
+// followReader reads a file that is still being written: at the end it
+// waits for more data instead of returning io.EOF, until stopped
+type followReader struct {
+	file   *os.File
+	stop   <-chan struct{}
+	offset int64
+}
+
+func (r *followReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.file.Read(p)
+		r.offset += int64(n)
+		if n > 0 || err != io.EOF {
+			return n, err
+		}
+		if info, err := r.file.Stat(); err == nil && info.Size() < r.offset {
+			return 0, errRewritten
+		}
+		select {
+		case <-r.stop:
+			return 0, io.EOF
+		case <-time.After(FollowInterval):
+		}
+	}
+}
@@ -0,0 +1,159 @@
+package har
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func ndjsonEntry(n int) string {
+	return fmt.Sprintf(`{"startedDateTime":"2024-05-01T10:00:0%d.000Z","time":5,"request":{"method":"GET","url":"https://example.com/%d"},"response":{"status":200}}`, n, n) + "\n"
+}
+
+func TestLoadNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.ndjson")
+	os.WriteFile(path, []byte(ndjsonEntry(0)+"\n"+ndjsonEntry(1)), 0644)
+
+	harFile, err := LoadHARFile(path)
+	if err != nil {
+		t.Fatalf("LoadHARFile failed: %v", err)
+	}
+	if harFile.Log.Version != "1.2" || len(harFile.Log.Entries) != 2 || harFile.Log.Entries[1].Request.URL != "https://example.com/1" {
+		t.Errorf("unexpected NDJSON log: %+v", harFile.Log)
+	}
+
+	os.WriteFile(path, []byte(ndjsonEntry(0)+`{"method":"GET"}`+"\n"), 0644)
+	if _, err := LoadHARFile(path); err == nil {
+		t.Error("expected an error for a line that isn't an entry")
+	}
+}
+
+func TestLoadHARWithOtherMembersFirst(t *testing.T) {
+	entry := strings.TrimSpace(ndjsonEntry(0))
+	for name, doc := range map[string]string{
+		"one line": `{"_comment":"exported","log":{"version":"1.2","entries":[` + entry + `]}}`,
+		"indented": "{\n  \"_comment\": \"exported\",\n  \"log\": {\"version\": \"1.2\", \"entries\": [" + entry + "]}\n}\n",
+	} {
+		path := filepath.Join(t.TempDir(), "capture.har")
+		os.WriteFile(path, []byte(doc), 0644)
+
+		harFile, err := LoadHARFile(path)
+		if err != nil {
+			t.Fatalf("%s: LoadHARFile failed: %v", name, err)
+		}
+		if len(harFile.Log.Entries) != 1 {
+			t.Errorf("%s: expected 1 entry, got %d", name, len(harFile.Log.Entries))
+		}
+
+		loader := NewStreamingLoader()
+		if err := loader.parse(strings.NewReader(doc)); err != nil {
+			t.Fatalf("%s: streaming parse failed: %v", name, err)
+		}
+		if loader.GetEntryCount() != 1 {
+			t.Errorf("%s: expected 1 streamed entry, got %d", name, loader.GetEntryCount())
+		}
+	}
+}
+
+// followed starts following path and returns a function waiting for the
+// loader to have n entries
+func followed(t *testing.T, path string) (*StreamingLoader, func(n int), chan error) {
+	t.Helper()
+	FollowInterval = 10 * time.Millisecond
+	var mu sync.Mutex
+	count := 0
+	done := make(chan error, 1)
+	loader := NewStreamingLoader()
+	loader.SetCallbacks(func(HAREntry, int) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}, func() { done <- nil }, func(err error) { done <- err }, nil)
+	loader.FollowHARFile(path)
+	t.Cleanup(loader.StopFollowing)
+
+	waitFor := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			mu.Lock()
+			got := count
+			mu.Unlock()
+			if got >= n {
+				if got > n {
+					t.Fatalf("expected %d entries, got %d", n, got)
+				}
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+		t.Fatalf("timed out waiting for %d entries, have %d", n, loader.GetEntryCount())
+	}
+	return loader, waitFor, done
+}
+
+func appendFile(t *testing.T, path, text string) {
+	t.Helper()
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	file.WriteString(text)
+}
+
+func TestFollowNDJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.ndjson")
+	os.WriteFile(path, []byte(ndjsonEntry(0)), 0644)
+	loader, waitFor, done := followed(t, path)
+	waitFor(1)
+
+	// A line written in two parts is only added once complete
+	line := ndjsonEntry(1)
+	appendFile(t, path, line[:20])
+	time.Sleep(50 * time.Millisecond)
+	appendFile(t, path, line[20:]+ndjsonEntry(2))
+	waitFor(3)
+
+	loader.StopFollowing()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("follow failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("follow did not stop")
+	}
+	if entries := loader.GetEntries(); entries[2].Request.URL != "https://example.com/2" {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
+
+func TestFollowHAR(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "capture.har")
+	head := `{"log":{"version":"1.2","creator":{"name":"t","version":"1"},"entries":[`
+	os.WriteFile(path, []byte(head+ndjsonEntry(0)), 0644)
+	loader, waitFor, _ := followed(t, path)
+	waitFor(1)
+
+	// Entries are added as the document grows
+	appendFile(t, path, ","+ndjsonEntry(1))
+	waitFor(2)
+
+	// Once complete, the document is read again when it changes
+	appendFile(t, path, "]}}")
+	time.Sleep(50 * time.Millisecond)
+	os.WriteFile(path, []byte(head+ndjsonEntry(0)+","+ndjsonEntry(1)+","+ndjsonEntry(2)+"]}}"), 0644)
+	waitFor(3)
+
+	// Rewritten again and still being written, its new entries are added
+	os.WriteFile(path, []byte(head+ndjsonEntry(0)+","+ndjsonEntry(1)+","+ndjsonEntry(2)+","+ndjsonEntry(3)), 0644)
+	waitFor(4)
+	if loader.GetHARFile().Log.Creator.Name != "t" {
+		t.Errorf("log metadata lost: %+v", loader.GetHARFile().Log)
+	}
+}
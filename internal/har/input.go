@@ -0,0 +1,176 @@
+package har
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// StdinPath is the file name that reads a HAR from standard input
+const StdinPath = "-"
+
+// SourceNames returns the label each input file's entries are tagged with
+// when several files are loaded together: the base name, or "stdin" for "-",
+// with "#2", "#3"... added to tell repeated names apart
+func SourceNames(filePaths []string) []string {
+	names := make([]string, len(filePaths))
+	seen := make(map[string]int)
+	for i, path := range filePaths {
+		name := filepath.Base(path)
+		if path == StdinPath {
+			name = "stdin"
+		}
+		seen[name]++
+		if seen[name] > 1 {
+			name += "#" + strconv.Itoa(seen[name])
+		}
+		names[i] = name
+	}
+	return names
+}
+
+// Magic bytes of the compression formats OpenHARInput understands
+var (
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh")
+)
+
+// Format is a capture format other tools write, converted to HAR as it is read
+type Format struct {
+	Name    string
+	Detect  func(head []byte) bool // head is the start of the decompressed input
+	Convert func(r io.Reader) (*HARFile, error)
+}
+
+// formats are the registered capture formats, tried in order
+var formats []Format
+
+// formatSniffLen is how much input a format is detected from
+const formatSniffLen = 4096
+
+// RegisterFormat makes OpenHARInput, and so every loader, recognize another
+// capture format and convert it to HAR
+func RegisterFormat(format Format) {
+	formats = append(formats, format)
+}
+
+// OpenHARInput opens a HAR for reading from a path, or from stdin for "-".
+// gzip, zstd and bzip2 input is detected from its magic bytes and
+// decompressed as a stream, so the format doesn't depend on the file name.
+// Captures in a registered format are converted to HAR JSON
+func OpenHARInput(path string) (io.ReadCloser, error) {
+	var source io.ReadCloser
+	if path == StdinPath {
+		// Leave stdin open for the rest of the process
+		source = io.NopCloser(os.Stdin)
+	} else {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		source = file
+	}
+
+	reader, err := decompress(source)
+	if err != nil {
+		source.Close()
+		return nil, err
+	}
+	return convert(reader)
+}
+
+// convert reads input in a registered format as HAR JSON, and passes
+// anything else through
+func convert(input io.ReadCloser) (io.ReadCloser, error) {
+	if len(formats) == 0 {
+		return input, nil
+	}
+	buffered := bufio.NewReaderSize(input, formatSniffLen)
+	head, err := buffered.Peek(formatSniffLen)
+	if err != nil && err != io.EOF {
+		input.Close()
+		return nil, err
+	}
+	// A HAR may quote other formats anywhere in its bodies, so it is never
+	// handed to their detectors
+	if key, _ := firstKey(head); key == "log" {
+		return &decompressedReader{Reader: buffered, closers: []io.Closer{input}}, nil
+	}
+	for _, format := range formats {
+		if !format.Detect(head) {
+			continue
+		}
+		harFile, err := format.Convert(buffered)
+		input.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading %s capture: %w", format.Name, err)
+		}
+		data, err := json.Marshal(harFile)
+		if err != nil {
+			return nil, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	return &decompressedReader{Reader: buffered, closers: []io.Closer{input}}, nil
+}
+
+// decompress wraps source in a decompressor matching its magic bytes
+func decompress(source io.ReadCloser) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(source)
+	magic, err := buffered.Peek(len(zstdMagic))
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("reading gzip input: %w", err)
+		}
+		return &decompressedReader{Reader: gz, closers: []io.Closer{gz, source}}, nil
+	case bytes.HasPrefix(magic, zstdMagic):
+		zr, err := zstd.NewReader(buffered)
+		if err != nil {
+			return nil, fmt.Errorf("reading zstd input: %w", err)
+		}
+		return &decompressedReader{Reader: zr, closers: []io.Closer{zstdCloser{zr}, source}}, nil
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return &decompressedReader{Reader: bzip2.NewReader(buffered), closers: []io.Closer{source}}, nil
+	}
+	return &decompressedReader{Reader: buffered, closers: []io.Closer{source}}, nil
+}
+
+// decompressedReader closes the decompressor and the underlying source together
+type decompressedReader struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *decompressedReader) Close() error {
+	var firstErr error
+	for _, closer := range r.closers {
+		if err := closer.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// zstdCloser adapts zstd.Decoder, whose Close returns nothing
+type zstdCloser struct{ decoder *zstd.Decoder }
+
+func (c zstdCloser) Close() error {
+	c.decoder.Close()
+	return nil
+}
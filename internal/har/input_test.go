@@ -0,0 +1,191 @@
+package har
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// emptyHARBzip2 is {"log":{"version":"1.2","creator":{"name":"t","version":"1"},"entries":[]}} compressed with bzip2
+const emptyHARBzip2 = "QlpoOTFBWSZTWf+zRRMAACKbgBAFMBAACiqnnQogAEDUeqeQR6TI9J6hQAYjTTRoj0Ggzvm4+FSW6iZmMTEqdsybYwNwmgu0BtFQ6wVlcVsxHmQTH4u5IpwoSH/ZoomA"
+
+func writeCompressedHAR(t *testing.T, name string, compress func(*bytes.Buffer)) string {
+	t.Helper()
+	var buf bytes.Buffer
+	compress(&buf)
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+	return path
+}
+
+func TestLoadHARFileCompressed(t *testing.T) {
+	gzipPath := writeCompressedHAR(t, "capture.har.gz", func(buf *bytes.Buffer) {
+		gz := gzip.NewWriter(buf)
+		gz.Write([]byte(chromeHAR))
+		gz.Close()
+	})
+	// Detection uses magic bytes, so the extension doesn't matter
+	zstdPath := writeCompressedHAR(t, "capture.har", func(buf *bytes.Buffer) {
+		zw, _ := zstd.NewWriter(buf)
+		zw.Write([]byte(chromeHAR))
+		zw.Close()
+	})
+
+	for _, path := range []string{gzipPath, zstdPath} {
+		harFile, err := LoadHARFile(path)
+		if err != nil {
+			t.Fatalf("LoadHARFile(%s) failed: %v", filepath.Base(path), err)
+		}
+		if len(harFile.Log.Entries) != 2 {
+			t.Errorf("%s: expected 2 entries, got %d", filepath.Base(path), len(harFile.Log.Entries))
+		}
+	}
+
+	bzip2Path := writeCompressedHAR(t, "capture.har.bz2", func(buf *bytes.Buffer) {
+		data, _ := base64.StdEncoding.DecodeString(emptyHARBzip2)
+		buf.Write(data)
+	})
+	harFile, err := LoadHARFile(bzip2Path)
+	if err != nil {
+		t.Fatalf("LoadHARFile(bzip2) failed: %v", err)
+	}
+	if harFile.Log.Creator.Name != "t" {
+		t.Errorf("unexpected bzip2 HAR: %+v", harFile.Log)
+	}
+}
+
+func TestStreamingLoaderCompressed(t *testing.T) {
+	path := writeCompressedHAR(t, "capture.har.zst", func(buf *bytes.Buffer) {
+		zw, _ := zstd.NewWriter(buf)
+		zw.Write([]byte(chromeHAR))
+		zw.Close()
+	})
+
+	loader := NewStreamingLoader()
+	done := make(chan error, 1)
+	progress := 0
+	loader.SetCallbacks(nil, func() { done <- nil }, func(err error) { done <- err }, func(count int) { progress = count })
+	loader.LoadHARFileStreaming(path)
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("streaming load failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("streaming load timed out")
+	}
+	if loader.GetEntryCount() != 2 || progress == 0 {
+		t.Errorf("expected 2 entries with progress, got %d entries, progress %d", loader.GetEntryCount(), progress)
+	}
+}
+
+func TestLoadHARFileStdin(t *testing.T) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("failed to create pipe: %v", err)
+	}
+	stdin := os.Stdin
+	os.Stdin = reader
+	defer func() { os.Stdin = stdin }()
+
+	go func() {
+		gz := gzip.NewWriter(writer)
+		gz.Write([]byte(chromeHAR))
+		gz.Close()
+		writer.Close()
+	}()
+
+	harFile, err := LoadHARFile(StdinPath)
+	if err != nil {
+		t.Fatalf("LoadHARFile(-) failed: %v", err)
+	}
+	if len(harFile.Log.Entries) != 2 {
+		t.Errorf("expected 2 entries from stdin, got %d", len(harFile.Log.Entries))
+	}
+}
+
+func TestSourceNames(t *testing.T) {
+	got := SourceNames([]string{"/tmp/a/run.har", "-", "/tmp/b/run.har", "other.har.gz"})
+	want := []string{"run.har", "stdin", "run.har#2", "other.har.gz"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("SourceNames()[%d] = %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestStreamingLoaderMultipleFiles(t *testing.T) {
+	first := writeTempHAR(t, chromeHAR)
+	second := writeCompressedHAR(t, "input.har", func(buf *bytes.Buffer) {
+		gz := gzip.NewWriter(buf)
+		gz.Write([]byte(chromeHAR))
+		gz.Close()
+	})
+
+	loader := NewStreamingLoader()
+	done := make(chan error, 1)
+	loader.SetCallbacks(nil, func() { done <- nil }, func(err error) { done <- err }, nil)
+	loader.LoadHARFilesStreaming([]string{first, second})
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("streaming load failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("streaming load timed out")
+	}
+
+	entries := loader.GetEntries()
+	if len(entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(entries))
+	}
+	if entries[0].Source != "input.har" || entries[2].Source != "input.har#2" {
+		t.Errorf("unexpected sources: %q, %q", entries[0].Source, entries[2].Source)
+	}
+	if entries[2].Pageref != "input.har#2/page_1" {
+		t.Errorf("expected page ids prefixed with the source, got %q", entries[2].Pageref)
+	}
+	if got := loader.GetIndex().GetBySource("input.har#2"); len(got) != 2 || got[0] != 2 {
+		t.Errorf("expected entries 2 and 3 indexed by source, got %v", got)
+	}
+
+	// Entries added after loading, like replays, are indexed but not tagged again
+	replayed := entries[0]
+	if index := loader.AppendEntry(replayed); index != 4 || loader.GetEntries()[4].Pageref != "input.har/page_1" {
+		t.Errorf("unexpected appended entry %d: %+v", index, loader.GetEntries()[index])
+	}
+	if got := loader.GetIndex().GetBySource("input.har"); len(got) != 3 || got[2] != 4 {
+		t.Errorf("expected the appended entry indexed by source, got %v", got)
+	}
+
+	harFile := loader.GetHARFile()
+	if len(harFile.Log.Pages) != 4 || harFile.Log.Creator.Name != "WebInspector" {
+		t.Errorf("expected merged pages and the first file's creator, got %d pages, %+v", len(harFile.Log.Pages), harFile.Log.Creator)
+	}
+
+	// Saving keeps the source attribution, and reloading the merged file keeps it too
+	output := filepath.Join(t.TempDir(), "merged.har")
+	if err := SaveFilteredHAR(harFile, []int{1, 2}, output); err != nil {
+		t.Fatalf("SaveFilteredHAR failed: %v", err)
+	}
+	saved, err := LoadHARFile(output)
+	if err != nil {
+		t.Fatalf("failed to reload output: %v", err)
+	}
+	if len(saved.Log.Entries) != 2 || saved.Log.Entries[0].Source != "input.har" || saved.Log.Entries[1].Source != "input.har#2" {
+		t.Errorf("source attribution lost: %+v", saved.Log.Entries)
+	}
+	if len(saved.Log.Pages) != 2 {
+		t.Errorf("expected the two referenced pages to remain, got %+v", saved.Log.Pages)
+	}
+}
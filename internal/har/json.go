@@ -0,0 +1,595 @@
+package har
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// jsonObject is a HAR object: struct fields hold the members it models,
+// and Custom the rest, with the member order it was loaded with
+type jsonObject interface {
+	members() (order *[]string, custom *map[string]json.RawMessage)
+}
+
+var jsonObjectType = reflect.TypeOf((*jsonObject)(nil)).Elem()
+
+// jsonField is a member a HAR struct models directly
+type jsonField struct {
+	name      string
+	quoted    []byte // name as a JSON string
+	index     int
+	omitEmpty bool
+	slice     bool // written as [] rather than null
+	walked    bool // a HAR object, or a pointer to or slice of them
+}
+
+// fieldInfo describes the JSON members a HAR struct models directly
+type fieldInfo struct {
+	fields []jsonField
+	names  map[string]int // member name -> position in fields
+}
+
+var fieldInfoCache sync.Map // reflect.Type -> *fieldInfo
+
+// knownFields returns the JSON members declared on a HAR struct type
+func knownFields(t reflect.Type) *fieldInfo {
+	if cached, ok := fieldInfoCache.Load(t); ok {
+		return cached.(*fieldInfo)
+	}
+
+	info := &fieldInfo{names: make(map[string]int)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue // unexported
+		}
+		tag := strings.Split(field.Tag.Get("json"), ",")
+		name := tag[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		quoted, _ := json.Marshal(name)
+		info.names[name] = len(info.fields)
+		info.fields = append(info.fields, jsonField{
+			name:      name,
+			quoted:    quoted,
+			index:     i,
+			omitEmpty: len(tag) > 1 && tag[1] == "omitempty",
+			slice:     field.Type.Kind() == reflect.Slice,
+			walked:    isWalked(field.Type),
+		})
+	}
+
+	fieldInfoCache.Store(t, info)
+	return info
+}
+
+// isWalked reports whether values of t are read and written member by
+// member, rather than handed to encoding/json whole
+func isWalked(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && reflect.PtrTo(t).Implements(jsonObjectType)
+}
+
+// decodeJSON fills a HAR object from data
+func decodeJSON(data []byte, v jsonObject) error {
+	return readJSON(json.NewDecoder(bytes.NewReader(data)), v)
+}
+
+// readJSON fills a HAR object from the next value of decoder. The whole
+// tree below it is read in the same pass, so each byte is scanned once
+// however deeply it is nested
+func readJSON(decoder *json.Decoder, v jsonObject) error {
+	return readValue(decoder, reflect.ValueOf(v).Elem())
+}
+
+// readValue reads the next value of decoder into a HAR object, a pointer
+// to one or a slice of them
+func readValue(decoder *json.Decoder, value reflect.Value) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token == nil {
+		// null leaves a struct as it was, like encoding/json does
+		if value.Kind() != reflect.Struct {
+			value.SetZero()
+		}
+		return nil
+	}
+
+	switch value.Kind() {
+	case reflect.Slice:
+		if token != json.Delim('[') {
+			return fmt.Errorf("expected JSON array")
+		}
+		elements := reflect.MakeSlice(value.Type(), 0, 0)
+		for decoder.More() {
+			elements = reflect.Append(elements, reflect.Zero(value.Type().Elem()))
+			if err := readValue(decoder, elements.Index(elements.Len()-1)); err != nil {
+				return err
+			}
+		}
+		value.Set(elements)
+		_, err := decoder.Token()
+		return err
+	case reflect.Ptr:
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	if token != json.Delim('{') {
+		return fmt.Errorf("expected JSON object")
+	}
+	return readMembers(decoder, value)
+}
+
+// readMembers reads the members of an object whose opening brace has been
+// read into a HAR struct, keeping those it doesn't model in Custom
+func readMembers(decoder *json.Decoder, value reflect.Value) error {
+	info := knownFields(value.Type())
+	order, custom := value.Addr().Interface().(jsonObject).members()
+	*order = make([]string, 0, len(info.fields))
+	*custom = nil
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("expected object key")
+		}
+		if !slices.Contains(*order, key) {
+			*order = append(*order, key)
+		}
+
+		if position, known := info.names[key]; known {
+			field := info.fields[position]
+			target := value.Field(field.index)
+			if field.walked {
+				err = readValue(decoder, target)
+			} else {
+				err = decoder.Decode(target.Addr().Interface())
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return err
+		}
+		if *custom == nil {
+			*custom = make(map[string]json.RawMessage)
+		}
+		(*custom)[key] = raw
+	}
+
+	_, err := decoder.Token()
+	return err
+}
+
+// encodeJSON writes a HAR object and everything below it in one pass
+func encodeJSON(v jsonObject) ([]byte, error) {
+	var buf bytes.Buffer
+	err := writeValue(&buf, reflect.ValueOf(v).Elem())
+	return buf.Bytes(), err
+}
+
+// writeValue writes a HAR object, a pointer to one or a slice of them
+func writeValue(buf *bytes.Buffer, value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Slice:
+		if value.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		buf.WriteByte('[')
+		for i := 0; i < value.Len(); i++ {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeValue(buf, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+	case reflect.Ptr:
+		if value.IsNil() {
+			buf.WriteString("null")
+			return nil
+		}
+		value = value.Elem()
+	}
+	return writeMembers(buf, value)
+}
+
+// writeMembers writes a HAR struct and its custom members. Objects that were
+// loaded from JSON keep their original member order and only gain members
+// that now hold a non-zero value.
+func writeMembers(buf *bytes.Buffer, value reflect.Value) error {
+	info := knownFields(value.Type())
+	orderPtr, customPtr := value.Addr().Interface().(jsonObject).members()
+	order, custom := *orderPtr, *customPtr
+
+	written := make(map[string]bool, len(info.fields)+len(custom))
+	// writeKey starts a member, returning where it starts so it can be taken back
+	writeKey := func(quoted []byte) int {
+		start := buf.Len()
+		if len(written) > 0 {
+			buf.WriteByte(',')
+		}
+		buf.Write(quoted)
+		buf.WriteByte(':')
+		return start
+	}
+	writeField := func(field jsonField, target reflect.Value) error {
+		if field.walked {
+			return writeValue(buf, target)
+		}
+		return writeLeaf(buf, target.Interface())
+	}
+	writeCustom := func(key string, raw json.RawMessage) error {
+		quoted, err := marshalUnescaped(key)
+		if err != nil {
+			return err
+		}
+		writeKey(quoted)
+		buf.Write(raw)
+		written[key] = true
+		return nil
+	}
+
+	buf.WriteByte('{')
+
+	// Members in the order they were loaded
+	for _, key := range order {
+		if written[key] {
+			continue
+		}
+		position, known := info.names[key]
+		if !known {
+			if raw, ok := custom[key]; ok {
+				if err := writeCustom(key, raw); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		field := info.fields[position]
+		target := value.Field(field.index)
+		if field.omitEmpty && isEmptyValue(target) {
+			// Omitted by an omitempty tag; keep it since the source had it
+			target = reflect.Zero(target.Type())
+		}
+		writeKey(field.quoted)
+		if err := writeField(field, target); err != nil {
+			return err
+		}
+		written[key] = true
+	}
+
+	// Modelled members that were filled in after loading
+	for _, field := range info.fields {
+		target := value.Field(field.index)
+		if written[field.name] || field.omitEmpty && isEmptyValue(target) {
+			continue
+		}
+		start := writeKey(field.quoted)
+		valueStart := buf.Len()
+		if err := writeField(field, target); err != nil {
+			return err
+		}
+		encoded := buf.Bytes()[valueStart:]
+		if order != nil && isZeroJSON(encoded) {
+			buf.Truncate(start)
+			continue
+		}
+		if field.slice && string(encoded) == "null" {
+			buf.Truncate(valueStart)
+			buf.WriteString("[]")
+		}
+		written[field.name] = true
+	}
+
+	// Custom members that were added after loading
+	var added []string
+	for key := range custom {
+		if !written[key] {
+			added = append(added, key)
+		}
+	}
+	sort.Strings(added)
+	for _, key := range added {
+		if err := writeCustom(key, custom[key]); err != nil {
+			return err
+		}
+	}
+
+	buf.WriteByte('}')
+	return nil
+}
+
+// writeLeaf writes a value encoding/json handles, leaving <, > and & as-is
+func writeLeaf(buf *bytes.Buffer, v interface{}) error {
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	buf.Truncate(buf.Len() - 1) // the newline Encode ends with
+	return nil
+}
+
+// isEmptyValue reports whether an omitempty member is left out, as
+// encoding/json decides it
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.Interface, reflect.Ptr:
+		return v.IsZero()
+	}
+	return false
+}
+
+// assembleObject builds a JSON object from members in the given order
+func assembleObject(order []string, members map[string]json.RawMessage) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range order {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, _ := json.Marshal(key)
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(members[key])
+	}
+	buf.WriteByte('}')
+	return buf.Bytes()
+}
+
+// marshalUnescaped marshals v without escaping <, > and &, which HAR bodies are full of
+func marshalUnescaped(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := writeLeaf(&buf, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// isZeroJSON reports whether a JSON value is the encoding of a Go zero value
+func isZeroJSON(value []byte) bool {
+	switch string(value) {
+	case "0", `""`, "false", "null", "[]", "{}":
+		return true
+	}
+	return false
+}
+
+// JSON codecs for the HAR types. A HAR object decoded or encoded on its own
+// reads or writes the objects inside it too, without their own codecs.
+
+func (h *HARHeader) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, h)
+}
+
+func (h HARHeader) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&h)
+}
+
+func (h *HARHeader) members() (*[]string, *map[string]json.RawMessage) {
+	return &h.order, &h.Custom
+}
+
+func (c *HARCookie) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, c)
+}
+
+func (c HARCookie) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&c)
+}
+
+func (c *HARCookie) members() (*[]string, *map[string]json.RawMessage) {
+	return &c.order, &c.Custom
+}
+
+func (q *HARQueryParam) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, q)
+}
+
+func (q HARQueryParam) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&q)
+}
+
+func (q *HARQueryParam) members() (*[]string, *map[string]json.RawMessage) {
+	return &q.order, &q.Custom
+}
+
+func (p *HARParam) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, p)
+}
+
+func (p HARParam) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&p)
+}
+
+func (p *HARParam) members() (*[]string, *map[string]json.RawMessage) {
+	return &p.order, &p.Custom
+}
+
+func (p *HARPostData) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, p)
+}
+
+func (p HARPostData) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&p)
+}
+
+func (p *HARPostData) members() (*[]string, *map[string]json.RawMessage) {
+	return &p.order, &p.Custom
+}
+
+func (r *HARRequest) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, r)
+}
+
+func (r HARRequest) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&r)
+}
+
+func (r *HARRequest) members() (*[]string, *map[string]json.RawMessage) {
+	return &r.order, &r.Custom
+}
+
+func (c *HARContent) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, c)
+}
+
+func (c HARContent) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&c)
+}
+
+func (c *HARContent) members() (*[]string, *map[string]json.RawMessage) {
+	return &c.order, &c.Custom
+}
+
+func (r *HARResponse) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, r)
+}
+
+func (r HARResponse) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&r)
+}
+
+func (r *HARResponse) members() (*[]string, *map[string]json.RawMessage) {
+	return &r.order, &r.Custom
+}
+
+func (c *HARCacheEntry) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, c)
+}
+
+func (c HARCacheEntry) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&c)
+}
+
+func (c *HARCacheEntry) members() (*[]string, *map[string]json.RawMessage) {
+	return &c.order, &c.Custom
+}
+
+func (c *HARCache) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, c)
+}
+
+func (c HARCache) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&c)
+}
+
+func (c *HARCache) members() (*[]string, *map[string]json.RawMessage) {
+	return &c.order, &c.Custom
+}
+
+func (t *HARTimings) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, t)
+}
+
+func (t HARTimings) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&t)
+}
+
+func (t *HARTimings) members() (*[]string, *map[string]json.RawMessage) {
+	return &t.order, &t.Custom
+}
+
+func (e *HAREntry) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, e)
+}
+
+func (e HAREntry) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&e)
+}
+
+func (e *HAREntry) members() (*[]string, *map[string]json.RawMessage) {
+	return &e.order, &e.Custom
+}
+
+func (p *HARPageTimings) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, p)
+}
+
+func (p HARPageTimings) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&p)
+}
+
+func (p *HARPageTimings) members() (*[]string, *map[string]json.RawMessage) {
+	return &p.order, &p.Custom
+}
+
+func (p *HARPage) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, p)
+}
+
+func (p HARPage) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&p)
+}
+
+func (p *HARPage) members() (*[]string, *map[string]json.RawMessage) {
+	return &p.order, &p.Custom
+}
+
+func (c *HARCreator) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, c)
+}
+
+func (c HARCreator) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&c)
+}
+
+func (c *HARCreator) members() (*[]string, *map[string]json.RawMessage) {
+	return &c.order, &c.Custom
+}
+
+func (l *HARLog) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, l)
+}
+
+func (l HARLog) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&l)
+}
+
+func (l *HARLog) members() (*[]string, *map[string]json.RawMessage) {
+	return &l.order, &l.Custom
+}
+
+func (f *HARFile) UnmarshalJSON(data []byte) error {
+	return decodeJSON(data, f)
+}
+
+func (f HARFile) MarshalJSON() ([]byte, error) {
+	return encodeJSON(&f)
+}
+
+func (f *HARFile) members() (*[]string, *map[string]json.RawMessage) {
+	return &f.order, &f.Custom
+}
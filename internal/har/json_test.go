@@ -0,0 +1,423 @@
+package har
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// chromeHAR is a trimmed Chrome export exercising most of the HAR 1.2 spec
+// plus vendor fields, formatted the way SaveFilteredHAR writes files
+const chromeHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {
+      "name": "WebInspector",
+      "version": "537.36"
+    },
+    "browser": {
+      "name": "Chrome",
+      "version": "126.0",
+      "comment": "captured for ticket"
+    },
+    "pages": [
+      {
+        "startedDateTime": "2024-05-01T10:00:00.000Z",
+        "id": "page_1",
+        "title": "https://example.com/",
+        "pageTimings": {
+          "onContentLoad": 412.5,
+          "onLoad": 980.25
+        }
+      },
+      {
+        "startedDateTime": "2024-05-01T10:00:05.000Z",
+        "id": "page_2",
+        "title": "https://example.com/next",
+        "pageTimings": {
+          "onContentLoad": -1,
+          "onLoad": -1
+        }
+      }
+    ],
+    "entries": [
+      {
+        "_initiator": {
+          "type": "parser",
+          "url": "https://example.com/",
+          "lineNumber": 12
+        },
+        "_priority": "VeryHigh",
+        "_resourceType": "document",
+        "cache": {},
+        "connection": "443",
+        "pageref": "page_1",
+        "request": {
+          "method": "POST",
+          "url": "https://example.com/api/login?next=%2Fhome&a=b",
+          "httpVersion": "http/2.0",
+          "headers": [
+            {
+              "name": "content-type",
+              "value": "application/x-www-form-urlencoded"
+            }
+          ],
+          "queryString": [
+            {
+              "name": "next",
+              "value": "/home"
+            },
+            {
+              "name": "a",
+              "value": "b"
+            }
+          ],
+          "cookies": [
+            {
+              "name": "sid",
+              "value": "abc",
+              "path": "/",
+              "domain": "example.com",
+              "expires": "2025-01-01T00:00:00.000Z",
+              "httpOnly": true,
+              "secure": true,
+              "sameSite": "Lax"
+            }
+          ],
+          "headersSize": -1,
+          "bodySize": 19,
+          "postData": {
+            "mimeType": "application/x-www-form-urlencoded",
+            "text": "user=a&pass=b<c>",
+            "params": [
+              {
+                "name": "user",
+                "value": "a"
+              }
+            ]
+          }
+        },
+        "response": {
+          "status": 200,
+          "statusText": "",
+          "httpVersion": "http/2.0",
+          "headers": [],
+          "cookies": [],
+          "content": {
+            "size": 41,
+            "mimeType": "text/html",
+            "compression": 12,
+            "text": "<html><body>ok & done</body></html>"
+          },
+          "redirectURL": "",
+          "headersSize": -1,
+          "bodySize": -1,
+          "_transferSize": 512,
+          "_error": null
+        },
+        "serverIPAddress": "93.184.216.34",
+        "startedDateTime": "2024-05-01T10:00:00.123Z",
+        "time": 120.45600000000002,
+        "timings": {
+          "blocked": 1.2,
+          "dns": -1,
+          "ssl": -1,
+          "connect": -1,
+          "send": 0.1,
+          "wait": 100.5,
+          "receive": 18.656,
+          "_blocked_queueing": 0.9
+        },
+        "comment": "first"
+      },
+      {
+        "pageref": "page_2",
+        "startedDateTime": "2024-05-01T10:00:05.500Z",
+        "time": 10,
+        "request": {
+          "method": "GET",
+          "url": "https://example.com/next",
+          "httpVersion": "HTTP/1.1",
+          "headers": [],
+          "queryString": [],
+          "cookies": [],
+          "headersSize": 100,
+          "bodySize": 0
+        },
+        "response": {
+          "status": 304,
+          "statusText": "Not Modified",
+          "httpVersion": "HTTP/1.1",
+          "headers": [],
+          "cookies": [],
+          "content": {
+            "size": 0,
+            "mimeType": "x-unknown"
+          },
+          "redirectURL": "",
+          "headersSize": 80,
+          "bodySize": 0
+        },
+        "cache": {
+          "beforeRequest": {
+            "lastAccess": "2024-05-01T09:00:00.000Z",
+            "eTag": "\"v1\"",
+            "hitCount": 3
+          }
+        },
+        "timings": {
+          "send": 1,
+          "wait": 8,
+          "receive": 1
+        }
+      }
+    ],
+    "_exportedBy": "devtools"
+  },
+  "_meta": [
+    1,
+    2
+  ]
+}
+`
+
+func writeTempHAR(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input.har")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+	return path
+}
+
+func TestSaveFilteredHARRoundTrip(t *testing.T) {
+	harFile, err := LoadHARFile(writeTempHAR(t, chromeHAR))
+	if err != nil {
+		t.Fatalf("LoadHARFile failed: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "output.har")
+	if err := SaveFilteredHAR(harFile, []int{0, 1}, output); err != nil {
+		t.Fatalf("SaveFilteredHAR failed: %v", err)
+	}
+
+	saved, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(saved) != chromeHAR {
+		t.Errorf("round trip changed the file\n--- got ---\n%s", saved)
+	}
+}
+
+func TestLoadHARFileKeepsSpecFields(t *testing.T) {
+	harFile, err := LoadHARFile(writeTempHAR(t, chromeHAR))
+	if err != nil {
+		t.Fatalf("LoadHARFile failed: %v", err)
+	}
+
+	log := harFile.Log
+	if log.Creator.Name != "WebInspector" || log.Browser == nil || log.Browser.Comment != "captured for ticket" {
+		t.Errorf("creator/browser not loaded: %+v %+v", log.Creator, log.Browser)
+	}
+	if len(log.Pages) != 2 || log.Pages[0].PageTimings.OnLoad != 980.25 {
+		t.Errorf("pages not loaded: %+v", log.Pages)
+	}
+
+	entry := log.Entries[0]
+	if entry.Pageref != "page_1" || entry.ServerIPAddress != "93.184.216.34" || entry.Connection != "443" {
+		t.Errorf("entry fields not loaded: %+v", entry)
+	}
+	if len(entry.Request.QueryString) != 2 || entry.Request.BodySize != 19 || entry.Request.HeadersSize != -1 {
+		t.Errorf("request fields not loaded: %+v", entry.Request)
+	}
+	if entry.Response.Content.Compression != 12 {
+		t.Errorf("expected compression 12, got %d", entry.Response.Content.Compression)
+	}
+	if entry.ResourceType != "document" || entry.Priority != "VeryHigh" {
+		t.Errorf("chrome fields not loaded: %q %q", entry.ResourceType, entry.Priority)
+	}
+	if _, ok := entry.Custom["_initiator"]; !ok {
+		t.Errorf("expected _initiator to be kept as a custom field, got %v", entry.Custom)
+	}
+	if string(entry.Request.Cookies[0].Custom["sameSite"]) != `"Lax"` {
+		t.Errorf("expected sameSite custom field, got %v", entry.Request.Cookies[0].Custom)
+	}
+	if log.Entries[1].Cache.BeforeRequest.ETag != `"v1"` {
+		t.Errorf("cache entry not loaded: %+v", log.Entries[1].Cache.BeforeRequest)
+	}
+}
+
+func TestSaveFilteredHARDropsEmptiedPages(t *testing.T) {
+	harFile, err := LoadHARFile(writeTempHAR(t, chromeHAR))
+	if err != nil {
+		t.Fatalf("LoadHARFile failed: %v", err)
+	}
+
+	output := filepath.Join(t.TempDir(), "output.har")
+	if err := SaveFilteredHAR(harFile, []int{1}, output); err != nil {
+		t.Fatalf("SaveFilteredHAR failed: %v", err)
+	}
+
+	saved, err := LoadHARFile(output)
+	if err != nil {
+		t.Fatalf("failed to reload output: %v", err)
+	}
+	if len(saved.Log.Entries) != 1 || saved.Log.Entries[0].Pageref != "page_2" {
+		t.Fatalf("unexpected entries: %+v", saved.Log.Entries)
+	}
+	if len(saved.Log.Pages) != 1 || saved.Log.Pages[0].ID != "page_2" {
+		t.Errorf("expected only page_2 to remain, got %+v", saved.Log.Pages)
+	}
+	if saved.Log.Creator.Name != "WebInspector" || string(saved.Log.Custom["_exportedBy"]) != `"devtools"` {
+		t.Errorf("log metadata lost: %+v", saved.Log)
+	}
+}
+
+func TestStreamingLoaderGetHARFileRoundTrip(t *testing.T) {
+	loader := NewStreamingLoader()
+	done := make(chan error, 1)
+	loader.SetCallbacks(nil, func() { done <- nil }, func(err error) { done <- err }, nil)
+	loader.LoadHARFileStreaming(writeTempHAR(t, chromeHAR))
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("streaming load failed: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("streaming load timed out")
+	}
+
+	output := filepath.Join(t.TempDir(), "output.har")
+	if err := SaveFilteredHAR(loader.GetHARFile(), []int{0, 1}, output); err != nil {
+		t.Fatalf("SaveFilteredHAR failed: %v", err)
+	}
+	saved, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatalf("failed to read output: %v", err)
+	}
+	if string(saved) != chromeHAR {
+		t.Errorf("streaming round trip changed the file\n--- got ---\n%s", saved)
+	}
+}
+
+func TestStreamingLoaderLive(t *testing.T) {
+	loader := NewStreamingLoader()
+	var added []int
+	loader.SetCallbacks(func(entry HAREntry, index int) { added = append(added, index) }, nil, nil, nil)
+	loader.StartLive(HARCreator{Name: "har-tui", Version: "test"})
+
+	for _, u := range []string{"https://example.com/a", "https://example.com/b"} {
+		loader.AddEntry(HAREntry{
+			StartedDateTime: "2024-05-01T10:00:00.000Z",
+			Request:         HARRequest{Method: "GET", URL: u},
+			Response:        HARResponse{Status: 200},
+		})
+	}
+	if len(added) != 2 || added[1] != 1 {
+		t.Fatalf("unexpected entries reported: %v", added)
+	}
+	if hosts := loader.GetIndex().GetByHost("example.com"); len(hosts) != 2 {
+		t.Errorf("expected both entries indexed, got %v", hosts)
+	}
+
+	output := filepath.Join(t.TempDir(), "live.har")
+	if err := SaveFilteredHAR(loader.GetHARFile(), []int{0, 1}, output); err != nil {
+		t.Fatalf("SaveFilteredHAR failed: %v", err)
+	}
+	saved, err := LoadHARFile(output)
+	if err != nil {
+		t.Fatalf("failed to load saved capture: %v", err)
+	}
+	if saved.Log.Version != "1.2" || saved.Log.Creator.Name != "har-tui" || len(saved.Log.Entries) != 2 {
+		t.Errorf("unexpected saved log: %+v", saved.Log)
+	}
+}
+
+func TestMarshalConstructedEntry(t *testing.T) {
+	entry := HAREntry{
+		StartedDateTime: "2024-05-01T10:00:00.000Z",
+		Request:         HARRequest{Method: "GET", URL: "https://example.com/"},
+		Response:        HARResponse{Status: 200},
+	}
+	entry.Custom = map[string]json.RawMessage{"_source": json.RawMessage(`"replay"`)}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	got := string(data)
+	for _, want := range []string{`"headers":[]`, `"queryString":[]`, `"cookies":[]`, `"_source":"replay"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("expected %s in %s", want, got)
+		}
+	}
+	if strings.Contains(got, "postData") || strings.Contains(got, "pageref") {
+		t.Errorf("unexpected optional members in %s", got)
+	}
+}
+
+// benchmarkHAR loads a capture of entries with large bodies and vendor fields
+func benchmarkHAR(b *testing.B) (string, *HARFile) {
+	b.Helper()
+	body := strings.Repeat(`{"id":1,"name":"<b>item</b>","tags":["a","b"]},`, 400)
+	entries := make([]string, 500)
+	for i := range entries {
+		entries[i] = `{"_priority":"High","startedDateTime":"2024-05-01T10:00:00.000Z","time":12.5,
+"request":{"method":"POST","url":"https://example.com/api/items","httpVersion":"HTTP/1.1","headers":[{"name":"Content-Type","value":"application/json"}],
+"queryString":[],"cookies":[],"postData":{"mimeType":"application/json","text":` + strconv.Quote(body) + `},"headersSize":-1,"bodySize":-1},
+"response":{"status":200,"statusText":"OK","httpVersion":"HTTP/1.1","headers":[],"cookies":[],"content":{"size":1,"mimeType":"application/json","text":` + strconv.Quote(body) + `,"_encoded":1},
+"redirectURL":"","headersSize":-1,"bodySize":-1,"_transferSize":1},"cache":{},"timings":{"send":1,"wait":10,"receive":1.5}}`
+	}
+	path := filepath.Join(b.TempDir(), "bench.har")
+	data := `{"log":{"version":"1.2","creator":{"name":"t","version":"1"},"entries":[` + strings.Join(entries, ",") + `]}}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		b.Fatal(err)
+	}
+	harFile, err := LoadHARFile(path)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return path, harFile
+}
+
+func BenchmarkLoadHARFile(b *testing.B) {
+	path, _ := benchmarkHAR(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadHARFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkStreamingLoader(b *testing.B) {
+	path, _ := benchmarkHAR(b)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := NewStreamingLoader().parseFile(path); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkWriteFilteredHAR(b *testing.B) {
+	_, harFile := benchmarkHAR(b)
+	indices := make([]int, len(harFile.Log.Entries))
+	for i := range indices {
+		indices[i] = i
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := WriteFilteredHAR(io.Discard, harFile, indices); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
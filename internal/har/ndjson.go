@@ -0,0 +1,137 @@
+package har
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// ndjsonCreator is the creator of logs built from NDJSON entries, which
+// carry no log metadata of their own
+var ndjsonCreator = HARCreator{Name: "har-tui"}
+
+// isNDJSON tells HAR entries written one per line from a HAR document. A
+// document starting with "log" is a HAR; otherwise the first line has to
+// be a whole HAR entry, so HARs with other members first still load. The
+// returned reader starts over from the beginning of r
+func isNDJSON(r *bufio.Reader) (bool, *bufio.Reader, error) {
+	// Peek a byte at a time, since a followed file may not have more yet
+	for n := 1; ; n++ {
+		data, err := r.Peek(n)
+		if key, decided := firstKey(data); decided {
+			if key == "" || key == "log" {
+				return false, r, nil
+			}
+			break
+		}
+		if err == io.EOF || err == bufio.ErrBufferFull {
+			return false, r, nil // let the HAR parser report what is wrong
+		}
+		if err != nil {
+			return false, nil, err
+		}
+	}
+
+	var read []byte
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return false, nil, err
+		}
+		read = append(read, line...)
+		if len(bytes.TrimSpace(line)) > 0 || err == io.EOF {
+			var entry HAREntry
+			ndjson := json.Unmarshal(line, &entry) == nil && entry.Request.URL != ""
+			return ndjson, bufio.NewReader(io.MultiReader(bytes.NewReader(read), r)), nil
+		}
+	}
+}
+
+// firstKey returns the first key of the JSON object data starts with, or
+// "" when data doesn't start with an object with keys. decided is false
+// while more data is needed
+func firstKey(data []byte) (key string, decided bool) {
+	data = bytes.TrimLeft(data, " \t\r\n")
+	if len(data) == 0 {
+		return "", false
+	}
+	if data[0] != '{' {
+		return "", true
+	}
+	data = bytes.TrimLeft(data[1:], " \t\r\n")
+	if len(data) == 0 {
+		return "", false
+	}
+	if data[0] != '"' {
+		return "", true
+	}
+	end := bytes.IndexByte(data[1:], '"')
+	if end < 0 {
+		return "", false
+	}
+	return string(data[1 : end+1]), true
+}
+
+// readNDJSON decodes one HAR entry per non-blank line, calling add for each
+func readNDJSON(r *bufio.Reader, add func(HAREntry)) error {
+	for line := 1; ; line++ {
+		data, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if len(bytes.TrimSpace(data)) > 0 {
+			var entry HAREntry
+			if jsonErr := json.Unmarshal(data, &entry); jsonErr != nil {
+				return fmt.Errorf("line %d: %w", line, jsonErr)
+			}
+			if entry.Request.URL == "" {
+				return fmt.Errorf("line %d: not a HAR entry", line)
+			}
+			add(entry)
+		}
+		if err == io.EOF {
+			return nil
+		}
+	}
+}
+
+// parseNDJSON streams HAR entries written one per line into the loader
+func (sl *StreamingLoader) parseNDJSON(r *bufio.Reader) error {
+	creator, err := json.Marshal(ndjsonCreator)
+	if err != nil {
+		return err
+	}
+	sl.recordMember(&sl.logOrder, sl.logMembers, "version", json.RawMessage(`"1.2"`))
+	sl.recordMember(&sl.logOrder, sl.logMembers, "creator", creator)
+	sl.recordMember(&sl.logOrder, sl.logMembers, "entries", json.RawMessage("[]"))
+
+	batchSize := 50
+	entryCount := 0
+	err = readNDJSON(r, func(entry HAREntry) {
+		if !sl.addParsed(entry) {
+			return
+		}
+		entryCount++
+		if entryCount%batchSize == 0 && sl.onProgress != nil {
+			sl.onProgress(sl.GetEntryCount())
+		}
+	})
+	if sl.onProgress != nil {
+		sl.onProgress(sl.GetEntryCount())
+	}
+	return err
+}
+
+// loadNDJSON builds a HAR 1.2 log from HAR entries written one per line
+func loadNDJSON(data []byte) (*HARFile, error) {
+	harFile := &HARFile{Log: HARLog{Version: "1.2", Creator: ndjsonCreator, Entries: []HAREntry{}}}
+	err := readNDJSON(bufio.NewReader(bytes.NewReader(data)), func(entry HAREntry) {
+		harFile.Log.Entries = append(harFile.Log.Entries, entry)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return harFile, nil
+}
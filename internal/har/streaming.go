@@ -1,11 +1,11 @@
 package har
 
 import (
+	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/url"
-	"os"
-	"strings"
 	"sync"
 )
 
@@ -16,6 +16,10 @@ type EntryIndex struct {
 	byHost   map[string][]int
 	byPath   map[string][]int
 	byType   map[string][]int
+	byPage   map[string][]int
+	bySource map[string][]int
+	sources  []string   // distinct non-empty sources in the order first seen
+	bodies   *TextIndex // built in the background as entries are added
 	mutex    sync.RWMutex
 }
 
@@ -27,6 +31,9 @@ func NewEntryIndex() *EntryIndex {
 		byHost:   make(map[string][]int),
 		byPath:   make(map[string][]int),
 		byType:   make(map[string][]int),
+		byPage:   make(map[string][]int),
+		bySource: make(map[string][]int),
+		bodies:   NewTextIndex(),
 	}
 }
 
@@ -45,6 +52,30 @@ func (idx *EntryIndex) AddEntry(entry HAREntry, index int) {
 	
 	requestType := GetRequestType(entry)
 	idx.byType[requestType] = append(idx.byType[requestType], index)
+	
+	idx.byPage[entry.Pageref] = append(idx.byPage[entry.Pageref], index)
+	if _, seen := idx.bySource[entry.Source]; !seen && entry.Source != "" {
+		idx.sources = append(idx.sources, entry.Source)
+	}
+	idx.bySource[entry.Source] = append(idx.bySource[entry.Source], index)
+	
+	// Decoding and indexing bodies is the slow part, so leave it to the worker
+	idx.bodies.AddAsync(index, func() (string, bool) { return entryBodies(entry) })
+}
+
+// BodyFilter returns a filter for entries whose bodies may contain text, or
+// nil if text is too short for the body index to help
+func (idx *EntryIndex) BodyFilter(text string) *BodyFilter {
+	candidates, ok := idx.bodies.Lookup(text)
+	if !ok {
+		return nil
+	}
+	return &BodyFilter{bodies: idx.bodies, candidates: candidates}
+}
+
+// WaitForBodies blocks until every added entry's bodies are in the body index
+func (idx *EntryIndex) WaitForBodies() {
+	idx.bodies.Wait()
 }
 
 func (idx *EntryIndex) GetByMethod(method string) []int {
@@ -79,119 +110,75 @@ func (idx *EntryIndex) GetByHost(host string) []int {
 	return result
 }
 
-func (idx *EntryIndex) GetErrorIndices(entries []HAREntry) []int {
+func (idx *EntryIndex) GetByPage(pageref string) []int {
 	idx.mutex.RLock()
 	defer idx.mutex.RUnlock()
-	
-	var result []int
-	for status, indices := range idx.byStatus {
-		if status >= 400 || status == 0 {
-			result = append(result, indices...)
-		}
-	}
+	result := make([]int, len(idx.byPage[pageref]))
+	copy(result, idx.byPage[pageref])
 	return result
 }
 
-func (idx *EntryIndex) FilterByText(entries []HAREntry, text string) []int {
-	if text == "" {
-		result := make([]int, len(entries))
-		for i := range entries {
-			result[i] = i
-		}
-		return result
-	}
-	
-	var result []int
-	text = strings.ToLower(text)
-	
-	for i, entry := range entries {
-		if idx.matchesTextSearch(entry, text) {
-			result = append(result, i)
-		}
-	}
+func (idx *EntryIndex) GetBySource(source string) []int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	result := make([]int, len(idx.bySource[source]))
+	copy(result, idx.bySource[source])
 	return result
 }
 
-// matchesTextSearch performs comprehensive text matching across all request/response fields
-func (idx *EntryIndex) matchesTextSearch(entry HAREntry, searchText string) bool {
-	// 1. Search URL (host, path, query parameters)
-	if u, err := url.Parse(entry.Request.URL); err == nil {
-		if strings.Contains(strings.ToLower(u.Host), searchText) ||
-		   strings.Contains(strings.ToLower(u.Path), searchText) ||
-		   strings.Contains(strings.ToLower(u.RawQuery), searchText) {
-			return true
-		}
-	}
-	
-	// 2. Search request method
-	if strings.Contains(strings.ToLower(entry.Request.Method), searchText) {
-		return true
-	}
-	
-	// 3. Search request headers (names and values)
-	for _, header := range entry.Request.Headers {
-		if strings.Contains(strings.ToLower(header.Name), searchText) ||
-		   strings.Contains(strings.ToLower(header.Value), searchText) {
-			return true
-		}
-	}
-	
-	// 4. Search response headers (names and values)
-	for _, header := range entry.Response.Headers {
-		if strings.Contains(strings.ToLower(header.Name), searchText) ||
-		   strings.Contains(strings.ToLower(header.Value), searchText) {
-			return true
-		}
-	}
-	
-	// 5. Search response status text
-	if strings.Contains(strings.ToLower(entry.Response.StatusText), searchText) {
-		return true
-	}
-	
-	// 6. Search response content type
-	if strings.Contains(strings.ToLower(entry.Response.Content.MimeType), searchText) {
-		return true
-	}
-	
-	// 7. Search request body (if present and not too large)
-	if entry.Request.PostData != nil && entry.Request.PostData.Text != "" {
-		// Limit body search to reasonable size for performance
-		bodyText := entry.Request.PostData.Text
-		if len(bodyText) <= 10000 { // 10KB limit for body search
-			if strings.Contains(strings.ToLower(bodyText), searchText) {
-				return true
-			}
-		}
+// Sources returns the distinct entry sources in the order they were loaded
+func (idx *EntryIndex) Sources() []string {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	result := make([]string, len(idx.sources))
+	copy(result, idx.sources)
+	return result
+}
+
+// Methods returns every indexed request method
+func (idx *EntryIndex) Methods() []string {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	result := make([]string, 0, len(idx.byMethod))
+	for method := range idx.byMethod {
+		result = append(result, method)
 	}
-	
-	// 8. Search response body (if present and not too large)
-	if entry.Response.Content.Text != "" {
-		// Decode and search response body
-		bodyText := DecodeBase64(entry.Response.Content.Text, entry.Response.Content.Encoding)
-		if len(bodyText) <= 10000 { // 10KB limit for body search
-			if strings.Contains(strings.ToLower(bodyText), searchText) {
-				return true
-			}
-		}
+	return result
+}
+
+// Statuses returns every indexed response status
+func (idx *EntryIndex) Statuses() []int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	result := make([]int, 0, len(idx.byStatus))
+	for status := range idx.byStatus {
+		result = append(result, status)
 	}
-	
-	// 9. Search cookies (names and values)
-	for _, cookie := range entry.Request.Cookies {
-		if strings.Contains(strings.ToLower(cookie.Name), searchText) ||
-		   strings.Contains(strings.ToLower(cookie.Value), searchText) {
-			return true
-		}
+	return result
+}
+
+// Hosts returns every indexed request host
+func (idx *EntryIndex) Hosts() []string {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
+	result := make([]string, 0, len(idx.byHost))
+	for host := range idx.byHost {
+		result = append(result, host)
 	}
+	return result
+}
+
+func (idx *EntryIndex) GetErrorIndices(entries []HAREntry) []int {
+	idx.mutex.RLock()
+	defer idx.mutex.RUnlock()
 	
-	for _, cookie := range entry.Response.Cookies {
-		if strings.Contains(strings.ToLower(cookie.Name), searchText) ||
-		   strings.Contains(strings.ToLower(cookie.Value), searchText) {
-			return true
+	var result []int
+	for status, indices := range idx.byStatus {
+		if status >= 400 || status == 0 {
+			result = append(result, indices...)
 		}
 	}
-	
-	return false
+	return result
 }
 
 // Use util.IntersectIndices for index intersection operations
@@ -201,6 +188,24 @@ type StreamingLoader struct {
 	index   *EntryIndex
 	mutex   sync.RWMutex
 	
+	// Members around the entries array, kept so saved files stay lossless
+	fileOrder   []string
+	fileMembers map[string]json.RawMessage
+	logOrder    []string
+	logMembers  map[string]json.RawMessage
+	pages       []HARPage
+	
+	// Set while loading several files: the current file's source name, and
+	// whether it is the first file (whose metadata is kept)
+	source  string
+	primary bool
+	merged  bool // more than one file was loaded
+	
+	// Set while following a file: entries already loaded that a re-read of
+	// the file skips, and the channel closed to stop following
+	skip int
+	stop chan struct{}
+	
 	onEntryAdded func(entry HAREntry, index int)
 	onComplete   func()
 	onError      func(error)
@@ -209,8 +214,11 @@ type StreamingLoader struct {
 
 func NewStreamingLoader() *StreamingLoader {
 	return &StreamingLoader{
-		entries: make([]HAREntry, 0),
-		index:   NewEntryIndex(),
+		entries:     make([]HAREntry, 0),
+		index:       NewEntryIndex(),
+		fileMembers: make(map[string]json.RawMessage),
+		logMembers:  make(map[string]json.RawMessage),
+		primary:     true,
 	}
 }
 
@@ -237,58 +245,72 @@ func (sl *StreamingLoader) GetEntryCount() int {
 	return len(sl.entries)
 }
 
-func (sl *StreamingLoader) LoadHARFileStreaming(filePath string) {
-	go func() {
-		file, err := os.Open(filePath)
-		if err != nil {
-			if sl.onError != nil {
-				sl.onError(err)
-			}
-			return
-		}
-		defer file.Close()
+// GetPages returns the pages listed in the log, if they have been read yet
+func (sl *StreamingLoader) GetPages() []HARPage {
+	sl.mutex.RLock()
+	defer sl.mutex.RUnlock()
+	return sl.pages
+}
 
-		decoder := json.NewDecoder(file)
-		
-		token, err := decoder.Token()
-		if err != nil {
-			if sl.onError != nil {
-				sl.onError(err)
-			}
-			return
+// GetHARFile returns the loaded entries together with the log metadata
+// (creator, browser, pages, comments and custom fields) read so far
+func (sl *StreamingLoader) GetHARFile() *HARFile {
+	sl.mutex.RLock()
+	defer sl.mutex.RUnlock()
+	
+	harFile := &HARFile{}
+	if len(sl.logOrder) > 0 {
+		if err := json.Unmarshal(assembleObject(sl.logOrder, sl.logMembers), &harFile.Log); err != nil {
+			harFile.Log = HARLog{}
 		}
-		
-		if delim, ok := token.(json.Delim); !ok || delim != '{' {
-			if sl.onError != nil {
-				sl.onError(fmt.Errorf("expected opening brace"))
-			}
-			return
+	}
+	if len(sl.fileOrder) > 0 {
+		var file HARFile
+		if err := json.Unmarshal(assembleObject(sl.fileOrder, sl.fileMembers), &file); err == nil {
+			file.Log = harFile.Log
+			harFile = &file
 		}
+	}
+	if sl.merged {
+		harFile.Log.Pages = sl.pages
+	}
+	harFile.Log.Entries = sl.entries
+	return harFile
+}
 
-		for decoder.More() {
-			token, err := decoder.Token()
-			if err != nil {
-				if sl.onError != nil {
-					sl.onError(err)
-				}
-				return
+// LoadHARFileStreaming parses a HAR in the background, reporting entries as
+// they arrive. filePath may be compressed (see OpenHARInput) or "-" for stdin
+func (sl *StreamingLoader) LoadHARFileStreaming(filePath string) {
+	sl.LoadHARFilesStreaming([]string{filePath})
+}
+
+// LoadHARFilesStreaming parses several HARs one after another into a single
+// list of entries. With more than one file each entry is tagged with its
+// source (see SourceNames) and page ids are prefixed with it to stay unique.
+// Log metadata such as the creator comes from the first file
+func (sl *StreamingLoader) LoadHARFilesStreaming(filePaths []string) {
+	go func() {
+		sources := SourceNames(filePaths)
+		sl.mutex.Lock()
+		sl.merged = len(filePaths) > 1
+		sl.mutex.Unlock()
+		for i, filePath := range filePaths {
+			sl.mutex.Lock()
+			sl.source = ""
+			if len(filePaths) > 1 {
+				sl.source = sources[i]
 			}
+			sl.primary = i == 0
+			sl.mutex.Unlock()
 			
-			if key, ok := token.(string); ok && key == "log" {
-				if err := sl.parseLog(decoder); err != nil {
-					if sl.onError != nil {
-						sl.onError(err)
-					}
-					return
-				}
-			} else {
-				var dummy interface{}
-				if err := decoder.Decode(&dummy); err != nil {
-					if sl.onError != nil {
-						sl.onError(err)
+			if err := sl.parseFile(filePath); err != nil {
+				if sl.onError != nil {
+					if len(filePaths) > 1 {
+						err = fmt.Errorf("%s: %w", sources[i], err)
 					}
-					return
+					sl.onError(err)
 				}
+				return
 			}
 		}
 
@@ -298,6 +320,138 @@ func (sl *StreamingLoader) LoadHARFileStreaming(filePath string) {
 	}()
 }
 
+// StartLive prepares the loader for entries added one at a time with
+// AddEntry, as when capturing traffic, saving them as a HAR 1.2 log
+func (sl *StreamingLoader) StartLive(creator HARCreator) {
+	raw, err := json.Marshal(creator)
+	if err != nil {
+		raw = json.RawMessage(`{"name":"har-tui","version":""}`)
+	}
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+	sl.logOrder = []string{"version", "creator", "entries"}
+	sl.logMembers = map[string]json.RawMessage{
+		"version": json.RawMessage(`"1.2"`),
+		"creator": raw,
+		"entries": json.RawMessage("[]"),
+	}
+}
+
+// AddEntry appends a captured entry, reporting it like a streamed one, and
+// returns its index
+func (sl *StreamingLoader) AddEntry(entry HAREntry) int {
+	index := sl.appendEntry(entry)
+	if sl.onProgress != nil {
+		sl.onProgress(index + 1)
+	}
+	return index
+}
+
+// AppendEntry adds an entry made after loading, such as a replay, to the
+// entries and the index as it is, and returns its index
+func (sl *StreamingLoader) AppendEntry(entry HAREntry) int {
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+	sl.entries = append(sl.entries, entry)
+	index := len(sl.entries) - 1
+	sl.index.AddEntry(entry, index)
+	return index
+}
+
+// appendEntry adds an entry read from the current file, tagging it with the
+// file's source, and reports it
+func (sl *StreamingLoader) appendEntry(entry HAREntry) int {
+	sl.mutex.Lock()
+	if sl.source != "" {
+		// Entries from a previously merged file keep their original source
+		if entry.Source == "" {
+			entry.Source = sl.source
+		}
+		if entry.Pageref != "" {
+			entry.Pageref = sl.source + "/" + entry.Pageref
+		}
+	}
+	sl.entries = append(sl.entries, entry)
+	index := len(sl.entries) - 1
+	sl.index.AddEntry(entry, index)
+	sl.mutex.Unlock()
+	
+	if sl.onEntryAdded != nil {
+		sl.onEntryAdded(entry, index)
+	}
+	return index
+}
+
+// addParsed adds a parsed entry unless a followed file is being re-read and
+// the entry was loaded before. It returns false for skipped entries
+func (sl *StreamingLoader) addParsed(entry HAREntry) bool {
+	sl.mutex.Lock()
+	skipped := sl.skip > 0
+	if skipped {
+		sl.skip--
+	}
+	sl.mutex.Unlock()
+	if skipped {
+		return false
+	}
+	sl.appendEntry(entry)
+	return true
+}
+
+// parseFile streams one HAR file into the loader
+func (sl *StreamingLoader) parseFile(filePath string) error {
+	file, err := OpenHARInput(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return sl.parse(file)
+}
+
+// parse reads a HAR document, or HAR entries one per line (NDJSON)
+func (sl *StreamingLoader) parse(r io.Reader) error {
+	ndjson, reader, err := isNDJSON(bufio.NewReader(r))
+	if err != nil {
+		return err
+	}
+	if ndjson {
+		return sl.parseNDJSON(reader)
+	}
+	
+	decoder := json.NewDecoder(reader)
+	
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	
+	if delim, ok := token.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("expected opening brace")
+	}
+
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		
+		key, _ := token.(string)
+		if key == "log" {
+			sl.recordMember(&sl.fileOrder, sl.fileMembers, key, json.RawMessage("{}"))
+			if err := sl.parseLog(decoder); err != nil {
+				return err
+			}
+		} else {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				return err
+			}
+			sl.recordMember(&sl.fileOrder, sl.fileMembers, key, raw)
+		}
+	}
+	return nil
+}
+
 func (sl *StreamingLoader) parseLog(decoder *json.Decoder) error {
 	token, err := decoder.Token()
 	if err != nil {
@@ -314,19 +468,59 @@ func (sl *StreamingLoader) parseLog(decoder *json.Decoder) error {
 			return err
 		}
 		
-		if key, ok := token.(string); ok && key == "entries" {
+		key, _ := token.(string)
+		if key == "entries" {
+			sl.recordMember(&sl.logOrder, sl.logMembers, key, json.RawMessage("[]"))
 			if err := sl.parseEntries(decoder); err != nil {
 				return err
 			}
 		} else {
-			var dummy interface{}
-			if err := decoder.Decode(&dummy); err != nil {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
 				return err
 			}
+			sl.recordMember(&sl.logOrder, sl.logMembers, key, raw)
+			
+			// Pages usually precede the entries, so the UI can group them while loading
+			if key == "pages" {
+				var pages []HARPage
+				if err := json.Unmarshal(raw, &pages); err != nil {
+					return err
+				}
+				sl.mutex.Lock()
+				if sl.source != "" {
+					for i := range pages {
+						pages[i].ID = sl.source + "/" + pages[i].ID
+					}
+				}
+				if sl.merged {
+					sl.pages = append(sl.pages, pages...)
+				} else {
+					// A followed file read again lists its pages again
+					sl.pages = pages
+				}
+				sl.mutex.Unlock()
+			}
 		}
 	}
 
-	return nil
+	// Consume the closing brace so members after the log are still read
+	_, err = decoder.Token()
+	return err
+}
+
+// recordMember remembers a non-entry member of the file or log object.
+// Only the first file's members are kept when loading several files
+func (sl *StreamingLoader) recordMember(order *[]string, members map[string]json.RawMessage, key string, raw json.RawMessage) {
+	sl.mutex.Lock()
+	defer sl.mutex.Unlock()
+	if !sl.primary {
+		return
+	}
+	if _, seen := members[key]; !seen {
+		*order = append(*order, key)
+	}
+	members[key] = raw
 }
 
 func (sl *StreamingLoader) parseEntries(decoder *json.Decoder) error {
@@ -344,30 +538,26 @@ func (sl *StreamingLoader) parseEntries(decoder *json.Decoder) error {
 	
 	for decoder.More() {
 		var entry HAREntry
-		if err := decoder.Decode(&entry); err != nil {
+		if err := readJSON(decoder, &entry); err != nil {
 			return err
 		}
-		
-		sl.mutex.Lock()
-		sl.entries = append(sl.entries, entry)
-		index := len(sl.entries) - 1
-		sl.index.AddEntry(entry, index)
-		sl.mutex.Unlock()
-		
-		if sl.onEntryAdded != nil {
-			sl.onEntryAdded(entry, index)
+		if !sl.addParsed(entry) {
+			continue
 		}
 		
 		entryCount++
 		
+		// Progress counts every entry loaded so far, across files
 		if entryCount%batchSize == 0 && sl.onProgress != nil {
-			sl.onProgress(entryCount)
+			sl.onProgress(sl.GetEntryCount())
 		}
 	}
 
 	if sl.onProgress != nil {
-		sl.onProgress(entryCount)
+		sl.onProgress(sl.GetEntryCount())
 	}
 
-	return nil
+	// Consume the closing bracket so members after the entries are still read
+	_, err = decoder.Token()
+	return err
 }
@@ -0,0 +1,208 @@
+package har
+
+import (
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// TextIndex is a trigram index for substring search over large texts such as
+// decoded bodies. Lookups return a superset of the matching ids, so callers
+// still confirm each candidate with a real substring match. Texts are indexed
+// lowercased, so the candidates hold for case-sensitive searches too.
+//
+// Texts are added by a background worker; ids that haven't been indexed yet
+// are reported by MayContain so searches stay complete while loading.
+type TextIndex struct {
+	mutex    sync.RWMutex
+	postings map[uint32][]int
+	indexed  map[int]bool
+
+	queueMutex sync.Mutex
+	queue      []textJob
+	working    bool
+	pending    sync.WaitGroup
+}
+
+// textJob is a text waiting to be indexed; load runs on the worker
+type textJob struct {
+	id   int
+	load func() (string, bool)
+}
+
+// NewTextIndex creates an empty trigram index
+func NewTextIndex() *TextIndex {
+	return &TextIndex{
+		postings: make(map[uint32][]int),
+		indexed:  make(map[int]bool),
+	}
+}
+
+// AddAsync queues the text returned by load to be indexed under id. When
+// load returns false id is left unindexed, so it is always a candidate
+func (ti *TextIndex) AddAsync(id int, load func() (string, bool)) {
+	ti.pending.Add(1)
+
+	ti.queueMutex.Lock()
+	ti.queue = append(ti.queue, textJob{id: id, load: load})
+	startWorker := !ti.working
+	ti.working = true
+	ti.queueMutex.Unlock()
+
+	if startWorker {
+		go ti.work()
+	}
+}
+
+// work drains the queue, then exits until the next AddAsync
+func (ti *TextIndex) work() {
+	for {
+		ti.queueMutex.Lock()
+		jobs := ti.queue
+		ti.queue = nil
+		if len(jobs) == 0 {
+			ti.working = false
+			ti.queueMutex.Unlock()
+			return
+		}
+		ti.queueMutex.Unlock()
+
+		for _, job := range jobs {
+			if text, ok := job.load(); ok {
+				ti.Add(job.id, text)
+			}
+			ti.pending.Done()
+		}
+	}
+}
+
+// Wait blocks until every queued text has been indexed
+func (ti *TextIndex) Wait() {
+	ti.pending.Wait()
+}
+
+// Add indexes text under id synchronously
+func (ti *TextIndex) Add(id int, text string) {
+	grams := trigrams(strings.ToLower(text))
+
+	ti.mutex.Lock()
+	defer ti.mutex.Unlock()
+	for gram := range grams {
+		ti.postings[gram] = append(ti.postings[gram], id)
+	}
+	ti.indexed[id] = true
+}
+
+// Lookup returns the ids whose text may contain query. ok is false when the
+// query is too short to narrow anything down (under three bytes)
+func (ti *TextIndex) Lookup(query string) (ids map[int]bool, ok bool) {
+	grams := trigrams(strings.ToLower(query))
+	if len(grams) == 0 {
+		return nil, false
+	}
+
+	ti.mutex.RLock()
+	defer ti.mutex.RUnlock()
+
+	// Start from the rarest trigram and intersect the rest into it
+	var rarest []int
+	first := true
+	for gram := range grams {
+		if posting := ti.postings[gram]; first || len(posting) < len(rarest) {
+			rarest = posting
+			first = false
+		}
+	}
+	ids = make(map[int]bool, len(rarest))
+	for _, id := range rarest {
+		ids[id] = true
+	}
+	for gram := range grams {
+		if len(ids) == 0 {
+			break
+		}
+		inPosting := make(map[int]bool, len(ti.postings[gram]))
+		for _, id := range ti.postings[gram] {
+			inPosting[id] = true
+		}
+		for id := range ids {
+			if !inPosting[id] {
+				delete(ids, id)
+			}
+		}
+	}
+	return ids, true
+}
+
+// IsIndexed reports whether id's text has been indexed yet
+func (ti *TextIndex) IsIndexed(id int) bool {
+	ti.mutex.RLock()
+	defer ti.mutex.RUnlock()
+	return ti.indexed[id]
+}
+
+// trigrams returns the distinct three-byte sequences of text
+func trigrams(text string) map[uint32]struct{} {
+	if len(text) < 3 {
+		return nil
+	}
+	grams := make(map[uint32]struct{})
+	for i := 0; i+3 <= len(text); i++ {
+		grams[uint32(text[i])<<16|uint32(text[i+1])<<8|uint32(text[i+2])] = struct{}{}
+	}
+	return grams
+}
+
+// BodyFilter narrows a body search to the entries whose bodies may contain a term
+type BodyFilter struct {
+	bodies     *TextIndex
+	candidates map[int]bool
+}
+
+// MayContain reports whether entry id's bodies could contain the term. It is
+// true for entries still waiting on the background indexer
+func (bf *BodyFilter) MayContain(id int) bool {
+	if bf == nil {
+		return true
+	}
+	return bf.candidates[id] || !bf.bodies.IsIndexed(id)
+}
+
+// textMimeTypes are the content types whose bodies are always text
+var textMimeTypes = []string{"text/", "json", "xml", "javascript", "ecmascript", "x-www-form-urlencoded", "graphql", "csv", "yaml"}
+
+// isTextBody reports whether a body is worth indexing: its content type is
+// textual, or it is valid UTF-8 without NUL bytes. Images, fonts and other
+// binary bodies would only fill the index with noise
+func isTextBody(body, mimeType string) bool {
+	mimeType = strings.ToLower(mimeType)
+	for _, textType := range textMimeTypes {
+		if strings.Contains(mimeType, textType) {
+			return true
+		}
+	}
+	return utf8.ValidString(body) && !strings.ContainsRune(body, 0)
+}
+
+// entryBodies returns the request and decoded response bodies of an entry as
+// one text. ok is false when either body is binary, leaving the entry for
+// searches to check directly
+func entryBodies(entry HAREntry) (text string, ok bool) {
+	var body strings.Builder
+	if postData := entry.Request.PostData; postData != nil {
+		if !isTextBody(postData.Text, postData.MimeType) {
+			return "", false
+		}
+		body.WriteString(postData.Text)
+	}
+	// Keep trigrams from spanning the two bodies
+	body.WriteByte(0)
+	if content := entry.Response.Content; content.Text != "" {
+		decoded := DecodeBase64(content.Text, content.Encoding)
+		if !isTextBody(decoded, content.MimeType) {
+			return "", false
+		}
+		body.WriteString(decoded)
+	}
+	return body.String(), true
+}
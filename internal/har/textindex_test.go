@@ -0,0 +1,80 @@
+package har
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestTextIndexLookup(t *testing.T) {
+	ti := NewTextIndex()
+	ti.Add(0, "The quick brown fox")
+	ti.Add(1, "jumps over the LAZY dog")
+	ti.Add(2, "lazy afternoon")
+
+	tests := []struct {
+		query    string
+		expected []int
+	}{
+		{"quick", []int{0}},
+		{"lazy", []int{1, 2}},
+		{"THE", []int{0, 1}},
+		{"missing", nil},
+	}
+	for _, tt := range tests {
+		ids, ok := ti.Lookup(tt.query)
+		if !ok {
+			t.Fatalf("Lookup(%q) should use the index", tt.query)
+		}
+		if len(ids) != len(tt.expected) {
+			t.Errorf("Lookup(%q): expected %v, got %v", tt.query, tt.expected, ids)
+		}
+		for _, id := range tt.expected {
+			if !ids[id] {
+				t.Errorf("Lookup(%q): missing %d in %v", tt.query, id, ids)
+			}
+		}
+	}
+
+	if _, ok := ti.Lookup("ab"); ok {
+		t.Error("expected queries under three bytes to skip the index")
+	}
+}
+
+func TestEntryIndexBodyFilter(t *testing.T) {
+	largeBody := strings.Repeat(`{"item":"filler"},`, 5000) + `{"needle":"deep-token"}`
+	entries := []HAREntry{
+		{Response: HARResponse{Content: HARContent{Text: largeBody}}},
+		{Response: HARResponse{Content: HARContent{
+			Text:     base64.StdEncoding.EncodeToString([]byte("encoded deep-token")),
+			Encoding: "base64",
+		}}},
+		{Request: HARRequest{PostData: &HARPostData{Text: "nothing here"}}},
+		{Response: HARResponse{Content: HARContent{
+			MimeType: "image/png",
+			Text:     base64.StdEncoding.EncodeToString([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\xff\xfe")),
+			Encoding: "base64",
+		}}},
+	}
+
+	index := NewEntryIndex()
+	for i, entry := range entries {
+		index.AddEntry(entry, i)
+	}
+
+	// Let the background worker finish so unindexed entries don't count as candidates
+	index.WaitForBodies()
+
+	filter := index.BodyFilter("DEEP-TOKEN")
+	if !filter.MayContain(0) || !filter.MayContain(1) || filter.MayContain(2) {
+		t.Errorf("unexpected candidates: %v", filter.candidates)
+	}
+
+	if index.bodies.IsIndexed(3) || !filter.MayContain(3) {
+		t.Error("binary bodies should be left unindexed")
+	}
+
+	if index.BodyFilter("de") != nil {
+		t.Error("expected no body filter for a two byte query")
+	}
+}
@@ -1,9 +1,20 @@
 package har
 
+import "encoding/json"
+
+// Every HAR object below carries a Custom map and the member order it was
+// loaded with. Custom keeps anything the struct does not model (mostly
+// "_"-prefixed vendor fields), and the order lets SaveFilteredHAR write
+// entries back out the way they came in. See json.go for the codec.
+
 // HARHeader represents an HTTP header in a HAR file
 type HARHeader struct {
-	Name  string `json:"name"`
-	Value string `json:"value"`
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Comment string `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HARCookie represents an HTTP cookie in a HAR file
@@ -12,32 +23,76 @@ type HARCookie struct {
 	Value    string `json:"value"`
 	Domain   string `json:"domain"`
 	Path     string `json:"path"`
+	Expires  string `json:"expires,omitempty"`
 	Secure   bool   `json:"secure"`
 	HTTPOnly bool   `json:"httpOnly"`
+	Comment  string `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
+}
+
+// HARQueryParam represents a parsed query string parameter in a HAR file
+type HARQueryParam struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Comment string `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
+}
+
+// HARParam represents a posted parameter (form field or uploaded file) in a HAR file
+type HARParam struct {
+	Name        string `json:"name"`
+	Value       string `json:"value,omitempty"`
+	FileName    string `json:"fileName,omitempty"`
+	ContentType string `json:"contentType,omitempty"`
+	Comment     string `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HARPostData represents POST data in a HAR file
 type HARPostData struct {
-	MimeType string `json:"mimeType"`
-	Text     string `json:"text"`
+	MimeType string     `json:"mimeType"`
+	Params   []HARParam `json:"params,omitempty"`
+	Text     string     `json:"text"`
+	Comment  string     `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HARRequest represents an HTTP request in a HAR file
 type HARRequest struct {
-	Method      string        `json:"method"`
-	URL         string        `json:"url"`
-	HTTPVersion string        `json:"httpVersion"`
-	Headers     []HARHeader   `json:"headers"`
-	Cookies     []HARCookie   `json:"cookies"`
-	PostData    *HARPostData  `json:"postData"`
+	Method      string          `json:"method"`
+	URL         string          `json:"url"`
+	HTTPVersion string          `json:"httpVersion"`
+	Headers     []HARHeader     `json:"headers"`
+	Cookies     []HARCookie     `json:"cookies"`
+	QueryString []HARQueryParam `json:"queryString"`
+	PostData    *HARPostData    `json:"postData,omitempty"`
+	HeadersSize int             `json:"headersSize"`
+	BodySize    int             `json:"bodySize"`
+	Comment     string          `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HARContent represents response content in a HAR file
 type HARContent struct {
-	Size     int    `json:"size"`
-	MimeType string `json:"mimeType"`
-	Text     string `json:"text"`
-	Encoding string `json:"encoding"`
+	Size        int    `json:"size"`
+	Compression int    `json:"compression,omitempty"`
+	MimeType    string `json:"mimeType"`
+	Text        string `json:"text"`
+	Encoding    string `json:"encoding"`
+	Comment     string `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HARResponse represents an HTTP response in a HAR file
@@ -48,6 +103,35 @@ type HARResponse struct {
 	Headers     []HARHeader `json:"headers"`
 	Cookies     []HARCookie `json:"cookies"`
 	Content     HARContent  `json:"content"`
+	RedirectURL string      `json:"redirectURL"`
+	HeadersSize int         `json:"headersSize"`
+	BodySize    int         `json:"bodySize"`
+	Comment     string      `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
+}
+
+// HARCacheEntry represents the state of a cache entry before or after a request
+type HARCacheEntry struct {
+	Expires    string `json:"expires,omitempty"`
+	LastAccess string `json:"lastAccess"`
+	ETag       string `json:"eTag"`
+	HitCount   int    `json:"hitCount"`
+	Comment    string `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
+}
+
+// HARCache represents cache usage information for an entry
+type HARCache struct {
+	BeforeRequest *HARCacheEntry `json:"beforeRequest,omitempty"`
+	AfterRequest  *HARCacheEntry `json:"afterRequest,omitempty"`
+	Comment       string         `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HARTimings represents timing information in a HAR file
@@ -59,28 +143,88 @@ type HARTimings struct {
 	Wait    float64 `json:"wait"`
 	Receive float64 `json:"receive"`
 	SSL     float64 `json:"ssl"`
+	Comment string  `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HAREntry represents a single HTTP transaction in a HAR file
 type HAREntry struct {
+	Pageref         string      `json:"pageref,omitempty"`
 	StartedDateTime string      `json:"startedDateTime"`
 	Time            float64     `json:"time"`
 	Request         HARRequest  `json:"request"`
 	Response        HARResponse `json:"response"`
+	Cache           HARCache    `json:"cache"`
 	Timings         HARTimings  `json:"timings"`
-	
+	ServerIPAddress string      `json:"serverIPAddress,omitempty"`
+	Connection      string      `json:"connection,omitempty"`
+	Comment         string      `json:"comment,omitempty"`
+
 	// Chrome-specific fields (optional)
 	ResourceType string `json:"_resourceType,omitempty"`
 	Priority     string `json:"_priority,omitempty"`
+
+	// Source file the entry was loaded from when several HARs are opened together
+	Source string `json:"_source,omitempty"`
+
+	// Index of the entry this one was replayed from, nil for captured entries
+	ReplayOf *int `json:"_replayOf,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
+}
+
+// HARPageTimings represents page load milestones, in ms relative to the page start
+type HARPageTimings struct {
+	OnContentLoad float64 `json:"onContentLoad,omitempty"`
+	OnLoad        float64 `json:"onLoad,omitempty"`
+	Comment       string  `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
+}
+
+// HARPage represents a page (navigation) that entries can belong to
+type HARPage struct {
+	StartedDateTime string         `json:"startedDateTime"`
+	ID              string         `json:"id"`
+	Title           string         `json:"title"`
+	PageTimings     HARPageTimings `json:"pageTimings"`
+	Comment         string         `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
+}
+
+// HARCreator represents the creator or browser application of a HAR file
+type HARCreator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+	Comment string `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HARLog represents the log object in a HAR file
 type HARLog struct {
-	Version string     `json:"version"`
-	Entries []HAREntry `json:"entries"`
+	Version string      `json:"version"`
+	Creator HARCreator  `json:"creator"`
+	Browser *HARCreator `json:"browser,omitempty"`
+	Pages   []HARPage   `json:"pages,omitempty"`
+	Entries []HAREntry  `json:"entries"`
+	Comment string      `json:"comment,omitempty"`
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
 }
 
 // HARFile represents the root HAR file structure
 type HARFile struct {
 	Log HARLog `json:"log"`
-}
\ No newline at end of file
+
+	Custom map[string]json.RawMessage `json:"-"`
+	order  []string
+}
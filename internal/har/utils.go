@@ -1,8 +1,11 @@
 package har
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/base64"
 	"encoding/json"
+	"io"
 	"net"
 	"net/url"
 	"os"
@@ -10,13 +13,25 @@ import (
 	"strings"
 )
 
-// LoadHARFile loads and parses a HAR file from the given path
+// LoadHARFile loads and parses a HAR file from the given path, which may be
+// compressed (see OpenHARInput) or "-" for stdin. HAR entries written one
+// per line (NDJSON) are loaded as a HAR 1.2 log
 func LoadHARFile(filePath string) (*HARFile, error) {
-	data, err := os.ReadFile(filePath)
+	input, err := OpenHARInput(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer input.Close()
+	
+	data, err := io.ReadAll(input)
 	if err != nil {
 		return nil, err
 	}
 
+	if ndjson, _, _ := isNDJSON(bufio.NewReader(bytes.NewReader(data))); ndjson {
+		return loadNDJSON(data)
+	}
+
 	var harFile HARFile
 	if err := json.Unmarshal(data, &harFile); err != nil {
 		return nil, err
@@ -199,6 +214,22 @@ func GenerateDescriptiveFilename(entry HAREntry, suffix string) string {
 	return filename
 }
 
+// HeaderValue returns the value of the first header with a name, ignoring case
+func HeaderValue(headers []HARHeader, name string) string {
+	for _, header := range headers {
+		if strings.EqualFold(header.Name, name) {
+			return header.Value
+		}
+	}
+	return ""
+}
+
+// IsLocal reports whether a host is this machine, where plain HTTP is fine
+// and browsers treat pages as secure
+func IsLocal(host string) bool {
+	return host == "localhost" || host == "::1" || strings.HasPrefix(host, "127.") || strings.HasSuffix(host, ".localhost")
+}
+
 // IsCORSError determines if a request failed due to CORS issues
 func IsCORSError(entry HAREntry) bool {
 	// Only check status 0 (blocked) requests
@@ -278,27 +309,50 @@ func IsCORSError(entry HAREntry) bool {
 
 // SaveFilteredHAR saves filtered HAR entries to a new file
 func SaveFilteredHAR(originalHAR *HARFile, filteredIndices []int, outputPath string) error {
-	// Create a new HAR file with only filtered entries
-	filteredHAR := &HARFile{
-		Log: HARLog{
-			Version: originalHAR.Log.Version,
-			Entries: make([]HAREntry, 0, len(filteredIndices)),
-		},
+	var data bytes.Buffer
+	if err := WriteFilteredHAR(&data, originalHAR, filteredIndices); err != nil {
+		return err
 	}
 	
+	// Write to file
+	return os.WriteFile(outputPath, data.Bytes(), 0644)
+}
+
+// WriteFilteredHAR writes filtered HAR entries as an indented HAR document
+func WriteFilteredHAR(w io.Writer, originalHAR *HARFile, filteredIndices []int) error {
+	// Keep everything from the original log except the entries we filtered out
+	filteredHAR := *originalHAR
+	filteredHAR.Log.Entries = make([]HAREntry, 0, len(filteredIndices))
+	
 	// Copy only the filtered entries
+	referencedPages := make(map[string]bool)
 	for _, idx := range filteredIndices {
 		if idx >= 0 && idx < len(originalHAR.Log.Entries) {
-			filteredHAR.Log.Entries = append(filteredHAR.Log.Entries, originalHAR.Log.Entries[idx])
+			entry := originalHAR.Log.Entries[idx]
+			filteredHAR.Log.Entries = append(filteredHAR.Log.Entries, entry)
+			if entry.Pageref != "" {
+				referencedPages[entry.Pageref] = true
+			}
 		}
 	}
 	
-	// Marshal to JSON with proper formatting
-	data, err := json.MarshalIndent(filteredHAR, "", "  ")
-	if err != nil {
-		return err
+	// Drop pages whose entries were all filtered out
+	if originalHAR.Log.Pages != nil {
+		originalPages := make(map[string]bool)
+		for _, entry := range originalHAR.Log.Entries {
+			originalPages[entry.Pageref] = true
+		}
+		filteredHAR.Log.Pages = make([]HARPage, 0, len(originalHAR.Log.Pages))
+		for _, page := range originalHAR.Log.Pages {
+			if referencedPages[page.ID] || !originalPages[page.ID] {
+				filteredHAR.Log.Pages = append(filteredHAR.Log.Pages, page)
+			}
+		}
 	}
 	
-	// Write to file
-	return os.WriteFile(outputPath, data, 0644)
-}
\ No newline at end of file
+	// Marshal to JSON with proper formatting, leaving <, > and & in bodies as-is
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(filteredHAR)
+}
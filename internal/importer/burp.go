@@ -0,0 +1,135 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// burpTimeLayout is how Burp writes times, the way Java's Date prints them
+const burpTimeLayout = "Mon Jan 02 15:04:05 MST 2006"
+
+// burpItems is a Burp Suite XML export ("Save items")
+type burpItems struct {
+	Items []burpItem `xml:"item"`
+}
+
+type burpItem struct {
+	Time     string      `xml:"time"`
+	URL      string      `xml:"url"`
+	Host     burpHost    `xml:"host"`
+	Method   string      `xml:"method"`
+	Request  burpMessage `xml:"request"`
+	Response burpMessage `xml:"response"`
+	Comment  string      `xml:"comment"`
+}
+
+type burpHost struct {
+	Name string `xml:",chardata"`
+	IP   string `xml:"ip,attr"`
+}
+
+type burpMessage struct {
+	Base64 bool   `xml:"base64,attr"`
+	Data   string `xml:",chardata"`
+}
+
+// isBurp tells a Burp Suite XML export by its root element, an <items
+// burpVersion="..."> after nothing but the XML prolog
+func isBurp(head []byte) bool {
+	head = bytes.TrimLeft(head, "\ufeff \t\r\n")
+	if !bytes.HasPrefix(head, []byte("<?xml")) && !bytes.HasPrefix(head, []byte("<!DOCTYPE items")) && !bytes.HasPrefix(head, []byte("<items")) {
+		return false
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(head))
+	for {
+		token, err := decoder.RawToken()
+		if err != nil {
+			return false
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			if token.Name.Local != "items" {
+				return false
+			}
+			for _, attr := range token.Attr {
+				if attr.Name.Local == "burpVersion" {
+					return true
+				}
+			}
+			return false
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				return false
+			}
+		case xml.EndElement:
+			return false
+		}
+	}
+}
+
+// convertBurp converts the items of a Burp Suite XML export. Burp records
+// neither timings nor the time taken, only when each request was sent
+func convertBurp(r io.Reader) (*har.HARFile, error) {
+	decoder := xml.NewDecoder(r)
+	decoder.Strict = false
+	var items burpItems
+	if err := decoder.Decode(&items); err != nil {
+		return nil, err
+	}
+
+	var entries []har.HAREntry
+	for i, item := range items.Items {
+		if strings.TrimSpace(item.Method) == "CONNECT" {
+			continue
+		}
+		rawRequest, err := item.Request.bytes()
+		if err != nil {
+			return nil, fmt.Errorf("item %d: request: %w", i+1, err)
+		}
+		rawResponse, err := item.Response.bytes()
+		if err != nil {
+			return nil, fmt.Errorf("item %d: response: %w", i+1, err)
+		}
+
+		request := parseRawMessage(rawRequest).request(strings.TrimSpace(item.URL))
+		request.HTTPVersion = burpVersion(request.HTTPVersion)
+		response := failedResponse("no response")
+		if len(rawResponse) > 0 {
+			response = parseRawMessage(rawResponse).response()
+			response.HTTPVersion = burpVersion(response.HTTPVersion)
+		}
+
+		started, _ := time.Parse(burpTimeLayout, strings.TrimSpace(item.Time))
+		entry := newEntry(started, request, response, har.HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1})
+		if ip := net.ParseIP(item.Host.IP); ip != nil {
+			entry.ServerIPAddress = ip.String()
+		}
+		entry.Comment = strings.TrimSpace(item.Comment)
+		entries = append(entries, entry)
+	}
+	return newLog("Burp Suite", entries), nil
+}
+
+// bytes returns the raw message, decoding base64
+func (m burpMessage) bytes() ([]byte, error) {
+	if !m.Base64 {
+		return []byte(m.Data), nil
+	}
+	return base64.StdEncoding.DecodeString(strings.TrimSpace(m.Data))
+}
+
+// burpVersion spells the HTTP/2 Burp writes into messages the way HAR does
+func burpVersion(version string) string {
+	if version == "HTTP/2" {
+		return "HTTP/2.0"
+	}
+	return version
+}
@@ -0,0 +1,202 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// charlesSession is one exchange of a Charles JSON session (.chlsj)
+type charlesSession struct {
+	Method          string           `json:"method"`
+	ProtocolVersion string           `json:"protocolVersion"`
+	Scheme          string           `json:"scheme"`
+	Host            string           `json:"host"`
+	ActualPort      int              `json:"actualPort"`
+	Path            string           `json:"path"`
+	Query           *string          `json:"query"`
+	Tunnel          bool             `json:"tunnel"`
+	RemoteAddress   string           `json:"remoteAddress"`
+	ErrorMessage    string           `json:"errorMessage"`
+	Times           charlesTimes     `json:"times"`
+	Durations       charlesDurations `json:"durations"`
+	Request         charlesMessage   `json:"request"`
+	Response        *charlesMessage  `json:"response"`
+}
+
+type charlesTimes struct {
+	Start string `json:"start"`
+}
+
+// charlesDurations are the phases of an exchange in ms, null when they
+// didn't happen
+type charlesDurations struct {
+	DNS      *float64 `json:"dns"`
+	Connect  *float64 `json:"connect"`
+	SSL      *float64 `json:"ssl"`
+	Request  *float64 `json:"request"`
+	Latency  *float64 `json:"latency"`
+	Response *float64 `json:"response"`
+}
+
+type charlesMessage struct {
+	Status          int    `json:"status"`
+	ContentEncoding string `json:"contentEncoding"`
+	Sizes           struct {
+		Body *int `json:"body"`
+	} `json:"sizes"`
+	Header struct {
+		FirstLine string `json:"firstLine"`
+		Headers   []struct {
+			Name  string `json:"name"`
+			Value string `json:"value"`
+		} `json:"headers"`
+	} `json:"header"`
+	Body *struct {
+		Text    *string `json:"text"`
+		Encoded string  `json:"encoded"`
+		Decoded bool    `json:"decoded"`
+	} `json:"body"`
+}
+
+// isCharles tells a Charles JSON session, an array of exchanges, by the
+// fields only it has
+func isCharles(head []byte) bool {
+	head = bytes.TrimLeft(head, " \t\r\n")
+	return bytes.HasPrefix(head, []byte("[")) &&
+		(bytes.Contains(head, []byte(`"actualPort"`)) || bytes.Contains(head, []byte(`"protocolVersion"`)))
+}
+
+// convertCharles converts the exchanges of a Charles JSON session. Tunnels
+// Charles didn't decrypt are skipped
+func convertCharles(r io.Reader) (*har.HARFile, error) {
+	var sessions []charlesSession
+	if err := json.NewDecoder(r).Decode(&sessions); err != nil {
+		return nil, err
+	}
+
+	var entries []har.HAREntry
+	for i, session := range sessions {
+		if session.Tunnel || session.Method == "CONNECT" {
+			continue
+		}
+		requestHeaders := session.Request.headers()
+		requestBody, err := session.Request.body()
+		if err != nil {
+			return nil, fmt.Errorf("session %d: request: %w", i+1, err)
+		}
+		request := newRequest(session.Method, session.url(), session.ProtocolVersion, requestHeaders, requestBody)
+
+		response := failedResponse("no response")
+		if session.ErrorMessage != "" {
+			response = failedResponse(session.ErrorMessage)
+		}
+		if session.Response != nil && session.Response.Status != 0 {
+			content, err := session.Response.body()
+			if err != nil {
+				return nil, fmt.Errorf("session %d: response: %w", i+1, err)
+			}
+			bodySize := len(content)
+			if session.Response.Sizes.Body != nil {
+				bodySize = *session.Response.Sizes.Body
+			}
+			version, _, _ := strings.Cut(session.Response.Header.FirstLine, " ")
+			if version == "" {
+				version = session.ProtocolVersion
+			}
+			response = newResponse(session.Response.Status, session.Response.statusText(), version, session.Response.headers(), content, bodySize)
+		}
+
+		durations := session.Durations
+		timings := har.HARTimings{
+			Blocked: -1,
+			DNS:     optionalPhase(durations.DNS),
+			Connect: optionalPhase(durations.Connect),
+			SSL:     optionalPhase(durations.SSL),
+			Send:    phase(optionalPhase(durations.Request)),
+			Wait:    phase(optionalPhase(durations.Latency)),
+			Receive: phase(optionalPhase(durations.Response)),
+		}
+		// HAR counts the TLS handshake as part of connecting
+		if timings.Connect >= 0 && timings.SSL > 0 {
+			timings.Connect += timings.SSL
+		}
+
+		started, _ := har.ParseHARDateTime(session.Times.Start)
+		entry := newEntry(started, request, response, timings)
+		// Charles writes the remote address as host/ip
+		if _, ip, ok := strings.Cut(session.RemoteAddress, "/"); ok && net.ParseIP(ip) != nil {
+			entry.ServerIPAddress = ip
+		}
+		entries = append(entries, entry)
+	}
+	return newLog("Charles", entries), nil
+}
+
+// url rebuilds the URL of the exchange, leaving default ports out
+func (s charlesSession) url() string {
+	host := s.Host
+	if s.ActualPort != 0 && !(s.Scheme == "http" && s.ActualPort == 80) && !(s.Scheme == "https" && s.ActualPort == 443) {
+		host = net.JoinHostPort(s.Host, strconv.Itoa(s.ActualPort))
+	}
+	u := s.Scheme + "://" + host + s.Path
+	if s.Query != nil && *s.Query != "" {
+		u += "?" + *s.Query
+	}
+	return u
+}
+
+// headers returns the headers of the message in the order they were sent
+func (m charlesMessage) headers() []har.HARHeader {
+	headers := []har.HARHeader{}
+	for _, header := range m.Header.Headers {
+		headers = append(headers, har.HARHeader{Name: header.Name, Value: header.Value})
+	}
+	return headers
+}
+
+// body returns the body without any Content-Encoding, which Charles has
+// usually removed already
+func (m charlesMessage) body() ([]byte, error) {
+	if m.Body == nil {
+		return nil, nil
+	}
+	var body []byte
+	if m.Body.Text != nil {
+		body = []byte(*m.Body.Text)
+	} else if m.Body.Encoded != "" {
+		decoded, err := base64.StdEncoding.DecodeString(m.Body.Encoded)
+		if err != nil {
+			return nil, err
+		}
+		body = decoded
+	}
+	if !m.Body.Decoded && m.ContentEncoding != "" {
+		body = decodeBody(body, m.ContentEncoding)
+	}
+	return body, nil
+}
+
+// statusText is the reason phrase of the status line
+func (m charlesMessage) statusText() string {
+	fields := strings.SplitN(m.Header.FirstLine, " ", 3)
+	if len(fields) < 3 {
+		return ""
+	}
+	return fields[2]
+}
+
+// optionalPhase is a phase Charles may not have timed, -1 when it didn't
+func optionalPhase(ms *float64) float64 {
+	if ms == nil || *ms < 0 {
+		return -1
+	}
+	return *ms
+}
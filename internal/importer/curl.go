@@ -0,0 +1,564 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// notSent is the response of a request that was written by hand rather
+// than captured
+const notSent = "not sent"
+
+// curlValueOptions are the long options that take a value. Options that
+// curlCommand.option doesn't handle are ignored, along with their value
+var curlValueOptions = map[string]bool{
+	"request": true, "header": true, "data": true, "data-raw": true, "data-ascii": true,
+	"data-binary": true, "data-urlencode": true, "json": true, "form": true, "form-string": true,
+	"user": true, "cookie": true, "user-agent": true, "referer": true, "url": true,
+	"upload-file": true, "oauth2-bearer": true, "output": true, "max-time": true,
+	"connect-timeout": true, "write-out": true, "retry": true, "retry-delay": true,
+	"retry-max-time": true, "proxy": true, "proxy-user": true, "cacert": true, "capath": true,
+	"cert": true, "cert-type": true, "key": true, "key-type": true, "config": true,
+	"cookie-jar": true, "dump-header": true, "range": true, "resolve": true, "connect-to": true,
+	"interface": true, "limit-rate": true, "max-redirs": true, "max-filesize": true,
+	"unix-socket": true, "time-cond": true, "expect100-timeout": true, "keepalive-time": true,
+	"local-port": true, "dns-servers": true, "trace": true, "trace-ascii": true, "stderr": true,
+	"ciphers": true, "tls-max": true, "pinnedpubkey": true, "proto": true, "proto-redir": true,
+	"variable": true, "aws-sigv4": true, "output-dir": true, "netrc-file": true,
+	"speed-time": true, "speed-limit": true, "continue-at": true, "ftp-port": true, "quote": true,
+	"telnet-option": true,
+}
+
+// curlShortOptions maps curl's short options to long ones; those mapped to
+// "" take no value
+var curlShortOptions = map[byte]string{
+	'X': "request", 'H': "header", 'd': "data", 'F': "form", 'u': "user", 'b': "cookie",
+	'A': "user-agent", 'e': "referer", 'T': "upload-file", 'o': "output", 'm': "max-time",
+	'w': "write-out", 'x': "proxy", 'U': "proxy-user", 'E': "cert", 'K': "config",
+	'c': "cookie-jar", 'D': "dump-header", 'r': "range", 'G': "get", 'I': "head",
+	'y': "speed-time", 'Y': "speed-limit", 'z': "time-cond", 'C': "continue-at", 'P': "ftp-port",
+	'Q': "quote", 't': "telnet-option", '0': "http1.0",
+}
+
+// isCurl tells a file of curl commands by its first command
+func isCurl(head []byte) bool {
+	for _, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "$ ")
+		return line == "curl" || strings.HasPrefix(line, "curl ")
+	}
+	return false
+}
+
+// convertCurl converts every curl command of a file, like those browsers
+// copy with "Copy as cURL", into a request that wasn't sent yet
+func convertCurl(r io.Reader) (*har.HARFile, error) {
+	text, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := ParseCurl(string(text))
+	if err != nil {
+		return nil, err
+	}
+	return newLog("curl", entries), nil
+}
+
+// ParseCurl turns curl commands, written for a POSIX shell, into entries
+// for the requests they would send, without a response. Commands other than
+// curl are ignored, so pipelines and "$ " prompts can be pasted as they are
+func ParseCurl(text string) ([]har.HAREntry, error) {
+	commands, err := shellCommands(text)
+	if err != nil {
+		return nil, err
+	}
+	started := time.Now()
+	var entries []har.HAREntry
+	n := 0
+	for _, words := range commands {
+		// Commands may be copied along with a shell prompt
+		if words[0] == "$" {
+			words = words[1:]
+		}
+		if len(words) == 0 {
+			continue
+		}
+		if name := path.Base(words[0]); name != "curl" && name != "curl.exe" {
+			continue
+		}
+		n++
+		requests, err := parseCurlCommand(words[1:])
+		if err != nil {
+			return nil, fmt.Errorf("curl command %d: %w", n, err)
+		}
+		for _, request := range requests {
+			entries = append(entries, newEntry(started, request, failedResponse(notSent), har.HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}))
+		}
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("no curl commands")
+	}
+	return entries, nil
+}
+
+// curlCommand collects the options of a curl command
+type curlCommand struct {
+	method      string
+	urls        []string
+	headers     []har.HARHeader
+	data        []string
+	form        []curlFormField
+	get, head   bool
+	upload      string
+	httpVersion string
+	comments    []string
+}
+
+// curlFormField is a -F field; files are referred to, not read
+type curlFormField struct {
+	name, value, file, contentType string
+}
+
+// parseCurlCommand builds the requests of a curl command, one per URL
+func parseCurlCommand(args []string) ([]har.HARRequest, error) {
+	cmd := curlCommand{httpVersion: "HTTP/1.1"}
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			cmd.urls = append(cmd.urls, args[i+1:]...)
+			i = len(args)
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+			if curlValueOptions[name] && !hasValue {
+				if i+1 >= len(args) {
+					return nil, fmt.Errorf("--%s needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+			if err := cmd.option(name, value); err != nil {
+				return nil, err
+			}
+		case strings.HasPrefix(arg, "-") && len(arg) > 1:
+			// Short options may be combined (-sSL) or have their value attached (-XPOST)
+			for j := 1; j < len(arg); j++ {
+				name := curlShortOptions[arg[j]]
+				if !curlValueOptions[name] {
+					if err := cmd.option(name, ""); err != nil {
+						return nil, err
+					}
+					continue
+				}
+				value := arg[j+1:]
+				if value == "" {
+					if i+1 >= len(args) {
+						return nil, fmt.Errorf("-%c needs a value", arg[j])
+					}
+					i++
+					value = args[i]
+				}
+				if err := cmd.option(name, value); err != nil {
+					return nil, err
+				}
+				break
+			}
+		default:
+			cmd.urls = append(cmd.urls, arg)
+		}
+	}
+	if len(cmd.urls) == 0 {
+		return nil, errors.New("no URL")
+	}
+	return cmd.requests()
+}
+
+// option applies one option; unknown options are ignored
+func (cmd *curlCommand) option(name, value string) error {
+	switch name {
+	case "request":
+		cmd.method = value
+	case "header":
+		header, ok := curlHeader(value)
+		if ok {
+			cmd.headers = append(cmd.headers, header)
+		}
+	case "data", "data-ascii", "data-binary", "data-raw":
+		if name != "data-raw" && strings.HasPrefix(value, "@") {
+			cmd.comments = append(cmd.comments, fmt.Sprintf("body read from %s by curl, not included", value[1:]))
+			value = ""
+		}
+		cmd.data = append(cmd.data, value)
+	case "data-urlencode":
+		cmd.data = append(cmd.data, curlURLEncode(value))
+	case "json":
+		cmd.data = append(cmd.data, value)
+		cmd.defaultHeader("Content-Type", "application/json")
+		cmd.defaultHeader("Accept", "application/json")
+	case "form", "form-string":
+		field := curlFormField{}
+		var ok bool
+		if field.name, field.value, ok = strings.Cut(value, "="); !ok {
+			return fmt.Errorf("invalid form field %q", value)
+		}
+		if name == "form" && (strings.HasPrefix(field.value, "@") || strings.HasPrefix(field.value, "<")) {
+			file, contentType := curlFormFile(field.value[1:])
+			field.value, field.file, field.contentType = "", file, contentType
+		}
+		cmd.form = append(cmd.form, field)
+	case "user":
+		cmd.headers = append(cmd.headers, har.HARHeader{Name: "Authorization", Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(value))})
+	case "oauth2-bearer":
+		cmd.headers = append(cmd.headers, har.HARHeader{Name: "Authorization", Value: "Bearer " + value})
+	case "cookie":
+		// Without a "=" the value names a cookie file
+		if strings.Contains(value, "=") {
+			cmd.headers = append(cmd.headers, har.HARHeader{Name: "Cookie", Value: value})
+		}
+	case "user-agent":
+		cmd.headers = append(cmd.headers, har.HARHeader{Name: "User-Agent", Value: value})
+	case "referer":
+		cmd.headers = append(cmd.headers, har.HARHeader{Name: "Referer", Value: value})
+	case "url":
+		cmd.urls = append(cmd.urls, value)
+	case "upload-file":
+		cmd.upload = value
+		cmd.comments = append(cmd.comments, fmt.Sprintf("body read from %s by curl, not included", value))
+	case "get":
+		cmd.get = true
+	case "head":
+		cmd.head = true
+	case "http1.0":
+		cmd.httpVersion = "HTTP/1.0"
+	case "http1.1":
+		cmd.httpVersion = "HTTP/1.1"
+	case "http2", "http2-prior-knowledge":
+		cmd.httpVersion = "HTTP/2.0"
+	case "http3", "http3-only":
+		cmd.httpVersion = "HTTP/3.0"
+	}
+	return nil
+}
+
+// defaultHeader adds a header unless the command sets it
+func (cmd *curlCommand) defaultHeader(name, value string) {
+	if har.HeaderValue(cmd.headers, name) == "" {
+		cmd.headers = append(cmd.headers, har.HARHeader{Name: name, Value: value})
+	}
+}
+
+// requests builds the request the command sends to each of its URLs
+func (cmd *curlCommand) requests() ([]har.HARRequest, error) {
+	body := strings.Join(cmd.data, "&")
+	method := cmd.method
+	switch {
+	case method != "":
+	case cmd.head:
+		method = "HEAD"
+	case cmd.upload != "":
+		method = "PUT"
+	case len(cmd.form) > 0 || (len(cmd.data) > 0 && !cmd.get):
+		method = "POST"
+	default:
+		method = "GET"
+	}
+
+	headers := append([]har.HARHeader(nil), cmd.headers...)
+	if len(cmd.form) > 0 {
+		form, contentType, err := cmd.multipartBody()
+		if err != nil {
+			return nil, err
+		}
+		body = form
+		if har.HeaderValue(headers, "Content-Type") == "" {
+			headers = append(headers, har.HARHeader{Name: "Content-Type", Value: contentType})
+		}
+	} else if len(cmd.data) > 0 && !cmd.get && har.HeaderValue(headers, "Content-Type") == "" {
+		headers = append(headers, har.HARHeader{Name: "Content-Type", Value: "application/x-www-form-urlencoded"})
+	}
+
+	var requests []har.HARRequest
+	for _, rawURL := range cmd.urls {
+		if !strings.Contains(rawURL, "://") {
+			rawURL = "http://" + rawURL
+		}
+		if _, err := url.Parse(rawURL); err != nil {
+			return nil, err
+		}
+		requestBody := body
+		if cmd.get && len(cmd.data) > 0 {
+			separator := "?"
+			if strings.Contains(rawURL, "?") {
+				separator = "&"
+			}
+			rawURL += separator + body
+			requestBody = ""
+		}
+		request := newRequest(method, rawURL, cmd.httpVersion, append([]har.HARHeader(nil), headers...), []byte(requestBody))
+		if request.PostData != nil && len(cmd.form) > 0 {
+			for _, field := range cmd.form {
+				request.PostData.Params = append(request.PostData.Params, har.HARParam{Name: field.name, Value: field.value, FileName: field.file, ContentType: field.contentType})
+			}
+		}
+		request.Comment = strings.Join(cmd.comments, "; ")
+		requests = append(requests, request)
+	}
+	return requests, nil
+}
+
+// multipartBody encodes the -F fields; files are left empty
+func (cmd *curlCommand) multipartBody() (string, string, error) {
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for _, field := range cmd.form {
+		var err error
+		if field.file == "" {
+			err = writer.WriteField(field.name, field.value)
+		} else {
+			_, err = writer.CreateFormFile(field.name, path.Base(field.file))
+			cmd.comments = append(cmd.comments, fmt.Sprintf("file %s not included", field.file))
+		}
+		if err != nil {
+			return "", "", err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", "", err
+	}
+	return buf.String(), writer.FormDataContentType(), nil
+}
+
+// curlHeader parses a -H value. "Name;" sends an empty header, while
+// "Name:" only removes one curl would add, so it is dropped
+func curlHeader(value string) (har.HARHeader, bool) {
+	if name, found := strings.CutSuffix(strings.TrimSpace(value), ";"); found && !strings.Contains(name, ":") {
+		return har.HARHeader{Name: name}, true
+	}
+	name, headerValue, ok := strings.Cut(value, ":")
+	headerValue = strings.TrimSpace(headerValue)
+	if !ok || headerValue == "" {
+		return har.HARHeader{}, false
+	}
+	return har.HARHeader{Name: strings.TrimSpace(name), Value: headerValue}, true
+}
+
+// curlFormFile splits a -F file reference into the file name, which may be
+// double-quoted, and its ;type=
+func curlFormFile(value string) (string, string) {
+	if !strings.HasPrefix(value, `"`) {
+		file, contentType, _ := strings.Cut(value, ";type=")
+		return file, contentType
+	}
+	var file strings.Builder
+	for i := 1; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '\\' && i+1 < len(value):
+			i++
+			file.WriteByte(value[i])
+		case c == '"':
+			_, contentType, _ := strings.Cut(value[i+1:], ";type=")
+			return file.String(), contentType
+		default:
+			file.WriteByte(c)
+		}
+	}
+	return file.String(), ""
+}
+
+// curlURLEncode encodes a --data-urlencode value: "content", "=content" and
+// "name=content" have the content encoded
+func curlURLEncode(value string) string {
+	name, content, found := strings.Cut(value, "=")
+	if !found {
+		return url.QueryEscape(value)
+	}
+	if name == "" {
+		return url.QueryEscape(content)
+	}
+	return name + "=" + url.QueryEscape(content)
+}
+
+// shellCommands splits text into the words of each command, quoted the way
+// a POSIX shell quotes them. Commands end at unquoted newlines and at ;, &,
+// && and | (which also drops the rest of the pipeline)
+func shellCommands(text string) ([][]string, error) {
+	var commands [][]string
+	var words []string
+	var word strings.Builder
+	inWord := false
+	skipping := false // in the commands a pipe feeds
+	endWord := func() {
+		if inWord {
+			words = append(words, word.String())
+			word.Reset()
+			inWord = false
+		}
+	}
+	endCommand := func() {
+		endWord()
+		if len(words) > 0 && !skipping {
+			commands = append(commands, words)
+		}
+		words = nil
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case c == '\\':
+			i++
+			switch {
+			case i >= len(text):
+			case text[i] == '\n':
+				// Line continuation
+			case text[i] == '\r' && i+1 < len(text) && text[i+1] == '\n':
+				i++
+			default:
+				word.WriteByte(text[i])
+				inWord = true
+			}
+		case c == ' ' || c == '\t' || c == '\r':
+			endWord()
+		case c == '\n':
+			endCommand()
+			skipping = false
+		case c == ';' || c == '&':
+			endCommand()
+			skipping = false
+			if c == '&' && i+1 < len(text) && text[i+1] == '&' {
+				i++
+			}
+		case c == '|':
+			endCommand()
+			skipping = true
+			if i+1 < len(text) && text[i+1] == '|' {
+				i++
+				skipping = false
+			}
+		case c == '#' && !inWord:
+			for i+1 < len(text) && text[i+1] != '\n' {
+				i++
+			}
+		case c == '\'':
+			end := strings.IndexByte(text[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			word.WriteString(text[i+1 : i+1+end])
+			inWord = true
+			i += end + 1
+		case c == '$' && i+1 < len(text) && text[i+1] == '\'':
+			n, err := ansiCQuoted(text[i+2:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i += n + 1
+		case c == '"':
+			n, err := doubleQuoted(text[i+1:], &word)
+			if err != nil {
+				return nil, err
+			}
+			inWord = true
+			i += n
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	endCommand()
+	return commands, nil
+}
+
+// doubleQuoted writes the contents of a "..." string starting at text,
+// returning how many bytes it took including the closing quote
+func doubleQuoted(text string, word *strings.Builder) (int, error) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; {
+		case c == '"':
+			return i + 1, nil
+		case c == '\\' && i+1 < len(text) && strings.IndexByte("$`\"\\\n", text[i+1]) >= 0:
+			i++
+			if text[i] != '\n' {
+				word.WriteByte(text[i])
+			}
+		default:
+			word.WriteByte(c)
+		}
+	}
+	return 0, errors.New("unterminated double quote")
+}
+
+// ansiCQuoted writes the contents of a $'...' string starting at text,
+// decoding its backslash escapes, and returns how many bytes it took
+// including the closing quote
+func ansiCQuoted(text string, word *strings.Builder) (int, error) {
+	escapes := map[byte]byte{'a': '\a', 'b': '\b', 'e': 0x1b, 'E': 0x1b, 'f': '\f', 'n': '\n', 'r': '\r', 't': '\t', 'v': '\v', '\\': '\\', '\'': '\'', '"': '"', '?': '?'}
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c == '\'' {
+			return i + 1, nil
+		}
+		if c != '\\' || i+1 >= len(text) {
+			word.WriteByte(c)
+			continue
+		}
+		i++
+		if escaped, ok := escapes[text[i]]; ok {
+			word.WriteByte(escaped)
+			continue
+		}
+		// Numeric escapes: \NNN octal, \xHH, \uHHHH and \UHHHHHHHH
+		base, maxDigits, start := 8, 3, i
+		switch text[i] {
+		case 'x':
+			base, maxDigits, start = 16, 2, i+1
+		case 'u':
+			base, maxDigits, start = 16, 4, i+1
+		case 'U':
+			base, maxDigits, start = 16, 8, i+1
+		}
+		end := start
+		for end < len(text) && end-start < maxDigits && isDigit(text[end], base) {
+			end++
+		}
+		if end == start {
+			word.WriteByte('\\')
+			word.WriteByte(text[i])
+			continue
+		}
+		value, _ := strconv.ParseUint(text[start:end], base, 32)
+		if text[i] == 'u' || text[i] == 'U' {
+			word.WriteRune(rune(value))
+		} else {
+			word.WriteByte(byte(value))
+		}
+		i = end - 1
+	}
+	return 0, errors.New("unterminated $' quote")
+}
+
+// isDigit tells whether c is a digit in base 8 or 16
+func isDigit(c byte, base int) bool {
+	switch {
+	case c >= '0' && c <= '7':
+		return true
+	case base == 16:
+		return c >= '8' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/export"
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func parseOne(t *testing.T, command string) har.HAREntry {
+	t.Helper()
+	entries, err := ParseCurl(command)
+	if err != nil {
+		t.Fatalf("ParseCurl(%q) failed: %v", command, err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected 1 entry, got %d", len(entries))
+	}
+	return entries[0]
+}
+
+func TestParseCurlCopiedFromBrowser(t *testing.T) {
+	command := `curl 'https://api.example.com/v1/items?page=2' \
+  -H 'accept: application/json' \
+  -H $'x-note: it\'s é' \
+  -b 'session=abc; theme=dark' \
+  --data-raw '{"name":"a b","tags":["x"]}' \
+  --compressed`
+	entry := parseOne(t, command)
+
+	if entry.Request.Method != "POST" || entry.Request.URL != "https://api.example.com/v1/items?page=2" {
+		t.Errorf("unexpected request: %s %s", entry.Request.Method, entry.Request.URL)
+	}
+	if got := har.HeaderValue(entry.Request.Headers, "x-note"); got != "it's é" {
+		t.Errorf("unexpected $'' header value %q", got)
+	}
+	if len(entry.Request.Cookies) != 2 || entry.Request.Cookies[1].Value != "dark" {
+		t.Errorf("unexpected cookies: %+v", entry.Request.Cookies)
+	}
+	if entry.Request.PostData == nil || entry.Request.PostData.Text != `{"name":"a b","tags":["x"]}` {
+		t.Errorf("unexpected body: %+v", entry.Request.PostData)
+	}
+	if entry.Request.PostData.MimeType != "application/x-www-form-urlencoded" {
+		t.Errorf("expected curl's default content type, got %q", entry.Request.PostData.MimeType)
+	}
+	if entry.Response.Status != 0 || entry.Response.Comment != notSent || entry.StartedDateTime == "" {
+		t.Errorf("unexpected response: %+v", entry.Response)
+	}
+}
+
+func TestParseCurlOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		check   func(har.HAREntry) bool
+	}{
+		{"combined short options", `curl -sSLXPUT example.com/a`, func(e har.HAREntry) bool {
+			return e.Request.Method == "PUT" && e.Request.URL == "http://example.com/a"
+		}},
+		{"long option with =", `curl --request=DELETE --url=https://example.com/`, func(e har.HAREntry) bool {
+			return e.Request.Method == "DELETE" && e.Request.URL == "https://example.com/"
+		}},
+		{"get with data", `curl -G -d q=1 --data-urlencode 'name=a b' https://example.com/s`, func(e har.HAREntry) bool {
+			return e.Request.Method == "GET" && e.Request.URL == "https://example.com/s?q=1&name=a+b" && e.Request.PostData == nil
+		}},
+		{"head", `curl -I https://example.com`, func(e har.HAREntry) bool { return e.Request.Method == "HEAD" }},
+		{"basic auth", `curl -u user:pass https://example.com`, func(e har.HAREntry) bool {
+			return har.HeaderValue(e.Request.Headers, "Authorization") == "Basic dXNlcjpwYXNz"
+		}},
+		{"json", `curl --json '{"a":1}' https://example.com`, func(e har.HAREntry) bool {
+			return e.Request.Method == "POST" && e.Request.PostData.MimeType == "application/json" && har.HeaderValue(e.Request.Headers, "Accept") == "application/json"
+		}},
+		{"double quotes", `curl -H "X-Var: \$HOME \"q\"" https://example.com`, func(e har.HAREntry) bool {
+			return har.HeaderValue(e.Request.Headers, "X-Var") == `$HOME "q"`
+		}},
+		{"empty and removed headers", `curl -H 'X-Empty;' -H 'Accept:' https://example.com`, func(e har.HAREntry) bool {
+			return len(e.Request.Headers) == 1 && e.Request.Headers[0].Name == "X-Empty" && e.Request.Headers[0].Value == ""
+		}},
+		{"form", `curl -F name=a -F 'upload=@photo.png;type=image/png' https://example.com/up`, func(e har.HAREntry) bool {
+			return e.Request.Method == "POST" && strings.HasPrefix(e.Request.PostData.MimeType, "multipart/form-data; boundary=") &&
+				len(e.Request.PostData.Params) == 2 && e.Request.PostData.Params[1].FileName == "photo.png" &&
+				strings.Contains(e.Request.PostData.Text, "name=\"name\"\r\n\r\na\r\n") && strings.Contains(e.Request.Comment, "photo.png")
+		}},
+		{"body from file", `curl --data-binary @body.json https://example.com`, func(e har.HAREntry) bool {
+			return e.Request.Method == "POST" && strings.Contains(e.Request.Comment, "body.json")
+		}},
+		{"http2", `curl --http2 https://example.com`, func(e har.HAREntry) bool { return e.Request.HTTPVersion == "HTTP/2.0" }},
+		{"ignored options with values", `curl -o out.txt --max-time 5 -w '%{http_code}' https://example.com`, func(e har.HAREntry) bool {
+			return e.Request.URL == "https://example.com"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if entry := parseOne(t, tt.command); !tt.check(entry) {
+				t.Errorf("unexpected entry: %+v", entry.Request)
+			}
+		})
+	}
+}
+
+func TestParseCurlCommands(t *testing.T) {
+	text := `# reproduce the bug
+$ curl https://example.com/a | jq .
+curl https://example.com/b https://example.com/c; echo done
+cd /tmp && curl -X POST https://example.com/d`
+	entries, err := ParseCurl(text)
+	if err != nil {
+		t.Fatalf("ParseCurl failed: %v", err)
+	}
+	var urls []string
+	for _, entry := range entries {
+		urls = append(urls, entry.Request.Method+" "+entry.Request.URL)
+	}
+	if got := strings.Join(urls, ", "); got != "GET https://example.com/a, GET https://example.com/b, GET https://example.com/c, POST https://example.com/d" {
+		t.Errorf("unexpected requests: %s", got)
+	}
+
+	for _, bad := range []string{`curl 'https://example.com`, `curl -H`, `curl -s`, `echo hi`} {
+		if _, err := ParseCurl(bad); err == nil {
+			t.Errorf("expected an error for %q", bad)
+		}
+	}
+}
+
+func TestParseCurlReversesExport(t *testing.T) {
+	entry := har.HAREntry{Request: har.HARRequest{
+		Method:   "PATCH",
+		URL:      "https://example.com/items/1?x=y",
+		Headers:  []har.HARHeader{{Name: "Host", Value: "example.com"}, {Name: "Content-Type", Value: "application/json"}},
+		PostData: &har.HARPostData{MimeType: "application/json", Text: `{"note":"it's"}`},
+	}}
+	parsed := parseOne(t, export.GenerateCurlCommand(entry))
+	if parsed.Request.Method != "PATCH" || parsed.Request.URL != entry.Request.URL || parsed.Request.PostData.Text != entry.Request.PostData.Text {
+		t.Errorf("round trip changed the request: %+v", parsed.Request)
+	}
+	if len(parsed.Request.Headers) != 1 || parsed.Request.Headers[0].Value != "application/json" {
+		t.Errorf("unexpected headers: %+v", parsed.Request.Headers)
+	}
+}
+
+func TestParseCurlReversesQuotedExport(t *testing.T) {
+	entry := har.HAREntry{Request: har.HARRequest{
+		Method:      "POST",
+		URL:         "https://example.com/search?q='x'&v=$HOME",
+		HTTPVersion: "HTTP/2.0",
+		Headers: []har.HARHeader{
+			{Name: "X-Note", Value: `it's "quoted" $(id) \ back`},
+			{Name: "Cookie", Value: "a=1; b=2"},
+			{Name: "Content-Type", Value: "multipart/form-data; boundary=B"},
+		},
+		PostData: &har.HARPostData{MimeType: "multipart/form-data; boundary=B", Params: []har.HARParam{
+			{Name: "title", Value: "@not-a-file"},
+			{Name: "doc", FileName: "a;b.txt", ContentType: "text/plain"},
+		}},
+	}}
+	parsed := parseOne(t, export.GenerateCurlCommand(entry)).Request
+	if parsed.URL != entry.Request.URL || parsed.HTTPVersion != "HTTP/2.0" {
+		t.Errorf("round trip changed the request line: %s %s", parsed.URL, parsed.HTTPVersion)
+	}
+	if har.HeaderValue(parsed.Headers, "X-Note") != entry.Request.Headers[0].Value || har.HeaderValue(parsed.Headers, "Cookie") != "a=1; b=2" {
+		t.Errorf("round trip changed the headers: %+v", parsed.Headers)
+	}
+	params := parsed.PostData.Params
+	if len(params) != 2 || params[0].Value != "@not-a-file" || params[1].FileName != "a;b.txt" || params[1].ContentType != "text/plain" {
+		t.Errorf("round trip changed the form: %+v", params)
+	}
+}
+
+func TestCurlFileOpens(t *testing.T) {
+	harFile := load(t, "bug.sh", []byte("curl -X POST https://example.com/a -d x=1\n"))
+	if len(harFile.Log.Entries) != 1 || harFile.Log.Creator.Comment != "converted from curl" {
+		t.Errorf("unexpected log: %+v", harFile.Log)
+	}
+}
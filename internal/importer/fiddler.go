@@ -0,0 +1,212 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// fiddlerTimeLayouts are how Fiddler writes session timers, with or without
+// a zone
+var fiddlerTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.9999999"}
+
+// fiddlerSession is the metadata of a session in a SAZ archive (NN_m.xml)
+type fiddlerSession struct {
+	Timers struct {
+		ClientBeginRequest  string `xml:"ClientBeginRequest,attr"`
+		FiddlerBeginRequest string `xml:"FiddlerBeginRequest,attr"`
+		ServerGotRequest    string `xml:"ServerGotRequest,attr"`
+		ServerBeginResponse string `xml:"ServerBeginResponse,attr"`
+		ServerDoneResponse  string `xml:"ServerDoneResponse,attr"`
+		DNSTime             string `xml:"DNSTime,attr"`
+		TCPConnectTime      string `xml:"TCPConnectTime,attr"`
+		HTTPSHandshakeTime  string `xml:"HTTPSHandshakeTime,attr"`
+	} `xml:"SessionTimers"`
+	Flags []struct {
+		Name  string `xml:"N,attr"`
+		Value string `xml:"V,attr"`
+	} `xml:"SessionFlags>SessionFlag"`
+}
+
+// fiddlerFiles are the parts of one session in a SAZ archive
+type fiddlerFiles struct {
+	request, response, metadata *zip.File
+}
+
+// isFiddler tells a Fiddler SAZ archive, which is a zip file
+func isFiddler(head []byte) bool {
+	return bytes.HasPrefix(head, []byte("PK\x03\x04"))
+}
+
+// convertFiddler converts the sessions of a Fiddler SAZ archive, in the
+// order they were numbered
+func convertFiddler(r io.Reader) (*har.HARFile, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := map[int]*fiddlerFiles{}
+	for _, file := range archive.File {
+		name := path.Base(file.Name)
+		if path.Dir(file.Name) != "raw" || len(name) < 6 {
+			continue
+		}
+		number, err := strconv.Atoi(strings.SplitN(name, "_", 2)[0])
+		if err != nil {
+			continue
+		}
+		files := sessions[number]
+		if files == nil {
+			files = &fiddlerFiles{}
+			sessions[number] = files
+		}
+		switch {
+		case strings.HasSuffix(name, "_c.txt"):
+			files.request = file
+		case strings.HasSuffix(name, "_s.txt"):
+			files.response = file
+		case strings.HasSuffix(name, "_m.xml"):
+			files.metadata = file
+		}
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("no sessions in archive")
+	}
+	numbers := make([]int, 0, len(sessions))
+	for number := range sessions {
+		numbers = append(numbers, number)
+	}
+	sort.Ints(numbers)
+
+	var entries []har.HAREntry
+	for _, number := range numbers {
+		entry, ok, err := fiddlerEntry(sessions[number])
+		if err != nil {
+			return nil, fmt.Errorf("session %d: %w", number, err)
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	return newLog("Fiddler", entries), nil
+}
+
+// fiddlerEntry converts one session, reporting false for tunnels and
+// sessions without a request
+func fiddlerEntry(files *fiddlerFiles) (har.HAREntry, bool, error) {
+	if files.request == nil {
+		return har.HAREntry{}, false, nil
+	}
+	rawRequest, err := readZipFile(files.request)
+	if err != nil {
+		return har.HAREntry{}, false, err
+	}
+	requestMessage := parseRawMessage(rawRequest)
+	method, target, _ := requestMessage.requestLine()
+	if method == "CONNECT" {
+		return har.HAREntry{}, false, nil
+	}
+
+	var session fiddlerSession
+	if files.metadata != nil {
+		metadata, err := readZipFile(files.metadata)
+		if err != nil {
+			return har.HAREntry{}, false, err
+		}
+		if err := xml.Unmarshal(metadata, &session); err != nil {
+			return har.HAREntry{}, false, fmt.Errorf("metadata: %w", err)
+		}
+	}
+	timers := session.Timers
+	handshake := fiddlerDuration(timers.HTTPSHandshakeTime)
+
+	// Requests kept in origin form are resolved against their Host header;
+	// a TLS handshake or port 443 means they went over HTTPS
+	scheme := "http"
+	if handshake > 0 || strings.HasSuffix(har.HeaderValue(requestMessage.headers, "Host"), ":443") {
+		scheme = "https"
+	}
+	request := requestMessage.request(absoluteURL(target, scheme, requestMessage.headers))
+
+	response := failedResponse("no response")
+	if files.response != nil {
+		rawResponse, err := readZipFile(files.response)
+		if err != nil {
+			return har.HAREntry{}, false, err
+		}
+		if len(rawResponse) > 0 {
+			response = parseRawMessage(rawResponse).response()
+		}
+	}
+
+	timings := har.HARTimings{
+		Blocked: -1,
+		DNS:     fiddlerDuration(timers.DNSTime),
+		Connect: fiddlerDuration(timers.TCPConnectTime),
+		SSL:     handshake,
+		Send:    phase(elapsed(fiddlerTime(timers.FiddlerBeginRequest), fiddlerTime(timers.ServerGotRequest))),
+		Wait:    phase(elapsed(fiddlerTime(timers.ServerGotRequest), fiddlerTime(timers.ServerBeginResponse))),
+		Receive: phase(elapsed(fiddlerTime(timers.ServerBeginResponse), fiddlerTime(timers.ServerDoneResponse))),
+	}
+	// HAR counts the TLS handshake as part of connecting
+	if timings.Connect >= 0 && timings.SSL > 0 {
+		timings.Connect += timings.SSL
+	}
+
+	entry := newEntry(fiddlerTime(timers.ClientBeginRequest), request, response, timings)
+	for _, flag := range session.Flags {
+		if flag.Name == "x-hostip" && net.ParseIP(flag.Value) != nil {
+			entry.ServerIPAddress = flag.Value
+		}
+	}
+	return entry, true, nil
+}
+
+// readZipFile reads a whole file of the archive
+func readZipFile(file *zip.File) ([]byte, error) {
+	reader, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// fiddlerTime parses a session timer; Fiddler writes the zero time for
+// events that didn't happen, which is returned as the zero time.Time
+func fiddlerTime(value string) time.Time {
+	for _, layout := range fiddlerTimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			if t.Year() <= 1 {
+				return time.Time{}
+			}
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// fiddlerDuration parses a phase Fiddler timed in whole ms, -1 when it
+// didn't happen
+func fiddlerDuration(value string) float64 {
+	ms, err := strconv.Atoi(value)
+	if err != nil || ms < 0 {
+		return -1
+	}
+	return float64(ms)
+}
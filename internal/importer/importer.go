@@ -0,0 +1,308 @@
+// Package importer converts captures written by other tools (mitmproxy,
+// Burp Suite, Charles and Fiddler), and requests written by hand as curl
+// commands or Postman collections, to HAR, so every loader reads them as if
+// they were HAR files
+package importer
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"encoding/base64"
+	"io"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/replay"
+	"github.com/klauspost/compress/zstd"
+)
+
+var registerOnce sync.Once
+
+// Register makes har.OpenHARInput, and so every loader, detect and convert
+// the capture formats of this package
+func Register() {
+	registerOnce.Do(func() {
+		har.RegisterFormat(har.Format{Name: "mitmproxy", Detect: isMitmproxy, Convert: convertMitmproxy})
+		har.RegisterFormat(har.Format{Name: "Burp Suite", Detect: isBurp, Convert: convertBurp})
+		har.RegisterFormat(har.Format{Name: "Charles", Detect: isCharles, Convert: convertCharles})
+		har.RegisterFormat(har.Format{Name: "Fiddler", Detect: isFiddler, Convert: convertFiddler})
+		har.RegisterFormat(har.Format{Name: "Postman", Detect: isPostman, Convert: convertPostman})
+		har.RegisterFormat(har.Format{Name: "curl", Detect: isCurl, Convert: convertCurl})
+	})
+}
+
+// newLog builds a HAR 1.2 log of entries converted from source
+func newLog(source string, entries []har.HAREntry) *har.HARFile {
+	if entries == nil {
+		entries = []har.HAREntry{}
+	}
+	return &har.HARFile{Log: har.HARLog{
+		Version: "1.2",
+		Creator: har.HARCreator{Name: "har-tui", Comment: "converted from " + source},
+		Entries: entries,
+	}}
+}
+
+// newEntry assembles an entry, taking its time from the timings
+func newEntry(started time.Time, request har.HARRequest, response har.HARResponse, timings har.HARTimings) har.HAREntry {
+	entry := har.HAREntry{
+		Request:  request,
+		Response: response,
+		Timings:  timings,
+	}
+	if !started.IsZero() {
+		entry.StartedDateTime = har.FormatHARDateTime(started)
+	}
+	for _, phase := range []float64{timings.Blocked, timings.DNS, timings.Connect, timings.Send, timings.Wait, timings.Receive} {
+		if phase > 0 {
+			entry.Time += phase
+		}
+	}
+	return entry
+}
+
+// failedResponse stands in for the response of an exchange that failed
+func failedResponse(reason string) har.HARResponse {
+	return har.HARResponse{
+		Headers:     []har.HARHeader{},
+		Cookies:     []har.HARCookie{},
+		HeadersSize: -1,
+		BodySize:    -1,
+		Comment:     reason,
+	}
+}
+
+// newRequest records a request; body is as sent, before any
+// Content-Encoding is removed
+func newRequest(method, rawURL, httpVersion string, headers []har.HARHeader, body []byte) har.HARRequest {
+	if headers == nil {
+		headers = []har.HARHeader{}
+	}
+	request := har.HARRequest{
+		Method:      method,
+		URL:         rawURL,
+		HTTPVersion: httpVersion,
+		Headers:     headers,
+		Cookies:     []har.HARCookie{},
+		QueryString: []har.HARQueryParam{},
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	if u, err := url.Parse(rawURL); err == nil {
+		request.QueryString = replay.QueryString(u.RawQuery)
+	}
+	for _, header := range headers {
+		if !strings.EqualFold(header.Name, "Cookie") {
+			continue
+		}
+		cookies, err := http.ParseCookie(header.Value)
+		if err != nil {
+			continue
+		}
+		for _, cookie := range cookies {
+			request.Cookies = append(request.Cookies, har.HARCookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	if len(body) > 0 {
+		content := decodeBody(body, har.HeaderValue(headers, "Content-Encoding"))
+		request.PostData = replay.PostData(har.HeaderValue(headers, "Content-Type"), content)
+	}
+	return request
+}
+
+// newResponse records a response whose body is content once any
+// Content-Encoding is removed; bodySize is its size as transferred
+func newResponse(status int, statusText, httpVersion string, headers []har.HARHeader, content []byte, bodySize int) har.HARResponse {
+	if headers == nil {
+		headers = []har.HARHeader{}
+	}
+	response := har.HARResponse{
+		Status:      status,
+		StatusText:  statusText,
+		HTTPVersion: httpVersion,
+		Headers:     headers,
+		Cookies:     []har.HARCookie{},
+		RedirectURL: har.HeaderValue(headers, "Location"),
+		HeadersSize: -1,
+		BodySize:    bodySize,
+	}
+	for _, header := range headers {
+		if !strings.EqualFold(header.Name, "Set-Cookie") {
+			continue
+		}
+		if cookie, err := http.ParseSetCookie(header.Value); err == nil {
+			response.Cookies = append(response.Cookies, replay.ResponseCookie(cookie))
+		}
+	}
+
+	response.Content = har.HARContent{
+		Size:     len(content),
+		MimeType: har.HeaderValue(headers, "Content-Type"),
+	}
+	if saved := len(content) - bodySize; bodySize >= 0 && saved > 0 {
+		response.Content.Compression = saved
+	}
+	if utf8.Valid(content) {
+		response.Content.Text = string(content)
+	} else {
+		response.Content.Text = base64.StdEncoding.EncodeToString(content)
+		response.Content.Encoding = "base64"
+	}
+	return response
+}
+
+// decodeBody removes a Content-Encoding from body. Encodings it doesn't
+// know, or bodies that don't decode, are returned unchanged
+func decodeBody(body []byte, encoding string) []byte {
+	decoded := body
+	codings := strings.Split(encoding, ",")
+	// Codings are listed in the order they were applied
+	for i := len(codings) - 1; i >= 0; i-- {
+		var reader io.Reader
+		var err error
+		switch strings.ToLower(strings.TrimSpace(codings[i])) {
+		case "", "identity":
+			continue
+		case "gzip", "x-gzip":
+			reader, err = gzip.NewReader(bytes.NewReader(decoded))
+		case "deflate":
+			// Servers send either zlib-wrapped or raw deflate
+			if reader, err = zlib.NewReader(bytes.NewReader(decoded)); err != nil {
+				reader, err = flate.NewReader(bytes.NewReader(decoded)), nil
+			}
+		case "zstd":
+			var decoder *zstd.Decoder
+			if decoder, err = zstd.NewReader(bytes.NewReader(decoded)); err == nil {
+				defer decoder.Close()
+				reader = decoder
+			}
+		default:
+			return body
+		}
+		if err != nil {
+			return body
+		}
+		if decoded, err = io.ReadAll(reader); err != nil {
+			return body
+		}
+	}
+	return decoded
+}
+
+// rawMessage is an HTTP/1 message as it was sent
+type rawMessage struct {
+	startLine   string
+	headers     []har.HARHeader
+	body        []byte // chunked transfer encoding removed
+	headersSize int    // bytes up to and including the blank line
+}
+
+// parseRawMessage splits an HTTP/1 message into its start line, headers and
+// body, keeping the headers in the order and case they were sent
+func parseRawMessage(data []byte) rawMessage {
+	var msg rawMessage
+	head := data
+	if end := bytes.Index(data, []byte("\r\n\r\n")); end >= 0 {
+		head, msg.body, msg.headersSize = data[:end], data[end+4:], end+4
+	} else if end := bytes.Index(data, []byte("\n\n")); end >= 0 {
+		head, msg.body, msg.headersSize = data[:end], data[end+2:], end+2
+	} else {
+		msg.headersSize = len(data)
+	}
+
+	msg.headers = []har.HARHeader{}
+	for i, line := range strings.Split(string(head), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if i == 0 {
+			msg.startLine = line
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if !ok || strings.TrimSpace(name) == "" {
+			continue
+		}
+		msg.headers = append(msg.headers, har.HARHeader{Name: strings.TrimSpace(name), Value: strings.TrimSpace(value)})
+	}
+
+	if strings.Contains(strings.ToLower(har.HeaderValue(msg.headers, "Transfer-Encoding")), "chunked") {
+		if body, err := io.ReadAll(httputil.NewChunkedReader(bytes.NewReader(msg.body))); err == nil {
+			msg.body = body
+		}
+	}
+	return msg
+}
+
+// requestLine splits a request line into method, target and version
+func (msg rawMessage) requestLine() (method, target, version string) {
+	fields := strings.Fields(msg.startLine)
+	if len(fields) > 0 {
+		method = fields[0]
+	}
+	if len(fields) > 1 {
+		target = fields[1]
+	}
+	if len(fields) > 2 {
+		version = fields[2]
+	}
+	return method, target, version
+}
+
+// statusLine splits a status line into version, status and reason
+func (msg rawMessage) statusLine() (version string, status int, reason string) {
+	version, rest, _ := strings.Cut(msg.startLine, " ")
+	code, reason, _ := strings.Cut(rest, " ")
+	status, _ = strconv.Atoi(code)
+	return version, status, reason
+}
+
+// request records the message as a request to rawURL
+func (msg rawMessage) request(rawURL string) har.HARRequest {
+	method, _, version := msg.requestLine()
+	request := newRequest(method, rawURL, version, msg.headers, msg.body)
+	request.HeadersSize = msg.headersSize
+	return request
+}
+
+// response records the message as a response
+func (msg rawMessage) response() har.HARResponse {
+	version, status, reason := msg.statusLine()
+	content := decodeBody(msg.body, har.HeaderValue(msg.headers, "Content-Encoding"))
+	response := newResponse(status, reason, version, msg.headers, content, len(msg.body))
+	response.HeadersSize = msg.headersSize
+	return response
+}
+
+// absoluteURL resolves a request target against the Host header, for
+// captures that kept requests in origin form
+func absoluteURL(target, scheme string, headers []har.HARHeader) string {
+	if strings.Contains(target, "://") {
+		return target
+	}
+	return scheme + "://" + har.HeaderValue(headers, "Host") + target
+}
+
+// elapsed is the time from start to end in ms, or -1 when either is unknown
+// or they are out of order
+func elapsed(start, end time.Time) float64 {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return -1
+	}
+	return float64(end.Sub(start).Microseconds()) / 1000
+}
+
+// phase is a required timing phase, which can't be -1
+func phase(ms float64) float64 {
+	if ms < 0 {
+		return 0
+	}
+	return ms
+}
@@ -0,0 +1,432 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// tnetstring encodes a value the way mitmproxy writes flows
+func tnetstring(value any) string {
+	var payload, kind string
+	switch v := value.(type) {
+	case nil:
+		kind = "~"
+	case string:
+		payload, kind = v, ";"
+	case []byte:
+		payload, kind = string(v), ","
+	case int:
+		payload, kind = fmt.Sprint(v), "#"
+	case float64:
+		payload, kind = fmt.Sprint(v), "^"
+	case bool:
+		payload, kind = fmt.Sprint(v), "!"
+	case []any:
+		for _, item := range v {
+			payload += tnetstring(item)
+		}
+		kind = "]"
+	case map[string]any:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			payload += tnetstring(key) + tnetstring(v[key])
+		}
+		kind = "}"
+	}
+	return fmt.Sprintf("%d:%s%s", len(payload), payload, kind)
+}
+
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(data))
+	zw.Close()
+	return buf.Bytes()
+}
+
+// load writes data to a file and loads it as HAR
+func load(t *testing.T, name string, data []byte) *har.HARFile {
+	t.Helper()
+	Register()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	harFile, err := har.LoadHARFile(path)
+	if err != nil {
+		t.Fatalf("LoadHARFile failed: %v", err)
+	}
+	return harFile
+}
+
+func TestMitmproxy(t *testing.T) {
+	body := gzipped(t, `{"ok":true}`)
+	flow := map[string]any{
+		"type": "http",
+		"request": map[string]any{
+			"method": []byte("POST"), "scheme": []byte("https"), "host": "api.example.com", "port": 8443,
+			"path": []byte("/login?next=%2Fhome"), "http_version": []byte("HTTP/1.1"),
+			"headers":         []any{[]any{[]byte("Content-Type"), []byte("application/json")}, []any{[]byte("Cookie"), []byte("a=1; b=2")}},
+			"content":         []byte(`{"user":"a"}`),
+			"timestamp_start": 1714557600.0, "timestamp_end": 1714557600.01,
+		},
+		"response": map[string]any{
+			"status_code": 200, "reason": []byte("OK"), "http_version": []byte("HTTP/1.1"),
+			"headers":         []any{[]any{[]byte("Content-Encoding"), []byte("gzip")}, []any{[]byte("Content-Type"), []byte("application/json")}},
+			"content":         body,
+			"timestamp_start": 1714557600.11, "timestamp_end": 1714557600.12,
+		},
+		"server_conn": map[string]any{
+			"id": "c1", "peername": []any{"93.184.216.34", 8443},
+			"timestamp_start": 1714557599.9, "timestamp_tcp_setup": 1714557599.95, "timestamp_tls_setup": 1714557599.98,
+		},
+	}
+	failed := map[string]any{
+		"type":    "http",
+		"request": map[string]any{"method": []byte("GET"), "scheme": []byte("http"), "host": "example.com", "port": 80, "path": []byte("/"), "http_version": []byte("HTTP/1.1"), "headers": []any{}, "content": []byte(""), "timestamp_start": 1714557601.0},
+		"error":   map[string]any{"msg": "Connection refused"},
+	}
+	tcp := map[string]any{"type": "tcp"}
+	harFile := load(t, "flows", []byte(tnetstring(flow)+tnetstring(tcp)+tnetstring(failed)))
+
+	if len(harFile.Log.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(harFile.Log.Entries))
+	}
+	entry := harFile.Log.Entries[0]
+	if entry.Request.URL != "https://api.example.com:8443/login?next=%2Fhome" || entry.Request.Method != "POST" {
+		t.Errorf("unexpected request: %s %s", entry.Request.Method, entry.Request.URL)
+	}
+	if len(entry.Request.QueryString) != 1 || entry.Request.QueryString[0].Value != "/home" {
+		t.Errorf("unexpected query string: %+v", entry.Request.QueryString)
+	}
+	if len(entry.Request.Cookies) != 2 || entry.Request.PostData == nil || entry.Request.PostData.Text != `{"user":"a"}` {
+		t.Errorf("unexpected cookies or body: %+v %+v", entry.Request.Cookies, entry.Request.PostData)
+	}
+	if entry.Response.Status != 200 || entry.Response.Content.Text != `{"ok":true}` || entry.Response.BodySize != len(body) {
+		t.Errorf("unexpected response: %+v", entry.Response)
+	}
+	if entry.ServerIPAddress != "93.184.216.34" || entry.StartedDateTime != "2024-05-01T10:00:00.000Z" {
+		t.Errorf("unexpected entry: %s %s", entry.ServerIPAddress, entry.StartedDateTime)
+	}
+	if timings := entry.Timings; timings.Connect < 79 || timings.Connect > 81 || timings.SSL < 29 || timings.SSL > 31 || timings.Wait < 99 || timings.Wait > 101 {
+		t.Errorf("unexpected timings: %+v", timings)
+	}
+	if failed := harFile.Log.Entries[1]; failed.Response.Status != 0 || failed.Response.Comment != "Connection refused" {
+		t.Errorf("unexpected failed entry: %+v", failed.Response)
+	}
+}
+
+func TestBurp(t *testing.T) {
+	request := "POST /api/items HTTP/2\r\nHost: example.com\r\nContent-Type: application/x-www-form-urlencoded\r\n\r\nname=a"
+	response := "HTTP/2 201 Created\r\nContent-Type: text/plain\r\nTransfer-Encoding: chunked\r\n\r\n5\r\nhello\r\n0\r\n\r\n"
+	export := `<?xml version="1.0"?>
+<!DOCTYPE items [
+<!ELEMENT items (item*)>
+<!ATTLIST items burpVersion CDATA "">
+]>
+<items burpVersion="2024.3" exportTime="Wed May 01 10:00:05 UTC 2024">
+  <item>
+    <time>Wed May 01 10:00:00 UTC 2024</time>
+    <url><![CDATA[https://example.com/api/items]]></url>
+    <host ip="93.184.216.34">example.com</host>
+    <port>443</port>
+    <protocol>https</protocol>
+    <method><![CDATA[POST]]></method>
+    <path><![CDATA[/api/items]]></path>
+    <request base64="true"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte(request)) + `]]></request>
+    <status>201</status>
+    <response base64="true"><![CDATA[` + base64.StdEncoding.EncodeToString([]byte(response)) + `]]></response>
+    <comment>created</comment>
+  </item>
+  <item>
+    <time>Wed May 01 10:00:01 UTC 2024</time>
+    <url><![CDATA[http://example.com/missing]]></url>
+    <host ip="">example.com</host>
+    <method><![CDATA[GET]]></method>
+    <request base64="false"><![CDATA[GET /missing HTTP/1.1
+Host: example.com
+
+]]></request>
+    <response base64="true"></response>
+  </item>
+</items>`
+	harFile := load(t, "burp.xml", []byte(export))
+
+	if len(harFile.Log.Entries) != 2 || harFile.Log.Creator.Comment != "converted from Burp Suite" {
+		t.Fatalf("unexpected log: %+v", harFile.Log)
+	}
+	entry := harFile.Log.Entries[0]
+	if entry.Request.HTTPVersion != "HTTP/2.0" || entry.Request.PostData == nil || entry.Request.PostData.Text != "name=a" {
+		t.Errorf("unexpected request: %+v", entry.Request)
+	}
+	if entry.Request.Headers[0].Name != "Host" || entry.Request.HeadersSize != strings.Index(request, "\r\n\r\n")+4 {
+		t.Errorf("headers not kept as sent: %+v", entry.Request.Headers)
+	}
+	if entry.Response.Status != 201 || entry.Response.StatusText != "Created" || entry.Response.Content.Text != "hello" {
+		t.Errorf("unexpected response: %+v", entry.Response)
+	}
+	if entry.StartedDateTime != "2024-05-01T10:00:00.000Z" || entry.ServerIPAddress != "93.184.216.34" || entry.Comment != "created" {
+		t.Errorf("unexpected entry: %+v", entry)
+	}
+	if missing := harFile.Log.Entries[1]; missing.Response.Status != 0 || missing.Request.URL != "http://example.com/missing" {
+		t.Errorf("unexpected entry without a response: %+v", missing)
+	}
+}
+
+func TestIsBurp(t *testing.T) {
+	for head, want := range map[string]bool{
+		"<?xml version=\"1.0\"?>\n<!DOCTYPE items [\n<!ELEMENT items (item*)>\n]>\n<items burpVersion=\"2024.3\">": true,
+		"<items burpVersion=\"2024.3\" exportTime=\"Wed May 01 10:00:05 UTC 2024\">":                               true,
+		"<?xml version=\"1.0\"?>\n<items>":                                              false,
+		"<?xml version=\"1.0\"?>\n<feed><title>&lt;items burpVersion=\"1\"&gt;</title>": false,
+		"{\"text\": \"<items burpVersion=\\\"2024.3\\\">\"}":                            false,
+	} {
+		if got := isBurp([]byte(head)); got != want {
+			t.Errorf("isBurp(%q) = %v, want %v", head, got, want)
+		}
+	}
+}
+
+func TestCharles(t *testing.T) {
+	session := `[
+  {
+    "status": "COMPLETE", "method": "CONNECT", "protocolVersion": "HTTP/1.1", "scheme": "https",
+    "host": "example.com", "actualPort": 443, "tunnel": true
+  },
+  {
+    "status": "COMPLETE", "method": "GET", "protocolVersion": "HTTP/1.1", "scheme": "https",
+    "host": "example.com", "actualPort": 443, "path": "/data", "query": "q=1", "tunnel": false,
+    "remoteAddress": "example.com/93.184.216.34",
+    "times": {"start": "2024-05-01T12:00:00.000+02:00"},
+    "durations": {"total": 60, "dns": 5, "connect": 10, "ssl": 20, "request": 1, "response": 4, "latency": 20},
+    "request": {"header": {"firstLine": "GET /data?q=1 HTTP/1.1", "headers": [{"name": "Host", "value": "example.com"}]}},
+    "response": {
+      "status": 200, "contentEncoding": "gzip", "sizes": {"headers": 80, "body": 31},
+      "header": {"firstLine": "HTTP/1.1 200 OK", "headers": [{"name": "Content-Type", "value": "image/png"}, {"name": "Content-Encoding", "value": "gzip"}]},
+      "body": {"encoded": "iVBORw0KGgo=", "decoded": true}
+    }
+  },
+  {
+    "status": "FAILED", "method": "GET", "protocolVersion": "HTTP/1.1", "scheme": "http",
+    "host": "example.com", "actualPort": 8080, "path": "/", "query": null, "tunnel": false,
+    "errorMessage": "Connection refused",
+    "times": {"start": "2024-05-01T10:00:01.000Z"},
+    "durations": {"dns": null},
+    "request": {"header": {"firstLine": "GET / HTTP/1.1", "headers": []}}
+  }
+]`
+	harFile := load(t, "session.chlsj.gz", gzipped(t, session))
+
+	if len(harFile.Log.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(harFile.Log.Entries))
+	}
+	entry := harFile.Log.Entries[0]
+	if entry.Request.URL != "https://example.com/data?q=1" || entry.StartedDateTime != "2024-05-01T10:00:00.000Z" {
+		t.Errorf("unexpected entry: %s at %s", entry.Request.URL, entry.StartedDateTime)
+	}
+	if entry.Response.Content.Encoding != "base64" || entry.Response.Content.Text != "iVBORw0KGgo=" || entry.Response.BodySize != 31 {
+		t.Errorf("unexpected content: %+v", entry.Response.Content)
+	}
+	if timings := entry.Timings; timings.DNS != 5 || timings.Connect != 30 || timings.SSL != 20 || timings.Wait != 20 || entry.Time != 60 {
+		t.Errorf("unexpected timings: %+v (time %v)", timings, entry.Time)
+	}
+	if entry.ServerIPAddress != "93.184.216.34" {
+		t.Errorf("unexpected server IP: %q", entry.ServerIPAddress)
+	}
+	failed := harFile.Log.Entries[1]
+	if failed.Request.URL != "http://example.com:8080/" || failed.Response.Comment != "Connection refused" || failed.Timings.DNS != -1 {
+		t.Errorf("unexpected failed entry: %+v", failed)
+	}
+}
+
+func TestFiddler(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name, data string) {
+		w, _ := zw.Create(name)
+		w.Write([]byte(data))
+	}
+	add("[Content_Types].xml", "<Types/>")
+	// Numbered so that sorting by name would put them out of order
+	add("raw/10_c.txt", "GET /second HTTP/1.1\r\nHost: example.com:443\r\n\r\n")
+	add("raw/10_s.txt", "HTTP/1.1 404 Not Found\r\nContent-Length: 0\r\n\r\n")
+	add("raw/9_c.txt", "POST http://example.com/first HTTP/1.1\r\nHost: example.com\r\nContent-Type: text/plain\r\n\r\nping")
+	add("raw/9_s.txt", "HTTP/1.1 200 OK\r\nContent-Type: text/plain\r\nSet-Cookie: id=1; Path=/; HttpOnly\r\n\r\npong")
+	add("raw/9_m.xml", `<?xml version="1.0" encoding="utf-8"?>
+<Session SID="9" BitFlags="0">
+  <SessionTimers ClientConnected="2024-05-01T09:59:59.9000000+00:00" ClientBeginRequest="2024-05-01T10:00:00.0000000+00:00"
+    FiddlerBeginRequest="2024-05-01T10:00:00.0100000+00:00" ServerGotRequest="2024-05-01T10:00:00.0200000+00:00"
+    ServerBeginResponse="2024-05-01T10:00:00.1200000+00:00" ServerDoneResponse="2024-05-01T10:00:00.1500000+00:00"
+    ClientDoneResponse="0001-01-01T00:00:00" DNSTime="3" TCPConnectTime="7" HTTPSHandshakeTime="0" />
+  <SessionFlags>
+    <SessionFlag N="x-hostip" V="93.184.216.34" />
+  </SessionFlags>
+</Session>`)
+	add("raw/11_c.txt", "CONNECT example.com:443 HTTP/1.1\r\nHost: example.com:443\r\n\r\n")
+	zw.Close()
+	harFile := load(t, "session.saz", buf.Bytes())
+
+	if len(harFile.Log.Entries) != 2 {
+		t.Fatalf("expected 2 entries, got %d", len(harFile.Log.Entries))
+	}
+	first := harFile.Log.Entries[0]
+	if first.Request.URL != "http://example.com/first" || first.Request.PostData == nil || first.Request.PostData.Text != "ping" {
+		t.Errorf("unexpected request: %+v", first.Request)
+	}
+	if first.Response.Content.Text != "pong" || len(first.Response.Cookies) != 1 || !first.Response.Cookies[0].HTTPOnly {
+		t.Errorf("unexpected response: %+v", first.Response)
+	}
+	if timings := first.Timings; timings.DNS != 3 || timings.Connect != 7 || timings.SSL != 0 || timings.Send != 10 || timings.Wait != 100 || timings.Receive != 30 {
+		t.Errorf("unexpected timings: %+v", timings)
+	}
+	if first.StartedDateTime != "2024-05-01T10:00:00.000Z" || first.ServerIPAddress != "93.184.216.34" {
+		t.Errorf("unexpected entry: %+v", first)
+	}
+	if second := harFile.Log.Entries[1]; second.Request.URL != "https://example.com:443/second" || second.Response.Status != 404 {
+		t.Errorf("unexpected second entry: %s %d", second.Request.URL, second.Response.Status)
+	}
+}
+
+func TestConvertedCaptureSavesAsHAR(t *testing.T) {
+	session := `[{"method":"GET","protocolVersion":"HTTP/1.1","scheme":"https","host":"example.com","actualPort":443,"path":"/",
+"times":{"start":"2024-05-01T10:00:00.000Z"},"request":{"header":{"headers":[]}},
+"response":{"status":204,"header":{"firstLine":"HTTP/1.1 204 No Content","headers":[]}}}]`
+	harFile := load(t, "session.chlsj", []byte(session))
+
+	path := filepath.Join(t.TempDir(), "saved.har")
+	if err := har.SaveFilteredHAR(harFile, []int{0}, path); err != nil {
+		t.Fatalf("SaveFilteredHAR failed: %v", err)
+	}
+	saved, err := har.LoadHARFile(path)
+	if err != nil {
+		t.Fatalf("loading saved HAR failed: %v", err)
+	}
+	if saved.Log.Version != "1.2" || len(saved.Log.Entries) != 1 || saved.Log.Entries[0].Response.Status != 204 {
+		t.Errorf("unexpected saved log: %+v", saved.Log)
+	}
+}
+
+func TestHARPassesThrough(t *testing.T) {
+	harFile := load(t, "plain.har", []byte(`{"log":{"version":"1.2","creator":{"name":"t","version":"1"},"entries":[]}}`))
+	if harFile.Log.Creator.Name != "t" {
+		t.Errorf("HAR input was converted: %+v", harFile.Log.Creator)
+	}
+}
+
+func TestHARQuotingOtherFormatsPassesThrough(t *testing.T) {
+	body := `{\"info\":{\"schema\":\"https://schema.getpostman.com/json/collection/v2.1.0/collection.json\"}} <items burpVersion=\"2023.1\">`
+	data := `{"log":{"version":"1.2","creator":{"name":"t","version":"1"},"entries":[{"startedDateTime":"2024-05-01T10:00:00Z","time":1,
+"request":{"method":"GET","url":"https://example.com/","httpVersion":"HTTP/1.1","headers":[],"queryString":[],"cookies":[],"headersSize":-1,"bodySize":0},
+"response":{"status":200,"statusText":"OK","httpVersion":"HTTP/1.1","headers":[],"cookies":[],"content":{"size":0,"mimeType":"text/plain","text":"` + body + `"},"redirectURL":"","headersSize":-1,"bodySize":0},
+"cache":{},"timings":{"send":0,"wait":1,"receive":0}}]}}`
+	harFile := load(t, "quoting.har", []byte(data))
+	if len(harFile.Log.Entries) != 1 || harFile.Log.Creator.Name != "t" {
+		t.Errorf("HAR input was converted: %+v", harFile.Log)
+	}
+}
+
+func TestIsPostman(t *testing.T) {
+	for head, want := range map[string]bool{
+		`{"info": {"name": "API", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"}, "item": [`:      true,
+		`{"variable": [{"key": "a"}], "info": {"_postman_id": "1", "schema": "https://schema.postman.com/json/collection/v2.1.0/"}}`: true,
+		`{"info": {"name": "API"}, "item": [{"description": "https://schema.getpostman.com/json/collection/v2.1.0/"}]}`:              false,
+		`{"log": {"comment": "{\"info\": {\"schema\": \"https://schema.getpostman.com/json/collection/v2.1.0/\"}}"}}`:                false,
+		`[{"info": {"schema": "https://schema.getpostman.com/json/collection/v2.1.0/"}}]`:                                            false,
+	} {
+		if got := isPostman([]byte(head)); got != want {
+			t.Errorf("isPostman(%s) = %v, want %v", head, got, want)
+		}
+	}
+}
+
+func TestPostman(t *testing.T) {
+	collection := `{
+  "info": {"name": "API", "schema": "https://schema.getpostman.com/json/collection/v2.1.0/collection.json"},
+  "auth": {"type": "bearer", "bearer": [{"key": "token", "value": "{{token}}", "type": "string"}]},
+  "variable": [{"key": "base", "value": "https://api.example.com"}, {"key": "token", "value": "t0k"}],
+  "item": [
+    {
+      "name": "Users",
+      "item": [
+        {
+          "name": "Create user",
+          "request": {
+            "method": "POST",
+            "header": [{"key": "X-Trace", "value": "1"}, {"key": "X-Off", "value": "1", "disabled": true}],
+            "url": {"raw": "{{base}}/users?notify=true", "host": ["{{base}}"], "path": ["users"]},
+            "body": {"mode": "raw", "raw": "{\"name\":\"{{user}}\"}", "options": {"raw": {"language": "json"}}}
+          },
+          "response": [
+            {"name": "Created", "status": "Created", "code": 201, "header": [{"key": "Content-Type", "value": "application/json"}], "body": "{\"id\":1}"}
+          ]
+        }
+      ]
+    },
+    {
+      "name": "Login",
+      "auth": {"type": "basic", "basic": [{"key": "username", "value": "u"}, {"key": "password", "value": "p"}]},
+      "request": {
+        "method": "POST",
+        "url": "{{base}}/login",
+        "body": {"mode": "urlencoded", "urlencoded": [{"key": "remember", "value": "yes"}, {"key": "skip", "value": "1", "disabled": true}]}
+      }
+    },
+    {
+      "name": "Search",
+      "request": {
+        "method": "POST",
+        "auth": {"type": "apikey", "apikey": [{"key": "key", "value": "api_key"}, {"key": "value", "value": "k"}, {"key": "in", "value": "query"}]},
+        "url": {"raw": "{{base}}/graphql"},
+        "body": {"mode": "graphql", "graphql": {"query": "{ items { id } }", "variables": "{\"n\": 1}"}}
+      }
+    }
+  ]
+}`
+	harFile := load(t, "api.postman_collection.json", []byte(collection))
+
+	if len(harFile.Log.Entries) != 3 {
+		t.Fatalf("expected 3 entries, got %d", len(harFile.Log.Entries))
+	}
+	create := harFile.Log.Entries[0]
+	if create.Comment != "Users / Create user" || create.Request.URL != "https://api.example.com/users?notify=true" {
+		t.Errorf("unexpected request: %s %s", create.Comment, create.Request.URL)
+	}
+	// Variables the collection doesn't define are left for an environment
+	if create.Request.PostData == nil || create.Request.PostData.Text != `{"name":"{{user}}"}` || create.Request.PostData.MimeType != "application/json" {
+		t.Errorf("unexpected body: %+v", create.Request.PostData)
+	}
+	if har.HeaderValue(create.Request.Headers, "Authorization") != "Bearer t0k" || har.HeaderValue(create.Request.Headers, "X-Off") != "" {
+		t.Errorf("unexpected headers: %+v", create.Request.Headers)
+	}
+	if create.Response.Status != 201 || create.Response.Content.Text != `{"id":1}` {
+		t.Errorf("example response not used: %+v", create.Response)
+	}
+
+	login := harFile.Log.Entries[1]
+	if login.Request.PostData.Text != "remember=yes" || len(login.Request.PostData.Params) != 1 {
+		t.Errorf("unexpected form: %+v", login.Request.PostData)
+	}
+	if har.HeaderValue(login.Request.Headers, "Authorization") != "Basic dTpw" || login.Response.Status != 0 {
+		t.Errorf("unexpected login entry: %+v", login)
+	}
+
+	search := harFile.Log.Entries[2]
+	if search.Request.URL != "https://api.example.com/graphql?api_key=k" || search.Request.PostData.Text != `{"query":"{ items { id } }","variables":{"n":1}}` {
+		t.Errorf("unexpected search request: %s %+v", search.Request.URL, search.Request.PostData)
+	}
+}
@@ -0,0 +1,256 @@
+package importer
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// mitmproxyHead matches the start of a flow dump: a tnetstring dictionary
+// whose first key is itself a tnetstring
+var mitmproxyHead = regexp.MustCompile(`^\d{1,9}:\d{1,9}:`)
+
+// isMitmproxy tells a mitmproxy flow dump (mitmdump -w) by its first flow
+func isMitmproxy(head []byte) bool {
+	return mitmproxyHead.Match(head)
+}
+
+// convertMitmproxy converts the HTTP flows of a mitmproxy dump. Flows of
+// other kinds (TCP, UDP, DNS) are skipped
+func convertMitmproxy(r io.Reader) (*har.HARFile, error) {
+	reader := bufio.NewReader(r)
+	var entries []har.HAREntry
+	connected := map[string]bool{} // server connections already timed
+	for n := 1; ; n++ {
+		value, err := readTnetstring(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("flow %d: %w", n, err)
+		}
+		flow, ok := value.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("flow %d: not a flow", n)
+		}
+		if kind := str(flow["type"]); kind != "" && kind != "http" {
+			continue
+		}
+		if entry, ok := mitmproxyEntry(flow, connected); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return newLog("mitmproxy", entries), nil
+}
+
+// mitmproxyEntry converts one HTTP flow, reporting false for tunnels
+func mitmproxyEntry(flow map[string]any, connected map[string]bool) (har.HAREntry, bool) {
+	req := dict(flow["request"])
+	method := str(req["method"])
+	if req == nil || method == "CONNECT" {
+		return har.HAREntry{}, false
+	}
+
+	host := str(req["host"])
+	port := int(num(req["port"]))
+	scheme := str(req["scheme"])
+	authority := str(req["authority"])
+	if authority == "" {
+		authority = host
+		if (scheme == "http" && port != 80) || (scheme == "https" && port != 443) {
+			authority = net.JoinHostPort(host, strconv.Itoa(port))
+		}
+	}
+	request := newRequest(method, scheme+"://"+authority+str(req["path"]), str(req["http_version"]), mitmproxyHeaders(req["headers"]), bytesOf(req["content"]))
+	requestStart, requestEnd := timestamp(req["timestamp_start"]), timestamp(req["timestamp_end"])
+
+	timings := har.HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}
+	server := dict(flow["server_conn"])
+	// A connection is only set up by the first flow on it
+	if id := str(server["id"]); server != nil && !connected[id] {
+		connected[id] = true
+		tcpSetup := timestamp(server["timestamp_tcp_setup"])
+		timings.Connect = elapsed(timestamp(server["timestamp_start"]), tcpSetup)
+		if tlsSetup := timestamp(server["timestamp_tls_setup"]); !tlsSetup.IsZero() {
+			timings.SSL = elapsed(tcpSetup, tlsSetup)
+			if timings.Connect >= 0 && timings.SSL >= 0 {
+				timings.Connect += timings.SSL
+			}
+		}
+	}
+	timings.Send = phase(elapsed(requestStart, requestEnd))
+
+	var response har.HARResponse
+	if resp := dict(flow["response"]); resp != nil {
+		headers := mitmproxyHeaders(resp["headers"])
+		body := bytesOf(resp["content"])
+		content := decodeBody(body, har.HeaderValue(headers, "Content-Encoding"))
+		response = newResponse(int(num(resp["status_code"])), str(resp["reason"]), str(resp["http_version"]), headers, content, len(body))
+		responseStart := timestamp(resp["timestamp_start"])
+		timings.Wait = phase(elapsed(requestEnd, responseStart))
+		timings.Receive = phase(elapsed(responseStart, timestamp(resp["timestamp_end"])))
+	} else {
+		reason := "no response"
+		if msg := str(dict(flow["error"])["msg"]); msg != "" {
+			reason = msg
+		}
+		response = failedResponse(reason)
+	}
+
+	entry := newEntry(requestStart, request, response, timings)
+	for _, key := range []string{"peername", "ip_address", "address"} {
+		if address, ok := server[key].([]any); ok && len(address) > 0 {
+			if ip := net.ParseIP(str(address[0])); ip != nil {
+				entry.ServerIPAddress = ip.String()
+				break
+			}
+		}
+	}
+	return entry, true
+}
+
+// mitmproxyHeaders converts headers stored as [name, value] pairs
+func mitmproxyHeaders(value any) []har.HARHeader {
+	headers := []har.HARHeader{}
+	pairs, _ := value.([]any)
+	for _, pair := range pairs {
+		if fields, ok := pair.([]any); ok && len(fields) == 2 {
+			headers = append(headers, har.HARHeader{Name: str(fields[0]), Value: str(fields[1])})
+		}
+	}
+	return headers
+}
+
+// maxTnetstringLength bounds a single value, so corrupt input fails fast
+const maxTnetstringLength = 1 << 30
+
+// readTnetstring reads one tnetstring value: bytes become []byte, strings
+// string, numbers int64 or float64, lists []any and dictionaries map[string]any
+func readTnetstring(r *bufio.Reader) (any, error) {
+	prefix, err := r.ReadString(':')
+	if err != nil {
+		if err == io.EOF && prefix == "" {
+			return nil, io.EOF
+		}
+		return nil, io.ErrUnexpectedEOF
+	}
+	length, err := strconv.Atoi(prefix[:len(prefix)-1])
+	if err != nil || length < 0 || length > maxTnetstringLength {
+		return nil, fmt.Errorf("invalid tnetstring length %q", prefix[:len(prefix)-1])
+	}
+	data := make([]byte, length+1)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, io.ErrUnexpectedEOF
+	}
+	return parseTnetstring(data[:length], data[length])
+}
+
+// parseTnetstring decodes the payload of a tnetstring of the given type
+func parseTnetstring(data []byte, kind byte) (any, error) {
+	switch kind {
+	case ',':
+		return data, nil
+	case ';':
+		return string(data), nil
+	case '#':
+		return strconv.ParseInt(string(data), 10, 64)
+	case '^':
+		return strconv.ParseFloat(string(data), 64)
+	case '!':
+		return string(data) == "true", nil
+	case '~':
+		return nil, nil
+	case ']':
+		list := []any{}
+		reader := bufio.NewReader(bytes.NewReader(data))
+		for {
+			item, err := readTnetstring(reader)
+			if err == io.EOF {
+				return list, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, item)
+		}
+	case '}':
+		object := map[string]any{}
+		reader := bufio.NewReader(bytes.NewReader(data))
+		for {
+			key, err := readTnetstring(reader)
+			if err == io.EOF {
+				return object, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			value, err := readTnetstring(reader)
+			if err != nil {
+				if err == io.EOF {
+					err = errors.New("dictionary key without a value")
+				}
+				return nil, err
+			}
+			object[str(key)] = value
+		}
+	}
+	return nil, fmt.Errorf("unknown tnetstring type %q", kind)
+}
+
+// dict returns a tnetstring dictionary, or nil for anything else
+func dict(value any) map[string]any {
+	object, _ := value.(map[string]any)
+	return object
+}
+
+// str returns a tnetstring string or bytes value as a string
+func str(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return ""
+}
+
+// bytesOf returns a tnetstring bytes or string value as bytes
+func bytesOf(value any) []byte {
+	switch v := value.(type) {
+	case []byte:
+		return v
+	case string:
+		return []byte(v)
+	}
+	return nil
+}
+
+// num returns a tnetstring number as a float64
+func num(value any) float64 {
+	switch v := value.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// timestamp converts a Unix timestamp in seconds, zero when missing
+func timestamp(value any) time.Time {
+	seconds := num(value)
+	if seconds <= 0 {
+		return time.Time{}
+	}
+	whole, fraction := math.Modf(seconds)
+	return time.Unix(int64(whole), int64(fraction*1e9))
+}
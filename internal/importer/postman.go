@@ -0,0 +1,402 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// postmanCollection is a Postman v2.1 collection export
+type postmanCollection struct {
+	Info struct {
+		Name   string `json:"name"`
+		Schema string `json:"schema"`
+	} `json:"info"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Variable []postmanKeyValue `json:"variable"`
+}
+
+// postmanItem is a request, or a folder of them
+type postmanItem struct {
+	Name     string            `json:"name"`
+	Item     []postmanItem     `json:"item"`
+	Auth     *postmanAuth      `json:"auth"`
+	Request  *postmanRequest   `json:"request"`
+	Response []postmanResponse `json:"response"`
+}
+
+type postmanRequest struct {
+	Method string            `json:"method"`
+	URL    postmanURL        `json:"url"`
+	Header []postmanKeyValue `json:"header"`
+	Body   *postmanBody      `json:"body"`
+	Auth   *postmanAuth      `json:"auth"`
+}
+
+// postmanURL is a URL, written either as a string or as an object whose
+// raw form is authoritative
+type postmanURL struct {
+	Raw string `json:"raw"`
+}
+
+type postmanBody struct {
+	Mode       string            `json:"mode"`
+	Raw        string            `json:"raw"`
+	URLEncoded []postmanKeyValue `json:"urlencoded"`
+	FormData   []postmanKeyValue `json:"formdata"`
+	GraphQL    struct {
+		Query     string `json:"query"`
+		Variables string `json:"variables"`
+	} `json:"graphql"`
+	Options struct {
+		Raw struct {
+			Language string `json:"language"`
+		} `json:"raw"`
+	} `json:"options"`
+}
+
+type postmanAuth struct {
+	Type   string            `json:"type"`
+	Bearer []postmanKeyValue `json:"bearer"`
+	Basic  []postmanKeyValue `json:"basic"`
+	APIKey []postmanKeyValue `json:"apikey"`
+}
+
+// postmanKeyValue is a header, variable, form field or auth setting
+type postmanKeyValue struct {
+	Key         string `json:"key"`
+	Value       any    `json:"value"`
+	Type        string `json:"type"`
+	Src         any    `json:"src"`
+	ContentType string `json:"contentType"`
+	Disabled    bool   `json:"disabled"`
+}
+
+// postmanResponse is an example response saved with a request
+type postmanResponse struct {
+	Name   string            `json:"name"`
+	Status string            `json:"status"`
+	Code   int               `json:"code"`
+	Header []postmanKeyValue `json:"header"`
+	Body   string            `json:"body"`
+}
+
+// postmanRawLanguages are the content types of raw bodies by language
+var postmanRawLanguages = map[string]string{
+	"json":       "application/json",
+	"xml":        "application/xml",
+	"html":       "text/html",
+	"text":       "text/plain",
+	"javascript": "application/javascript",
+}
+
+// isPostman tells a Postman collection by the schema in the info of its
+// top-level object
+func isPostman(head []byte) bool {
+	decoder := json.NewDecoder(bytes.NewReader(head))
+	if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+		return false
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if key != "info" {
+			var skipped json.RawMessage
+			if decoder.Decode(&skipped) != nil {
+				return false
+			}
+			continue
+		}
+		if token, err := decoder.Token(); err != nil || token != json.Delim('{') {
+			return false
+		}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return false
+			}
+			var value json.RawMessage
+			if decoder.Decode(&value) != nil {
+				return false
+			}
+			if key == "schema" {
+				var schema string
+				json.Unmarshal(value, &schema)
+				return strings.Contains(schema, "schema.getpostman.com/json/collection/") ||
+					strings.Contains(schema, "schema.postman.com/json/collection/")
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// convertPostman converts the requests of a Postman collection
+func convertPostman(r io.Reader) (*har.HARFile, error) {
+	entries, err := ParsePostman(r)
+	if err != nil {
+		return nil, err
+	}
+	return newLog("Postman", entries), nil
+}
+
+// ParsePostman turns the requests of a Postman v2.1 collection, in all its
+// folders, into entries. Collection variables are filled in, leaving the
+// {{placeholders}} of others for an environment. A request with a saved
+// example response gets the first one as its response; others have none
+func ParsePostman(r io.Reader) ([]har.HAREntry, error) {
+	var collection postmanCollection
+	if err := json.NewDecoder(r).Decode(&collection); err != nil {
+		return nil, err
+	}
+	if strings.Contains(collection.Info.Schema, "/v1.") || strings.Contains(collection.Info.Schema, "/v2.0") {
+		return nil, fmt.Errorf("unsupported collection schema %s, export it as v2.1", collection.Info.Schema)
+	}
+
+	variables := map[string]string{}
+	for _, variable := range collection.Variable {
+		if !variable.Disabled {
+			variables[variable.Key] = stringValue(variable.Value)
+		}
+	}
+	converter := postmanConverter{variables: variables, started: time.Now()}
+	if err := converter.items(collection.Item, "", collection.Auth); err != nil {
+		return nil, err
+	}
+	if len(converter.entries) == 0 {
+		return nil, errors.New("no requests in collection")
+	}
+	return converter.entries, nil
+}
+
+// postmanConverter collects the entries of a collection
+type postmanConverter struct {
+	variables map[string]string
+	started   time.Time
+	entries   []har.HAREntry
+}
+
+// items converts the requests of a folder; requests inherit the auth of
+// the closest folder that sets one
+func (c *postmanConverter) items(items []postmanItem, folder string, auth *postmanAuth) error {
+	for _, item := range items {
+		name := item.Name
+		if folder != "" {
+			name = folder + " / " + item.Name
+		}
+		itemAuth := auth
+		if item.Auth != nil {
+			itemAuth = item.Auth
+		}
+		if item.Request == nil {
+			if err := c.items(item.Item, name, itemAuth); err != nil {
+				return err
+			}
+			continue
+		}
+		entry, err := c.entry(item, itemAuth)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		entry.Comment = name
+		c.entries = append(c.entries, entry)
+	}
+	return nil
+}
+
+// entry converts one request
+func (c *postmanConverter) entry(item postmanItem, auth *postmanAuth) (har.HAREntry, error) {
+	req := item.Request
+	if req.Auth != nil {
+		auth = req.Auth
+	}
+	rawURL := c.expand(req.URL.Raw)
+	if rawURL == "" {
+		return har.HAREntry{}, errors.New("no URL")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	method := req.Method
+	if method == "" {
+		method = "GET"
+	}
+
+	headers := []har.HARHeader{}
+	for _, header := range req.Header {
+		if !header.Disabled {
+			headers = append(headers, har.HARHeader{Name: c.expand(header.Key), Value: c.expand(stringValue(header.Value))})
+		}
+	}
+	body, contentType, comment, err := c.body(req.Body)
+	if err != nil {
+		return har.HAREntry{}, err
+	}
+	if contentType != "" && har.HeaderValue(headers, "Content-Type") == "" {
+		headers = append(headers, har.HARHeader{Name: "Content-Type", Value: contentType})
+	}
+	if auth != nil {
+		switch auth.Type {
+		case "bearer":
+			headers = append(headers, har.HARHeader{Name: "Authorization", Value: "Bearer " + c.setting(auth.Bearer, "token")})
+		case "basic":
+			credentials := c.setting(auth.Basic, "username") + ":" + c.setting(auth.Basic, "password")
+			headers = append(headers, har.HARHeader{Name: "Authorization", Value: "Basic " + base64.StdEncoding.EncodeToString([]byte(credentials))})
+		case "apikey":
+			key, value := c.setting(auth.APIKey, "key"), c.setting(auth.APIKey, "value")
+			if c.setting(auth.APIKey, "in") == "query" {
+				separator := "?"
+				if strings.Contains(rawURL, "?") {
+					separator = "&"
+				}
+				rawURL += separator + url.QueryEscape(key) + "=" + url.QueryEscape(value)
+			} else {
+				headers = append(headers, har.HARHeader{Name: key, Value: value})
+			}
+		}
+	}
+
+	request := newRequest(method, rawURL, "HTTP/1.1", headers, []byte(body))
+	request.Comment = comment
+	if request.PostData != nil && req.Body != nil && req.Body.Mode == "urlencoded" {
+		for _, field := range req.Body.URLEncoded {
+			if !field.Disabled {
+				request.PostData.Params = append(request.PostData.Params, har.HARParam{Name: c.expand(field.Key), Value: c.expand(stringValue(field.Value))})
+			}
+		}
+	}
+
+	response := failedResponse(notSent)
+	if len(item.Response) > 0 {
+		example := item.Response[0]
+		exampleHeaders := []har.HARHeader{}
+		for _, header := range example.Header {
+			exampleHeaders = append(exampleHeaders, har.HARHeader{Name: header.Key, Value: stringValue(header.Value)})
+		}
+		response = newResponse(example.Code, example.Status, "HTTP/1.1", exampleHeaders, []byte(example.Body), len(example.Body))
+		response.Comment = fmt.Sprintf("example response %q saved in Postman", example.Name)
+	}
+	return newEntry(c.started, request, response, har.HARTimings{Blocked: -1, DNS: -1, Connect: -1, SSL: -1}), nil
+}
+
+// body encodes a request body, returning the content type it implies and
+// a note on anything left out
+func (c *postmanConverter) body(body *postmanBody) (text, contentType, comment string, err error) {
+	if body == nil {
+		return "", "", "", nil
+	}
+	switch body.Mode {
+	case "raw":
+		return c.expand(body.Raw), postmanRawLanguages[body.Options.Raw.Language], "", nil
+	case "urlencoded":
+		form := []string{}
+		for _, field := range body.URLEncoded {
+			if !field.Disabled {
+				form = append(form, url.QueryEscape(c.expand(field.Key))+"="+url.QueryEscape(c.expand(stringValue(field.Value))))
+			}
+		}
+		return strings.Join(form, "&"), "application/x-www-form-urlencoded", "", nil
+	case "formdata":
+		var buf bytes.Buffer
+		writer := multipart.NewWriter(&buf)
+		var files []string
+		for _, field := range body.FormData {
+			if field.Disabled {
+				continue
+			}
+			if field.Type == "file" {
+				src := stringValue(field.Src)
+				files = append(files, src)
+				if _, err := writer.CreateFormFile(c.expand(field.Key), path.Base(src)); err != nil {
+					return "", "", "", err
+				}
+				continue
+			}
+			if err := writer.WriteField(c.expand(field.Key), c.expand(stringValue(field.Value))); err != nil {
+				return "", "", "", err
+			}
+		}
+		if err := writer.Close(); err != nil {
+			return "", "", "", err
+		}
+		if len(files) > 0 {
+			comment = fmt.Sprintf("files %s not included", strings.Join(files, ", "))
+		}
+		return buf.String(), writer.FormDataContentType(), comment, nil
+	case "graphql":
+		query := map[string]any{"query": c.expand(body.GraphQL.Query)}
+		if variables := strings.TrimSpace(c.expand(body.GraphQL.Variables)); variables != "" {
+			query["variables"] = json.RawMessage(variables)
+		}
+		data, err := json.Marshal(query)
+		if err != nil {
+			return "", "", "", fmt.Errorf("graphql variables: %w", err)
+		}
+		return string(data), "application/json", "", nil
+	case "file":
+		return "", "", "file body not included", nil
+	}
+	return "", "", "", nil
+}
+
+// setting returns an auth setting with variables filled in
+func (c *postmanConverter) setting(settings []postmanKeyValue, key string) string {
+	for _, setting := range settings {
+		if setting.Key == key {
+			return c.expand(stringValue(setting.Value))
+		}
+	}
+	return ""
+}
+
+// expand fills in {{name}} placeholders with collection variables
+func (c *postmanConverter) expand(text string) string {
+	if !strings.Contains(text, "{{") {
+		return text
+	}
+	for name, value := range c.variables {
+		text = strings.ReplaceAll(text, "{{"+name+"}}", value)
+	}
+	return text
+}
+
+// UnmarshalJSON accepts a URL written as a string or as an object
+func (u *postmanURL) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &u.Raw)
+	}
+	var object struct {
+		Raw string `json:"raw"`
+	}
+	if err := json.Unmarshal(data, &object); err != nil {
+		return err
+	}
+	u.Raw = object.Raw
+	return nil
+}
+
+// stringValue returns a JSON value Postman may write as a string, number
+// or boolean as a string
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	}
+	data, _ := json.Marshal(value)
+	return string(data)
+}
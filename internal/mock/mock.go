@@ -0,0 +1,330 @@
+// Package mock serves recorded HAR responses over HTTP, so a captured session
+// can be reproduced offline
+package mock
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/diff"
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// Request parts a live request can be matched on
+const (
+	MatchMethod = "method"
+	MatchHost   = "host"
+	MatchPath   = "path"
+	MatchQuery  = "query"
+	MatchBody   = "body"
+)
+
+// DefaultMatch ignores the host, since requests arrive at the mock server's own
+var DefaultMatch = []string{MatchMethod, MatchPath, MatchQuery, MatchBody}
+
+// maxBodySize bounds the request body read for matching
+const maxBodySize = 10 << 20
+
+// skippedHeaders are recorded response headers that no longer describe the
+// body, which is served decoded, or the connection
+var skippedHeaders = map[string]bool{
+	"content-encoding":  true,
+	"content-length":    true,
+	"transfer-encoding": true,
+	"connection":        true,
+	"keep-alive":        true,
+}
+
+// Options control how requests are matched to recorded entries
+type Options struct {
+	Match []string // parts that must match, from DefaultMatch when empty
+	// Strict requires every part in Match to be equal. Otherwise method, host
+	// and path must be, and the entries closest on query and body are served
+	Strict bool
+	Cycle  bool // start again from the first of repeated entries, instead of repeating the last
+	CORS   bool // allow cross-origin requests from any origin
+}
+
+// ParseMatch reads a comma-separated list of request parts
+func ParseMatch(spec string) ([]string, error) {
+	var parts []string
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		switch part {
+		case "":
+			continue
+		case MatchMethod, MatchHost, MatchPath, MatchQuery, MatchBody:
+			parts = append(parts, part)
+		default:
+			return nil, fmt.Errorf("unknown match part %q (expected method, host, path, query or body)", part)
+		}
+	}
+	if len(parts) == 0 {
+		return nil, fmt.Errorf("match at least one of method, host, path, query or body")
+	}
+	return parts, nil
+}
+
+// Hit is one request the server answered
+type Hit struct {
+	Time     time.Time `json:"time"`
+	Method   string    `json:"method"`
+	URL      string    `json:"url"`   // path and query as requested
+	Entry    int       `json:"entry"` // captured entry served, -1 when unmatched
+	Status   int       `json:"status"`
+	Sequence int       `json:"sequence,omitempty"` // which of the matching entries was served, from 1
+	Matches  int       `json:"matches,omitempty"`  // how many entries matched
+}
+
+// Matched reports whether a recorded response was served
+func (h Hit) Matched() bool {
+	return h.Entry >= 0
+}
+
+// Server answers requests with the recorded responses of entries[indices]
+type Server struct {
+	entries []har.HAREntry
+	indices []int
+	opts    Options
+	match   map[string]bool
+	onHit   func(Hit)
+
+	mu     sync.Mutex
+	served map[string]int // responses served so far, by set of matching entries
+}
+
+// NewServer returns a server for the given entries. onHit, if set, is called
+// for every request from the goroutine serving it
+func NewServer(entries []har.HAREntry, indices []int, opts Options, onHit func(Hit)) *Server {
+	parts := opts.Match
+	if len(parts) == 0 {
+		parts = DefaultMatch
+	}
+	match := make(map[string]bool, len(parts))
+	for _, part := range parts {
+		match[part] = true
+	}
+	return &Server{entries: entries, indices: indices, opts: opts, match: match, onHit: onHit, served: make(map[string]int)}
+}
+
+// Reset starts every sequence of repeated entries from the first again
+func (s *Server) Reset() {
+	s.mu.Lock()
+	s.served = make(map[string]int)
+	s.mu.Unlock()
+}
+
+// ServeHTTP answers with the best matching recorded response, or 404
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+	hit := Hit{Time: time.Now(), Method: r.Method, URL: r.URL.RequestURI(), Entry: -1}
+	if s.opts.CORS {
+		allowCORS(w, r)
+	}
+
+	candidates := s.candidates(r, string(body))
+	switch {
+	case len(candidates) > 0:
+		hit.Entry, hit.Sequence = s.next(candidates)
+		hit.Matches = len(candidates)
+		hit.Status = WriteResponse(w, s.entries[hit.Entry].Response)
+	case s.opts.CORS && r.Method == http.MethodOptions:
+		// Preflight for a request that may well be recorded
+		w.WriteHeader(http.StatusNoContent)
+		hit.Status = http.StatusNoContent
+	default:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, "har-tui serve: no recorded response for %s %s\n", r.Method, hit.URL)
+		hit.Status = http.StatusNotFound
+	}
+
+	if s.onHit != nil {
+		s.onHit(hit)
+	}
+}
+
+// candidates returns the entries a request matches, in capture order. In
+// loose mode only those scoring highest on query and body are kept
+func (s *Server) candidates(r *http.Request, body string) []int {
+	var matches []int
+	best := -1.0
+	for _, idx := range s.indices {
+		request := s.entries[idx].Request
+		recordedURL, err := url.Parse(request.URL)
+		if err != nil {
+			continue
+		}
+		if s.match[MatchMethod] && !strings.EqualFold(request.Method, r.Method) {
+			continue
+		}
+		if s.match[MatchHost] && !strings.EqualFold(recordedURL.Host, r.Host) {
+			continue
+		}
+		if s.match[MatchPath] && recordedURL.EscapedPath() != r.URL.EscapedPath() {
+			continue
+		}
+
+		score := 0.0
+		if s.match[MatchQuery] {
+			similarity := querySimilarity(recordedURL.Query(), r.URL.Query())
+			if s.opts.Strict && similarity < 1 {
+				continue
+			}
+			score += similarity
+		}
+		if s.match[MatchBody] {
+			if sameBody(recordedBody(request), body) {
+				score++
+			} else if s.opts.Strict {
+				continue
+			}
+		}
+
+		switch {
+		case score > best:
+			matches, best = []int{idx}, score
+		case score == best:
+			matches = append(matches, idx)
+		}
+	}
+	return matches
+}
+
+// next picks which of the matching entries to serve: each in turn, then the
+// last again, or the first again when cycling
+func (s *Server) next(candidates []int) (entry, sequence int) {
+	keys := make([]string, len(candidates))
+	for i, idx := range candidates {
+		keys[i] = strconv.Itoa(idx)
+	}
+	key := strings.Join(keys, ",")
+
+	s.mu.Lock()
+	n := s.served[key]
+	s.served[key] = n + 1
+	s.mu.Unlock()
+
+	if n >= len(candidates) {
+		if s.opts.Cycle {
+			n %= len(candidates)
+		} else {
+			n = len(candidates) - 1
+		}
+	}
+	return candidates[n], n + 1
+}
+
+// querySimilarity is 1 for equal query strings, in any order, and otherwise
+// the share of name=value pairs the two have in common
+func querySimilarity(recorded, live url.Values) float64 {
+	pairs := func(values url.Values) map[string]int {
+		counts := make(map[string]int)
+		for name, list := range values {
+			for _, value := range list {
+				counts[name+"="+value]++
+			}
+		}
+		return counts
+	}
+	a, b := pairs(recorded), pairs(live)
+	common, total := 0, 0
+	for pair, n := range a {
+		common += min(n, b[pair])
+		total += n
+	}
+	for pair, n := range b {
+		total += n - min(n, a[pair])
+	}
+	if total == 0 {
+		return 1
+	}
+	return float64(common) / float64(total)
+}
+
+// recordedBody returns a request's body, rebuilding forms only captured as params
+func recordedBody(request har.HARRequest) string {
+	if request.PostData == nil {
+		return ""
+	}
+	if request.PostData.Text != "" || len(request.PostData.Params) == 0 {
+		return request.PostData.Text
+	}
+	form := url.Values{}
+	for _, param := range request.PostData.Params {
+		form.Add(param.Name, param.Value)
+	}
+	return form.Encode()
+}
+
+// sameBody compares bodies as JSON or forms when both are, otherwise as text
+func sameBody(recorded, live string) bool {
+	if recorded == live {
+		return true
+	}
+	if changes, ok := diff.JSON(recorded, live); ok {
+		return len(changes) == 0
+	}
+	if strings.Contains(recorded, "=") && strings.Contains(live, "=") {
+		a, errA := url.ParseQuery(recorded)
+		b, errB := url.ParseQuery(live)
+		return errA == nil && errB == nil && querySimilarity(a, b) == 1
+	}
+	return false
+}
+
+// WriteResponse writes a recorded response and returns the status sent.
+// Requests that failed when captured have no status and are answered 502
+func WriteResponse(w http.ResponseWriter, response har.HARResponse) int {
+	status := response.Status
+	if status < 100 || status > 999 {
+		status = http.StatusBadGateway
+	}
+	for _, header := range response.Headers {
+		name := strings.ToLower(header.Name)
+		if strings.HasPrefix(name, ":") || skippedHeaders[name] {
+			continue
+		}
+		if strings.HasPrefix(name, "access-control-") && w.Header().Get(header.Name) != "" {
+			continue // already allowed for this origin
+		}
+		w.Header().Add(header.Name, header.Value)
+	}
+	if w.Header().Get("Content-Type") == "" {
+		// Stop net/http sniffing a type the recording didn't have
+		w.Header()["Content-Type"] = nil
+		if response.Content.MimeType != "" {
+			w.Header().Set("Content-Type", response.Content.MimeType)
+		}
+	}
+	w.WriteHeader(status)
+	io.WriteString(w, har.DecodeBase64(response.Content.Text, response.Content.Encoding))
+	return status
+}
+
+// allowCORS lets pages on any origin call the server, with credentials
+func allowCORS(w http.ResponseWriter, r *http.Request) {
+	header := w.Header()
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		header.Set("Access-Control-Allow-Origin", "*")
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	header.Set("Access-Control-Allow-Credentials", "true")
+	header.Add("Vary", "Origin")
+	if r.Method == http.MethodOptions {
+		if method := r.Header.Get("Access-Control-Request-Method"); method != "" {
+			header.Set("Access-Control-Allow-Methods", method)
+		}
+		if headers := r.Header.Get("Access-Control-Request-Headers"); headers != "" {
+			header.Set("Access-Control-Allow-Headers", headers)
+		}
+	}
+}
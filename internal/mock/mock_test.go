@@ -0,0 +1,155 @@
+package mock
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func recorded(method, rawURL, body string, status int, responseBody string) har.HAREntry {
+	entry := har.HAREntry{
+		Request: har.HARRequest{Method: method, URL: rawURL},
+		Response: har.HARResponse{
+			Status:  status,
+			Headers: []har.HARHeader{{Name: "Content-Type", Value: "application/json"}, {Name: "Content-Encoding", Value: "gzip"}},
+			Content: har.HARContent{MimeType: "application/json", Text: responseBody},
+		},
+	}
+	if body != "" {
+		entry.Request.PostData = &har.HARPostData{MimeType: "application/json", Text: body}
+	}
+	return entry
+}
+
+var testEntries = []har.HAREntry{
+	recorded("GET", "https://api.example.com/items?page=1&size=10", "", 200, `{"page":1}`),
+	recorded("GET", "https://api.example.com/items?page=2&size=10", "", 200, `{"page":2}`),
+	recorded("GET", "https://api.example.com/status", "", 200, `{"state":"pending"}`),
+	recorded("GET", "https://api.example.com/status", "", 200, `{"state":"done"}`),
+	recorded("POST", "https://api.example.com/login", `{"user":"a","pass":"b"}`, 200, `{"token":"a"}`),
+	recorded("POST", "https://api.example.com/login", `{"user":"c","pass":"d"}`, 401, `{"error":"denied"}`),
+}
+
+func serve(t *testing.T, opts Options) (*httptest.Server, *[]Hit) {
+	t.Helper()
+	var mu sync.Mutex
+	var hits []Hit
+	indices := make([]int, len(testEntries))
+	for i := range indices {
+		indices[i] = i
+	}
+	server := httptest.NewServer(NewServer(testEntries, indices, opts, func(hit Hit) {
+		mu.Lock()
+		hits = append(hits, hit)
+		mu.Unlock()
+	}))
+	t.Cleanup(server.Close)
+	return server, &hits
+}
+
+func fetch(t *testing.T, server *httptest.Server, method, path, body string) (int, string, http.Header) {
+	t.Helper()
+	req, _ := http.NewRequest(method, server.URL+path, strings.NewReader(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data), resp.Header
+}
+
+func TestServeMatching(t *testing.T) {
+	server, hits := serve(t, Options{})
+
+	if _, body, header := fetch(t, server, "GET", "/items?size=10&page=2", ""); body != `{"page":2}` || header.Get("Content-Encoding") != "" {
+		t.Errorf("expected page 2 regardless of parameter order, got %q (%v)", body, header)
+	}
+	// Loose mode serves the closest query
+	if _, body, _ := fetch(t, server, "GET", "/items?page=1&size=10&_=123", ""); body != `{"page":1}` {
+		t.Errorf("expected the closest query to match, got %q", body)
+	}
+	if status, body, _ := fetch(t, server, "POST", "/login", `{"pass":"d", "user":"c"}`); status != 401 || body != `{"error":"denied"}` {
+		t.Errorf("expected the JSON body to pick the second login, got %d %q", status, body)
+	}
+	if status, _, _ := fetch(t, server, "GET", "/missing", ""); status != http.StatusNotFound {
+		t.Errorf("expected 404 for an unmatched request, got %d", status)
+	}
+
+	if len(*hits) != 4 || (*hits)[1].Entry != 0 || (*hits)[3].Matched() || (*hits)[3].URL != "/missing" {
+		t.Errorf("unexpected hits: %+v", *hits)
+	}
+}
+
+func TestServeStrict(t *testing.T) {
+	server, _ := serve(t, Options{Strict: true})
+	if status, _, _ := fetch(t, server, "GET", "/items?page=1&size=10&_=123", ""); status != http.StatusNotFound {
+		t.Errorf("expected an extra parameter not to match in strict mode, got %d", status)
+	}
+	if status, _, _ := fetch(t, server, "POST", "/login", `{"user":"x"}`); status != http.StatusNotFound {
+		t.Errorf("expected a different body not to match in strict mode, got %d", status)
+	}
+
+	server, _ = serve(t, Options{Strict: true, Match: []string{MatchMethod, MatchPath}})
+	if status, _, _ := fetch(t, server, "GET", "/items?page=9", ""); status != http.StatusOK {
+		t.Errorf("expected the query to be ignored when not matched on, got %d", status)
+	}
+}
+
+func TestServeSequence(t *testing.T) {
+	server, hits := serve(t, Options{})
+	var states []string
+	for i := 0; i < 3; i++ {
+		_, body, _ := fetch(t, server, "GET", "/status", "")
+		states = append(states, body)
+	}
+	if strings.Join(states, " ") != `{"state":"pending"} {"state":"done"} {"state":"done"}` {
+		t.Errorf("expected the responses in order, then the last again: %v", states)
+	}
+	if (*hits)[1].Sequence != 2 || (*hits)[1].Matches != 2 {
+		t.Errorf("unexpected sequence: %+v", (*hits)[1])
+	}
+
+	server, _ = serve(t, Options{Cycle: true})
+	states = nil
+	for i := 0; i < 3; i++ {
+		_, body, _ := fetch(t, server, "GET", "/status", "")
+		states = append(states, body)
+	}
+	if states[2] != `{"state":"pending"}` {
+		t.Errorf("expected cycling to start again: %v", states)
+	}
+}
+
+func TestServeCORS(t *testing.T) {
+	server, _ := serve(t, Options{CORS: true})
+	req, _ := http.NewRequest("OPTIONS", server.URL+"/anything", nil)
+	req.Header.Set("Origin", "http://localhost:3000")
+	req.Header.Set("Access-Control-Request-Method", "PUT")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent || resp.Header.Get("Access-Control-Allow-Origin") != "http://localhost:3000" ||
+		resp.Header.Get("Access-Control-Allow-Methods") != "PUT" {
+		t.Errorf("unexpected preflight response: %d %v", resp.StatusCode, resp.Header)
+	}
+}
+
+func TestParseMatch(t *testing.T) {
+	parts, err := ParseMatch("Method, path,,query")
+	if err != nil || strings.Join(parts, ",") != "method,path,query" {
+		t.Errorf("ParseMatch = %v, %v", parts, err)
+	}
+	for _, spec := range []string{"", "method,cookies"} {
+		if _, err := ParseMatch(spec); err == nil {
+			t.Errorf("ParseMatch(%q): expected an error", spec)
+		}
+	}
+}
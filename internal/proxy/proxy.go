@@ -0,0 +1,361 @@
+// Package proxy is a forward HTTP(S) proxy that records every exchange it
+// relays as a HAR entry. HTTPS is intercepted with certificates for each host
+// signed by a local CA, which clients have to trust
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/mock"
+	"github.com/cnharrison/har-tui/internal/replay"
+)
+
+// DefaultTimeout bounds one relayed exchange, including the response body
+const DefaultTimeout = 2 * time.Minute
+
+// Options control how requests are relayed
+type Options struct {
+	Timeout  time.Duration // zero means DefaultTimeout
+	Insecure bool          // skip verification of upstream certificates
+}
+
+// hopHeaders describe the client's connection to the proxy and are not
+// relayed or recorded
+var hopHeaders = map[string]bool{
+	"connection":          true,
+	"keep-alive":          true,
+	"proxy-connection":    true,
+	"proxy-authorization": true,
+	"proxy-authenticate":  true,
+	"te":                  true,
+	"trailer":             true,
+	"transfer-encoding":   true,
+	"upgrade":             true,
+}
+
+// Creator identifies har-tui as the creator of recorded sessions
+func Creator() har.HARCreator {
+	version := "devel"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		version = info.Main.Version
+	}
+	return har.HARCreator{Name: "har-tui", Version: version}
+}
+
+// Proxy relays requests to their servers and reports each exchange
+type Proxy struct {
+	ca      *CA
+	opts    Options
+	client  *http.Client
+	onEntry func(har.HAREntry)
+	onError func(error)
+	logger  *log.Logger
+
+	mu      sync.Mutex
+	server  *http.Server
+	tunnels map[*http.Server]bool // servers for intercepted CONNECT tunnels
+	closed  bool
+}
+
+// New returns a proxy signing intercepted hosts' certificates with ca.
+// onEntry is called for every exchange, including failed ones, and onError
+// for connections that could not be intercepted; both from the goroutine
+// serving the request
+func New(ca *CA, opts Options, onEntry func(har.HAREntry), onError func(error)) *Proxy {
+	client := replay.NewClient(replay.Options{Insecure: opts.Insecure})
+	// Reuse connections as the client would, so timings look like a capture
+	client.Transport.(*http.Transport).DisableKeepAlives = false
+	return &Proxy{
+		ca:      ca,
+		opts:    opts,
+		client:  client,
+		onEntry: onEntry,
+		onError: onError,
+		logger:  log.New(io.Discard, "", 0),
+		tunnels: make(map[*http.Server]bool),
+	}
+}
+
+// Serve accepts proxy connections on listener until Close is called, which
+// makes it return nil
+func (p *Proxy) Serve(listener net.Listener) error {
+	server := &http.Server{Handler: p, ErrorLog: p.logger}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		listener.Close()
+		return nil
+	}
+	p.server = server
+	p.mu.Unlock()
+
+	if err := server.Serve(listener); err != http.ErrServerClosed {
+		return err
+	}
+	return nil
+}
+
+// Close stops accepting connections and closes open ones, including tunnels
+func (p *Proxy) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closed = true
+	for tunnel := range p.tunnels {
+		tunnel.Close()
+	}
+	if p.server != nil {
+		return p.server.Close()
+	}
+	return nil
+}
+
+// ServeHTTP relays proxy requests. Requested directly, it serves the CA
+// certificate at /ca.pem so clients can install it
+func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodConnect:
+		p.intercept(w, r)
+	case r.URL.IsAbs():
+		p.forward(w, r)
+	case r.URL.Path == "/ca.pem":
+		w.Header().Set("Content-Type", "application/x-pem-file")
+		w.Header().Set("Content-Disposition", `attachment; filename="har-tui-ca.pem"`)
+		w.Write(p.ca.CertPEM())
+	default:
+		http.Error(w, "har-tui record: this is an HTTP(S) proxy; fetch /ca.pem for the certificate to trust", http.StatusBadRequest)
+	}
+}
+
+// intercept answers a CONNECT request and then terminates TLS itself, so the
+// requests inside the tunnel can be relayed and recorded
+func (p *Proxy) intercept(w http.ResponseWriter, r *http.Request) {
+	authority := r.Host
+	hostname := authority
+	if host, _, err := net.SplitHostPort(authority); err == nil {
+		hostname = host
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "har-tui record: cannot intercept this connection", http.StatusInternalServerError)
+		return
+	}
+	conn, _, err := hijacker.Hijack()
+	if err != nil {
+		p.fail(fmt.Errorf("CONNECT %s: %w", authority, err))
+		return
+	}
+	if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		conn.Close()
+		return
+	}
+
+	tlsConn := tls.Server(conn, &tls.Config{
+		GetCertificate: func(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+			name := hello.ServerName
+			if name == "" {
+				name = hostname
+			}
+			return p.ca.Certificate(name)
+		},
+		// HTTP/1.1 only, so requests can be relayed one at a time
+		NextProtos: []string{"http/1.1"},
+	})
+	if err := tlsConn.Handshake(); err != nil {
+		p.fail(fmt.Errorf("CONNECT %s: TLS handshake failed (is the har-tui CA trusted?): %w", authority, err))
+		tlsConn.Close()
+		return
+	}
+
+	listener := newConnListener(tlsConn)
+	tunnel := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			req.URL.Scheme = "https"
+			req.URL.Host = req.Host
+			if req.URL.Host == "" {
+				req.URL.Host = authority
+			}
+			p.forward(w, req)
+		}),
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			if state == http.StateClosed || state == http.StateHijacked {
+				listener.Close()
+			}
+		},
+		ErrorLog: p.logger,
+	}
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		tlsConn.Close()
+		return
+	}
+	p.tunnels[tunnel] = true
+	p.mu.Unlock()
+
+	tunnel.Serve(listener)
+	tlsConn.Close() // in case the proxy closed before the tunnel was served
+
+	p.mu.Lock()
+	delete(p.tunnels, tunnel)
+	p.mu.Unlock()
+}
+
+// forward relays one request and records the exchange
+func (p *Proxy) forward(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		p.fail(fmt.Errorf("%s %s: reading request: %w", r.Method, r.URL, err))
+		return
+	}
+	request := newRequest(r, body)
+
+	timeout := p.opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	outgoing := r.Clone(ctx)
+	outgoing.RequestURI = ""
+	outgoing.Body = io.NopCloser(bytes.NewReader(body))
+	outgoing.ContentLength = int64(len(body))
+	if len(body) == 0 {
+		outgoing.Body = nil
+	}
+	removeHopHeaders(outgoing.Header)
+	// Left to the transport, so responses are recorded and relayed decoded
+	outgoing.Header.Del("Accept-Encoding")
+
+	entry, err := replay.Exchange(p.client, outgoing, request)
+	if err != nil {
+		entry = failedEntry(request, start, err)
+		http.Error(w, fmt.Sprintf("har-tui record: %v", err), http.StatusBadGateway)
+	} else {
+		mock.WriteResponse(w, entry.Response)
+	}
+	if p.onEntry != nil {
+		p.onEntry(entry)
+	}
+}
+
+// fail reports a connection that could not be relayed
+func (p *Proxy) fail(err error) {
+	if p.onError != nil {
+		p.onError(err)
+	}
+}
+
+// newRequest records a request as the client sent it, without the headers
+// meant for the proxy
+func newRequest(r *http.Request, body []byte) har.HARRequest {
+	header := r.Header.Clone()
+	removeHopHeaders(header)
+	request := har.HARRequest{
+		Method:      r.Method,
+		URL:         r.URL.String(),
+		HTTPVersion: r.Proto,
+		Headers:     []har.HARHeader{{Name: "Host", Value: r.Host}},
+		Cookies:     []har.HARCookie{},
+		QueryString: replay.QueryString(r.URL.RawQuery),
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			request.Headers = append(request.Headers, har.HARHeader{Name: name, Value: value})
+		}
+	}
+	for _, cookie := range r.Cookies() {
+		request.Cookies = append(request.Cookies, har.HARCookie{Name: cookie.Name, Value: cookie.Value})
+	}
+	if len(body) > 0 {
+		request.PostData = replay.PostData(r.Header.Get("Content-Type"), body)
+	}
+	return request
+}
+
+// removeHopHeaders drops connection-level headers, including any the
+// Connection header names
+func removeHopHeaders(header http.Header) {
+	for _, value := range header.Values("Connection") {
+		for _, name := range strings.Split(value, ",") {
+			header.Del(strings.TrimSpace(name))
+		}
+	}
+	for name := range header {
+		if hopHeaders[strings.ToLower(name)] {
+			header.Del(name)
+		}
+	}
+}
+
+// failedEntry records a request that got no response, like browsers do,
+// with status 0 and the error as the response comment
+func failedEntry(request har.HARRequest, start time.Time, err error) har.HAREntry {
+	elapsed := float64(time.Since(start).Microseconds()) / 1000
+	return har.HAREntry{
+		StartedDateTime: har.FormatHARDateTime(start),
+		Time:            elapsed,
+		Request:         request,
+		Response: har.HARResponse{
+			Headers:     []har.HARHeader{},
+			Cookies:     []har.HARCookie{},
+			HeadersSize: -1,
+			BodySize:    -1,
+			Comment:     err.Error(),
+		},
+		Timings: har.HARTimings{Blocked: -1, DNS: -1, Connect: -1, Send: 0, Wait: elapsed, Receive: 0, SSL: -1},
+	}
+}
+
+// connListener hands a single connection to an http.Server, then blocks
+// until it is closed
+type connListener struct {
+	conn net.Conn
+	once sync.Once
+	done chan struct{}
+	stop sync.Once
+}
+
+func newConnListener(conn net.Conn) *connListener {
+	return &connListener{conn: conn, done: make(chan struct{})}
+}
+
+func (l *connListener) Accept() (net.Conn, error) {
+	var conn net.Conn
+	l.once.Do(func() { conn = l.conn })
+	if conn != nil {
+		return conn, nil
+	}
+	<-l.done
+	return nil, net.ErrClosed
+}
+
+func (l *connListener) Close() error {
+	l.stop.Do(func() { close(l.done) })
+	return nil
+}
+
+func (l *connListener) Addr() net.Addr {
+	return l.conn.LocalAddr()
+}
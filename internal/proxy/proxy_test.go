@@ -0,0 +1,182 @@
+package proxy
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func startProxy(t *testing.T, opts Options) (*Proxy, *CA, string, func() []har.HAREntry) {
+	t.Helper()
+	ca, err := NewCA()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mu sync.Mutex
+	var entries []har.HAREntry
+	p := New(ca, opts, func(entry har.HAREntry) {
+		mu.Lock()
+		entries = append(entries, entry)
+		mu.Unlock()
+	}, nil)
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go p.Serve(listener)
+	t.Cleanup(func() { p.Close() })
+	return p, ca, "http://" + listener.Addr().String(), func() []har.HAREntry {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]har.HAREntry(nil), entries...)
+	}
+}
+
+func proxyClient(t *testing.T, proxyURL string, roots *x509.CertPool) *http.Client {
+	t.Helper()
+	u, _ := url.Parse(proxyURL)
+	return &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(u),
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}}
+}
+
+func TestProxyHTTP(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Proxy-Authorization") != "" {
+			t.Error("proxy credentials were relayed")
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc"})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"echo":"`+string(body)+`"}`)
+	}))
+	defer backend.Close()
+	_, _, proxyURL, entries := startProxy(t, Options{})
+
+	req, _ := http.NewRequest("POST", backend.URL+"/items?b=2&a=1", strings.NewReader("hello"))
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("Proxy-Authorization", "Basic eDp5")
+	resp, err := proxyClient(t, proxyURL, nil).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || string(body) != `{"echo":"hello"}` {
+		t.Fatalf("unexpected relayed response: %d %q", resp.StatusCode, body)
+	}
+
+	got := entries()
+	if len(got) != 1 {
+		t.Fatalf("expected one entry, got %d", len(got))
+	}
+	entry := got[0]
+	if entry.Request.Method != "POST" || entry.Request.URL != backend.URL+"/items?b=2&a=1" {
+		t.Errorf("unexpected request: %s %s", entry.Request.Method, entry.Request.URL)
+	}
+	if len(entry.Request.QueryString) != 2 || entry.Request.QueryString[0].Name != "b" {
+		t.Errorf("expected the query in order, got %+v", entry.Request.QueryString)
+	}
+	if entry.Request.PostData == nil || entry.Request.PostData.Text != "hello" {
+		t.Errorf("unexpected post data: %+v", entry.Request.PostData)
+	}
+	for _, header := range entry.Request.Headers {
+		if strings.HasPrefix(strings.ToLower(header.Name), "proxy-") {
+			t.Errorf("recorded proxy header %s", header.Name)
+		}
+	}
+	if entry.Response.Status != http.StatusCreated || entry.Response.Content.Text != `{"echo":"hello"}` ||
+		len(entry.Response.Cookies) != 1 || entry.StartedDateTime == "" || entry.Time <= 0 {
+		t.Errorf("unexpected recorded response: %+v", entry)
+	}
+}
+
+func TestProxyHTTPS(t *testing.T) {
+	backend := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "secure "+r.URL.Path)
+	}))
+	defer backend.Close()
+	_, ca, proxyURL, entries := startProxy(t, Options{Insecure: true})
+
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.CertPEM())
+	resp, err := proxyClient(t, proxyURL, roots).Get(backend.URL + "/private")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "secure /private" {
+		t.Fatalf("unexpected relayed body %q", body)
+	}
+	got := entries()
+	if len(got) != 1 || got[0].Request.URL != backend.URL+"/private" || got[0].Response.Content.Text != "secure /private" {
+		t.Fatalf("unexpected entries: %+v", got)
+	}
+
+	// Without trusting the CA the interception is refused by the client
+	if _, err := proxyClient(t, proxyURL, x509.NewCertPool()).Get(backend.URL + "/private"); err == nil {
+		t.Error("expected an untrusted certificate error")
+	}
+}
+
+func TestProxyFailure(t *testing.T) {
+	listener, _ := net.Listen("tcp", "127.0.0.1:0")
+	closedURL := "http://" + listener.Addr().String() + "/"
+	listener.Close()
+	_, _, proxyURL, entries := startProxy(t, Options{})
+
+	resp, err := proxyClient(t, proxyURL, nil).Get(closedURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected 502, got %d", resp.StatusCode)
+	}
+	got := entries()
+	if len(got) != 1 || got[0].Response.Status != 0 || got[0].Response.Comment == "" {
+		t.Errorf("expected a failed entry, got %+v", got)
+	}
+}
+
+func TestLoadOrCreateCA(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "config")
+	ca, err := LoadOrCreateCA(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(filepath.Join(dir, KeyFile)); err != nil || info.Mode().Perm() != 0600 {
+		t.Errorf("expected a private key file, got %v, %v", info, err)
+	}
+	again, err := LoadOrCreateCA(dir)
+	if err != nil || string(again.CertPEM()) != string(ca.CertPEM()) {
+		t.Fatalf("expected the saved CA to be reused, got %v", err)
+	}
+
+	leaf, err := again.Certificate("example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots := x509.NewCertPool()
+	roots.AppendCertsFromPEM(ca.CertPEM())
+	if _, err := leaf.Leaf.Verify(x509.VerifyOptions{DNSName: "example.com", Roots: roots}); err != nil {
+		t.Errorf("leaf does not verify against the CA: %v", err)
+	}
+	if cached, _ := again.Certificate("example.com"); cached != leaf {
+		t.Error("expected the leaf certificate to be cached")
+	}
+}
@@ -0,0 +1,133 @@
+package replay
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// BatchOptions control a batch replay
+type BatchOptions struct {
+	Options
+	Concurrency int     // requests in flight at once; 0 or 1 replays strictly in order
+	Rate        float64 // most requests started per second; 0 means no limit
+	Extractors  []Extractor
+	Verify      VerifyOptions
+}
+
+// BatchResult is the outcome of replaying one entry of a batch
+type BatchResult struct {
+	Index       int          // index of the captured entry
+	Replayed    har.HAREntry // zero when Err is set
+	Err         error
+	Report      Report
+	Substituted []string // variables substituted into the request
+	Extracted   []string // variables set from the response
+}
+
+// Passed reports whether the entry replayed and its response matched the capture
+func (r BatchResult) Passed() bool {
+	return r.Err == nil && r.Report.Pass
+}
+
+// RunBatch replays entries[indices] as a sequence. Values extracted from each
+// response are substituted into the requests after it (see Variables.Apply).
+// With concurrency above one, requests overlap, except that an entry whose
+// capture provides a variable finishes before any later entry starts, so
+// chained values are always fresh. done is called as each result arrives,
+// from the goroutine that ran it; results are returned in entry order
+func RunBatch(ctx context.Context, entries []har.HAREntry, indices []int, opts BatchOptions, done func(BatchResult)) []BatchResult {
+	concurrency := opts.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	var interval time.Duration
+	if opts.Rate > 0 {
+		interval = time.Duration(float64(time.Second) / opts.Rate)
+	}
+
+	// Extracted values are expected to change, so they don't fail verification
+	verify := VerifyOptions{
+		IgnoreHeaders: append([]string(nil), opts.Verify.IgnoreHeaders...),
+		IgnorePaths:   append([]string(nil), opts.Verify.IgnorePaths...),
+	}
+	for _, extractor := range opts.Extractors {
+		switch extractor.Source {
+		case FromJSON:
+			verify.IgnorePaths = append(verify.IgnorePaths, extractor.Key)
+		case FromHeader:
+			verify.IgnoreHeaders = append(verify.IgnoreHeaders, extractor.Key)
+		}
+	}
+
+	client := NewClient(opts.Options)
+	vars := NewVariables()
+	results := make([]BatchResult, len(indices))
+
+	var (
+		mu       sync.Mutex // guards vars
+		inFlight sync.WaitGroup
+		slots    = make(chan struct{}, concurrency)
+		next     time.Time
+	)
+	for i, idx := range indices {
+		if ctx.Err() != nil {
+			results[i] = BatchResult{Index: idx, Err: ctx.Err()}
+			continue
+		}
+		if interval > 0 {
+			if wait := time.Until(next); wait > 0 {
+				time.Sleep(wait)
+			}
+			next = time.Now().Add(interval)
+		}
+
+		slots <- struct{}{}
+		inFlight.Add(1)
+		recorded := entries[idx]
+		mu.Lock()
+		request, substituted := vars.Apply(recorded)
+		mu.Unlock()
+
+		run := func(i, idx int) {
+			defer func() {
+				<-slots
+				inFlight.Done()
+			}()
+			result := BatchResult{Index: idx, Substituted: substituted}
+			result.Replayed, result.Err = SendWith(ctx, client, request, opts.Options)
+			if result.Err == nil {
+				result.Report = Verify(recorded, result.Replayed, verify)
+				mu.Lock()
+				result.Extracted = vars.Update(opts.Extractors, recorded, result.Replayed)
+				mu.Unlock()
+			}
+			results[i] = result
+			if done != nil {
+				done(result)
+			}
+		}
+
+		if concurrency == 1 || providesVariables(opts.Extractors, recorded) {
+			// In order, or because later requests may need what this one returns
+			run(i, idx)
+			inFlight.Wait()
+		} else {
+			go run(i, idx)
+		}
+	}
+	inFlight.Wait()
+	return results
+}
+
+// providesVariables reports whether any extractor finds a value in a captured response
+func providesVariables(extractors []Extractor, entry har.HAREntry) bool {
+	for _, extractor := range extractors {
+		if _, ok := extractor.Extract(entry); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package replay
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func TestParseExtractor(t *testing.T) {
+	extractor, err := ParseExtractor("token=json:data.items[0].token")
+	if err != nil || extractor.Name != "token" || extractor.Source != FromJSON || extractor.Key != "data.items[0].token" {
+		t.Errorf("unexpected extractor %+v (%v)", extractor, err)
+	}
+	for _, spec := range []string{"token", "token=json", "token=body:x", "=header:X"} {
+		if _, err := ParseExtractor(spec); err == nil {
+			t.Errorf("expected %q to be rejected", spec)
+		}
+	}
+}
+
+func TestVariablesApply(t *testing.T) {
+	vars := NewVariables()
+	vars.Set("token", "fresh-token", "stale-token")
+	vars.Set("id", "42", "7")
+
+	entry := har.HAREntry{}
+	entry.Request.URL = "https://example.com/items/{{id}}?t=stale-token&page=7"
+	entry.Request.Headers = []har.HARHeader{{Name: "Authorization", Value: "Bearer stale-token"}}
+	entry.Request.PostData = &har.HARPostData{Text: `{"id": 7}`}
+
+	applied, names := vars.Apply(entry)
+	if applied.Request.URL != "https://example.com/items/42?t=fresh-token&page=7" {
+		t.Errorf("unexpected URL %q", applied.Request.URL)
+	}
+	if applied.Request.Headers[0].Value != "Bearer fresh-token" || entry.Request.Headers[0].Value != "Bearer stale-token" {
+		t.Errorf("expected the header to change on a copy only, got %q / %q", applied.Request.Headers[0].Value, entry.Request.Headers[0].Value)
+	}
+	if applied.Request.PostData.Text != `{"id": 7}` {
+		t.Errorf("short captured values should not be replaced, got %q", applied.Request.PostData.Text)
+	}
+	if len(names) != 2 || names[0] != "id" || names[1] != "token" {
+		t.Errorf("unexpected substituted names %v", names)
+	}
+}
+
+func TestRunBatchChainsValues(t *testing.T) {
+	var logins int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/login":
+			atomic.AddInt32(&logins, 1)
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"token": "live-token-123"}`))
+		case "/me":
+			if r.Header.Get("Authorization") != "Bearer live-token-123" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer server.Close()
+
+	login := har.HAREntry{}
+	login.Request.Method = "POST"
+	login.Request.URL = server.URL + "/login"
+	login.Response.Status = 200
+	login.Response.Headers = []har.HARHeader{{Name: "Content-Type", Value: "application/json"}}
+	login.Response.Content.Text = `{"token": "captured-token"}`
+
+	me := har.HAREntry{}
+	me.Request.Method = "GET"
+	me.Request.URL = server.URL + "/me"
+	me.Request.Headers = []har.HARHeader{{Name: "Authorization", Value: "Bearer captured-token"}}
+	me.Response.Status = 200
+	me.Response.Content.Text = "ok"
+
+	extractor, _ := ParseExtractor("token=json:token")
+	opts := BatchOptions{Concurrency: 4, Rate: 1000, Extractors: []Extractor{extractor}, Verify: DefaultVerifyOptions()}
+	opts.Verify.IgnoreHeaders = append(opts.Verify.IgnoreHeaders, "content-type")
+
+	var reported int32
+	results := RunBatch(context.Background(), []har.HAREntry{login, me}, []int{0, 1}, opts, func(BatchResult) {
+		atomic.AddInt32(&reported, 1)
+	})
+	if len(results) != 2 || reported != 2 || logins != 1 {
+		t.Fatalf("unexpected run: %d results, %d reported, %d logins", len(results), reported, logins)
+	}
+	if !results[0].Passed() || len(results[0].Extracted) != 1 {
+		t.Errorf("login: %+v", results[0])
+	}
+	if !results[1].Passed() || len(results[1].Substituted) != 1 || results[1].Replayed.Response.Status != 200 {
+		t.Errorf("expected /me to use the fresh token: %+v", results[1])
+	}
+}
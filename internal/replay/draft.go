@@ -0,0 +1,171 @@
+package replay
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// Draft is an editable copy of a recorded request, as shown in the request composer
+type Draft struct {
+	Method   string
+	URL      string // without the query string, which is kept in Query
+	Query    []har.HARQueryParam
+	Headers  []har.HARHeader
+	Body     string
+	MimeType string
+}
+
+// NewDraft copies a request into a draft
+func NewDraft(request har.HARRequest) Draft {
+	draft := Draft{
+		Method:  request.Method,
+		URL:     request.URL,
+		Headers: append([]har.HARHeader(nil), request.Headers...),
+		Body:    requestBody(request),
+	}
+	if base, rawQuery, found := strings.Cut(request.URL, "?"); found {
+		draft.URL = base
+		draft.Query = QueryString(rawQuery)
+	}
+	if request.PostData != nil {
+		draft.MimeType = request.PostData.MimeType
+	}
+	return draft
+}
+
+// QueryString splits a raw query into unescaped HAR parameters, keeping their order
+func QueryString(rawQuery string) []har.HARQueryParam {
+	rawQuery, _, _ = strings.Cut(rawQuery, "#")
+	params := []har.HARQueryParam{}
+	for _, pair := range strings.Split(rawQuery, "&") {
+		if pair == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(pair, "=")
+		if unescaped, err := url.QueryUnescape(name); err == nil {
+			name = unescaped
+		}
+		if unescaped, err := url.QueryUnescape(value); err == nil {
+			value = unescaped
+		}
+		params = append(params, har.HARQueryParam{Name: name, Value: value})
+	}
+	return params
+}
+
+// FullURL returns the draft URL with its query parameters encoded
+func (d Draft) FullURL() string {
+	if len(d.Query) == 0 {
+		return d.URL
+	}
+	pairs := make([]string, len(d.Query))
+	for i, param := range d.Query {
+		pairs[i] = url.QueryEscape(param.Name) + "=" + url.QueryEscape(param.Value)
+	}
+	return d.URL + "?" + strings.Join(pairs, "&")
+}
+
+// Request turns the draft back into a HAR request. Cookies are taken from
+// the Cookie header, so that removing the header removes them
+func (d Draft) Request() (har.HARRequest, error) {
+	method := strings.ToUpper(strings.TrimSpace(d.Method))
+	if method == "" {
+		return har.HARRequest{}, fmt.Errorf("method is empty")
+	}
+	parsed, err := url.Parse(strings.TrimSpace(d.URL))
+	if err != nil {
+		return har.HARRequest{}, fmt.Errorf("invalid URL: %w", err)
+	}
+	if parsed.Scheme == "" || parsed.Host == "" {
+		return har.HARRequest{}, fmt.Errorf("URL needs a scheme and host, e.g. https://example.com/")
+	}
+	d.URL = parsed.String()
+
+	request := har.HARRequest{
+		Method:      method,
+		URL:         d.FullURL(),
+		HTTPVersion: "HTTP/1.1",
+		Headers:     append([]har.HARHeader{}, d.Headers...),
+		Cookies:     []har.HARCookie{},
+		QueryString: append([]har.HARQueryParam{}, d.Query...),
+		HeadersSize: -1,
+		BodySize:    len(d.Body),
+	}
+	for _, header := range d.Headers {
+		if !strings.EqualFold(header.Name, "cookie") {
+			continue
+		}
+		cookies, err := http.ParseCookie(header.Value)
+		if err != nil {
+			return har.HARRequest{}, fmt.Errorf("invalid Cookie header: %w", err)
+		}
+		for _, cookie := range cookies {
+			request.Cookies = append(request.Cookies, har.HARCookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	if d.Body != "" || d.MimeType != "" {
+		request.PostData = &har.HARPostData{MimeType: d.MimeType, Text: d.Body}
+	}
+	return request, nil
+}
+
+// FormatHeaders writes headers one "Name: Value" per line, for editing
+func FormatHeaders(headers []har.HARHeader) string {
+	lines := make([]string, len(headers))
+	for i, header := range headers {
+		lines[i] = header.Name + ": " + header.Value
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseHeaders reads headers written by FormatHeaders, ignoring blank lines
+func ParseHeaders(text string) ([]har.HARHeader, error) {
+	var headers []har.HARHeader
+	for i, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		// HTTP/2 pseudo-headers such as ":authority" start with the separator
+		start := 0
+		if strings.HasPrefix(line, ":") {
+			start = 1
+		}
+		colon := strings.Index(line[start:], ":")
+		if colon < 0 {
+			return nil, fmt.Errorf("header line %d: expected \"Name: Value\"", i+1)
+		}
+		colon += start
+		name := strings.TrimSpace(line[:colon])
+		if name == "" {
+			return nil, fmt.Errorf("header line %d: missing name", i+1)
+		}
+		headers = append(headers, har.HARHeader{Name: name, Value: strings.TrimSpace(line[colon+1:])})
+	}
+	return headers, nil
+}
+
+// FormatQuery writes query parameters one "name=value" per line, unescaped
+func FormatQuery(params []har.HARQueryParam) string {
+	lines := make([]string, len(params))
+	for i, param := range params {
+		lines[i] = param.Name + "=" + param.Value
+	}
+	return strings.Join(lines, "\n")
+}
+
+// ParseQuery reads query parameters written by FormatQuery, ignoring blank lines
+func ParseQuery(text string) []har.HARQueryParam {
+	var params []har.HARQueryParam
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		name, value, _ := strings.Cut(line, "=")
+		params = append(params, har.HARQueryParam{Name: strings.TrimSpace(name), Value: value})
+	}
+	return params
+}
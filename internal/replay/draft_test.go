@@ -0,0 +1,70 @@
+package replay
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func TestDraftRoundTrip(t *testing.T) {
+	request := har.HARRequest{
+		Method: "POST",
+		URL:    "https://example.com/search?q=a%20b&page=2",
+		Headers: []har.HARHeader{
+			{Name: ":authority", Value: "example.com"},
+			{Name: "Cookie", Value: "session=abc; theme=dark"},
+		},
+		PostData: &har.HARPostData{MimeType: "application/json", Text: `{"x":1}`},
+	}
+
+	draft := NewDraft(request)
+	if draft.URL != "https://example.com/search" || FormatQuery(draft.Query) != "q=a b\npage=2" {
+		t.Fatalf("unexpected draft URL %q and query %q", draft.URL, FormatQuery(draft.Query))
+	}
+
+	headers, err := ParseHeaders(FormatHeaders(draft.Headers) + "\n\nX-Added: 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(headers) != 3 || headers[0].Name != ":authority" || headers[2].Value != "1" {
+		t.Errorf("unexpected headers: %+v", headers)
+	}
+	draft.Headers = headers
+	draft.Query = ParseQuery("q=c\n")
+	draft.Method = "put"
+
+	edited, err := draft.Request()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if edited.Method != "PUT" || edited.URL != "https://example.com/search?q=c" {
+		t.Errorf("unexpected request line: %s %s", edited.Method, edited.URL)
+	}
+	wantCookies := []string{"session", "theme"}
+	var cookies []string
+	for _, cookie := range edited.Cookies {
+		cookies = append(cookies, cookie.Name)
+	}
+	if !reflect.DeepEqual(cookies, wantCookies) {
+		t.Errorf("expected cookies %v, got %v", wantCookies, cookies)
+	}
+	if edited.PostData == nil || edited.PostData.Text != `{"x":1}` || edited.PostData.MimeType != "application/json" {
+		t.Errorf("unexpected post data: %+v", edited.PostData)
+	}
+}
+
+func TestDraftRequestValidation(t *testing.T) {
+	for _, draft := range []Draft{
+		{Method: "", URL: "https://example.com/"},
+		{Method: "GET", URL: "/relative"},
+		{Method: "GET", URL: "https://example.com/", Headers: []har.HARHeader{{Name: "Cookie", Value: "bad cookie"}}},
+	} {
+		if _, err := draft.Request(); err == nil {
+			t.Errorf("expected %+v to be rejected", draft)
+		}
+	}
+	if _, err := ParseHeaders("no separator"); err == nil {
+		t.Error("expected a header line without a colon to be rejected")
+	}
+}
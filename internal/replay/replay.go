@@ -0,0 +1,250 @@
+// Package replay sends HAR requests again with net/http and records the
+// exchange as a new HAR entry
+package replay
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// DefaultTimeout bounds a whole replay, including reading the response body
+const DefaultTimeout = 30 * time.Second
+
+// Options control how requests are replayed
+type Options struct {
+	Timeout  time.Duration // zero means DefaultTimeout
+	Insecure bool          // skip TLS certificate verification
+}
+
+// skippedHeaders are recorded headers net/http sets itself or that only make
+// sense for the original connection. Accept-Encoding is left to the transport
+// so that gzip responses are decoded before they are recorded
+var skippedHeaders = map[string]bool{
+	"host":              true,
+	"content-length":    true,
+	"connection":        true,
+	"keep-alive":        true,
+	"proxy-connection":  true,
+	"transfer-encoding": true,
+	"upgrade":           true,
+	"te":                true,
+	"accept-encoding":   true,
+}
+
+// NewRequest builds the HTTP request a HAR entry describes
+func NewRequest(ctx context.Context, entry har.HAREntry) (*http.Request, error) {
+	var body io.Reader
+	if text := requestBody(entry.Request); text != "" {
+		body = strings.NewReader(text)
+	}
+	req, err := http.NewRequestWithContext(ctx, entry.Request.Method, entry.Request.URL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, header := range entry.Request.Headers {
+		name := strings.ToLower(header.Name)
+		switch {
+		case name == "host" || name == ":authority":
+			req.Host = header.Value
+		case strings.HasPrefix(name, ":") || skippedHeaders[name]:
+			// HTTP/2 pseudo-headers and connection-level headers
+		default:
+			req.Header.Add(header.Name, header.Value)
+		}
+	}
+	if req.Header.Get("Cookie") == "" {
+		for _, cookie := range entry.Request.Cookies {
+			req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+		}
+	}
+	if entry.Request.PostData != nil && entry.Request.PostData.MimeType != "" && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", entry.Request.PostData.MimeType)
+	}
+	return req, nil
+}
+
+// requestBody returns the recorded body, rebuilding form bodies that were
+// only captured as params
+func requestBody(request har.HARRequest) string {
+	postData := request.PostData
+	if postData == nil {
+		return ""
+	}
+	if postData.Text != "" || len(postData.Params) == 0 {
+		return postData.Text
+	}
+	form := url.Values{}
+	for _, param := range postData.Params {
+		form.Add(param.Name, param.Value)
+	}
+	return form.Encode()
+}
+
+// NewClient returns the client replays are sent with. Redirects are not
+// followed so that each replay records exactly one exchange, like a capture
+func NewClient(opts Options) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts.Insecure {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
+	}
+	// Fresh connections, so that DNS, connect and TLS timings are measured
+	transport.DisableKeepAlives = true
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// Send replays a HAR entry and records the result, with timings, as a new entry
+func Send(ctx context.Context, entry har.HAREntry, opts Options) (har.HAREntry, error) {
+	return SendWith(ctx, NewClient(opts), entry, opts)
+}
+
+// SendWith replays a HAR entry using client, which lets callers share one
+// client across many replays
+func SendWith(ctx context.Context, client *http.Client, entry har.HAREntry, opts Options) (har.HAREntry, error) {
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := NewRequest(ctx, entry)
+	if err != nil {
+		return har.HAREntry{}, err
+	}
+	recorded, err := Exchange(client, req, entry.Request)
+	recorded.ResourceType = entry.ResourceType
+	return recorded, err
+}
+
+// Exchange sends req and records the exchange, with timings, as an entry
+// whose request is request. The request's context bounds the whole exchange
+func Exchange(client *http.Client, req *http.Request, request har.HARRequest) (har.HAREntry, error) {
+	trace := newTimer()
+	req = req.WithContext(trace.withTrace(req.Context()))
+	resp, err := client.Do(req)
+	if err != nil {
+		return har.HAREntry{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return har.HAREntry{}, fmt.Errorf("reading response: %w", err)
+	}
+	end := time.Now()
+
+	recorded := har.HAREntry{
+		StartedDateTime: har.FormatHARDateTime(trace.start),
+		Request:         request,
+		Response:        newResponse(resp, body),
+		ServerIPAddress: trace.serverIP(),
+		Timings:         trace.timings(end),
+	}
+	recorded.Time = totalTime(recorded.Timings)
+	return recorded, nil
+}
+
+// newResponse records an HTTP response, base64-encoding bodies that aren't text
+func newResponse(resp *http.Response, body []byte) har.HARResponse {
+	response := har.HARResponse{
+		Status:      resp.StatusCode,
+		StatusText:  strings.TrimSpace(strings.TrimPrefix(resp.Status, fmt.Sprint(resp.StatusCode))),
+		HTTPVersion: resp.Proto,
+		Headers:     headers(resp.Header),
+		Cookies:     []har.HARCookie{},
+		RedirectURL: resp.Header.Get("Location"),
+		HeadersSize: -1,
+		BodySize:    len(body),
+	}
+	if resp.Uncompressed {
+		response.BodySize = -1 // the transferred size is unknown once decoded
+	}
+	for _, cookie := range resp.Cookies() {
+		response.Cookies = append(response.Cookies, ResponseCookie(cookie))
+	}
+
+	response.Content = har.HARContent{
+		Size:     len(body),
+		MimeType: resp.Header.Get("Content-Type"),
+	}
+	if utf8.Valid(body) {
+		response.Content.Text = string(body)
+	} else {
+		response.Content.Text = base64.StdEncoding.EncodeToString(body)
+		response.Content.Encoding = "base64"
+	}
+	return response
+}
+
+// ResponseCookie records a cookie set by a response
+func ResponseCookie(cookie *http.Cookie) har.HARCookie {
+	c := har.HARCookie{
+		Name:     cookie.Name,
+		Value:    cookie.Value,
+		Domain:   cookie.Domain,
+		Path:     cookie.Path,
+		Secure:   cookie.Secure,
+		HTTPOnly: cookie.HttpOnly,
+	}
+	if !cookie.Expires.IsZero() {
+		c.Expires = har.FormatHARDateTime(cookie.Expires)
+	}
+	return c
+}
+
+// PostData records a request body, noting the size of bodies that aren't
+// text instead of keeping them
+func PostData(mimeType string, body []byte) *har.HARPostData {
+	postData := &har.HARPostData{MimeType: mimeType}
+	if utf8.Valid(body) {
+		postData.Text = string(body)
+	} else {
+		postData.Comment = fmt.Sprintf("binary body of %d bytes not recorded", len(body))
+	}
+	return postData
+}
+
+// headers converts an http.Header to HAR headers, sorted by name
+func headers(header http.Header) []har.HARHeader {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := []har.HARHeader{}
+	for _, name := range names {
+		for _, value := range header[name] {
+			result = append(result, har.HARHeader{Name: name, Value: value})
+		}
+	}
+	return result
+}
+
+// totalTime sums the phases as HAR defines entry time; ssl is part of connect
+func totalTime(timings har.HARTimings) float64 {
+	total := 0.0
+	for _, phase := range []float64{timings.Blocked, timings.DNS, timings.Connect, timings.Send, timings.Wait, timings.Receive} {
+		if phase > 0 {
+			total += phase
+		}
+	}
+	return total
+}
@@ -0,0 +1,103 @@
+package replay
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func TestSend(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != "POST" || r.URL.Path != "/api" || r.URL.Query().Get("q") != "1" {
+			t.Errorf("unexpected request line: %s %s", r.Method, r.URL)
+		}
+		if r.Header.Get("X-Test") != "yes" || r.Host != "example.com" || r.Header.Get("Connection") == "keep-alive" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		if string(body) != "a=1&b=2" || r.Header.Get("Content-Type") != "application/x-www-form-urlencoded" {
+			t.Errorf("unexpected body %q (%s)", body, r.Header.Get("Content-Type"))
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	entry := har.HAREntry{}
+	entry.Request.Method = "POST"
+	entry.Request.URL = server.URL + "/api?q=1"
+	entry.Request.Headers = []har.HARHeader{
+		{Name: ":authority", Value: "example.com"},
+		{Name: "Connection", Value: "keep-alive"},
+		{Name: "X-Test", Value: "yes"},
+	}
+	entry.Request.PostData = &har.HARPostData{
+		MimeType: "application/x-www-form-urlencoded",
+		Params:   []har.HARParam{{Name: "a", Value: "1"}, {Name: "b", Value: "2"}},
+	}
+
+	recorded, err := Send(context.Background(), entry, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded.Response.Status != 201 || recorded.Response.StatusText != "Created" {
+		t.Errorf("unexpected status %d %q", recorded.Response.Status, recorded.Response.StatusText)
+	}
+	if recorded.Response.Content.Text != `{"ok":true}` || recorded.Response.Content.MimeType != "application/json" {
+		t.Errorf("unexpected content: %+v", recorded.Response.Content)
+	}
+	if len(recorded.Response.Cookies) != 1 || recorded.Response.Cookies[0].Value != "abc" {
+		t.Errorf("unexpected cookies: %+v", recorded.Response.Cookies)
+	}
+	if recorded.Request.URL != entry.Request.URL || recorded.ServerIPAddress != "127.0.0.1" {
+		t.Errorf("unexpected request or server: %s %s", recorded.Request.URL, recorded.ServerIPAddress)
+	}
+	timings := recorded.Timings
+	if timings.Connect < 0 || timings.Send < 0 || timings.Wait < 0 || timings.Receive < 0 || timings.SSL != -1 {
+		t.Errorf("unexpected timings: %+v", timings)
+	}
+	if recorded.Time <= 0 {
+		t.Errorf("expected a total time, got %v", recorded.Time)
+	}
+	if _, err := har.ParseHARDateTime(recorded.StartedDateTime); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSendDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/old" {
+			http.Redirect(w, r, "/new", http.StatusFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Write([]byte{0xff, 0xfe, 0x00})
+	}))
+	defer server.Close()
+
+	entry := har.HAREntry{}
+	entry.Request.Method = "GET"
+	entry.Request.URL = server.URL + "/old"
+	recorded, err := Send(context.Background(), entry, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded.Response.Status != http.StatusFound || recorded.Response.RedirectURL != "/new" {
+		t.Errorf("expected the redirect itself to be recorded, got %d %q", recorded.Response.Status, recorded.Response.RedirectURL)
+	}
+
+	entry.Request.URL = server.URL + "/new"
+	recorded, err = Send(context.Background(), entry, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if recorded.Response.Content.Encoding != "base64" || har.DecodeBase64(recorded.Response.Content.Text, "base64") != "\xff\xfe\x00" {
+		t.Errorf("expected a base64 binary body, got %+v", recorded.Response.Content)
+	}
+}
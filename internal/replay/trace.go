@@ -0,0 +1,112 @@
+package replay
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// timer records when each phase of a request happened via httptrace
+type timer struct {
+	mu    sync.Mutex
+	start time.Time
+
+	dnsStart, dnsDone         time.Time
+	connectStart, connectDone time.Time
+	tlsStart, tlsDone         time.Time
+	gotConn, wroteRequest     time.Time
+	firstByte                 time.Time
+	remoteAddr                net.Addr
+}
+
+func newTimer() *timer {
+	return &timer{start: time.Now()}
+}
+
+// withTrace attaches the timer to a request context
+func (t *timer) withTrace(ctx context.Context) context.Context {
+	record := func(at *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		// Only the first attempt counts when the dialer races addresses
+		if at.IsZero() {
+			*at = time.Now()
+		}
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&t.dnsDone) },
+		ConnectStart:         func(string, string) { record(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { record(&t.connectDone) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		WroteRequest:         func(httptrace.WroteRequestInfo) { record(&t.wroteRequest) },
+		GotFirstResponseByte: func() { record(&t.firstByte) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			record(&t.gotConn)
+			t.mu.Lock()
+			t.remoteAddr = info.Conn.RemoteAddr()
+			t.mu.Unlock()
+		},
+	})
+}
+
+// timings converts the recorded instants to HAR phases, in milliseconds.
+// Phases that didn't happen, such as DNS on a reused connection, are -1
+func (t *timer) timings(end time.Time) har.HARTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Blocked is the wait before any network activity for the request began
+	firstActivity := t.gotConn
+	for _, at := range []time.Time{t.connectStart, t.dnsStart} {
+		if !at.IsZero() {
+			firstActivity = at
+		}
+	}
+
+	connectEnd := t.connectDone
+	if t.tlsDone.After(connectEnd) {
+		connectEnd = t.tlsDone // HAR counts TLS as part of connect
+	}
+	return har.HARTimings{
+		Blocked: span(t.start, firstActivity),
+		DNS:     span(t.dnsStart, t.dnsDone),
+		Connect: span(t.connectStart, connectEnd),
+		SSL:     span(t.tlsStart, t.tlsDone),
+		Send:    span(t.gotConn, t.wroteRequest),
+		Wait:    span(t.wroteRequest, t.firstByte),
+		Receive: span(t.firstByte, end),
+	}
+}
+
+// serverIP returns the address of the server the request was sent to
+func (t *timer) serverIP() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.remoteAddr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(t.remoteAddr.String())
+	if err != nil {
+		return t.remoteAddr.String()
+	}
+	return host
+}
+
+// span returns the milliseconds between two instants, or -1 if either is missing
+func span(from, to time.Time) float64 {
+	if from.IsZero() || to.IsZero() {
+		return -1
+	}
+	ms := float64(to.Sub(from)) / float64(time.Millisecond)
+	if ms < 0 {
+		return 0
+	}
+	return ms
+}
@@ -0,0 +1,196 @@
+package replay
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/diff"
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/util"
+	"github.com/tidwall/gjson"
+)
+
+// Where an Extractor reads its value from
+const (
+	FromJSON   = "json"
+	FromHeader = "header"
+	FromCookie = "cookie"
+)
+
+// minCorrelatedLength is the shortest captured value replaced wherever it
+// appears in later requests; shorter values such as "1" would match too much
+const minCorrelatedLength = 4
+
+// Extractor names a value taken from responses, such as a fresh auth token
+type Extractor struct {
+	Name   string
+	Source string // FromJSON, FromHeader or FromCookie
+	Key    string // JSON path (e.g. "data.token"), header name or cookie name
+}
+
+// ParseExtractor reads an extractor written as name=source:key, e.g.
+// "token=json:data.access_token", "csrf=header:X-CSRF-Token" or "sid=cookie:session"
+func ParseExtractor(spec string) (Extractor, error) {
+	name, rule, ok := strings.Cut(spec, "=")
+	if !ok {
+		return Extractor{}, fmt.Errorf("extractor %q: expected name=source:key", spec)
+	}
+	source, key, ok := strings.Cut(rule, ":")
+	extractor := Extractor{Name: strings.TrimSpace(name), Source: strings.ToLower(strings.TrimSpace(source)), Key: strings.TrimSpace(key)}
+	if !ok || extractor.Name == "" || extractor.Key == "" {
+		return Extractor{}, fmt.Errorf("extractor %q: expected name=source:key", spec)
+	}
+	switch extractor.Source {
+	case FromJSON, FromHeader, FromCookie:
+		return extractor, nil
+	}
+	return Extractor{}, fmt.Errorf("extractor %q: source must be json, header or cookie", spec)
+}
+
+// Extract returns the extractor's value in an entry's response
+func (e Extractor) Extract(entry har.HAREntry) (string, bool) {
+	response := entry.Response
+	switch e.Source {
+	case FromJSON:
+		body := diff.ResponseBody(entry)
+		if !gjson.Valid(body) {
+			return "", false
+		}
+		result := gjson.Get(body, util.GJSONPath(e.Key))
+		if !result.Exists() {
+			return "", false
+		}
+		return result.String(), true
+	case FromHeader:
+		for _, header := range response.Headers {
+			if strings.EqualFold(header.Name, e.Key) {
+				return header.Value, true
+			}
+		}
+	case FromCookie:
+		for _, cookie := range response.Cookies {
+			if cookie.Name == e.Key {
+				return cookie.Value, true
+			}
+		}
+		// Some captures only keep the Set-Cookie headers
+		for _, header := range response.Headers {
+			if !strings.EqualFold(header.Name, "set-cookie") {
+				continue
+			}
+			if cookie, err := http.ParseSetCookie(header.Value); err == nil && cookie.Name == e.Key {
+				return cookie.Value, true
+			}
+		}
+	}
+	return "", false
+}
+
+// Variables holds values extracted while replaying a sequence, together with
+// the values the capture had in the same place
+type Variables struct {
+	names    []string // in the order they were first set
+	values   map[string]string
+	captured map[string]string
+}
+
+// NewVariables returns an empty set of variables
+func NewVariables() *Variables {
+	return &Variables{values: make(map[string]string), captured: make(map[string]string)}
+}
+
+// Set records a variable's live value and, when known, its captured value
+func (v *Variables) Set(name, value, captured string) {
+	if _, ok := v.values[name]; !ok {
+		v.names = append(v.names, name)
+	}
+	v.values[name] = value
+	if captured != "" {
+		v.captured[name] = captured
+	}
+}
+
+// Get returns a variable's live value
+func (v *Variables) Get(name string) (string, bool) {
+	value, ok := v.values[name]
+	return value, ok
+}
+
+// Update extracts every variable an entry's response provides. recorded is
+// the captured entry the response replays, whose values are the ones to
+// replace in later requests. It returns the names that were set
+func (v *Variables) Update(extractors []Extractor, recorded, replayed har.HAREntry) []string {
+	var names []string
+	for _, extractor := range extractors {
+		value, ok := extractor.Extract(replayed)
+		if !ok {
+			continue
+		}
+		captured, _ := extractor.Extract(recorded)
+		v.Set(extractor.Name, value, captured)
+		names = append(names, extractor.Name)
+	}
+	return names
+}
+
+// replace substitutes {{name}} placeholders and captured values in text
+func (v *Variables) replace(text string, used map[string]bool) string {
+	for _, name := range v.names {
+		value := v.values[name]
+		placeholder := "{{" + name + "}}"
+		if strings.Contains(text, placeholder) {
+			text = strings.ReplaceAll(text, placeholder, value)
+			used[name] = true
+		}
+		if captured := v.captured[name]; len(captured) >= minCorrelatedLength && captured != value && strings.Contains(text, captured) {
+			text = strings.ReplaceAll(text, captured, value)
+			used[name] = true
+		}
+	}
+	return text
+}
+
+// Apply returns a copy of entry whose request uses the current values, and the
+// names of the variables it substituted. Both {{name}} placeholders and the
+// values the capture had are replaced, in the URL, headers, cookies and body
+func (v *Variables) Apply(entry har.HAREntry) (har.HAREntry, []string) {
+	used := make(map[string]bool)
+	request := entry.Request
+	request.URL = v.replace(request.URL, used)
+
+	request.Headers = make([]har.HARHeader, len(entry.Request.Headers))
+	for i, header := range entry.Request.Headers {
+		header.Value = v.replace(header.Value, used)
+		request.Headers[i] = header
+	}
+	request.Cookies = make([]har.HARCookie, len(entry.Request.Cookies))
+	for i, cookie := range entry.Request.Cookies {
+		cookie.Value = v.replace(cookie.Value, used)
+		request.Cookies[i] = cookie
+	}
+	request.QueryString = make([]har.HARQueryParam, len(entry.Request.QueryString))
+	for i, param := range entry.Request.QueryString {
+		param.Value = v.replace(param.Value, used)
+		request.QueryString[i] = param
+	}
+	if entry.Request.PostData != nil {
+		postData := *entry.Request.PostData
+		postData.Text = v.replace(postData.Text, used)
+		postData.Params = make([]har.HARParam, len(entry.Request.PostData.Params))
+		for i, param := range entry.Request.PostData.Params {
+			param.Value = v.replace(param.Value, used)
+			postData.Params[i] = param
+		}
+		request.PostData = &postData
+	}
+	entry.Request = request
+
+	names := make([]string, 0, len(used))
+	for name := range used {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return entry, names
+}
@@ -0,0 +1,116 @@
+package replay
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/diff"
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// DefaultIgnoredHeaders are response headers expected to differ between a
+// capture and a replay: per-response values, and the encoding headers that
+// change because replays are recorded decoded
+var DefaultIgnoredHeaders = []string{
+	"date", "etag", "age", "expires", "last-modified", "set-cookie",
+	"content-length", "content-encoding", "transfer-encoding", "connection", "keep-alive",
+	"x-request-id", "x-amzn-requestid", "x-amz-cf-id", "cf-ray", "server-timing",
+	"report-to", "nel", "traceparent", "x-trace-id",
+}
+
+// VerifyOptions control what a replayed response is allowed to change
+type VerifyOptions struct {
+	IgnoreHeaders []string // response header names, case-insensitive
+	IgnorePaths   []string // JSON body paths, each also covering everything below it
+}
+
+// DefaultVerifyOptions ignores DefaultIgnoredHeaders
+func DefaultVerifyOptions() VerifyOptions {
+	return VerifyOptions{IgnoreHeaders: append([]string(nil), DefaultIgnoredHeaders...)}
+}
+
+// Report compares a replayed response with the recorded one
+type Report struct {
+	Pass         bool                `json:"pass"`
+	StatusBefore int                 `json:"statusBefore"`
+	StatusAfter  int                 `json:"statusAfter"`
+	Headers      []diff.HeaderChange `json:"headers"`               // differing headers that aren't ignored
+	BodyIsJSON   bool                `json:"bodyIsJSON"`            // both bodies are JSON, compared structurally
+	BodyChanges  []diff.PathChange   `json:"bodyChanges,omitempty"` // JSON paths that differ and aren't ignored
+	BodyChanged  bool                `json:"bodyChanged"`
+}
+
+// Verify compares the response of a replayed entry with the recorded entry's
+func Verify(recorded, replayed har.HAREntry, opts VerifyOptions) Report {
+	report := Report{
+		StatusBefore: recorded.Response.Status,
+		StatusAfter:  replayed.Response.Status,
+		Headers:      []diff.HeaderChange{},
+	}
+
+	ignored := make(map[string]bool, len(opts.IgnoreHeaders))
+	for _, name := range opts.IgnoreHeaders {
+		ignored[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	for _, change := range diff.Headers(recorded.Response.Headers, replayed.Response.Headers) {
+		if change.Kind != diff.Unchanged && !ignored[strings.ToLower(change.Name)] {
+			report.Headers = append(report.Headers, change)
+		}
+	}
+
+	before, after := diff.ResponseBody(recorded), diff.ResponseBody(replayed)
+	if changes, ok := diff.JSON(before, after); ok {
+		report.BodyIsJSON = true
+		for _, change := range changes {
+			if !ignoredPath(change.Path, opts.IgnorePaths) {
+				report.BodyChanges = append(report.BodyChanges, change)
+			}
+		}
+		report.BodyChanged = len(report.BodyChanges) > 0
+	} else {
+		report.BodyChanged = before != after
+	}
+
+	report.Pass = report.StatusBefore == report.StatusAfter && len(report.Headers) == 0 && !report.BodyChanged
+	return report
+}
+
+// ignoredPath reports whether path is one of the ignored paths or lies below one
+func ignoredPath(path string, ignored []string) bool {
+	for _, prefix := range ignored {
+		if path == prefix || strings.HasPrefix(path, prefix+".") || strings.HasPrefix(path, prefix+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// Failures lists why a report failed, empty when it passed
+func (r Report) Failures() []string {
+	var failures []string
+	if r.StatusBefore != r.StatusAfter {
+		failures = append(failures, fmt.Sprintf("status %d -> %d", r.StatusBefore, r.StatusAfter))
+	}
+	if len(r.Headers) > 0 {
+		names := make([]string, len(r.Headers))
+		for i, change := range r.Headers {
+			names[i] = change.Name
+		}
+		failures = append(failures, fmt.Sprintf("%d header(s) differ: %s", len(r.Headers), strings.Join(names, ", ")))
+	}
+	switch {
+	case r.BodyIsJSON && len(r.BodyChanges) > 0:
+		failures = append(failures, fmt.Sprintf("%d JSON path(s) differ", len(r.BodyChanges)))
+	case r.BodyChanged:
+		failures = append(failures, "body differs")
+	}
+	return failures
+}
+
+// Verdict is "PASS", or "FAIL" followed by the reasons
+func (r Report) Verdict() string {
+	if r.Pass {
+		return "PASS"
+	}
+	return "FAIL: " + strings.Join(r.Failures(), "; ")
+}
@@ -0,0 +1,57 @@
+package replay
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+func verifyEntry(status int, body string, headers ...har.HARHeader) har.HAREntry {
+	entry := har.HAREntry{}
+	entry.Response.Status = status
+	entry.Response.Headers = headers
+	entry.Response.Content.Text = body
+	return entry
+}
+
+func TestVerify(t *testing.T) {
+	recorded := verifyEntry(200, `{"id": 1, "updatedAt": "2024-01-01", "items": [{"n": 1}]}`,
+		har.HARHeader{Name: "date", Value: "Mon"},
+		har.HARHeader{Name: "Content-Type", Value: "application/json"})
+
+	same := verifyEntry(200, `{"id":1,"updatedAt":"2024-06-01","items":[{"n":1}]}`,
+		har.HARHeader{Name: "Date", Value: "Tue"},
+		har.HARHeader{Name: "Content-Type", Value: "application/json"})
+	opts := DefaultVerifyOptions()
+	opts.IgnorePaths = []string{"updatedAt"}
+	if report := Verify(recorded, same, opts); !report.Pass || report.Verdict() != "PASS" {
+		t.Errorf("expected a pass, got %+v", report)
+	}
+
+	changed := verifyEntry(500, `{"id": 1, "updatedAt": "2024-01-01", "items": [{"n": 2}]}`,
+		har.HARHeader{Name: "Content-Type", Value: "text/plain"})
+	report := Verify(recorded, changed, opts)
+	if report.Pass {
+		t.Fatal("expected a failure")
+	}
+	if len(report.Headers) != 1 || report.Headers[0].Name != "Content-Type" {
+		t.Errorf("expected only Content-Type to count, got %+v", report.Headers)
+	}
+	if len(report.BodyChanges) != 1 || report.BodyChanges[0].Path != "items[0].n" {
+		t.Errorf("unexpected body changes: %+v", report.BodyChanges)
+	}
+	if verdict := report.Verdict(); !strings.HasPrefix(verdict, "FAIL: status 200 -> 500") {
+		t.Errorf("unexpected verdict %q", verdict)
+	}
+
+	opts.IgnorePaths = []string{"items"}
+	if report := Verify(recorded, changed, opts); len(report.BodyChanges) != 0 {
+		t.Errorf("expected items[0].n to be covered by items, got %+v", report.BodyChanges)
+	}
+
+	text := Verify(verifyEntry(200, "hello"), verifyEntry(200, "hello!"), VerifyOptions{})
+	if text.Pass || text.BodyIsJSON || !text.BodyChanged {
+		t.Errorf("expected a text body change, got %+v", text)
+	}
+}
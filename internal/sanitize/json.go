@@ -0,0 +1,180 @@
+package sanitize
+
+import (
+	"encoding/json"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/util"
+)
+
+// anyDepth marks a JSON path rule that is a single key, matched anywhere
+const anyDepth = "**"
+
+// parseJSONPath splits a rule such as items[*].token into segments; index
+// segments start with # so a key can't match them
+func parseJSONPath(rule string) ([]string, error) {
+	if rule == "" {
+		return nil, errors.New("empty path")
+	}
+	var segments []string
+	for _, part := range strings.Split(strings.TrimPrefix(rule, "$."), ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key != "" {
+			segments = append(segments, strings.ToLower(key))
+		}
+		for rest != "" {
+			index, after, found := strings.Cut(rest, "]")
+			if !found {
+				return nil, errors.New("missing ]")
+			}
+			if _, err := strconv.Atoi(index); err != nil && index != "*" {
+				return nil, errors.New("index must be a number or *")
+			}
+			segments = append(segments, "#"+index)
+			rest = strings.TrimPrefix(after, "[")
+		}
+	}
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, err
+		}
+	}
+	if len(segments) == 1 && !strings.HasPrefix(segments[0], "#") {
+		segments = []string{anyDepth, segments[0]}
+	}
+	return segments, nil
+}
+
+// matchJSONPath reports whether a value's path matches a rule
+func matchJSONPath(rule, segments []string) bool {
+	if len(rule) == 2 && rule[0] == anyDepth {
+		if len(segments) == 0 {
+			return false
+		}
+		matched, _ := path.Match(rule[1], segments[len(segments)-1])
+		return matched
+	}
+	if len(rule) != len(segments) {
+		return false
+	}
+	for i, segment := range segments {
+		if matched, _ := path.Match(rule[i], segment); !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// jsonFrame is an object or array being read
+type jsonFrame struct {
+	array    bool
+	index    int
+	key      string
+	wantKey  bool
+	path     string   // shown in redactions, e.g. items[0].token
+	segments []string // matched against rules
+}
+
+// json masks values at matching paths, keeping the rest of the document
+// byte for byte. Text that isn't JSON is returned unchanged
+func (r *redactor) json(location, text string) string {
+	if len(r.s.jsonPaths) == 0 {
+		return text
+	}
+	type span struct{ start, end int }
+	var spans []span
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var stack []*jsonFrame
+	for {
+		offset := int(decoder.InputOffset())
+		token, err := decoder.Token()
+		if err != nil {
+			break
+		}
+		delim, isDelim := token.(json.Delim)
+		if isDelim && (delim == '}' || delim == ']') {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+
+		// A value's path comes from its parent
+		valuePath, segments := "", []string(nil)
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1]
+			if parent.wantKey {
+				parent.key, _ = token.(string)
+				parent.wantKey = false
+				continue
+			}
+			segments = append(append([]string(nil), parent.segments...), "")
+			if parent.array {
+				valuePath = util.JSONIndexPath(parent.path, parent.index)
+				segments[len(segments)-1] = "#" + strconv.Itoa(parent.index)
+				parent.index++
+			} else {
+				valuePath = util.JSONKeyPath(parent.path, parent.key)
+				segments[len(segments)-1] = strings.ToLower(parent.key)
+				parent.wantKey = true
+			}
+		}
+
+		if len(segments) > 0 && r.matchJSON(segments) {
+			// Tokens start after the separators left from the previous one
+			start := offset + len(text[offset:]) - len(strings.TrimLeft(text[offset:], " \t\r\n,:"))
+			if isDelim {
+				for depth := 1; depth > 0; {
+					token, err := decoder.Token()
+					if err != nil {
+						return text
+					}
+					if d, ok := token.(json.Delim); ok {
+						if d == '{' || d == '[' {
+							depth++
+						} else {
+							depth--
+						}
+					}
+				}
+			}
+			end := int(decoder.InputOffset())
+			value := text[start:end]
+			if str, ok := token.(string); ok {
+				value = str
+			}
+			if value != "" && value != Mask {
+				r.mask(location+" "+valuePath, "json", value)
+				spans = append(spans, span{start, end})
+			}
+			continue
+		}
+		if isDelim {
+			stack = append(stack, &jsonFrame{array: delim == '[', wantKey: delim == '{', path: valuePath, segments: segments})
+		}
+	}
+	if len(spans) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last := 0
+	for _, s := range spans {
+		b.WriteString(text[last:s.start])
+		b.WriteString(strconv.Quote(Mask))
+		last = s.end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// matchJSON reports whether any rule matches a value's path
+func (r *redactor) matchJSON(segments []string) bool {
+	for _, rule := range r.s.jsonPaths {
+		if matchJSONPath(rule, segments) {
+			return true
+		}
+	}
+	return false
+}
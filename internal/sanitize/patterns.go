@@ -0,0 +1,39 @@
+package sanitize
+
+// defaultPatterns are the built-in patterns for personal data and
+// credentials recognizable by their shape
+func defaultPatterns() []Pattern {
+	return []Pattern{
+		{Name: "jwt", Regex: `\beyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`},
+		{Name: "email", Regex: `\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`},
+		{Name: "credit card", Regex: `\b(?:\d[ -]?){12,18}\d\b`, check: luhn},
+		{Name: "AWS access key", Regex: `\b(?:AKIA|ASIA)[A-Z0-9]{16}\b`},
+		{Name: "GitHub token", Regex: `\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})\b`},
+		{Name: "Slack token", Regex: `\bxox[abprs]-[A-Za-z0-9-]{10,}`},
+		{Name: "Stripe key", Regex: `\b[sr]k_(?:live|test)_[A-Za-z0-9]{16,}\b`},
+		{Name: "Google API key", Regex: `\bAIza[0-9A-Za-z_-]{35}\b`},
+		{Name: "private key", Regex: `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	}
+}
+
+// luhn reports whether a card number, possibly with spaces or dashes,
+// passes the Luhn checksum
+func luhn(number string) bool {
+	sum, digits := 0, 0
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if digits%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+	}
+	return digits >= 13 && sum%10 == 0
+}
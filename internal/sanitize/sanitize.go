@@ -0,0 +1,482 @@
+// Package sanitize masks secrets and personal data in captured entries, so
+// a HAR can be attached to a ticket or pasted into a chat
+package sanitize
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/cnharrison/har-tui/internal/env"
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// Mask replaces every masked value
+const Mask = "REDACTED"
+
+// Rules say what to mask. Names are case-insensitive globs such as *token*
+type Rules struct {
+	// Headers have their whole value masked
+	Headers []string `json:"headers,omitempty"`
+	// Cookies have their value masked, in Cookie and Set-Cookie headers too
+	Cookies []string `json:"cookies,omitempty"`
+	// QueryParams are masked in URLs and in form bodies
+	QueryParams []string `json:"queryParams,omitempty"`
+	// JSONPaths are masked in JSON bodies. A single key, e.g. password,
+	// matches at any depth; a path such as user.tokens[*].value is matched
+	// from the root, with * for any key and [*] for any index
+	JSONPaths []string `json:"jsonPaths,omitempty"`
+	// Patterns are masked wherever they appear in URLs, headers, cookies and bodies
+	Patterns []Pattern `json:"patterns,omitempty"`
+	// NoDefaults uses only these rules instead of adding them to DefaultRules
+	NoDefaults bool `json:"noDefaults,omitempty"`
+}
+
+// Pattern is a named regular expression (RE2) for values to mask
+type Pattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
+	// check rejects false positives, e.g. numbers failing a card checksum
+	check func(match string) bool
+}
+
+// Valid reports whether a match of the regex is a real value, not a
+// false positive
+func (p Pattern) Valid(match string) bool {
+	return p.check == nil || p.check(match)
+}
+
+// DefaultRules are the built-in rules: credentials headers, every cookie,
+// token-like query parameters and JSON keys, and patterns for emails,
+// JWTs, card numbers and well-known API keys
+func DefaultRules() Rules {
+	return Rules{
+		Headers: []string{
+			"authorization", "proxy-authorization", "x-api-key", "api-key", "x-auth-token",
+			"x-csrf-token", "x-xsrf-token", "x-amz-security-token", "*-token", "*secret*", "*apikey*", "*api-key*",
+		},
+		Cookies: []string{"*"},
+		QueryParams: []string{
+			"*token*", "*secret*", "*password*", "*passwd*", "api_key", "apikey", "api-key", "key",
+			"sig", "signature", "x-amz-*", "code", "auth", "*session*",
+		},
+		JSONPaths: []string{"*password*", "*passwd*", "*secret*", "*token", "apikey", "api_key", "api-key"},
+		Patterns:  defaultPatterns(),
+	}
+}
+
+// Redaction records one masked value
+type Redaction struct {
+	Location string // where the value was, e.g. "request header Authorization"
+	Rule     string // what matched: header, cookie, query, json or a pattern name
+	Value    string // the value before masking
+}
+
+// Sanitizer masks values matching a set of rules
+type Sanitizer struct {
+	headers, cookies, queryParams []string
+	jsonPaths                     [][]string
+	patterns                      []compiledPattern
+}
+
+type compiledPattern struct {
+	name  string
+	re    *regexp.Regexp
+	check func(string) bool
+}
+
+// New compiles rules, added to the defaults unless NoDefaults is set
+func New(rules Rules) (*Sanitizer, error) {
+	if !rules.NoDefaults {
+		defaults := DefaultRules()
+		rules.Headers = append(defaults.Headers, rules.Headers...)
+		rules.Cookies = append(defaults.Cookies, rules.Cookies...)
+		rules.QueryParams = append(defaults.QueryParams, rules.QueryParams...)
+		rules.JSONPaths = append(defaults.JSONPaths, rules.JSONPaths...)
+		rules.Patterns = append(defaults.Patterns, rules.Patterns...)
+	}
+	s := &Sanitizer{}
+	for _, list := range []struct {
+		kind  string
+		names []string
+		into  *[]string
+	}{
+		{"header", rules.Headers, &s.headers},
+		{"cookie", rules.Cookies, &s.cookies},
+		{"query parameter", rules.QueryParams, &s.queryParams},
+	} {
+		for _, name := range list.names {
+			name = strings.ToLower(name)
+			if _, err := path.Match(name, ""); err != nil {
+				return nil, fmt.Errorf("%s %q: %w", list.kind, name, err)
+			}
+			*list.into = append(*list.into, name)
+		}
+	}
+	for _, jsonPath := range rules.JSONPaths {
+		segments, err := parseJSONPath(jsonPath)
+		if err != nil {
+			return nil, fmt.Errorf("JSON path %q: %w", jsonPath, err)
+		}
+		s.jsonPaths = append(s.jsonPaths, segments)
+	}
+	for _, pattern := range rules.Patterns {
+		re, err := regexp.Compile(pattern.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", pattern.Name, err)
+		}
+		name := pattern.Name
+		if name == "" {
+			name = pattern.Regex
+		}
+		s.patterns = append(s.patterns, compiledPattern{name: name, re: re, check: pattern.check})
+	}
+	return s, nil
+}
+
+// Default returns a sanitizer with the built-in rules
+func Default() *Sanitizer {
+	return defaultSanitizer()
+}
+
+// defaultSanitizer compiles the built-in rules once; a Sanitizer is never modified
+var defaultSanitizer = sync.OnceValue(func() *Sanitizer {
+	s, err := New(Rules{})
+	if err != nil {
+		panic(err)
+	}
+	return s
+})
+
+// configFile is the part of the har-tui config file sanitizing reads
+type configFile struct {
+	Sanitize Rules `json:"sanitize"`
+}
+
+// LoadRules reads the "sanitize" section of a har-tui config file
+func LoadRules(p string) (Rules, error) {
+	data, err := os.ReadFile(p)
+	if err != nil {
+		return Rules{}, err
+	}
+	var config configFile
+	if err := json.Unmarshal(data, &config); err != nil {
+		return Rules{}, fmt.Errorf("%s: %w", p, err)
+	}
+	return config.Sanitize, nil
+}
+
+// Load builds a sanitizer from the config file at p, or at env.DefaultPath
+// when p is empty. A missing default config leaves the built-in rules
+func Load(p string) (*Sanitizer, error) {
+	explicit := p != ""
+	if !explicit {
+		var err error
+		if p, err = env.DefaultPath(); err != nil {
+			return Default(), nil
+		}
+	}
+	rules, err := LoadRules(p)
+	if errors.Is(err, fs.ErrNotExist) && !explicit {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := New(rules)
+	if err != nil {
+		return nil, fmt.Errorf("%s: sanitize: %w", p, err)
+	}
+	return s, nil
+}
+
+// Entry returns a copy of entry with matching values masked, and what was masked
+func (s *Sanitizer) Entry(entry har.HAREntry) (har.HAREntry, []Redaction) {
+	r := &redactor{s: s, seen: map[Redaction]bool{}}
+
+	request := entry.Request
+	request.URL = r.url("request URL", request.URL)
+	request.Headers = r.headers("request", request.Headers)
+	request.Cookies = r.cookies("request", request.Cookies)
+	request.QueryString = make([]har.HARQueryParam, len(entry.Request.QueryString))
+	for i, param := range entry.Request.QueryString {
+		param.Value = r.param("query", param.Name, param.Value)
+		request.QueryString[i] = param
+	}
+	if entry.Request.PostData != nil {
+		postData := *entry.Request.PostData
+		postData.Text = r.body("request body", postData.Text, postData.MimeType)
+		if request.BodySize == len(entry.Request.PostData.Text) {
+			request.BodySize = len(postData.Text)
+		}
+		postData.Params = make([]har.HARParam, len(entry.Request.PostData.Params))
+		for i, param := range entry.Request.PostData.Params {
+			param.Value = r.param("request body field", param.Name, param.Value)
+			postData.Params[i] = param
+		}
+		request.PostData = &postData
+	}
+
+	response := entry.Response
+	response.Headers = r.headers("response", response.Headers)
+	response.Cookies = r.cookies("response", response.Cookies)
+	response.RedirectURL = r.url("redirect URL", response.RedirectURL)
+	if text := r.content(response.Content); text != response.Content.Text {
+		// Sizes follow the masked body; a compressed transfer size can't be
+		// recomputed and is left as it was
+		before := len(har.DecodeBase64(response.Content.Text, response.Content.Encoding))
+		after := len(har.DecodeBase64(text, response.Content.Encoding))
+		response.Content.Text, response.Content.Size = text, after
+		if response.BodySize == before {
+			response.BodySize = after
+		}
+	}
+
+	entry.Request, entry.Response = request, response
+	return entry, r.redactions
+}
+
+// File returns a copy of a HAR file whose entries at indices are sanitized,
+// e.g. before har.SaveFilteredHAR writes them. Page titles, often URLs, are
+// sanitized too
+func (s *Sanitizer) File(harFile *har.HARFile, indices []int) *har.HARFile {
+	sanitized := *harFile
+	sanitized.Log.Entries = append([]har.HAREntry(nil), harFile.Log.Entries...)
+	for _, idx := range indices {
+		if idx >= 0 && idx < len(sanitized.Log.Entries) {
+			sanitized.Log.Entries[idx], _ = s.Entry(sanitized.Log.Entries[idx])
+		}
+	}
+	if harFile.Log.Pages != nil {
+		sanitized.Log.Pages = append([]har.HARPage(nil), harFile.Log.Pages...)
+		r := &redactor{s: s, seen: map[Redaction]bool{}}
+		for i := range sanitized.Log.Pages {
+			sanitized.Log.Pages[i].Title = r.url("page title", sanitized.Log.Pages[i].Title)
+		}
+	}
+	return &sanitized
+}
+
+// redactor sanitizes one entry, collecting what it masks
+type redactor struct {
+	s          *Sanitizer
+	redactions []Redaction
+	seen       map[Redaction]bool // the same value is often in the URL and the query string
+}
+
+// mask records value as masked and returns the mask
+func (r *redactor) mask(location, rule, value string) string {
+	if value == "" || value == Mask {
+		return value
+	}
+	redaction := Redaction{Location: location, Rule: rule, Value: value}
+	if !r.seen[redaction] {
+		r.seen[redaction] = true
+		r.redactions = append(r.redactions, redaction)
+	}
+	return Mask
+}
+
+// patterns masks pattern matches in text
+func (r *redactor) patterns(location, text string) string {
+	for _, pattern := range r.s.patterns {
+		text = pattern.re.ReplaceAllStringFunc(text, func(match string) string {
+			if pattern.check != nil && !pattern.check(match) {
+				return match
+			}
+			return r.mask(location, pattern.name, match)
+		})
+	}
+	return text
+}
+
+// param masks a query parameter or form field by name, then by pattern
+func (r *redactor) param(kind, name, value string) string {
+	location := kind + " " + name
+	if matchName(r.s.queryParams, name) {
+		return r.mask(location, "query", value)
+	}
+	return r.patterns(location, value)
+}
+
+// query masks the parameters of a query string or form body, keeping its order and encoding
+func (r *redactor) query(kind, query string) string {
+	pairs := strings.Split(query, "&")
+	for i, pair := range pairs {
+		rawName, rawValue, found := strings.Cut(pair, "=")
+		if !found || rawValue == "" {
+			continue
+		}
+		name, err := url.QueryUnescape(rawName)
+		if err != nil {
+			name = rawName
+		}
+		value, err := url.QueryUnescape(rawValue)
+		if err != nil {
+			value = rawValue
+		}
+		if masked := r.param(kind, name, value); masked != value {
+			pairs[i] = rawName + "=" + url.QueryEscape(masked)
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// url masks a URL's query parameters and password, then any patterns
+func (r *redactor) url(location, raw string) string {
+	if raw == "" {
+		return raw
+	}
+	if u, err := url.Parse(raw); err == nil {
+		// Reserialized only when masked, as String normalizes some URLs
+		masked := false
+		if password, ok := u.User.Password(); ok && password != "" {
+			u.User = url.UserPassword(u.User.Username(), r.mask(location, "password", password))
+			masked = true
+		}
+		if query := r.query("query", u.RawQuery); query != u.RawQuery {
+			u.RawQuery = query
+			masked = true
+		}
+		if masked {
+			raw = u.String()
+		}
+	}
+	// Patterns skip the scheme and authority, where a masked password
+	// followed by @host would read as an email
+	authority := 0
+	if i := strings.Index(raw, "://"); i >= 0 {
+		authority = i + 3
+		if end := strings.IndexAny(raw[authority:], "/?#"); end >= 0 {
+			authority += end
+		} else {
+			authority = len(raw)
+		}
+	}
+	return raw[:authority] + r.patterns(location, raw[authority:])
+}
+
+// urlHeaders hold URLs that carry the query parameters of another request
+var urlHeaders = map[string]bool{"referer": true, "location": true, "content-location": true, "origin": true}
+
+// headers masks header values by name; cookies are masked one by one
+func (r *redactor) headers(side string, headers []har.HARHeader) []har.HARHeader {
+	sanitized := make([]har.HARHeader, len(headers))
+	for i, header := range headers {
+		name := strings.ToLower(header.Name)
+		location := side + " header " + header.Name
+		switch {
+		case matchName(r.s.headers, name):
+			header.Value = r.mask(location, "header", header.Value)
+		case name == "cookie":
+			header.Value = r.cookieHeader(side, header.Value)
+		case name == "set-cookie":
+			header.Value = r.setCookie(side, header.Value)
+		case urlHeaders[name]:
+			header.Value = r.url(location, header.Value)
+		default:
+			header.Value = r.patterns(location, header.Value)
+		}
+		sanitized[i] = header
+	}
+	return sanitized
+}
+
+// cookie masks one cookie value by name, then by pattern
+func (r *redactor) cookie(side, name, value string) string {
+	location := side + " cookie " + name
+	if matchName(r.s.cookies, name) {
+		return r.mask(location, "cookie", value)
+	}
+	return r.patterns(location, value)
+}
+
+// cookieHeader masks the cookies of a Cookie header
+func (r *redactor) cookieHeader(side, value string) string {
+	pairs := strings.Split(value, ";")
+	for i, pair := range pairs {
+		name, cookieValue, found := strings.Cut(pair, "=")
+		if !found {
+			continue
+		}
+		pairs[i] = name + "=" + r.cookie(side, strings.TrimSpace(name), cookieValue)
+	}
+	return strings.Join(pairs, ";")
+}
+
+// setCookie masks the value of a Set-Cookie header, keeping its attributes
+func (r *redactor) setCookie(side, value string) string {
+	pair, attributes, hasAttributes := strings.Cut(value, ";")
+	name, cookieValue, found := strings.Cut(pair, "=")
+	if !found {
+		return value
+	}
+	value = name + "=" + r.cookie(side, strings.TrimSpace(name), cookieValue)
+	if hasAttributes {
+		value += ";" + attributes
+	}
+	return value
+}
+
+// cookies masks parsed cookies
+func (r *redactor) cookies(side string, cookies []har.HARCookie) []har.HARCookie {
+	sanitized := make([]har.HARCookie, len(cookies))
+	for i, cookie := range cookies {
+		cookie.Value = r.cookie(side, cookie.Name, cookie.Value)
+		sanitized[i] = cookie
+	}
+	return sanitized
+}
+
+// body masks JSON paths or form fields by the body's type, then patterns
+func (r *redactor) body(location, text, mimeType string) string {
+	if text == "" {
+		return text
+	}
+	mimeType = strings.ToLower(mimeType)
+	trimmed := strings.TrimSpace(text)
+	switch {
+	case strings.Contains(mimeType, "json") || strings.HasPrefix(trimmed, "{") || strings.HasPrefix(trimmed, "["):
+		text = r.json(location, text)
+	case strings.Contains(mimeType, "x-www-form-urlencoded"):
+		text = r.query("request body field", text)
+	}
+	return r.patterns(location, text)
+}
+
+// content masks a response body, decoding base64 text first; binary
+// bodies are left alone
+func (r *redactor) content(content har.HARContent) string {
+	if content.Encoding != "base64" {
+		return r.body("response body", content.Text, content.MimeType)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(content.Text)
+	if err != nil || !utf8.Valid(decoded) {
+		return content.Text
+	}
+	sanitized := r.body("response body", string(decoded), content.MimeType)
+	if sanitized == string(decoded) {
+		return content.Text
+	}
+	return base64.StdEncoding.EncodeToString([]byte(sanitized))
+}
+
+// matchName reports whether name matches one of the globs
+func matchName(globs []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, glob := range globs {
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
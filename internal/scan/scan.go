@@ -0,0 +1,305 @@
+// Package scan looks through a capture for leaked secrets and personal
+// data: credentials in URLs or sent over plain HTTP, passwords in bodies,
+// and values shaped like API keys, private keys, card numbers or emails
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cnharrison/har-tui/internal/filter"
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/sanitize"
+	"github.com/cnharrison/har-tui/internal/severity"
+	"github.com/cnharrison/har-tui/internal/util"
+)
+
+// Finding is a leaked value and where it was found
+type Finding struct {
+	Entry    int
+	Location string // e.g. "query access_token" or "response body user.password"
+	Rule     string
+	Severity severity.Level
+	Value    string
+}
+
+// Preview shows enough of the value to recognize it without repeating it
+func (f Finding) Preview() string {
+	runes := []rune(strings.Join(strings.Fields(f.Value), " "))
+	if len(runes) <= 8 {
+		return strings.Repeat("*", len(runes))
+	}
+	return fmt.Sprintf("%s… (%d chars)", string(runes[:4]), len(runes))
+}
+
+// Names of parameters, form fields and JSON keys holding secrets; globs
+// are matched case-insensitively
+var (
+	passwordNames    = []string{"*password*", "*passwd*", "pwd", "*passphrase*"}
+	tokenNames       = []string{"*token*", "*secret*", "api_key", "apikey", "api-key", "access_key", "*session*", "auth"}
+	credentialHeader = []string{"authorization", "proxy-authorization", "cookie", "set-cookie", "x-api-key", "api-key", "*-token", "*secret*"}
+	cookieHeader     = []string{"cookie", "set-cookie"}
+)
+
+// patternSeverity ranks the sanitize patterns; others are Medium
+var patternSeverity = map[string]severity.Level{
+	"private key":    severity.Critical,
+	"AWS access key": severity.Critical,
+	"GitHub token":   severity.Critical,
+	"Slack token":    severity.Critical,
+	"Stripe key":     severity.Critical,
+	"Google API key": severity.High,
+	"credit card":    severity.High,
+	"jwt":            severity.Medium,
+	"email":          severity.Low,
+}
+
+// pattern is a compiled sanitize pattern
+type pattern struct {
+	sanitize.Pattern
+	re    *regexp.Regexp
+	level severity.Level
+}
+
+// patterns compiles the built-in sanitize patterns once
+var patterns = sync.OnceValue(func() []pattern {
+	var compiled []pattern
+	for _, p := range sanitize.DefaultRules().Patterns {
+		level, ok := patternSeverity[p.Name]
+		if !ok {
+			level = severity.Medium
+		}
+		compiled = append(compiled, pattern{Pattern: p, re: regexp.MustCompile(p.Regex), level: level})
+	}
+	return compiled
+})
+
+// Entries scans the entries at indices, most severe findings first
+func Entries(entries []har.HAREntry, indices []int) []Finding {
+	var findings []Finding
+	for _, idx := range indices {
+		findings = append(findings, Entry(idx, entries[idx])...)
+	}
+	sort.SliceStable(findings, func(i, j int) bool {
+		return findings[i].Severity > findings[j].Severity
+	})
+	return findings
+}
+
+// Counts returns the number of findings of each severity
+func Counts(findings []Finding) [severity.Critical + 1]int {
+	var counts [severity.Critical + 1]int
+	for _, f := range findings {
+		counts[f.Severity]++
+	}
+	return counts
+}
+
+// Entry scans one entry, numbered index in the findings. It visits the
+// fields a search of all fields does, plus the URL's password
+func Entry(index int, entry har.HAREntry) []Finding {
+	s := &scanner{index: index, seen: make(map[string]bool), cookies: make(map[string]bool)}
+	u, err := url.Parse(entry.Request.URL)
+	if err == nil {
+		s.plain = u.Scheme == "http" && !har.IsLocal(u.Hostname())
+		if password, ok := u.User.Password(); ok && password != "" {
+			s.add("request URL", "password in URL", severity.Critical, password)
+		}
+	}
+
+	filter.WalkFields(entry, filter.ScopeAll, true, func(field filter.Field) bool {
+		location := field.Location()
+		switch field.Kind {
+		case filter.FieldPath:
+			s.patterns(location, field.Value, true)
+		case filter.FieldQuery:
+			s.query(field.Value)
+		case filter.FieldHeader:
+			if s.plain && !field.Response && field.Value != "" && matchName(credentialHeader, field.Name) {
+				s.add(location, "credentials over HTTP", severity.High, field.Value)
+			}
+			// Cookies carry more than the session, so they are checked like other headers
+			if matchName(cookieHeader, field.Name) {
+				s.cookie(location, field.Value, true)
+			} else if !matchName(credentialHeader, field.Name) {
+				s.patterns(location, field.Value, false)
+			}
+		case filter.FieldCookie:
+			s.cookie(location, field.Value, false)
+		case filter.FieldBody:
+			s.body(location, field)
+		}
+		return false
+	})
+	return s.findings
+}
+
+// scanner collects the findings of one entry
+type scanner struct {
+	index    int
+	plain    bool // sent unencrypted to another host
+	findings []Finding
+	seen     map[string]bool
+	cookies  map[string]bool // pattern matches in Cookie and Set-Cookie headers
+}
+
+// add records a finding unless the value was already found at location
+func (s *scanner) add(location, rule string, level severity.Level, value string) {
+	key := location + "\x00" + value
+	if s.seen[key] {
+		return
+	}
+	s.seen[key] = true
+	s.findings = append(s.findings, Finding{Entry: s.index, Location: location, Rule: rule, Severity: level, Value: value})
+}
+
+// patterns records pattern matches in text; values in the URL leak into
+// logs and Referer headers, so they rank one higher
+func (s *scanner) patterns(location, text string, inURL bool) {
+	findPatterns(text, func(p pattern, match string) {
+		level := p.level
+		if inURL && level < severity.Critical {
+			level++
+		}
+		s.add(location, p.Name, level, match)
+	})
+}
+
+// cookie records pattern matches in a Cookie or Set-Cookie header, or in a
+// parsed cookie. Parsed cookies repeat their header, so matches already
+// found there aren't recorded twice
+func (s *scanner) cookie(location, text string, header bool) {
+	findPatterns(text, func(p pattern, match string) {
+		if header {
+			s.cookies[match] = true
+		} else if s.cookies[match] {
+			return
+		}
+		s.add(location, p.Name, p.level, match)
+	})
+}
+
+// findPatterns calls visit with every valid pattern match in text
+func findPatterns(text string, visit func(p pattern, match string)) {
+	for _, p := range patterns() {
+		for _, match := range p.re.FindAllString(text, -1) {
+			if p.Valid(match) {
+				visit(p, match)
+			}
+		}
+	}
+}
+
+// query records passwords and tokens passed as query parameters
+func (s *scanner) query(rawQuery string) {
+	for name, value := range params(rawQuery) {
+		location := "query " + name
+		switch {
+		case value == "":
+		case matchName(passwordNames, name):
+			s.add(location, "password in URL", severity.Critical, value)
+		case matchName(tokenNames, name):
+			s.add(location, "token in URL", severity.High, value)
+		}
+		s.patterns(location, value, true)
+	}
+}
+
+// body records passwords in request bodies and ones a response gives away,
+// then pattern matches
+func (s *scanner) body(location string, field filter.Field) {
+	rule, level := "password in request body", severity.Medium
+	if s.plain {
+		level = severity.High
+	}
+	if field.Response {
+		rule, level = "password in response", severity.High
+	}
+
+	mimeType := strings.ToLower(field.MimeType)
+	switch {
+	case strings.Contains(mimeType, "x-www-form-urlencoded") && !field.Response:
+		// Fields are checked unescaped, one by one
+		for name, value := range params(field.Value) {
+			if value != "" && matchName(passwordNames, name) {
+				s.add(location+" "+name, rule, level, value)
+			}
+			s.patterns(location+" "+name, value, false)
+		}
+		return
+	case strings.Contains(mimeType, "json"):
+		var doc interface{}
+		if json.Unmarshal([]byte(field.Value), &doc) == nil {
+			walkJSON(doc, "", func(keyPath, key, value string) {
+				if value != "" && matchName(passwordNames, key) {
+					s.add(location+" "+keyPath, rule, level, value)
+				}
+			})
+		}
+	}
+	s.patterns(location, field.Value, false)
+}
+
+// params splits a query string or form body into unescaped pairs, in order
+func params(query string) iter.Seq2[string, string] {
+	return func(yield func(string, string) bool) {
+		for _, pair := range strings.Split(query, "&") {
+			name, value, _ := strings.Cut(pair, "=")
+			if name == "" {
+				continue
+			}
+			if unescaped, err := url.QueryUnescape(name); err == nil {
+				name = unescaped
+			}
+			if unescaped, err := url.QueryUnescape(value); err == nil {
+				value = unescaped
+			}
+			if !yield(name, value) {
+				return
+			}
+		}
+	}
+}
+
+// walkJSON calls visit with the path, key and value of every string held
+// under an object key
+func walkJSON(value interface{}, valuePath string, visit func(keyPath, key, value string)) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			keyPath := util.JSONKeyPath(valuePath, key)
+			if str, ok := v[key].(string); ok {
+				visit(keyPath, key, str)
+			} else {
+				walkJSON(v[key], keyPath, visit)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			walkJSON(item, util.JSONIndexPath(valuePath, i), visit)
+		}
+	}
+}
+
+// matchName reports whether name matches any of the globs, ignoring case
+func matchName(globs []string, name string) bool {
+	name = strings.ToLower(name)
+	for _, glob := range globs {
+		if matched, _ := path.Match(glob, name); matched {
+			return true
+		}
+	}
+	return false
+}
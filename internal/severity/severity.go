@@ -0,0 +1,37 @@
+// Package severity ranks what the scanner and the auditor report
+package severity
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Level ranks a finding or an issue, Low to Critical
+type Level int
+
+// Levels, in increasing order
+const (
+	Low Level = iota
+	Medium
+	High
+	Critical
+)
+
+var names = []string{"low", "medium", "high", "critical"}
+
+func (l Level) String() string {
+	if l < Low || l > Critical {
+		return fmt.Sprintf("severity(%d)", int(l))
+	}
+	return names[l]
+}
+
+// Parse reads a level name such as high
+func Parse(name string) (Level, error) {
+	for i, levelName := range names {
+		if strings.EqualFold(name, levelName) {
+			return Level(i), nil
+		}
+	}
+	return Low, fmt.Errorf("unknown severity %q (expected %s)", name, strings.Join(names, ", "))
+}
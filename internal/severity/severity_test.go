@@ -0,0 +1,12 @@
+package severity
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	if level, err := Parse("HIGH"); err != nil || level != High {
+		t.Errorf("Parse(HIGH) = %v, %v", level, err)
+	}
+	if _, err := Parse("severe"); err == nil {
+		t.Error("expected an error for an unknown severity")
+	}
+}
@@ -13,16 +13,16 @@ func (app *Application) saveFilteredHAR() {
 	
 	var harData *har.HARFile
 	if app.isLoading {
-		harData = &har.HARFile{
-			Log: har.HARLog{
-				Version: harVersion,
-				Entries: app.streamingLoader.GetEntries(),
-			},
-		}
+		harData = app.streamingLoader.GetHARFile()
 	} else {
 		harData = app.harData
 	}
 	
+	// Shared captures are masked when sanitizing is on
+	if app.sanitizer != nil {
+		harData = app.sanitizer.File(harData, app.filteredEntries)
+	}
+	
 	// Save the filtered HAR file
 	if err := har.SaveFilteredHAR(harData, app.filteredEntries, filename); err != nil {
 		app.showStatusMessage(fmt.Sprintf("Error saving filtered HAR: %v", err))
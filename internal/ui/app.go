@@ -5,8 +5,12 @@ import (
 
 	"github.com/rivo/tview"
 	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/audit"
+	"github.com/cnharrison/har-tui/internal/env"
 	"github.com/cnharrison/har-tui/internal/filter"
 	"github.com/cnharrison/har-tui/internal/format"
+	"github.com/cnharrison/har-tui/internal/replay"
+	"github.com/cnharrison/har-tui/internal/sanitize"
 )
 
 // JSONPathInfo holds cached JSON path information for performance
@@ -29,6 +33,7 @@ const (
 	tabsHeightRatio = 2
 	maxPathDisplayLength = 50
 	pathTruncateOffset = 3
+	maxSourceDisplayLength = 16
 	
 	// HTTP status code thresholds
 	statusCodeSuccess = 200
@@ -51,6 +56,7 @@ const (
 type Application struct {
 	harData     *har.HARFile
 	filename    string
+	filenames   []string // every file opened; filename is the first
 	app         *tview.Application
 	filterState *filter.FilterState
 	formatter   *format.ContentFormatter
@@ -68,6 +74,20 @@ type Application struct {
 	focusOnBottom   bool
 	animationFrame  int
 	selectedFilterIndex int
+	selectedPageIndex   int // 0 = all pages, otherwise 1-based index into the log's pages
+	selectedSourceIndex int // 0 = all files, otherwise 1-based index into the loaded sources
+	markedEntry         int // entry marked with x for an entry diff, -1 when none
+	composerHistory     map[int][]composerVariant // variants sent from the composer, by captured entry
+	replayReports       map[int]replay.Report     // replayed entries verified against their originals
+	batchRunning        bool                      // a batch replay is in progress
+	config              *env.Config
+	environment         *env.Environment // rewrites replayed and exported requests, nil for none
+	sanitizer           *sanitize.Sanitizer // masks saved, exported and copied entries, nil when off
+	auditor             *audit.Auditor      // audits the Security tab, rebuilt when entries are added
+	auditedEntries      int                 // number of entries auditor was built from
+	mock                *mockServerState // mock server started with M, nil when none
+	recording           *recordingState  // capture through the recording proxy, nil when not recording
+	following           bool             // the file is followed for new entries until r stops it
 	
 	// Side-by-side layout state
 	sideBySideViews [2]*tview.TextView // [0] = left pane, [1] = right pane
@@ -94,6 +114,7 @@ type Application struct {
 	cookiesView *tview.TextView
 	timingsView *tview.TextView
 	rawView     *tview.TextView
+	securityView *tview.TextView
 	waterfallView *WaterfallView
 	topBar      *tview.TextView
 	tabBar      *tview.TextView
@@ -126,6 +147,7 @@ func NewApplication(harData *har.HARFile, filename string) *Application {
 		loadingProgress: 0,
 		jsonPathCache: make(map[int]*JSONPathInfo),
 		currentJSONEntry: -1,
+		markedEntry: -1,
 	}
 	
 	// Initialize filtered entries for existing data
@@ -138,10 +160,12 @@ func NewApplication(harData *har.HARFile, filename string) *Application {
 	return app
 }
 
-// NewApplicationStreaming creates a new HAR TUI application with streaming loader
-func NewApplicationStreaming(filename string) *Application {
+// NewApplicationStreaming creates a new HAR TUI application with streaming loader.
+// Several files are loaded into one session, each entry tagged with its source file
+func NewApplicationStreaming(filenames ...string) *Application {
 	app := &Application{
-		filename:    filename,
+		filename:    filenames[0],
+		filenames:   filenames,
 		app:         tview.NewApplication(),
 		filterState: filter.NewFilterState(),
 		formatter:   format.NewContentFormatter(),
@@ -156,6 +180,7 @@ func NewApplicationStreaming(filename string) *Application {
 		batchUpdateSize: defaultBatchUpdateSize,
 		jsonPathCache: make(map[int]*JSONPathInfo),
 		currentJSONEntry: -1,
+		markedEntry: -1,
 	}
 	
 	// Set up streaming callbacks
@@ -169,15 +194,28 @@ func NewApplicationStreaming(filename string) *Application {
 	return app
 }
 
+// NewApplicationFollowing creates an application that loads a HAR or NDJSON
+// capture and keeps listing entries as they are written to it
+func NewApplicationFollowing(filename string) *Application {
+	app := NewApplicationStreaming(filename)
+	app.following = true
+	return app
+}
+
 // Run starts the TUI application
 func (app *Application) Run() error {
+	app.loadEnvironments()
 	app.setupUI()
 	app.setupEventHandling()
 	app.startAnimationLoop()
 	
 	// Start streaming load if needed
-	if app.isLoading {
-		app.streamingLoader.LoadHARFileStreaming(app.filename)
+	if app.recording != nil {
+		app.startRecording()
+	} else if app.following {
+		app.streamingLoader.FollowHARFile(app.filename)
+	} else if app.isLoading {
+		app.streamingLoader.LoadHARFilesStreaming(app.filenames)
 	}
 	
 	// Initialize display
@@ -187,7 +225,10 @@ func (app *Application) Run() error {
 	app.updateTabBar()
 	app.updateBottomBar()
 	
-	return app.app.SetRoot(app.layout, true).Run()
+	if err := app.app.SetRoot(app.layout, true).Run(); err != nil {
+		return err
+	}
+	return app.finishRecording()
 }
 
 // showStatusMessage shows a temporary status message
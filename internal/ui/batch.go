@@ -0,0 +1,251 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/replay"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showBatchReplayForm asks how to replay every filtered entry as a sequence
+func (app *Application) showBatchReplayForm() {
+	if app.isLoading || app.harData == nil {
+		app.showStatusMessage("Wait for loading to finish before replaying")
+		return
+	}
+	if app.batchRunning {
+		app.showStatusMessage("A batch replay is already running")
+		return
+	}
+	if len(app.filteredEntries) == 0 {
+		app.showStatusMessage("No requests match the current filters")
+		return
+	}
+
+	form := tview.NewForm()
+	form.AddInputField("Extract", "", 60, nil, nil)
+	form.AddInputField("Concurrency", "1", 6, tview.InputFieldInteger, nil)
+	form.AddInputField("Rate (req/s)", "0", 6, nil, nil)
+
+	helpView := tview.NewTextView()
+	helpView.SetDynamicColors(true)
+	defaultHelp := fmt.Sprintf(`Replays the %d filtered requests in order.
+
+[yellow]Extract[white] carries values from responses into later requests,
+comma-separated as name=source:key, e.g.
+  token=json:data.access_token, csrf=header:X-CSRF-Token, sid=cookie:session
+Later requests get the live value wherever the capture had the old one,
+and wherever they contain {{name}}.
+
+[yellow]Concurrency[white] is how many requests run at once; [yellow]Rate[white] caps how many
+start per second (0 for no limit).`, len(app.filteredEntries))
+	helpView.SetText(defaultHelp)
+
+	start := func() {
+		var extractors []replay.Extractor
+		for _, spec := range strings.Split(form.GetFormItemByLabel("Extract").(*tview.InputField).GetText(), ",") {
+			if spec = strings.TrimSpace(spec); spec == "" {
+				continue
+			}
+			extractor, err := replay.ParseExtractor(spec)
+			if err != nil {
+				helpView.SetText(fmt.Sprintf("[red]%v[white]\n\n%s", tview.Escape(err.Error()), defaultHelp))
+				return
+			}
+			extractors = append(extractors, extractor)
+		}
+		concurrency, err := strconv.Atoi(form.GetFormItemByLabel("Concurrency").(*tview.InputField).GetText())
+		if err != nil || concurrency < 1 {
+			helpView.SetText("[red]Concurrency must be at least 1[white]\n\n" + defaultHelp)
+			return
+		}
+		rate, err := strconv.ParseFloat(form.GetFormItemByLabel("Rate (req/s)").(*tview.InputField).GetText(), 64)
+		if err != nil || rate < 0 {
+			helpView.SetText("[red]Rate must be a number, 0 for no limit[white]\n\n" + defaultHelp)
+			return
+		}
+
+		app.app.SetRoot(app.layout, true)
+		app.runBatchReplay(replay.BatchOptions{
+			Concurrency: concurrency,
+			Rate:        rate,
+			Extractors:  extractors,
+			Verify:      replay.DefaultVerifyOptions(),
+		})
+	}
+	form.AddButton("Start", start)
+	form.AddButton("Cancel", func() {
+		app.app.SetRoot(app.layout, true)
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(form, 9, 0, true)
+	layout.AddItem(helpView, 0, 1, false)
+	layout.SetBorder(true)
+	layout.SetTitle(" ⏵ Batch Replay ")
+	layout.SetBorderColor(tcell.ColorPurple)
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			app.app.SetRoot(app.layout, true)
+			return nil
+		}
+		return event
+	})
+
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+	container.AddItem(nil, 0, 1, false)
+	container.AddItem(
+		tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(layout, 0, 3, true).
+			AddItem(nil, 0, 1, false),
+		24, 0, true)
+	container.AddItem(nil, 0, 1, false)
+
+	app.app.SetRoot(container, true)
+}
+
+// runBatchReplay replays the filtered entries in the background, reporting
+// progress in the status bar. The replayed exchanges are added to the session
+// in entry order once the batch finishes, then the summary is shown
+func (app *Application) runBatchReplay(opts replay.BatchOptions) {
+	// The session grows while the batch runs, so it works on a snapshot
+	entries := append([]har.HAREntry(nil), app.harData.Log.Entries...)
+	indices := append([]int(nil), app.filteredEntries...)
+	for _, idx := range indices {
+		entries[idx] = app.targetEntry(idx)
+	}
+	app.batchRunning = true
+	app.showStatusMessage(fmt.Sprintf("Batch replay: 0/%d done...", len(indices)))
+
+	go func() {
+		started := time.Now()
+		completed, failed := 0, 0
+		results := replay.RunBatch(context.Background(), entries, indices, opts, func(result replay.BatchResult) {
+			app.app.QueueUpdateDraw(func() {
+				completed++
+				if !result.Passed() {
+					failed++
+				}
+				app.showStatusMessage(fmt.Sprintf("Batch replay: %d/%d done, %d failed...", completed, len(indices), failed))
+			})
+		})
+		elapsed := time.Since(started)
+
+		app.app.QueueUpdateDraw(func() {
+			app.batchRunning = false
+			replayedAs := make([]int, len(results))
+			for i, result := range results {
+				replayedAs[i] = -1
+				if result.Err == nil {
+					replayedAs[i] = app.appendReplayedEntry(result.Index, result.Replayed, result.Report)
+				}
+			}
+			app.updateRequestsList()
+			app.updateBottomBar()
+			app.showBatchSummary(entries, results, replayedAs, opts, elapsed)
+		})
+	}()
+}
+
+// showBatchSummary lists the outcome of every request in a batch replay.
+// Enter selects the replayed entry under the cursor
+func (app *Application) showBatchSummary(entries []har.HAREntry, results []replay.BatchResult, replayedAs []int, opts replay.BatchOptions, elapsed time.Duration) {
+	passed := 0
+	for _, result := range results {
+		if result.Passed() {
+			passed++
+		}
+	}
+	rate := "no rate limit"
+	if opts.Rate > 0 {
+		rate = fmt.Sprintf("%g req/s", opts.Rate)
+	}
+	summaryView := tview.NewTextView()
+	summaryView.SetDynamicColors(true)
+	summaryView.SetText(fmt.Sprintf("[green]Passed %d[white], [red]failed %d[white]  │  concurrency %d, %s  │  took %s",
+		passed, len(results)-passed, max(opts.Concurrency, 1), rate, elapsed.Round(time.Millisecond)))
+	summaryView.SetBorder(true)
+	summaryView.SetTitle(" ⏵ Batch Replay ")
+	summaryView.SetBorderColor(tcell.ColorPurple)
+
+	table := tview.NewTable()
+	table.SetFixed(1, 0)
+	table.SetSelectable(true, false)
+	table.SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkBlue))
+	table.SetBorder(true)
+	table.SetTitle(" Requests (Enter to select the replay, Esc to close) ")
+
+	for col, title := range []string{"#", "METHOD", "STATUS", "TIME", "VARS", "VERDICT", "URL"} {
+		table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+	for i, result := range results {
+		row := i + 1
+		request := entries[result.Index].Request
+		status, elapsedCell, verdict := "-", "-", ""
+		color := tcell.ColorRed
+		if result.Err != nil {
+			verdict = "ERROR: " + result.Err.Error()
+		} else {
+			status = fmt.Sprintf("%d → %d", result.Report.StatusBefore, result.Report.StatusAfter)
+			if result.Report.StatusBefore == result.Report.StatusAfter {
+				status = strconv.Itoa(result.Report.StatusAfter)
+			}
+			elapsedCell = fmt.Sprintf("%.0fms", result.Replayed.Time)
+			verdict = result.Report.Verdict()
+			if result.Passed() {
+				color = tcell.ColorGreen
+			}
+		}
+		table.SetCell(row, 0, tview.NewTableCell(strconv.Itoa(result.Index)))
+		table.SetCell(row, 1, tview.NewTableCell(request.Method))
+		table.SetCell(row, 2, tview.NewTableCell(status).SetTextColor(color))
+		table.SetCell(row, 3, tview.NewTableCell(elapsedCell))
+		table.SetCell(row, 4, tview.NewTableCell(formatBatchVars(result)).SetTextColor(tcell.ColorAqua))
+		table.SetCell(row, 5, tview.NewTableCell(tview.Escape(truncateString(verdict, 60))).SetTextColor(color))
+		table.SetCell(row, 6, tview.NewTableCell(tview.Escape(request.URL)).SetExpansion(1))
+	}
+	table.Select(1, 0)
+	table.SetSelectedFunc(func(row, column int) {
+		if row < 1 || row > len(replayedAs) || replayedAs[row-1] < 0 {
+			return
+		}
+		app.app.SetRoot(app.layout, true)
+		app.selectEntry(replayedAs[row-1])
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(summaryView, 3, 0, false)
+	layout.AddItem(table, 0, 1, true)
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Rune() == 'q' {
+			app.app.SetRoot(app.layout, true)
+			return nil
+		}
+		return event
+	})
+
+	app.app.SetRoot(layout, true)
+	app.app.SetFocus(table)
+}
+
+// formatBatchVars shows the variables a request used and set, e.g. "<token >csrf"
+func formatBatchVars(result replay.BatchResult) string {
+	var parts []string
+	for _, name := range result.Substituted {
+		parts = append(parts, "<"+name)
+	}
+	for _, name := range result.Extracted {
+		parts = append(parts, ">"+name)
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, " ")
+}
@@ -51,6 +51,7 @@ func (app *Application) createComponents() {
 	app.cookiesView = tview.NewTextView().SetDynamicColors(true).SetWrap(true).SetWordWrap(true)
 	app.timingsView = tview.NewTextView().SetDynamicColors(true).SetWrap(true).SetWordWrap(true)
 	app.rawView = tview.NewTextView().SetDynamicColors(true).SetWrap(true).SetWordWrap(true)
+	app.securityView = tview.NewTextView().SetDynamicColors(true).SetWrap(true).SetWordWrap(true)
 	app.waterfallView = NewWaterfallView()
 	app.waterfallView.SetSelectionChangedFunc(func(entryIndex int) {
 		if entryIndex >= 0 {
@@ -72,6 +73,7 @@ func (app *Application) createComponents() {
 	app.tabs.AddPage("Cookies", app.cookiesView, true, false)
 	app.tabs.AddPage("Timings", app.timingsView, true, false)
 	app.tabs.AddPage("Raw", app.rawView, true, false)
+	app.tabs.AddPage("Security", app.securityView, true, false)
 	
 	// Tab indicator bar
 	app.tabBar = tview.NewTextView().SetDynamicColors(true).SetTextAlign(tview.AlignCenter)
@@ -96,4 +98,5 @@ func (app *Application) styleComponents() {
 	app.timingsView.SetBorder(true).SetTitle(" ⏱️  Timings ").SetTitleAlign(tview.AlignCenter).SetBorderColor(tcell.ColorDarkRed)
 	app.waterfallView.SetBorder(true).SetTitle(" 🌊 Waterfall ").SetTitleAlign(tview.AlignCenter).SetBorderColor(tcell.ColorDarkCyan)
 	app.rawView.SetBorder(true).SetTitle(" 🔍 Raw ").SetTitleAlign(tview.AlignCenter).SetBorderColor(tcell.ColorYellow)
+	app.securityView.SetBorder(true).SetTitle(" 🛡️ Security ").SetTitleAlign(tview.AlignCenter).SetBorderColor(tcell.ColorOrange)
 }
\ No newline at end of file
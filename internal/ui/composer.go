@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/replay"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// composerVariant is an edited request sent from the composer
+type composerVariant struct {
+	draft  replay.Draft
+	entry  int // session entry the response was recorded as
+	status int
+	sent   time.Time
+}
+
+// replayRoot follows ReplayOf links back to the captured entry
+func (app *Application) replayRoot(entryIdx int) int {
+	for {
+		ref := app.harData.Log.Entries[entryIdx].ReplayOf
+		// Replays are always appended after the entry they came from
+		if ref == nil || *ref < 0 || *ref >= entryIdx {
+			return entryIdx
+		}
+		entryIdx = *ref
+	}
+}
+
+// showComposer opens an editable copy of a request. Sent variants are replayed,
+// added to the session and kept in a per-entry history. Callers make sure
+// loading has finished
+func (app *Application) showComposer(entryIdx int, draft replay.Draft) {
+	root := app.replayRoot(entryIdx)
+
+	methodField := tview.NewInputField().SetLabel("Method ").SetFieldWidth(10)
+	urlField := tview.NewInputField().SetLabel("URL ")
+
+	queryArea := tview.NewTextArea()
+	queryArea.SetBorder(true)
+	queryArea.SetTitle(" Query (name=value per line) ")
+	headersArea := tview.NewTextArea()
+	headersArea.SetBorder(true)
+	headersArea.SetTitle(" Headers (Name: Value per line) ")
+	bodyArea := tview.NewTextArea()
+	bodyArea.SetBorder(true)
+	bodyArea.SetTitle(" Body ")
+
+	load := func(d replay.Draft) {
+		methodField.SetText(d.Method)
+		urlField.SetText(d.URL)
+		queryArea.SetText(replay.FormatQuery(d.Query), false)
+		headersArea.SetText(replay.FormatHeaders(d.Headers), false)
+		bodyArea.SetText(d.Body, false)
+		draft = d
+	}
+	load(draft)
+
+	// The history offers the captured request and every variant sent from it
+	history := app.composerHistory[root]
+	historyDropDown := tview.NewDropDown().SetLabel("History ")
+	options := []string{fmt.Sprintf("Captured request (entry %d)", root)}
+	for i, variant := range history {
+		options = append(options, fmt.Sprintf("Variant %d: %s %s → %d (entry %d, %s)", i+1,
+			variant.draft.Method, truncateString(variant.draft.FullURL(), 60), variant.status,
+			variant.entry, variant.sent.Format("15:04:05")))
+	}
+	historyDropDown.SetOptions(options, func(text string, index int) {
+		switch {
+		case index == 0:
+			load(replay.NewDraft(app.targetEntry(root).Request))
+		case index > 0 && index <= len(history):
+			load(history[index-1].draft)
+		}
+	})
+
+	helpBar := tview.NewTextView()
+	helpBar.SetDynamicColors(true)
+	defaultHelp := "[yellow]Tab[white] next field  [yellow]Ctrl-S[white] send  [yellow]Ctrl-E[white] edit body in $EDITOR  [yellow]Esc[white] cancel"
+	helpBar.SetText(defaultHelp)
+
+	requestLine := tview.NewFlex().
+		AddItem(methodField, 18, 0, true).
+		AddItem(urlField, 0, 1, false)
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(requestLine, 1, 0, true)
+	layout.AddItem(historyDropDown, 1, 0, false)
+	layout.AddItem(queryArea, 6, 0, false)
+	layout.AddItem(headersArea, 0, 2, false)
+	layout.AddItem(bodyArea, 0, 2, false)
+	layout.AddItem(helpBar, 1, 0, false)
+	layout.SetBorder(true)
+	layout.SetTitle(fmt.Sprintf(" ✎ Compose Request (from entry %d) ", entryIdx))
+	layout.SetBorderColor(tcell.ColorPurple)
+
+	fields := []tview.Primitive{methodField, urlField, historyDropDown, queryArea, headersArea, bodyArea}
+	focused := 0
+	focus := func(i int) {
+		focused = (i + len(fields)) % len(fields)
+		app.app.SetFocus(fields[focused])
+	}
+
+	send := func() {
+		headers, err := replay.ParseHeaders(headersArea.GetText())
+		if err != nil {
+			helpBar.SetText(fmt.Sprintf("[red]%v[white]", err))
+			return
+		}
+		edited := replay.Draft{
+			Method:   methodField.GetText(),
+			URL:      urlField.GetText(),
+			Query:    replay.ParseQuery(queryArea.GetText()),
+			Headers:  headers,
+			Body:     bodyArea.GetText(),
+			MimeType: draft.MimeType,
+		}
+		for _, header := range headers {
+			if strings.EqualFold(header.Name, "content-type") {
+				edited.MimeType = header.Value
+			}
+		}
+		request, err := edited.Request()
+		if err != nil {
+			helpBar.SetText(fmt.Sprintf("[red]%v[white]", err))
+			return
+		}
+
+		entry := app.harData.Log.Entries[root]
+		entry.Request = request
+		app.app.SetRoot(app.layout, true)
+		app.sendReplay(root, entry, func(index int, recorded har.HAREntry, report replay.Report) {
+			if app.composerHistory == nil {
+				app.composerHistory = make(map[int][]composerVariant)
+			}
+			app.composerHistory[root] = append(app.composerHistory[root], composerVariant{
+				draft: edited, entry: index, status: recorded.Response.Status, sent: time.Now(),
+			})
+			// Mark the captured entry so x compares it with the response just shown
+			app.markedEntry = root
+			app.selectEntry(index)
+			verdict := "matches"
+			if !report.Pass {
+				verdict = "differs from"
+			}
+			app.showStatusMessage(fmt.Sprintf("Variant %d of entry %d: %d %s in %.0fms, %s the capture - press x to compare",
+				len(app.composerHistory[root]), root, recorded.Response.Status, recorded.Response.StatusText, recorded.Time, verdict))
+		})
+	}
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if historyDropDown.IsOpen() {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyEscape:
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case tcell.KeyTab:
+			focus(focused + 1)
+			return nil
+		case tcell.KeyBacktab:
+			focus(focused - 1)
+			return nil
+		case tcell.KeyCtrlS:
+			send()
+			return nil
+		case tcell.KeyCtrlE:
+			var edited string
+			var err error
+			app.app.Suspend(func() {
+				edited, err = app.openInEditor(bodyArea.GetText(), app.getExtensionFromMimeType(draft.MimeType))
+			})
+			if err != nil {
+				helpBar.SetText(fmt.Sprintf("[red]Editor error: %v[white]", err))
+			} else {
+				bodyArea.SetText(edited, false)
+				helpBar.SetText(defaultHelp)
+			}
+			return nil
+		}
+		return event
+	})
+
+	app.app.SetRoot(layout, true)
+	focus(0)
+}
@@ -0,0 +1,280 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/diff"
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// diffContextLines is how many unchanged body lines surround each change
+const diffContextLines = 3
+
+// showHARDiff compares the first two open files request by request
+func (app *Application) showHARDiff() {
+	if app.isLoading || app.harData == nil {
+		app.showStatusMessage("Wait for loading to finish before diffing")
+		return
+	}
+	sources := app.getSources()
+	if len(sources) < 2 {
+		app.showStatusMessage("Open two HAR files to diff them: har-tui before.har after.har")
+		return
+	}
+
+	var before, after []har.HAREntry
+	for _, entry := range app.harData.Log.Entries {
+		switch entry.Source {
+		case sources[0]:
+			before = append(before, entry)
+		case sources[1]:
+			after = append(after, entry)
+		}
+	}
+	result := diff.Compare(before, after, diff.Options{TimeThreshold: diff.DefaultTimeThreshold})
+
+	summary := result.Summary()
+	summaryView := tview.NewTextView()
+	summaryView.SetDynamicColors(true)
+	summaryView.SetText(fmt.Sprintf(
+		"[green]+%d added[white]  [red]-%d removed[white]  [yellow]~%d changed[white]  [dim]%d unchanged[white]  │  status changes %d  │  [red]%d slower[white], [green]%d faster[white]  │  time %+.0fms  size %s",
+		summary.Added, summary.Removed, summary.Changed, summary.Unchanged,
+		summary.StatusChanges, summary.Regressions, summary.Improvements, summary.TimeDelta, formatSignedSize(summary.SizeDelta)))
+	summaryView.SetBorder(true)
+	summaryView.SetTitle(fmt.Sprintf(" ⇄ HAR Diff: %s → %s ", tview.Escape(sources[0]), tview.Escape(sources[1])))
+	summaryView.SetBorderColor(tcell.ColorPurple)
+
+	list := tview.NewList()
+	list.ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetSelectedBackgroundColor(tcell.ColorDarkBlue)
+	list.SetBorder(true)
+	list.SetTitle(" Requests ")
+
+	detailView := tview.NewTextView()
+	detailView.SetDynamicColors(true)
+	detailView.SetWrap(false)
+	detailView.SetBorder(true)
+	detailView.SetTitle(" Headers & Bodies ")
+
+	showUnchanged := false
+	var rows []int // indices into result.Entries shown in the list
+	fillList := func() {
+		list.Clear()
+		rows = rows[:0]
+		for i, d := range result.Entries {
+			if d.Kind == diff.Unchanged && !showUnchanged {
+				continue
+			}
+			rows = append(rows, i)
+			list.AddItem(app.formatDiffRow(result, d), "", 0, nil)
+		}
+		if len(rows) == 0 {
+			detailView.SetText("[dim]No differences. Press u to show unchanged requests[white]")
+		}
+	}
+	list.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index >= 0 && index < len(rows) {
+			detailView.SetText(app.formatEntryDiff(result, result.Entries[rows[index]]))
+			detailView.ScrollToBeginning()
+		}
+	})
+	fillList()
+	if len(rows) > 0 {
+		detailView.SetText(app.formatEntryDiff(result, result.Entries[rows[0]]))
+	}
+
+	helpBar := tview.NewTextView()
+	helpBar.SetDynamicColors(true)
+	helpBar.SetText("[yellow]j/k[white] move  [yellow]Tab[white] switch pane  [yellow]u[white] toggle unchanged  [yellow]q/Esc[white] close")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(summaryView, 3, 0, false)
+	layout.AddItem(list, 0, 1, true)
+	layout.AddItem(detailView, 0, 2, false)
+	layout.AddItem(helpBar, 1, 0, false)
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case tcell.KeyTab:
+			if list.HasFocus() {
+				app.app.SetFocus(detailView)
+			} else {
+				app.app.SetFocus(list)
+			}
+			return nil
+		}
+		switch event.Rune() {
+		case 'q':
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case 'u':
+			showUnchanged = !showUnchanged
+			fillList()
+			return nil
+		case 'j':
+			if list.HasFocus() {
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			}
+		case 'k':
+			if list.HasFocus() {
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+		}
+		return event
+	})
+
+	app.app.SetRoot(layout, true)
+	app.app.SetFocus(list)
+}
+
+// formatDiffRow renders one compared request for the diff list
+func (app *Application) formatDiffRow(result *diff.Result, d diff.EntryDiff) string {
+	entry, other := diffSides(result, d)
+	var marker string
+	switch d.Kind {
+	case diff.Added:
+		marker = "[green]+[white]"
+	case diff.Removed:
+		marker = "[red]-[white]"
+	case diff.Changed:
+		marker = "[yellow]~[white]"
+	default:
+		marker = "[dim]=[white]"
+	}
+
+	status := fmt.Sprintf("%3d", entry.Response.Status)
+	timing := fmt.Sprintf("%.0fms", entry.Time)
+	size := formatBytes(entry.Response.Content.Size)
+	if other != nil {
+		status = fmt.Sprintf("%3d", other.Response.Status)
+		if d.StatusChanged {
+			status = fmt.Sprintf("[red]%d→%d[white]", other.Response.Status, entry.Response.Status)
+		}
+		timing = fmt.Sprintf("%+.0fms", d.TimeDelta)
+		if d.Regressed(result.Options.TimeThreshold) {
+			timing = "[red]" + timing + "[white]"
+		} else if d.TimeDelta < -result.Options.TimeThreshold {
+			timing = "[green]" + timing + "[white]"
+		}
+		size = formatSignedSize(d.SizeDelta)
+	}
+
+	return fmt.Sprintf("%s [cyan]%-6s[white] %s  [yellow]%s[white]  [blue]%s[white]  %s",
+		marker, entry.Request.Method, status, timing, size, tview.Escape(truncateString(entry.Request.URL, 100)))
+}
+
+// diffSides returns the entry a diff row describes (the after side when
+// present) and, for matched pairs, the before entry
+func diffSides(result *diff.Result, d diff.EntryDiff) (entry har.HAREntry, before *har.HAREntry) {
+	if d.After < 0 {
+		return result.Before[d.Before], nil
+	}
+	if d.Before < 0 {
+		return result.After[d.After], nil
+	}
+	return result.After[d.After], &result.Before[d.Before]
+}
+
+// formatEntryDiff renders the header and body differences of one compared request
+func (app *Application) formatEntryDiff(result *diff.Result, d diff.EntryDiff) string {
+	var before, after har.HAREntry
+	if d.Before >= 0 {
+		before = result.Before[d.Before]
+	}
+	if d.After >= 0 {
+		after = result.After[d.After]
+	}
+	detail := diff.CompareEntries(before, after)
+
+	var text strings.Builder
+	entry, _ := diffSides(result, d)
+	text.WriteString(fmt.Sprintf("[yellow]%s[white] %s [dim](%s)[white]\n", entry.Request.Method, tview.Escape(entry.Request.URL), d.Kind))
+	if d.Before >= 0 && d.After >= 0 {
+		text.WriteString(fmt.Sprintf("Status %d → %d   Time %.0fms → %.0fms (%+.0fms)   Size %d → %d bytes\n",
+			before.Response.Status, after.Response.Status, before.Time, after.Time, d.TimeDelta,
+			before.Response.Content.Size, after.Response.Content.Size))
+	}
+
+	app.writeHeaderDiff(&text, "Request Headers", detail.RequestHeaders)
+	app.writeHeaderDiff(&text, "Response Headers", detail.ResponseHeaders)
+	app.writeBodyDiff(&text, "Request Body", detail.RequestBody, requestMimeType(before), requestMimeType(after))
+	app.writeBodyDiff(&text, "Response Body", detail.ResponseBody, before.Response.Content.MimeType, after.Response.Content.MimeType)
+	return text.String()
+}
+
+func requestMimeType(entry har.HAREntry) string {
+	if entry.Request.PostData == nil {
+		return ""
+	}
+	return entry.Request.PostData.MimeType
+}
+
+func (app *Application) writeHeaderDiff(text *strings.Builder, title string, changes []diff.HeaderChange) {
+	text.WriteString(fmt.Sprintf("\n[yellow]%s:[white]\n", title))
+	changed := 0
+	for _, change := range changes {
+		name := tview.Escape(change.Name)
+		switch change.Kind {
+		case diff.Added:
+			text.WriteString(fmt.Sprintf("[green]+ %s: %s[white]\n", name, tview.Escape(change.After)))
+		case diff.Removed:
+			text.WriteString(fmt.Sprintf("[red]- %s: %s[white]\n", name, tview.Escape(change.Before)))
+		case diff.Changed:
+			text.WriteString(fmt.Sprintf("[red]- %s: %s[white]\n", name, tview.Escape(change.Before)))
+			text.WriteString(fmt.Sprintf("[green]+ %s: %s[white]\n", name, tview.Escape(change.After)))
+		default:
+			continue
+		}
+		changed++
+	}
+	if changed == 0 {
+		text.WriteString("[dim]  no changes[white]\n")
+	}
+}
+
+// writeBodyDiff renders a body line diff, each side highlighted like the Body tab
+func (app *Application) writeBodyDiff(text *strings.Builder, title string, body diff.BodyDiff, beforeMime, afterMime string) {
+	text.WriteString(fmt.Sprintf("\n[yellow]%s:[white]\n", title))
+	if body.Binary {
+		if body.Changed() {
+			text.WriteString(fmt.Sprintf("  binary bodies differ (%d → %d bytes)\n", len(body.Before), len(body.After)))
+		} else {
+			text.WriteString("[dim]  no changes[white]\n")
+		}
+		return
+	}
+	if !diff.HasChanges(body.Lines) {
+		text.WriteString("[dim]  no changes[white]\n")
+		return
+	}
+
+	beforeLines := app.formatter.HighlightLines(body.Before, app.formatter.DetectContentType(body.Before, beforeMime))
+	afterLines := app.formatter.HighlightLines(body.After, app.formatter.DetectContentType(body.After, afterMime))
+	for _, line := range diff.Fold(body.Lines, diffContextLines) {
+		switch line.Op {
+		case diff.OpSkip:
+			text.WriteString(fmt.Sprintf("[purple]@@ %s @@[white]\n", line.Text))
+		case diff.OpDelete:
+			text.WriteString("[red::b]-[-::-] " + beforeLines[line.Before] + "\n")
+		case diff.OpInsert:
+			text.WriteString("[green::b]+[-::-] " + afterLines[line.After] + "\n")
+		default:
+			text.WriteString("  " + afterLines[line.After] + "\n")
+		}
+	}
+}
+
+// formatSignedSize formats a byte count with an explicit sign, e.g. "+1.2KB"
+func formatSignedSize(bytes int) string {
+	if bytes < 0 {
+		return "-" + formatBytes(-bytes)
+	}
+	return "+" + formatBytes(bytes)
+}
@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/diff"
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxJSONDiffValueLength caps how much of a changed JSON value is shown
+const maxJSONDiffValueLength = 80
+
+// showEntryDiff compares two entries of the open capture, a (marked first) against b
+func (app *Application) showEntryDiff(a, b int) {
+	entries := app.harData.Log.Entries
+	if app.isLoading && app.streamingLoader != nil {
+		entries = app.streamingLoader.GetEntries()
+	}
+	if a < 0 || b < 0 || a >= len(entries) || b >= len(entries) {
+		app.showStatusMessage("Marked entry is no longer available")
+		return
+	}
+	before, after := entries[a], entries[b]
+
+	timingView := tview.NewTextView()
+	timingView.SetDynamicColors(true)
+	timingView.SetWrap(false)
+	timingView.SetText(formatEntryComparison(a, b, before, after))
+	timingView.SetBorder(true)
+	timingView.SetTitle(fmt.Sprintf(" ⇄ Entry Diff: #%d → #%d ", a, b))
+	timingView.SetBorderColor(tcell.ColorPurple)
+
+	detailView := tview.NewTextView()
+	detailView.SetDynamicColors(true)
+	detailView.SetWrap(false)
+	detailView.SetText(app.formatEntryPairDiff(before, after))
+	detailView.SetBorder(true)
+	detailView.SetTitle(" Headers & Bodies ")
+
+	helpBar := tview.NewTextView()
+	helpBar.SetDynamicColors(true)
+	helpBar.SetText("[yellow]j/k[white] scroll  [yellow]Tab[white] switch pane  [yellow]q/Esc[white] close")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(timingView, 14, 0, false)
+	layout.AddItem(detailView, 0, 1, true)
+	layout.AddItem(helpBar, 1, 0, false)
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case tcell.KeyTab:
+			if detailView.HasFocus() {
+				app.app.SetFocus(timingView)
+			} else {
+				app.app.SetFocus(detailView)
+			}
+			return nil
+		}
+		switch event.Rune() {
+		case 'q':
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		}
+		return event
+	})
+
+	app.app.SetRoot(layout, true)
+	app.app.SetFocus(detailView)
+}
+
+// formatEntryComparison lists both requests and their timings next to each other
+func formatEntryComparison(a, b int, before, after har.HAREntry) string {
+	var text strings.Builder
+	for _, side := range []struct {
+		label string
+		index int
+		entry har.HAREntry
+	}{{"A", a, before}, {"B", b, after}} {
+		text.WriteString(fmt.Sprintf("[purple::b]%s[-::-] #%-4d [cyan]%-6s[white] %3d  %-8s %s\n",
+			side.label, side.index, side.entry.Request.Method, side.entry.Response.Status,
+			formatBytes(side.entry.Response.Content.Size), tview.Escape(truncateString(side.entry.Request.URL, 100))))
+	}
+
+	text.WriteString(fmt.Sprintf("\n[yellow]%-10s %12s %12s %12s[white]\n", "Timing", "A", "B", "Δ"))
+	phases := []struct {
+		name          string
+		before, after float64
+	}{
+		{"Blocked", before.Timings.Blocked, after.Timings.Blocked},
+		{"DNS", before.Timings.DNS, after.Timings.DNS},
+		{"Connect", before.Timings.Connect, after.Timings.Connect},
+		{"SSL/TLS", before.Timings.SSL, after.Timings.SSL},
+		{"Send", before.Timings.Send, after.Timings.Send},
+		{"Wait", before.Timings.Wait, after.Timings.Wait},
+		{"Receive", before.Timings.Receive, after.Timings.Receive},
+		{"Total", before.Time, after.Time},
+	}
+	for _, phase := range phases {
+		text.WriteString(fmt.Sprintf("%-10s %12s %12s %s\n", phase.name,
+			formatTimingValue(phase.before), formatTimingValue(phase.after), formatTimingDelta(phase.before, phase.after)))
+	}
+	return text.String()
+}
+
+// formatTimingValue formats a phase duration; HAR uses -1 for phases that don't apply
+func formatTimingValue(ms float64) string {
+	if ms < 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fms", ms)
+}
+
+func formatTimingDelta(before, after float64) string {
+	delta := max(after, 0) - max(before, 0)
+	text := fmt.Sprintf("%12s", fmt.Sprintf("%+.2fms", delta))
+	switch {
+	case delta > 0:
+		return "[red]" + text + "[white]"
+	case delta < 0:
+		return "[green]" + text + "[white]"
+	}
+	return "[dim]" + text + "[white]"
+}
+
+// formatEntryPairDiff renders header set differences and body differences,
+// structural for JSON bodies and line by line otherwise
+func (app *Application) formatEntryPairDiff(before, after har.HAREntry) string {
+	detail := diff.CompareEntries(before, after)
+
+	var text strings.Builder
+	app.writeHeaderDiff(&text, "Request Headers", detail.RequestHeaders)
+	app.writeHeaderDiff(&text, "Response Headers", detail.ResponseHeaders)
+	app.writeEntryBodyDiff(&text, "Request Body", detail.RequestBody, requestMimeType(before), requestMimeType(after))
+	app.writeEntryBodyDiff(&text, "Response Body", detail.ResponseBody, before.Response.Content.MimeType, after.Response.Content.MimeType)
+	return text.String()
+}
+
+func (app *Application) writeEntryBodyDiff(text *strings.Builder, title string, body diff.BodyDiff, beforeMime, afterMime string) {
+	if !body.IsJSON {
+		app.writeBodyDiff(text, title, body, beforeMime, afterMime)
+		return
+	}
+
+	text.WriteString(fmt.Sprintf("\n[yellow]%s (JSON):[white]\n", title))
+	writeJSONPathChanges(text, body.JSONChanges)
+}
+
+// writeJSONPathChanges lists added, removed and changed JSON paths with their values
+func writeJSONPathChanges(text *strings.Builder, changes []diff.PathChange) {
+	if len(changes) == 0 {
+		text.WriteString("[dim]  no changes[white]\n")
+		return
+	}
+	for _, change := range changes {
+		path := change.Path
+		if path == "" {
+			path = "(root)"
+		}
+		path = tview.Escape(path)
+		switch change.Kind {
+		case diff.Added:
+			text.WriteString(fmt.Sprintf("[green]+ %s:[white] %s\n", path, formatJSONDiffValue(change.After)))
+		case diff.Removed:
+			text.WriteString(fmt.Sprintf("[red]- %s:[white] %s\n", path, formatJSONDiffValue(change.Before)))
+		default:
+			text.WriteString(fmt.Sprintf("[yellow]~ %s:[white] %s → %s\n", path,
+				formatJSONDiffValue(change.Before), formatJSONDiffValue(change.After)))
+		}
+	}
+}
+
+// formatJSONDiffValue collapses a raw JSON value onto one short line
+func formatJSONDiffValue(raw string) string {
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(raw)); err != nil {
+		return tview.Escape(truncateString(raw, maxJSONDiffValueLength))
+	}
+	return tview.Escape(truncateString(compact.String(), maxJSONDiffValueLength))
+}
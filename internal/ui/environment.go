@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/cnharrison/har-tui/internal/env"
+	"github.com/cnharrison/har-tui/internal/har"
+)
+
+// loadEnvironments reads the config file and selects its default environment
+func (app *Application) loadEnvironments() {
+	config, err := env.LoadDefault()
+	if err != nil {
+		app.config = &env.Config{}
+		app.showStatusMessage(fmt.Sprintf("Config error: %v", err))
+		return
+	}
+	app.config = config
+	if config.Default != "" {
+		app.environment, _ = config.Get(config.Default)
+	}
+}
+
+// cycleEnvironment selects the next environment, going back to none after the last
+func (app *Application) cycleEnvironment() {
+	if app.config == nil {
+		app.loadEnvironments()
+	}
+	names := app.config.Names()
+	if len(names) == 0 {
+		if path, err := env.DefaultPath(); err == nil {
+			app.showStatusMessage(fmt.Sprintf("No environments configured - add them to %s", path))
+		}
+		return
+	}
+
+	next := 0
+	if app.environment != nil {
+		for i, name := range names {
+			if name == app.environment.Name {
+				next = i + 1
+				break
+			}
+		}
+	}
+	if next >= len(names) {
+		app.environment = nil
+		app.showStatusMessage("Environment: none - requests go to the captured hosts")
+		return
+	}
+	app.environment, _ = app.config.Get(names[next])
+	app.showStatusMessage(fmt.Sprintf("Environment: %s - replays and exports are rewritten for it", names[next]))
+}
+
+// targetEntry returns an entry with its request rewritten for the active environment
+func (app *Application) targetEntry(entryIdx int) har.HAREntry {
+	return app.environment.Apply(app.currentEntries()[entryIdx])
+}
@@ -1,9 +1,11 @@
 package ui
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
 )
 
 // setupEventHandling configures all event handlers
@@ -11,13 +13,13 @@ func (app *Application) setupEventHandling() {
 	// Set up search input handlers
 	app.searchInput.SetChangedFunc(func(text string) {
 		app.filterState.SetTextFilter(text)
+		app.updateSearchTitle(true)
 		app.updateRequestsList()
 		app.updateBottomBar()
 	})
 	
 	app.searchInput.SetDoneFunc(func(key tcell.Key) {
 		if key == tcell.KeyEscape || key == tcell.KeyEnter {
-			app.searchInput.SetTitle(" 🔍 Search ")
 			app.focusOnBottom = false
 			app.updateFocusStyles()
 			app.app.SetFocus(app.requests)
@@ -25,13 +27,11 @@ func (app *Application) setupEventHandling() {
 	})
 	
 	app.searchInput.SetFocusFunc(func() {
-		app.searchInput.SetTitle(" 🔍 Searching... ")
-		app.searchInput.SetBorderColor(tcell.ColorYellow)
+		app.updateSearchTitle(true)
 	})
 	
 	app.searchInput.SetBlurFunc(func() {
-		app.searchInput.SetTitle(" 🔍 Search ")
-		app.searchInput.SetBorderColor(tcell.ColorGreen)
+		app.updateSearchTitle(false)
 	})
 	
 	// Set up request selection handler
@@ -43,6 +43,28 @@ func (app *Application) setupEventHandling() {
 	app.app.SetInputCapture(app.handleInput)
 }
 
+// updateSearchTitle shows the active search modes on the search box, and
+// query or regex errors inline while typing
+func (app *Application) updateSearchTitle(focused bool) {
+	if err := app.filterState.QueryError(); err != nil && focused {
+		app.searchInput.SetTitle(fmt.Sprintf(" ⚠️  %s (plain text search) ", tview.Escape(err.Error())))
+		app.searchInput.SetBorderColor(tcell.ColorRed)
+		return
+	}
+	
+	title := " 🔍 Search"
+	color := tcell.ColorGreen
+	if focused {
+		title = " 🔍 Searching..."
+		color = tcell.ColorYellow
+	}
+	if modes := app.filterState.SearchModeLabel(); modes != "" {
+		title += tview.Escape(fmt.Sprintf(" [%s]", modes))
+	}
+	app.searchInput.SetTitle(title + " ")
+	app.searchInput.SetBorderColor(color)
+}
+
 // startAnimationLoop starts the animation loop for focus arrows and status messages
 func (app *Application) startAnimationLoop() {
 	go func() {
@@ -50,6 +72,7 @@ func (app *Application) startAnimationLoop() {
 			time.Sleep(animationIntervalMs * time.Millisecond)
 			app.animationFrame++
 			app.app.QueueUpdateDraw(func() {
+				app.flushPendingEntries()
 				app.updateFocusStyles()
 				app.updateBottomBarSafe() // Use safe version that doesn't interfere with JSON navigation
 			})
@@ -0,0 +1,115 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/scan"
+	"github.com/cnharrison/har-tui/internal/severity"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// severityColors color findings by severity, Low to Critical
+var severityColors = []string{"blue", "yellow", "orange", "red"}
+
+// showFindings scans the filtered entries for leaked secrets and lists
+// them, most severe first
+func (app *Application) showFindings() {
+	if app.isLoading || app.harData == nil {
+		app.showStatusMessage("Wait for loading to finish before scanning")
+		return
+	}
+	findings := scan.Entries(app.currentEntries(), app.filteredEntries)
+	counts := scan.Counts(findings)
+
+	summaryView := tview.NewTextView()
+	summaryView.SetDynamicColors(true)
+	summaryView.SetBorder(true)
+	summaryView.SetTitle(" 🔑 Findings ")
+	summaryView.SetBorderColor(tcell.ColorRed)
+	summaryView.SetText(fmt.Sprintf("%d findings in %d requests  │  [red]%d critical[white]  [orange]%d high[white]  [yellow]%d medium[white]  [blue]%d low[white]",
+		len(findings), len(app.filteredEntries), counts[severity.Critical], counts[severity.High], counts[severity.Medium], counts[severity.Low]))
+
+	list := tview.NewList()
+	list.ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetSelectedBackgroundColor(tcell.ColorDarkBlue)
+	list.SetBorder(true)
+	list.SetTitle(" Leaked secrets and personal data (Enter jumps to the request) ")
+	for _, finding := range findings {
+		list.AddItem(formatFinding(finding), "", 0, nil)
+	}
+	if len(findings) == 0 {
+		list.AddItem("[dim]Nothing found in the filtered requests[white]", "", 0, nil)
+	}
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index < len(findings) {
+			app.app.SetRoot(app.layout, true)
+			app.selectEntry(findings[index].Entry)
+			app.switchToTab(findingTab(findings[index].Location))
+		}
+	})
+
+	helpBar := tview.NewTextView()
+	helpBar.SetDynamicColors(true)
+	helpBar.SetText("[yellow]j/k[white] move  [yellow]Enter[white] go to request  [yellow]Z[white] sanitize  [yellow]q/Esc[white] close")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(summaryView, 3, 0, false)
+	layout.AddItem(list, 0, 1, true)
+	layout.AddItem(helpBar, 1, 0, false)
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			app.app.SetRoot(app.layout, true)
+			return nil
+		}
+		switch event.Rune() {
+		case 'q':
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case 'Z':
+			app.showSanitizePreview()
+			return nil
+		case 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		}
+		return event
+	})
+
+	app.app.SetRoot(layout, true)
+	app.app.SetFocus(list)
+}
+
+// formatFinding renders a finding for the list
+func formatFinding(finding scan.Finding) string {
+	return fmt.Sprintf("[%s]%-8s[white] [dim]#%-4d[white] %-40s [yellow]%-24s[white] %s",
+		severityColors[finding.Severity], strings.ToUpper(finding.Severity.String()), finding.Entry,
+		tview.Escape(finding.Location), finding.Rule, tview.Escape(finding.Preview()))
+}
+
+// findingTab is the detail tab that shows a finding's location
+func findingTab(location string) int {
+	switch {
+	case strings.Contains(location, " cookie "):
+		return 3 // Cookies
+	case strings.HasPrefix(location, "response body"):
+		return 2 // Body
+	case strings.HasPrefix(location, "response"):
+		return 1 // Response
+	}
+	return 0 // Request, which shows the URL, headers and body
+}
+
+// switchToTab shows a detail tab as Tab and Shift+Tab do
+func (app *Application) switchToTab(tab int) {
+	tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw", "Security"}
+	app.currentTab = tab
+	app.tabs.SwitchToPage(tabNames[app.currentTab])
+	app.updateTabBar()
+	app.updateFocusStyles()
+	app.updateTabContent(app.requests.GetCurrentItem())
+}
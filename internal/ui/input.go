@@ -9,6 +9,7 @@ import (
 	"github.com/cnharrison/har-tui/internal/har"
 	"github.com/cnharrison/har-tui/internal/filter"
 	"github.com/cnharrison/har-tui/internal/export"
+	"github.com/cnharrison/har-tui/internal/replay"
 	"github.com/cnharrison/har-tui/pkg/clipboard"
 )
 
@@ -20,13 +21,14 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	if app.app.GetFocus() == app.searchInput {
 		// Handle escape to exit search
 		if event.Key() == tcell.KeyEscape {
-			app.searchInput.SetTitle(" 🔍 Search ")
-			app.searchInput.SetBorderColor(tcell.ColorGreen)
 			app.focusOnBottom = false
 			app.updateFocusStyles()
 			app.app.SetFocus(app.requests)
 			return nil
 		}
+		if app.handleSearchModeKey(event, true) {
+			return nil
+		}
 		// Don't block any letter keys when typing in search
 		switch event.Key() {
 		case tcell.KeyTab, tcell.KeyBacktab:
@@ -36,12 +38,16 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 		return event
 	}
 	
+	if app.handleSearchModeKey(event, false) {
+		return nil
+	}
+	
 	// Global navigation
 	switch event.Key() {
 	case tcell.KeyTab:
 		// Tab navigation should always work - switch to next tab
-		app.currentTab = (app.currentTab + 1) % 6
-		tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw"}
+		app.currentTab = (app.currentTab + 1) % 7
+		tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw", "Security"}
 		app.tabs.SwitchToPage(tabNames[app.currentTab])
 		app.updateTabBar()
 		app.updateFocusStyles()
@@ -50,8 +56,8 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 		return nil
 	case tcell.KeyBacktab:
 		// Shift+Tab navigation should always work - switch to previous tab
-		app.currentTab = (app.currentTab - 1 + 6) % 6
-		tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw"}
+		app.currentTab = (app.currentTab - 1 + 7) % 7
+		tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw", "Security"}
 		app.tabs.SwitchToPage(tabNames[app.currentTab])
 		app.updateTabBar()
 		app.updateFocusStyles()
@@ -141,8 +147,8 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	case 'h':
 		if app.focusOnBottom {
 			// Navigate tabs when bottom panel is focused
-			app.currentTab = (app.currentTab - 1 + 6) % 6
-			tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw"}
+			app.currentTab = (app.currentTab - 1 + 7) % 7
+			tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw", "Security"}
 			app.tabs.SwitchToPage(tabNames[app.currentTab])
 			app.updateTabBar()
 			app.updateFocusStyles()
@@ -165,8 +171,8 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	case 'l':
 		if app.focusOnBottom {
 			// Navigate tabs when bottom panel is focused
-			app.currentTab = (app.currentTab + 1) % 6
-			tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw"}
+			app.currentTab = (app.currentTab + 1) % 7
+			tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw", "Security"}
 			app.tabs.SwitchToPage(tabNames[app.currentTab])
 			app.updateTabBar()
 			app.updateFocusStyles()
@@ -186,6 +192,54 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 				app.showStatusMessage(fmt.Sprintf("Filtering by type: %s", typeFilters[app.selectedFilterIndex]))
 			}
 		}
+	case 'p', 'P':
+		// Cycle through the log's pages (navigations)
+		pages := app.getPages()
+		if len(pages) == 0 {
+			app.showStatusMessage("This HAR has no pages")
+			return nil
+		}
+		if event.Rune() == 'p' {
+			app.selectedPageIndex = (app.selectedPageIndex + 1) % (len(pages) + 1)
+		} else {
+			app.selectedPageIndex = (app.selectedPageIndex - 1 + len(pages) + 1) % (len(pages) + 1)
+		}
+		if app.selectedPageIndex == 0 {
+			app.filterState.SetPageFilter("")
+			app.showStatusMessage("Showing all pages")
+		} else {
+			page := pages[app.selectedPageIndex-1]
+			app.filterState.SetPageFilter(page.ID)
+			app.showStatusMessage(fmt.Sprintf("Filtering by page: %s", app.getPageLabel(page)))
+		}
+		app.updateRequestsList()
+		app.updateBottomBar()
+		app.updateFilterBar()
+		return nil
+	case 'f', 'F':
+		// Cycle through the source files when several HARs are open
+		sources := app.getSources()
+		if len(sources) == 0 {
+			app.showStatusMessage("Only one HAR file is open")
+			return nil
+		}
+		if event.Rune() == 'f' {
+			app.selectedSourceIndex = (app.selectedSourceIndex + 1) % (len(sources) + 1)
+		} else {
+			app.selectedSourceIndex = (app.selectedSourceIndex - 1 + len(sources) + 1) % (len(sources) + 1)
+		}
+		if app.selectedSourceIndex == 0 {
+			app.filterState.SetSourceFilter("")
+			app.showStatusMessage("Showing all files")
+		} else {
+			source := sources[app.selectedSourceIndex-1]
+			app.filterState.SetSourceFilter(source)
+			app.showStatusMessage(fmt.Sprintf("Filtering by file: %s", source))
+		}
+		app.updateRequestsList()
+		app.updateBottomBar()
+		app.updateFilterBar()
+		return nil
 	case '/':
 		// Focus on search input for inline filtering and clear any content
 		app.searchInput.SetText("")
@@ -215,6 +269,8 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	case 'a':
 		app.filterState.Reset()
 		app.selectedFilterIndex = 0
+		app.selectedPageIndex = 0
+		app.selectedSourceIndex = 0
 		app.searchInput.SetText("") // Clear the search box visually
 		app.updateRequestsList()
 		app.updateBottomBar()
@@ -223,7 +279,7 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	case 'b':
 		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
 			entryIdx := app.filteredEntries[currentIndex]
-			entry := app.harData.Log.Entries[entryIdx]
+			entry := app.shareEntry(app.currentEntries()[entryIdx])
 			if entry.Response.Content.Text != "" {
 				bodyText := entry.Response.Content.Text
 				filename := app.generateDescriptiveFilename(entry, ".body.txt")
@@ -237,8 +293,8 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	case 'c':
 		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
 			entryIdx := app.filteredEntries[currentIndex]
-			entry := app.harData.Log.Entries[entryIdx]
-			curl := export.GenerateCurlCommand(entry)
+			entry := app.currentEntries()[entryIdx]
+			curl := export.GenerateCurlCommand(app.shareEntry(app.targetEntry(entryIdx)))
 			filename := app.generateDescriptiveFilename(entry, ".curl.sh")
 			if err := os.WriteFile(filename, []byte(curl), 0755); err == nil {
 				app.showStatusMessage(fmt.Sprintf("cURL saved to %s", filename))
@@ -279,20 +335,42 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	case 'R':
 		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
 			entryIdx := app.filteredEntries[currentIndex]
-			entry := app.harData.Log.Entries[entryIdx]
-			app.showReplayModal(entry)
+			app.showReplayModal(entryIdx)
 		}
 	case 'm': // Generate markdown summary and copy to clipboard
 		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
 			entryIdx := app.filteredEntries[currentIndex]
-			entry := app.harData.Log.Entries[entryIdx]
-			summary := export.GenerateMarkdownSummary(entry)
+			entry := app.currentEntries()[entryIdx]
+			summary := export.GenerateMarkdownSummary(app.exportEntry(entry))
 			if err := clipboard.CopyToClipboard(summary); err == nil {
 				app.showStatusMessage("Markdown summary copied to clipboard!")
 			} else {
 				app.showStatusMessage(fmt.Sprintf("Clipboard error: %v", err))
 			}
 		}
+	case 'V': // Show how a replayed response compares with the recording
+		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) && app.harData != nil {
+			app.showReplayReport(app.filteredEntries[currentIndex])
+		}
+		return nil
+	case 'M': // Serve recorded responses, or show the live hits
+		app.toggleMockServer()
+		return nil
+	case 'N': // Cycle the environment replays and exports are rewritten for
+		app.cycleEnvironment()
+		app.updateBottomBar()
+		return nil
+	case 'B': // Replay every filtered request in order
+		app.showBatchReplayForm()
+		return nil
+	case 'C': // Compose an edited copy of the request and send it
+		if app.isLoading || app.harData == nil {
+			app.showStatusMessage("Wait for loading to finish before composing requests")
+		} else if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
+			entryIdx := app.filteredEntries[currentIndex]
+			app.showComposer(entryIdx, replay.NewDraft(app.targetEntry(entryIdx).Request))
+		}
+		return nil
 	case 'E': // Edit current content in $EDITOR
 		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
 			app.showEditorModal(currentIndex)
@@ -300,11 +378,49 @@ func (app *Application) handleInput(event *tcell.EventKey) *tcell.EventKey {
 	case 'y': // Copy modal (yank)
 		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
 			entryIdx := app.filteredEntries[currentIndex]
-			entry := app.harData.Log.Entries[entryIdx]
+			entry := app.currentEntries()[entryIdx]
 			app.showCopyModal(entry)
 		}
 	case 'S': // Save filtered HAR to file
 		app.saveFilteredHAR()
+	case 'Z': // Preview what sanitizing masks and turn it on or off
+		app.showSanitizePreview()
+		return nil
+	case 'K': // Scan for leaked secrets and personal data
+		app.showFindings()
+		return nil
+	case 'A': // Audit security headers, cookies and mixed content
+		app.showAuditReport()
+		return nil
+	case 'r': // Stop recording or following and keep the session open
+		if app.recording != nil {
+			app.stopRecording()
+		} else if app.following {
+			app.streamingLoader.StopFollowing()
+		}
+		return nil
+	case 'D': // Diff the first two open files
+		app.showHARDiff()
+		return nil
+	case 'x': // Mark an entry, then diff it with the next one marked
+		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
+			entryIdx := app.filteredEntries[currentIndex]
+			switch app.markedEntry {
+			case -1:
+				app.markedEntry = entryIdx
+				app.showStatusMessage(fmt.Sprintf("Marked entry %d - select another and press x to diff", entryIdx))
+			case entryIdx:
+				app.markedEntry = -1
+				app.showStatusMessage("Mark cleared")
+			default:
+				marked := app.markedEntry
+				app.markedEntry = -1
+				app.showEntryDiff(marked, entryIdx)
+			}
+			app.updateRequestsList()
+			app.requests.SetCurrentItem(currentIndex)
+		}
+		return nil
 	}
 	return event
 }
@@ -398,6 +514,39 @@ func (app *Application) navigateToBottom() {
 	}
 }
 
+// handleSearchModeKey toggles regex (Ctrl+R), case-sensitive (Ctrl+T) and field scope (Ctrl+S) search
+func (app *Application) handleSearchModeKey(event *tcell.EventKey, searchFocused bool) bool {
+	var message string
+	switch event.Key() {
+	case tcell.KeyCtrlR:
+		app.filterState.ToggleSearchRegex()
+		message = "Regex search off"
+		if app.filterState.SearchRegex {
+			message = "Regex search on"
+		}
+	case tcell.KeyCtrlT:
+		app.filterState.ToggleSearchCaseSensitive()
+		message = "Case-insensitive search"
+		if app.filterState.SearchCaseSensitive {
+			message = "Case-sensitive search"
+		}
+	case tcell.KeyCtrlS:
+		app.filterState.CycleSearchScope()
+		message = "Searching " + app.filterState.SearchScope + " fields"
+		if app.filterState.SearchScope == filter.ScopeAll {
+			message = "Searching all fields"
+		}
+	default:
+		return false
+	}
+
+	app.updateSearchTitle(searchFocused)
+	app.updateRequestsList()
+	app.updateBottomBar()
+	app.showStatusMessage(message)
+	return true
+}
+
 // syncRequestsListFromWaterfall syncs the requests list selection to match waterfall selection
 func (app *Application) syncRequestsListFromWaterfall() {
 	if !app.showWaterfall {
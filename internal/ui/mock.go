@@ -0,0 +1,270 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/mock"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// maxMockHits is how many mock server hits the hits view keeps
+const maxMockHits = 1000
+
+// defaultMockAddress is where the mock server listens unless changed
+const defaultMockAddress = "127.0.0.1:8080"
+
+// mockServerState is a mock server started from the session
+type mockServerState struct {
+	server  *http.Server
+	handler *mock.Server
+	address string
+	hits    []mock.Hit
+	total   int          // hits served, including those no longer kept
+	table   *tview.Table // the hits view while it is open
+}
+
+// toggleMockServer starts a mock server, or shows its hits when one is running
+func (app *Application) toggleMockServer() {
+	if app.isLoading || app.harData == nil {
+		app.showStatusMessage("Wait for loading to finish before serving responses")
+		return
+	}
+	if app.mock != nil {
+		app.showMockHits()
+		return
+	}
+	app.showMockServerForm()
+}
+
+// showMockServerForm asks where to serve the filtered entries and how to match requests
+func (app *Application) showMockServerForm() {
+	if len(app.filteredEntries) == 0 {
+		app.showStatusMessage("No requests match the current filters")
+		return
+	}
+
+	form := tview.NewForm()
+	form.AddInputField("Address", defaultMockAddress, 30, nil, nil)
+	form.AddInputField("Match", strings.Join(mock.DefaultMatch, ","), 30, nil, nil)
+	form.AddDropDown("Mode", []string{"loose", "strict"}, 0, nil)
+	form.AddCheckbox("Cycle", false, nil)
+	form.AddCheckbox("CORS", false, nil)
+
+	helpView := tview.NewTextView()
+	helpView.SetDynamicColors(true)
+	defaultHelp := fmt.Sprintf(`Serves the recorded responses of the %d filtered requests.
+
+[yellow]Match[white] lists the request parts compared: method, host, path, query, body.
+[yellow]Loose[white] serves the closest query and body; [yellow]strict[white] requires them equal.
+Repeated requests get their recorded responses in order, then the last
+again, or the first again with [yellow]Cycle[white]. [yellow]CORS[white] allows calls from any origin.`, len(app.filteredEntries))
+	helpView.SetText(defaultHelp)
+
+	start := func() {
+		address := form.GetFormItemByLabel("Address").(*tview.InputField).GetText()
+		parts, err := mock.ParseMatch(form.GetFormItemByLabel("Match").(*tview.InputField).GetText())
+		if err != nil {
+			helpView.SetText(fmt.Sprintf("[red]%v[white]\n\n%s", tview.Escape(err.Error()), defaultHelp))
+			return
+		}
+		_, mode := form.GetFormItemByLabel("Mode").(*tview.DropDown).GetCurrentOption()
+		opts := mock.Options{
+			Match:  parts,
+			Strict: mode == "strict",
+			Cycle:  form.GetFormItemByLabel("Cycle").(*tview.Checkbox).IsChecked(),
+			CORS:   form.GetFormItemByLabel("CORS").(*tview.Checkbox).IsChecked(),
+		}
+		if err := app.startMockServer(address, opts); err != nil {
+			helpView.SetText(fmt.Sprintf("[red]%v[white]\n\n%s", tview.Escape(err.Error()), defaultHelp))
+			return
+		}
+		app.app.SetRoot(app.layout, true)
+		app.showStatusMessage(fmt.Sprintf("Serving %d responses on http://%s - press M for live hits", len(app.filteredEntries), app.mock.address))
+		app.updateBottomBar()
+	}
+	form.AddButton("Start", start)
+	form.AddButton("Cancel", func() {
+		app.app.SetRoot(app.layout, true)
+	})
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(form, 13, 0, true)
+	layout.AddItem(helpView, 0, 1, false)
+	layout.SetBorder(true)
+	layout.SetTitle(" ⇆ Mock Server ")
+	layout.SetBorderColor(tcell.ColorPurple)
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			app.app.SetRoot(app.layout, true)
+			return nil
+		}
+		return event
+	})
+
+	container := tview.NewFlex().SetDirection(tview.FlexRow)
+	container.AddItem(nil, 0, 1, false)
+	container.AddItem(
+		tview.NewFlex().
+			AddItem(nil, 0, 1, false).
+			AddItem(layout, 0, 3, true).
+			AddItem(nil, 0, 1, false),
+		23, 0, true)
+	container.AddItem(nil, 0, 1, false)
+
+	app.app.SetRoot(container, true)
+}
+
+// startMockServer serves a snapshot of the filtered entries in the background
+func (app *Application) startMockServer(address string, opts mock.Options) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return err
+	}
+	entries := append([]har.HAREntry(nil), app.harData.Log.Entries...)
+	indices := append([]int(nil), app.filteredEntries...)
+
+	state := &mockServerState{address: listener.Addr().String()}
+	state.handler = mock.NewServer(entries, indices, opts, func(hit mock.Hit) {
+		app.app.QueueUpdateDraw(func() {
+			app.recordMockHit(state, hit)
+		})
+	})
+	state.server = &http.Server{Handler: state.handler}
+	app.mock = state
+
+	go func() {
+		if err := state.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			app.app.QueueUpdateDraw(func() {
+				app.showStatusMessage(fmt.Sprintf("Mock server stopped: %v", err))
+				if app.mock == state {
+					app.mock = nil
+				}
+			})
+		}
+	}()
+	return nil
+}
+
+// stopMockServer shuts the mock server down
+func (app *Application) stopMockServer() {
+	if app.mock == nil {
+		return
+	}
+	app.mock.server.Close()
+	app.showStatusMessage(fmt.Sprintf("Mock server on %s stopped after %d hits", app.mock.address, app.mock.total))
+	app.mock = nil
+}
+
+// recordMockHit keeps a hit, announces it and adds it to the hits view if open
+func (app *Application) recordMockHit(state *mockServerState, hit mock.Hit) {
+	if app.mock != state {
+		return // from a server since stopped
+	}
+	state.total++
+	state.hits = append(state.hits, hit)
+	if len(state.hits) > maxMockHits {
+		state.hits = state.hits[len(state.hits)-maxMockHits:]
+		if state.table != nil {
+			state.table.RemoveRow(1)
+		}
+	}
+	if state.table != nil {
+		app.setMockHitRow(state.table, state.table.GetRowCount(), hit)
+		state.table.SetTitle(app.mockHitsTitle())
+	}
+
+	if hit.Matched() {
+		app.showStatusMessage(fmt.Sprintf("Mock: %s %s → entry %d (%d)", hit.Method, truncateString(hit.URL, 60), hit.Entry, hit.Status))
+	} else {
+		app.showStatusMessage(fmt.Sprintf("Mock: [red]unmatched[white] %s %s", hit.Method, truncateString(hit.URL, 60)))
+	}
+	app.updateBottomBar()
+}
+
+// mockHitsTitle describes the running server for the hits view
+func (app *Application) mockHitsTitle() string {
+	unmatched := 0
+	for _, hit := range app.mock.hits {
+		if !hit.Matched() {
+			unmatched++
+		}
+	}
+	return fmt.Sprintf(" ⇆ Mock Server http://%s - %d hits, %d unmatched (Enter select  r reset sequences  s stop  Esc close) ",
+		app.mock.address, app.mock.total, unmatched)
+}
+
+// setMockHitRow fills one row of the hits view
+func (app *Application) setMockHitRow(table *tview.Table, row int, hit mock.Hit) {
+	served, color := "UNMATCHED", tcell.ColorRed
+	if hit.Matched() {
+		served, color = fmt.Sprintf("entry %d", hit.Entry), tcell.ColorGreen
+		if hit.Matches > 1 {
+			served += fmt.Sprintf(" (%d of %d)", hit.Sequence, hit.Matches)
+		}
+	}
+	table.SetCell(row, 0, tview.NewTableCell(hit.Time.Format("15:04:05")))
+	table.SetCell(row, 1, tview.NewTableCell(strconv.Itoa(hit.Status)).SetTextColor(color))
+	table.SetCell(row, 2, tview.NewTableCell(hit.Method))
+	table.SetCell(row, 3, tview.NewTableCell(tview.Escape(hit.URL)).SetExpansion(1))
+	table.SetCell(row, 4, tview.NewTableCell(served).SetTextColor(color))
+}
+
+// showMockHits shows the running server's hits, updated as they arrive
+func (app *Application) showMockHits() {
+	state := app.mock
+	table := tview.NewTable()
+	table.SetFixed(1, 0)
+	table.SetSelectable(true, false)
+	table.SetSelectedStyle(tcell.StyleDefault.Background(tcell.ColorDarkBlue))
+	table.SetBorder(true)
+	table.SetBorderColor(tcell.ColorPurple)
+	for col, title := range []string{"TIME", "STATUS", "METHOD", "URL", "SERVED"} {
+		table.SetCell(0, col, tview.NewTableCell(title).SetTextColor(tcell.ColorYellow).SetSelectable(false))
+	}
+	for i, hit := range state.hits {
+		app.setMockHitRow(table, i+1, hit)
+	}
+	table.SetTitle(app.mockHitsTitle())
+	if len(state.hits) > 0 {
+		table.Select(len(state.hits), 0)
+	}
+	state.table = table
+
+	closeView := func() {
+		state.table = nil
+		app.app.SetRoot(app.layout, true)
+	}
+	table.SetSelectedFunc(func(row, column int) {
+		if row < 1 || row > len(state.hits) || !state.hits[row-1].Matched() {
+			return
+		}
+		closeView()
+		app.selectEntry(state.hits[row-1].Entry)
+	})
+	table.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch {
+		case event.Key() == tcell.KeyEscape || event.Rune() == 'q':
+			closeView()
+		case event.Rune() == 'r':
+			state.handler.Reset()
+			app.showStatusMessage("Mock sequences reset - repeated requests start from the first response")
+		case event.Rune() == 's':
+			closeView()
+			app.stopMockServer()
+			app.updateBottomBar()
+		default:
+			return event
+		}
+		return nil
+	})
+
+	app.app.SetRoot(table, true)
+	app.app.SetFocus(table)
+}
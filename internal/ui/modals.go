@@ -11,6 +11,7 @@ import (
 	"github.com/rivo/tview"
 	"github.com/cnharrison/har-tui/internal/har"
 	"github.com/cnharrison/har-tui/internal/export"
+	"github.com/cnharrison/har-tui/internal/replay"
 	"github.com/cnharrison/har-tui/pkg/clipboard"
 )
 
@@ -33,20 +34,46 @@ func (app *Application) showHelpModal() {
   [cyan]-/_[white]          Zoom out (decrease chart width)
 
 [yellow]Filtering & Sorting:[white]
-  [cyan]/[white]            Open filter dialog (host/path)
+  [cyan]/[white]            Open search (plain text or query, see below)
   [cyan]h/l[white]          Navigate type filter buttons (when top focused)
+  [cyan]p/P[white]          Cycle page filter forward/backward (multi-page HARs)
+  [cyan]f/F[white]          Cycle source file filter forward/backward (several files open)
   [cyan]s[white]            Toggle sort by slowest requests
   [cyan]e[white]            Toggle errors-only view (4xx/5xx)
   [cyan]a[white]            Reset all filters and sorting
 
+[yellow]Search Queries:[white]
+  [cyan]status:>=400 status:4xx[white]       Status comparison or class
+  [cyan]method:POST type:fetch[white]        Method / request type
+  [cyan]host:*.example.com url: path:[white] Host glob, URL or path match
+  [cyan]size:>1mb time:>500ms[white]         Response size / total time
+  [cyan]header:x-request-id body:"oops"[white] Header name(=value), body text
+  [cyan]page:page_1 mime:json[white]         Page id / content type
+  [cyan]AND OR NOT - ( )[white]              Combine terms (space means AND)
+  [cyan]not found -webkit[white]             Plain text without fields matches as one phrase
+  [cyan]Ctrl+R[white]       Toggle regex search (RE2, whole search box)
+  [cyan]Ctrl+T[white]       Toggle case-sensitive search
+  [cyan]Ctrl+S[white]       Cycle search scope: all, URL, headers, bodies
+
 [yellow]Actions:[white]
   [cyan]b[white]            Save current response body to file
   [cyan]c[white]            Save current request as cURL command
   [cyan]m[white]            Generate markdown summary and copy to clipboard
   [cyan]y[white]            Copy modal - copy various request/response parts & JSON paths
   [cyan]E[white]            Edit request/response content in $EDITOR
-  [cyan]R[white]            Replay current request
+  [cyan]C[white]            Compose an edited copy of the request and send it
+  [cyan]V[white]            Show how a replayed response compares with the recording
+  [cyan]R[white]            Replay current request (response added as a new ↻ entry)
+  [cyan]B[white]            Batch replay all filtered requests, chaining extracted values
+  [cyan]N[white]            Cycle the environment replays and exports are rewritten for
+  [cyan]M[white]            Serve filtered responses as a mock server, then show its live hits
+  [cyan]r[white]            Stop recording or following a file and keep the session open
   [cyan]S[white]            Save filtered HAR entries to new file
+  [cyan]Z[white]            Preview masked secrets; turn sanitizing of saves, exports and copies on/off
+  [cyan]K[white]            Scan filtered requests for leaked secrets and personal data
+  [cyan]A[white]            Audit security headers, cookies and mixed content (per request: Security tab)
+  [cyan]D[white]            Diff the first two open HAR files
+  [cyan]x[white]            Mark a request; press again on another to diff the two
   [cyan]q[white]            Quit application`
 
 	// Create help text view
@@ -84,7 +111,12 @@ func (app *Application) showHelpModal() {
 }
 
 // showReplayModal displays the replay confirmation modal
-func (app *Application) showReplayModal(entry har.HAREntry) {
+func (app *Application) showReplayModal(entryIdx int) {
+	if app.isLoading || app.harData == nil {
+		app.showStatusMessage("Wait for loading to finish before replaying")
+		return
+	}
+	entry := app.targetEntry(entryIdx)
 	// Create the full modal text with clear Yes/No options
 	fullText := fmt.Sprintf(`Replay request to:
 [cyan]%s[white]
@@ -117,25 +149,10 @@ func (app *Application) showReplayModal(entry har.HAREntry) {
 	replayContainer.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
 		switch event.Rune() {
 		case 'y', 'Y':
-			// Immediately return to main app and execute
+			// Immediately return to main app and replay in the background
 			app.app.SetRoot(app.layout, true)
 			
-			go func() {
-				curlArgs := export.GenerateCurlArgs(entry)
-				cmd := exec.Command("curl", curlArgs...)
-				out, err := cmd.CombinedOutput()
-				
-				app.app.QueueUpdateDraw(func() {
-					result := "[green]✓[white] Replay completed!\n\n"
-					if err != nil {
-						result = "[red]✗[white] Replay failed!\n\n"
-						result += fmt.Sprintf("[red]Error:[white] %v\n\n", err)
-					}
-					result += "[yellow]Output:[white]\n" + string(out)
-					
-					app.showResultModal(result)
-				})
-			}()
+			app.replayEntry(entryIdx)
 			return nil
 		case 'n', 'N', 'q':
 			app.app.SetRoot(app.layout, true)
@@ -191,6 +208,11 @@ func (app *Application) showResultModal(result string) {
 
 // showCopyModal displays the copy options modal
 func (app *Application) showCopyModal(entry har.HAREntry) {
+	// Copies leave the app, so they are masked when sanitizing is on. Exports
+	// apply the environment first and mask afterwards, so they get the original
+	original := entry
+	entry = app.shareEntry(entry)
+
 	// Check availability
 	hasRequestBody := entry.Request.PostData != nil && entry.Request.PostData.Text != ""
 	hasResponseBody := entry.Response.Content.Text != ""
@@ -230,6 +252,7 @@ func (app *Application) showCopyModal(entry har.HAREntry) {
 	copyText.WriteString("[yellow]7[white] - Full Request Summary\n")
 	copyText.WriteString("[yellow]8[white] - Full Response Summary\n")
 	copyText.WriteString("[yellow]9[white] - cURL Command\n")
+	copyText.WriteString("[yellow]c[white] - Code Snippet...\n")
 	copyText.WriteString("[yellow]0[white] - Raw JSON (Complete Entry)\n")
 	
 	// JSON Path - only show if available
@@ -259,7 +282,7 @@ func (app *Application) showCopyModal(entry har.HAREntry) {
 			AddItem(nil, 0, 1, false).           // Left spacer
 			AddItem(copyView, 0, 1, true).       // Copy content
 			AddItem(nil, 0, 1, false),           // Right spacer
-		15, 0, true) // Fixed height (increased for JSON path option)
+		16, 0, true) // Fixed height (increased for JSON path and snippet options)
 	copyContainer.AddItem(nil, 0, 1, false) // Bottom spacer
 
 	copyContainer.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
@@ -335,8 +358,11 @@ func (app *Application) showCopyModal(entry har.HAREntry) {
 			content = respSummary.String()
 			description = "Response summary copied"
 		case '9':
-			content = export.GenerateCurlCommand(entry)
+			content = export.GenerateCurlCommand(app.exportEntry(original))
 			description = "cURL command copied"
+		case 'c':
+			app.showSnippetPicker(original)
+			return nil
 		case '0':
 			rawJSON, _ := json.MarshalIndent(entry, "", "  ")
 			content = string(rawJSON)
@@ -366,7 +392,7 @@ func (app *Application) showCopyModal(entry har.HAREntry) {
 				return nil
 			}
 		case 'm':
-			content = export.GenerateMarkdownSummary(entry)
+			content = export.GenerateMarkdownSummary(app.exportEntry(original))
 			description = "Markdown summary copied"
 		case 'q':
 			app.app.SetRoot(app.layout, true)
@@ -394,7 +420,8 @@ func (app *Application) showCopyModal(entry har.HAREntry) {
 // showEditorModal displays the editor selection modal
 func (app *Application) showEditorModal(currentIndex int) {
 	entryIdx := app.filteredEntries[currentIndex]
-	entry := app.harData.Log.Entries[entryIdx]
+	// Edited requests are sent, so they target the active environment
+	entry := app.targetEntry(entryIdx)
 	
 	// Check availability
 	hasRequestBody := entry.Request.PostData != nil && entry.Request.PostData.Text != ""
@@ -402,7 +429,7 @@ func (app *Application) showEditorModal(currentIndex int) {
 	
 	// Build conditional editor options
 	var editorText strings.Builder
-	editorText.WriteString("Select content to edit in $EDITOR:\n\n")
+	editorText.WriteString("Select content to edit in $EDITOR\n(edited requests open in the composer):\n\n")
 	editorText.WriteString("[yellow]1[white] - Request Headers\n")
 	
 	// Request Body - strikethrough if not available
@@ -440,20 +467,30 @@ func (app *Application) showEditorModal(currentIndex int) {
 			AddItem(nil, 0, 1, false).           // Left spacer
 			AddItem(editorView, 0, 1, true).     // Editor content
 			AddItem(nil, 0, 1, false),           // Right spacer
-		10, 0, true) // Fixed height
+		11, 0, true) // Fixed height
 	editorContainer.AddItem(nil, 0, 1, false) // Bottom spacer
 
 	editorContainer.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		// Edited requests open in the composer, which needs the loaded session
+		if key := event.Rune(); (key == '1' || key == '2') && (app.isLoading || app.harData == nil) {
+			app.showStatusMessage("Wait for loading to finish before composing requests")
+			app.app.SetRoot(app.layout, true)
+			return nil
+		}
 		switch event.Rune() {
 		case '1':
 			// Edit request headers
+			// Edited request parts open in the composer, ready to send
+			draft := replay.NewDraft(entry.Request)
 			headersJSON, _ := json.MarshalIndent(entry.Request.Headers, "", "  ")
-			if _, err := app.openInEditor(string(headersJSON), "json"); err == nil {
-				app.showStatusMessage("Request headers edited (note: changes not saved to HAR)")
-				app.app.SetRoot(app.layout, true)
-			} else {
+			if edited, err := app.openInEditor(string(headersJSON), "json"); err != nil {
 				app.showStatusMessage(fmt.Sprintf("Editor error: %v", err))
 				app.app.SetRoot(app.layout, true)
+			} else if err := json.Unmarshal([]byte(edited), &draft.Headers); err != nil {
+				app.showStatusMessage(fmt.Sprintf("Edited headers are not valid JSON: %v", err))
+				app.app.SetRoot(app.layout, true)
+			} else {
+				app.showComposer(entryIdx, draft)
 			}
 			return nil
 		case '2':
@@ -466,9 +503,10 @@ func (app *Application) showEditorModal(currentIndex int) {
 			content := entry.Request.PostData.Text
 			extension := app.getExtensionFromMimeType(entry.Request.PostData.MimeType)
 			
-			if _, err := app.openInEditor(content, extension); err == nil {
-				app.showStatusMessage("Request body edited (note: changes not saved to HAR)")
-				app.app.SetRoot(app.layout, true)
+			if edited, err := app.openInEditor(content, extension); err == nil {
+				draft := replay.NewDraft(entry.Request)
+				draft.Body = edited
+				app.showComposer(entryIdx, draft)
 			} else {
 				app.showStatusMessage(fmt.Sprintf("Editor error: %v", err))
 				app.app.SetRoot(app.layout, true)
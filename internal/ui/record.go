@@ -0,0 +1,96 @@
+package ui
+
+import (
+	"fmt"
+	"net"
+	"sync/atomic"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/proxy"
+	"github.com/rivo/tview"
+)
+
+// defaultRecordingName names a recorded session that isn't saved on exit
+const defaultRecordingName = "recording.har"
+
+// recordingState is a capture in progress through the recording proxy
+type recordingState struct {
+	proxy    *proxy.Proxy
+	listener net.Listener
+	address  string
+	output   string      // the session is saved here on exit, "" for none
+	stopped  atomic.Bool // set once recording stops; later exchanges are dropped
+}
+
+// NewApplicationRecording creates an application that lists the exchanges a
+// recording proxy on listener captures as they happen. When output is set
+// the whole session is saved there as HAR 1.2 on exit
+func NewApplicationRecording(ca *proxy.CA, listener net.Listener, opts proxy.Options, output string) *Application {
+	name := output
+	if name == "" {
+		name = defaultRecordingName
+	}
+	app := NewApplicationStreaming(name)
+	// Entries trickle in, so show each one as it arrives
+	app.batchUpdateSize = 1
+	app.streamingLoader.StartLive(proxy.Creator())
+
+	state := &recordingState{listener: listener, address: listener.Addr().String(), output: output}
+	state.proxy = proxy.New(ca, opts, func(entry har.HAREntry) {
+		if !state.stopped.Load() {
+			app.streamingLoader.AddEntry(entry)
+		}
+	}, func(err error) {
+		app.app.QueueUpdateDraw(func() {
+			app.showStatusMessage(fmt.Sprintf("Recording: %s", tview.Escape(err.Error())))
+		})
+	})
+	app.recording = state
+	return app
+}
+
+// startRecording serves the recording proxy in the background
+func (app *Application) startRecording() {
+	state := app.recording
+	app.showStatusMessage(fmt.Sprintf("Recording through the proxy on %s - trust http://%s/ca.pem for HTTPS", state.address, state.address))
+	go func() {
+		if err := state.proxy.Serve(state.listener); err != nil {
+			app.app.QueueUpdateDraw(func() {
+				app.showStatusMessage(fmt.Sprintf("Recording proxy stopped: %v", err))
+			})
+		}
+	}()
+}
+
+// stopRecording closes the proxy and switches to the complete captured session
+func (app *Application) stopRecording() {
+	if app.recording == nil || app.recording.stopped.Load() {
+		return
+	}
+	app.recording.stopped.Store(true)
+	app.recording.proxy.Close()
+	app.finishLoading(fmt.Sprintf("Recording stopped - %d requests captured, S saves them", app.streamingLoader.GetEntryCount()))
+}
+
+// finishRecording stops recording when the application exits and saves the
+// session if asked to
+func (app *Application) finishRecording() error {
+	if app.recording == nil {
+		return nil
+	}
+	app.recording.stopped.Store(true)
+	app.recording.proxy.Close()
+	if app.recording.output == "" {
+		return nil
+	}
+	harFile := app.streamingLoader.GetHARFile()
+	indices := make([]int, len(harFile.Log.Entries))
+	for i := range indices {
+		indices[i] = i
+	}
+	if err := har.SaveFilteredHAR(harFile, indices, app.recording.output); err != nil {
+		return fmt.Errorf("saving recording: %w", err)
+	}
+	fmt.Printf("Saved %d recorded requests to %s\n", len(indices), app.recording.output)
+	return nil
+}
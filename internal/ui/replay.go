@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/replay"
+	"github.com/rivo/tview"
+)
+
+// replayEntry sends an entry again in the background and adds the recorded
+// exchange to the session. Callers make sure loading has finished
+func (app *Application) replayEntry(entryIdx int) {
+	entry := app.targetEntry(entryIdx)
+	app.sendReplay(entryIdx, entry, func(index int, recorded har.HAREntry, report replay.Report) {
+		app.showStatusMessage(fmt.Sprintf("Replayed as entry %d: %d %s in %.0fms - %s (V for details)",
+			index, recorded.Response.Status, recorded.Response.StatusText, recorded.Time, report.Verdict()))
+	})
+}
+
+// sendReplay sends entry in the background as a replay of the original entry.
+// The recorded exchange is added to the session and passed to done on the UI
+// goroutine, together with how its response compares with the original's
+func (app *Application) sendReplay(original int, entry har.HAREntry, done func(index int, recorded har.HAREntry, report replay.Report)) {
+	app.showStatusMessage(fmt.Sprintf("Replaying %s %s...", entry.Request.Method, truncateString(entry.Request.URL, 60)))
+
+	go func() {
+		recorded, err := replay.Send(context.Background(), entry, replay.Options{})
+		app.app.QueueUpdateDraw(func() {
+			if err != nil {
+				app.showResultModal(fmt.Sprintf("[red]✗[white] Replay failed!\n\n[red]Error:[white] %v", err))
+				return
+			}
+			index := app.addReplayedEntry(original, recorded)
+			done(index, recorded, app.replayReports[index])
+		})
+	}()
+}
+
+// addReplayedEntry appends a replayed exchange to the session, linked to the
+// entry it came from and verified against it, and selects it when it passes
+// the current filters
+func (app *Application) addReplayedEntry(original int, recorded har.HAREntry) int {
+	report := replay.Verify(app.harData.Log.Entries[original], recorded, replay.DefaultVerifyOptions())
+	index := app.appendReplayedEntry(original, recorded, report)
+	app.selectEntry(index)
+	return index
+}
+
+// appendReplayedEntry appends a replayed exchange and its report to the
+// session without refreshing the list
+func (app *Application) appendReplayedEntry(original int, recorded har.HAREntry, report replay.Report) int {
+	originalEntry := app.harData.Log.Entries[original]
+	recorded.ReplayOf = &original
+	recorded.Source = originalEntry.Source
+	recorded.Pageref = originalEntry.Pageref
+
+	// Added through the loader so the entry index covers it
+	index := app.streamingLoader.AppendEntry(recorded)
+	app.harData.Log.Entries = app.streamingLoader.GetEntries()
+	if app.replayReports == nil {
+		app.replayReports = make(map[int]replay.Report)
+	}
+	app.replayReports[index] = report
+	return index
+}
+
+// selectEntry refreshes the request list and selects an entry if it passes the filters
+func (app *Application) selectEntry(entryIdx int) {
+	app.updateRequestsList()
+	for row, idx := range app.filteredEntries {
+		if idx == entryIdx {
+			app.requests.SetCurrentItem(row)
+			break
+		}
+	}
+	app.updateBottomBar()
+}
+
+// showReplayReport shows how a replayed entry's response compares with the recorded one
+func (app *Application) showReplayReport(entryIdx int) {
+	report, ok := app.replayReports[entryIdx]
+	entry := app.harData.Log.Entries[entryIdx]
+	if !ok || entry.ReplayOf == nil {
+		app.showStatusMessage("Not a replayed entry - press R to replay it first")
+		return
+	}
+
+	var text strings.Builder
+	if report.Pass {
+		text.WriteString("[green::b]✓ PASS[-::-] the replayed response matches the recording\n")
+	} else {
+		text.WriteString(fmt.Sprintf("[red::b]✗ FAIL[-::-] %s\n", tview.Escape(strings.Join(report.Failures(), "; "))))
+	}
+	text.WriteString(fmt.Sprintf("\nEntry %d replayed from entry %d: %s %s\n", entryIdx, *entry.ReplayOf,
+		entry.Request.Method, tview.Escape(truncateString(entry.Request.URL, 100))))
+
+	status := fmt.Sprintf("%d → %d", report.StatusBefore, report.StatusAfter)
+	if report.StatusBefore != report.StatusAfter {
+		status = "[red]" + status + "[white]"
+	}
+	text.WriteString("\n[yellow]Status:[white] " + status + "\n")
+	app.writeHeaderDiff(&text, "Response Headers", report.Headers)
+	text.WriteString(fmt.Sprintf("[dim]  ignored: %s[white]\n", strings.Join(replay.DefaultIgnoredHeaders, ", ")))
+
+	if report.BodyIsJSON {
+		text.WriteString("\n[yellow]Response Body (JSON):[white]\n")
+		writeJSONPathChanges(&text, report.BodyChanges)
+	} else {
+		text.WriteString("\n[yellow]Response Body:[white]\n")
+		if report.BodyChanged {
+			text.WriteString("  body differs - mark one entry with x and press x on the other for a line diff\n")
+		} else {
+			text.WriteString("[dim]  no changes[white]\n")
+		}
+	}
+	app.showResultModal(text.String())
+}
@@ -0,0 +1,147 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/sanitize"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// sanitizePreviewLength is how much of a masked value the preview shows
+const sanitizePreviewLength = 40
+
+// shareEntry returns an entry as it may leave the app: masked when
+// sanitizing is on
+func (app *Application) shareEntry(entry har.HAREntry) har.HAREntry {
+	if app.sanitizer == nil {
+		return entry
+	}
+	sanitized, _ := app.sanitizer.Entry(entry)
+	return sanitized
+}
+
+// exportEntry returns an entry rewritten for the active environment, then
+// masked, for exports that send or show the request
+func (app *Application) exportEntry(entry har.HAREntry) har.HAREntry {
+	return app.shareEntry(app.environment.Apply(entry))
+}
+
+// sanitizedRow is a masked value listed in the preview
+type sanitizedRow struct {
+	entryIdx  int
+	redaction sanitize.Redaction
+}
+
+// showSanitizePreview lists what sanitizing masks in the filtered entries,
+// and turns it on or off for saves, exports and the clipboard
+func (app *Application) showSanitizePreview() {
+	if app.isLoading || app.harData == nil {
+		app.showStatusMessage("Wait for loading to finish before sanitizing")
+		return
+	}
+	sanitizer := app.sanitizer
+	if sanitizer == nil {
+		var err error
+		if sanitizer, err = sanitize.Load(""); err != nil {
+			app.showStatusMessage(fmt.Sprintf("Config error: %v", err))
+			return
+		}
+	}
+
+	entries := app.currentEntries()
+	var rows []sanitizedRow
+	masked := 0
+	for _, idx := range app.filteredEntries {
+		_, redactions := sanitizer.Entry(entries[idx])
+		if len(redactions) > 0 {
+			masked++
+		}
+		for _, redaction := range redactions {
+			rows = append(rows, sanitizedRow{entryIdx: idx, redaction: redaction})
+		}
+	}
+
+	summaryView := tview.NewTextView()
+	summaryView.SetDynamicColors(true)
+	summaryView.SetBorder(true)
+	summaryView.SetTitle(" 🔒 Sanitize ")
+	summaryView.SetBorderColor(tcell.ColorGreen)
+	updateSummary := func() {
+		state := "[red]off[white] - saves, exports and copies contain these values"
+		if app.sanitizer != nil {
+			state = "[green]on[white] - saves, exports and copies are masked"
+		}
+		summaryView.SetText(fmt.Sprintf("%d values masked in %d of %d requests  │  Sanitizing %s", len(rows), masked, len(app.filteredEntries), state))
+	}
+	updateSummary()
+
+	list := tview.NewList()
+	list.ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetSelectedBackgroundColor(tcell.ColorDarkBlue)
+	list.SetBorder(true)
+	list.SetTitle(" Masked values (Enter jumps to the request) ")
+	for _, row := range rows {
+		list.AddItem(formatSanitizedRow(row), "", 0, nil)
+	}
+	if len(rows) == 0 {
+		list.AddItem("[dim]Nothing to mask in the filtered requests[white]", "", 0, nil)
+	}
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index < len(rows) {
+			app.app.SetRoot(app.layout, true)
+			app.selectEntry(rows[index].entryIdx)
+		}
+	})
+
+	helpBar := tview.NewTextView()
+	helpBar.SetDynamicColors(true)
+	helpBar.SetText("[yellow]j/k[white] move  [yellow]Enter[white] go to request  [yellow]t[white] turn sanitizing on/off  [yellow]q/Esc[white] close")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(summaryView, 3, 0, false)
+	layout.AddItem(list, 0, 1, true)
+	layout.AddItem(helpBar, 1, 0, false)
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			app.app.SetRoot(app.layout, true)
+			return nil
+		}
+		switch event.Rune() {
+		case 'q':
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case 't':
+			if app.sanitizer == nil {
+				app.sanitizer = sanitizer
+			} else {
+				app.sanitizer = nil
+			}
+			updateSummary()
+			app.updateBottomBar()
+			return nil
+		case 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		}
+		return event
+	})
+
+	app.app.SetRoot(layout, true)
+	app.app.SetFocus(list)
+}
+
+// formatSanitizedRow renders a masked value for the preview list
+func formatSanitizedRow(row sanitizedRow) string {
+	value := strings.Join(strings.Fields(row.redaction.Value), " ")
+	if len(value) > sanitizePreviewLength {
+		value = value[:sanitizePreviewLength] + "…"
+	}
+	return fmt.Sprintf("[dim]#%-4d[white] %-40s [yellow]%-14s[white] [red]%s[white]",
+		row.entryIdx, tview.Escape(row.redaction.Location), row.redaction.Rule, tview.Escape(value))
+}
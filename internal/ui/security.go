@@ -0,0 +1,172 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cnharrison/har-tui/internal/audit"
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/severity"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// securityHeaders are the response headers the Security tab shows
+var securityHeaders = []string{
+	"Strict-Transport-Security", "Content-Security-Policy", "Content-Security-Policy-Report-Only",
+	"X-Content-Type-Options", "Referrer-Policy", "Access-Control-Allow-Origin", "Access-Control-Allow-Credentials",
+}
+
+// entryAuditor returns the auditor for the loaded entries, rebuilt when
+// more have been added
+func (app *Application) entryAuditor() *audit.Auditor {
+	entries := app.currentEntries()
+	if app.auditor == nil || app.auditedEntries != len(entries) {
+		app.auditor = audit.New(entries)
+		app.auditedEntries = len(entries)
+	}
+	return app.auditor
+}
+
+// formatSecurityAudit renders the Security tab: the entry's issues, then
+// the security headers it was sent with
+func (app *Application) formatSecurityAudit(entryIdx int, entry har.HAREntry) string {
+	var text strings.Builder
+	issues := app.entryAuditor().Entry(entryIdx, entry)
+	if len(issues) == 0 {
+		text.WriteString("[green]✓ No issues found[white]\n")
+	} else {
+		text.WriteString(fmt.Sprintf("[yellow]Issues (%d):[white]\n", len(issues)))
+	}
+	for _, issue := range issues {
+		text.WriteString(fmt.Sprintf("  [%s]%-8s[white] %s", severityColors[issue.Severity], strings.ToUpper(issue.Severity.String()), issue.Rule))
+		if issue.Detail != "" {
+			text.WriteString(fmt.Sprintf(" [dim]%s[white]", tview.Escape(issue.Detail)))
+		}
+		text.WriteString("\n")
+	}
+
+	text.WriteString("\n[yellow]Security Headers:[white]\n")
+	for _, name := range securityHeaders {
+		value := "[dim]not set[white]"
+		for _, header := range entry.Response.Headers {
+			if strings.EqualFold(header.Name, name) {
+				value = "[cyan]" + tview.Escape(header.Value) + "[white]"
+				break
+			}
+		}
+		text.WriteString(fmt.Sprintf("  %s: %s\n", name, value))
+	}
+
+	switch har.GetRequestType(entry) {
+	case "doc", "fetch":
+	default:
+		text.WriteString("\n[dim]Headers are audited on documents and API responses; cookies and mixed content on every response[white]\n")
+	}
+	return text.String()
+}
+
+// getSecurityContext returns context info for the Security tab
+func (app *Application) getSecurityContext(entryIdx int) string {
+	entries := app.currentEntries()
+	if entryIdx >= len(entries) {
+		return ""
+	}
+	issues := app.entryAuditor().Entry(entryIdx, entries[entryIdx])
+	if len(issues) == 0 {
+		return "[green]No issues[white]"
+	}
+	counts := [severity.Critical + 1]int{}
+	for _, issue := range issues {
+		counts[issue.Severity]++
+	}
+	var context []string
+	for level := severity.Critical; level >= severity.Low; level-- {
+		if counts[level] > 0 {
+			context = append(context, fmt.Sprintf("[%s]%d %s[white]", severityColors[level], counts[level], level))
+		}
+	}
+	return strings.Join(context, " | ")
+}
+
+// showAuditReport audits the filtered entries and lists each issue found
+// with how many responses and hosts have it
+func (app *Application) showAuditReport() {
+	if app.isLoading || app.harData == nil {
+		app.showStatusMessage("Wait for loading to finish before auditing")
+		return
+	}
+	entries := app.currentEntries()
+	auditor := app.entryAuditor()
+	var issues []audit.Issue
+	for _, idx := range app.filteredEntries {
+		issues = append(issues, auditor.Entry(idx, entries[idx])...)
+	}
+	summaries := audit.Summarize(issues)
+
+	affected := make(map[int]bool)
+	for _, issue := range issues {
+		affected[issue.Entry] = true
+	}
+	summaryView := tview.NewTextView()
+	summaryView.SetDynamicColors(true)
+	summaryView.SetBorder(true)
+	summaryView.SetTitle(" 🛡️ Security Audit ")
+	summaryView.SetBorderColor(tcell.ColorOrange)
+	summaryView.SetText(fmt.Sprintf("%d issues of %d kinds in %d of %d requests  │  headers, cookies and mixed content",
+		len(issues), len(summaries), len(affected), len(app.filteredEntries)))
+
+	list := tview.NewList()
+	list.ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetSelectedBackgroundColor(tcell.ColorDarkBlue)
+	list.SetBorder(true)
+	list.SetTitle(" Issues (Enter opens the first request with it) ")
+	for _, summary := range summaries {
+		hosts := strings.Join(summary.Hosts, ", ")
+		if len(summary.Hosts) > 3 {
+			hosts = fmt.Sprintf("%s and %d more", strings.Join(summary.Hosts[:3], ", "), len(summary.Hosts)-3)
+		}
+		list.AddItem(fmt.Sprintf("[%s]%-8s[white] %-38s [yellow]%4d[white] responses  [dim]%s[white]",
+			severityColors[summary.Severity], strings.ToUpper(summary.Severity.String()), summary.Rule, summary.Responses, tview.Escape(hosts)), "", 0, nil)
+	}
+	if len(summaries) == 0 {
+		list.AddItem("[green]No issues in the filtered requests[white]", "", 0, nil)
+	}
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		if index < len(summaries) {
+			app.app.SetRoot(app.layout, true)
+			app.selectEntry(summaries[index].First)
+			app.switchToTab(6)
+		}
+	})
+
+	helpBar := tview.NewTextView()
+	helpBar.SetDynamicColors(true)
+	helpBar.SetText("[yellow]j/k[white] move  [yellow]Enter[white] go to request  [yellow]q/Esc[white] close")
+
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(summaryView, 3, 0, false)
+	layout.AddItem(list, 0, 1, true)
+	layout.AddItem(helpBar, 1, 0, false)
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			app.app.SetRoot(app.layout, true)
+			return nil
+		}
+		switch event.Rune() {
+		case 'q':
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		}
+		return event
+	})
+
+	app.app.SetRoot(layout, true)
+	app.app.SetFocus(list)
+}
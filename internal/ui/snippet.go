@@ -0,0 +1,102 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/cnharrison/har-tui/internal/export"
+	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/pkg/clipboard"
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// showSnippetPicker lists the code snippet targets with a preview of the
+// selected one, and copies it on Enter
+func (app *Application) showSnippetPicker(entry har.HAREntry) {
+	// Snippets are run, so they target the active environment
+	entry = app.exportEntry(entry)
+	targets := export.SnippetTargets()
+
+	list := tview.NewList()
+	list.ShowSecondaryText(false)
+	list.SetHighlightFullLine(true)
+	list.SetSelectedBackgroundColor(tcell.ColorDarkBlue)
+	list.SetBorder(true)
+	list.SetTitle(" Language ")
+
+	preview := tview.NewTextView()
+	preview.SetWrap(false)
+	preview.SetBorder(true)
+	preview.SetTitle(" Preview ")
+
+	snippets := make([]string, len(targets))
+	showPreview := func(index int) {
+		if index < 0 || index >= len(targets) {
+			return
+		}
+		if snippets[index] == "" {
+			snippets[index] = targets[index].Generate(entry)
+		}
+		preview.SetText(tview.Escape(snippets[index]))
+		preview.ScrollToBeginning()
+	}
+	for _, target := range targets {
+		list.AddItem(target.Title, "", 0, nil)
+	}
+	list.SetChangedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		showPreview(index)
+	})
+	list.SetSelectedFunc(func(index int, mainText, secondaryText string, shortcut rune) {
+		showPreview(index)
+		if err := clipboard.CopyToClipboard(snippets[index]); err == nil {
+			app.showStatusMessage(targets[index].Title + " snippet copied to clipboard!")
+		} else {
+			app.showStatusMessage(fmt.Sprintf("Clipboard error: %v", err))
+		}
+		app.app.SetRoot(app.layout, true)
+	})
+	showPreview(0)
+
+	helpBar := tview.NewTextView()
+	helpBar.SetDynamicColors(true)
+	helpBar.SetText("[yellow]j/k[white] move  [yellow]Enter[white] copy  [yellow]Tab[white] switch pane  [yellow]q/Esc[white] close")
+
+	panes := tview.NewFlex()
+	panes.AddItem(list, 32, 0, true)
+	panes.AddItem(preview, 0, 1, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow)
+	layout.AddItem(panes, 0, 1, true)
+	layout.AddItem(helpBar, 1, 0, false)
+
+	layout.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		switch event.Key() {
+		case tcell.KeyEscape:
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case tcell.KeyTab:
+			if list.HasFocus() {
+				app.app.SetFocus(preview)
+			} else {
+				app.app.SetFocus(list)
+			}
+			return nil
+		}
+		switch event.Rune() {
+		case 'q':
+			app.app.SetRoot(app.layout, true)
+			return nil
+		case 'j':
+			if list.HasFocus() {
+				return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+			}
+		case 'k':
+			if list.HasFocus() {
+				return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+			}
+		}
+		return event
+	})
+
+	app.app.SetRoot(layout, true)
+	app.app.SetFocus(list)
+}
@@ -6,6 +6,17 @@ import (
 	"github.com/cnharrison/har-tui/internal/har"
 )
 
+// currentEntries returns the entries listed, from the loader while loading
+func (app *Application) currentEntries() []har.HAREntry {
+	if app.isLoading {
+		return app.streamingLoader.GetEntries()
+	}
+	if app.harData == nil {
+		return nil
+	}
+	return app.harData.Log.Entries
+}
+
 // Streaming callback functions
 func (app *Application) onEntryAdded(entry har.HAREntry, index int) {
 	// Batch updates to avoid rubberbanding during scrolling
@@ -13,13 +24,7 @@ func (app *Application) onEntryAdded(entry har.HAREntry, index int) {
 	if entryCount-app.lastUpdateCount >= app.batchUpdateSize {
 		app.lastUpdateCount = entryCount
 		app.app.QueueUpdateDraw(func() {
-			// Preserve current selection
-			currentIndex := app.requests.GetCurrentItem()
-			app.updateRequestsList()
-			// Restore selection if valid
-			if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
-				app.requests.SetCurrentItem(currentIndex)
-			}
+			app.refreshKeepingSelection()
 			app.updateBottomBar()
 		})
 	} else {
@@ -32,24 +37,68 @@ func (app *Application) onEntryAdded(entry har.HAREntry, index int) {
 
 func (app *Application) onLoadingComplete() {
 	app.app.QueueUpdateDraw(func() {
-		app.isLoading = false
-		app.harData = &har.HARFile{
-			Log: har.HARLog{
-				Version: harVersion,
-				Entries: app.streamingLoader.GetEntries(),
-			},
-		}
-		// Preserve current selection during final update
-		currentIndex := app.requests.GetCurrentItem()
-		app.updateRequestsList()
-		if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
-			app.requests.SetCurrentItem(currentIndex)
+		if app.following {
+			app.following = false
+			app.finishLoading(fmt.Sprintf("Stopped following %s", app.filename))
+			return
 		}
-		app.updateBottomBar()
-		app.showStatusMessage("Loading complete!")
+		app.finishLoading("Loading complete!")
 	})
 }
 
+// refreshKeepingSelection lists entries that arrived while loading, keeping
+// the selected entry and the scroll position even when rows move
+func (app *Application) refreshKeepingSelection() {
+	selected := -1
+	currentIndex := app.requests.GetCurrentItem()
+	if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
+		selected = app.filteredEntries[currentIndex]
+	}
+	offset, horizontal := app.requests.GetOffset()
+	
+	app.updateRequestsList()
+	
+	for row, entryIdx := range app.filteredEntries {
+		if entryIdx == selected {
+			currentIndex = row
+			break
+		}
+	}
+	if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
+		app.requests.SetCurrentItem(currentIndex)
+		if app.showWaterfall {
+			app.waterfallView.SetSelectedEntry(app.filteredEntries[currentIndex])
+		}
+	}
+	app.requests.SetOffset(offset, horizontal)
+}
+
+// flushPendingEntries lists entries that arrived since the last batched
+// update, so the latest entries of a followed file don't wait for a batch
+func (app *Application) flushPendingEntries() {
+	if !app.isLoading {
+		return
+	}
+	if entryCount := app.streamingLoader.GetEntryCount(); entryCount != app.lastUpdateCount {
+		app.lastUpdateCount = entryCount
+		app.refreshKeepingSelection()
+	}
+}
+
+// finishLoading switches from the loader's entries to the complete HAR
+func (app *Application) finishLoading(message string) {
+	app.isLoading = false
+	app.harData = app.streamingLoader.GetHARFile()
+	// Preserve current selection during final update
+	currentIndex := app.requests.GetCurrentItem()
+	app.updateRequestsList()
+	if currentIndex >= 0 && currentIndex < len(app.filteredEntries) {
+		app.requests.SetCurrentItem(currentIndex)
+	}
+	app.updateBottomBar()
+	app.showStatusMessage(message)
+}
+
 func (app *Application) onLoadingError(err error) {
 	app.app.QueueUpdateDraw(func() {
 		app.isLoading = false
@@ -12,6 +12,7 @@ import (
 	"github.com/tidwall/gjson"
 	"github.com/cnharrison/har-tui/internal/filter"
 	"github.com/cnharrison/har-tui/internal/har"
+	"github.com/cnharrison/har-tui/internal/util"
 )
 
 func (app *Application) updateRequestsList() {
@@ -26,8 +27,9 @@ func (app *Application) updateRequestsList() {
 			app.filteredEntries = []int{}
 		}
 	} else if app.harData != nil {
+		// The loader's index covers every entry, so searches keep using it
 		entries = app.harData.Log.Entries
-		app.filteredEntries = app.filterState.FilterEntries(entries)
+		app.filteredEntries = app.filterState.FilterEntriesWithIndex(entries, app.streamingLoader.GetIndex())
 	} else {
 		return
 	}
@@ -71,6 +73,28 @@ func (app *Application) updateRequestsList() {
 		displayText := fmt.Sprintf("[cyan]%-4s[white] [%s]%3d[white] [blue]%s[white] [dim]%s[white] [yellow]%s[white]%s", 
 			method, statusColor, status, host, path, duration, corsIndicator)
 		
+		// Prefix the source file when several HARs are open
+		if entry.Source != "" {
+			source := fmt.Sprintf("%-*s", maxSourceDisplayLength, truncateString(entry.Source, maxSourceDisplayLength))
+			displayText = fmt.Sprintf("[green]%s[white] %s", tview.Escape(source), displayText)
+		}
+		
+		// Flag replayed entries
+		if entry.ReplayOf != nil {
+			marker := "[purple]↻[white]"
+			if report, ok := app.replayReports[idx]; ok && report.Pass {
+				marker = "[green]↻[white]"
+			} else if ok {
+				marker = "[red]↻[white]"
+			}
+			displayText = marker + " " + displayText
+		}
+		
+		// Flag the entry marked for an entry diff
+		if idx == app.markedEntry {
+			displayText = "[purple::b]◆[-::-] " + displayText
+		}
+		
 		app.requests.AddItem(displayText, "", 0, nil)
 	}
 	
@@ -214,6 +238,9 @@ func (app *Application) updateTabContent(selectedIndex int) {
 	// Raw tab
 	app.rawView.SetText(fmt.Sprintf("[yellow]Complete Entry:[white]\n\n%s", app.prettyJSON(entry)))
 	
+	// Security tab
+	app.securityView.SetText(app.formatSecurityAudit(entryIdx, entry))
+	
 	// Update bottom bar to reflect new context
 	app.updateBottomBar()
 }
@@ -239,12 +266,42 @@ func (app *Application) updateFilterBar() {
 		}
 	}
 	
+	// Page selector, only for logs that record pages
+	if pages := app.getPages(); len(pages) > 0 {
+		filterText.WriteString("│ ")
+		if app.filterState.ActivePage == "" {
+			filterText.WriteString(fmt.Sprintf("[magenta:black:b] PAGE: ALL (%d) [white:black:-] ", len(pages)))
+		} else {
+			for i, page := range pages {
+				if page.ID == app.filterState.ActivePage {
+					filterText.WriteString(fmt.Sprintf("[black:yellow:b] PAGE %d/%d: %s [white:black:-] ", i+1, len(pages), app.getPageLabel(page)))
+					break
+				}
+			}
+		}
+	}
+	
+	// Source selector, only when several files are open
+	if sources := app.getSources(); len(sources) > 0 {
+		filterText.WriteString("│ ")
+		if app.filterState.ActiveSource == "" {
+			filterText.WriteString(fmt.Sprintf("[magenta:black:b] FILE: ALL (%d) [white:black:-] ", len(sources)))
+		} else {
+			for i, source := range sources {
+				if source == app.filterState.ActiveSource {
+					filterText.WriteString(fmt.Sprintf("[black:yellow:b] FILE %d/%d: %s [white:black:-] ", i+1, len(sources), tview.Escape(source)))
+					break
+				}
+			}
+		}
+	}
+	
 	app.filterBar.SetText(filterText.String())
 }
 
 // updateTabBar updates the tab indicator bar
 func (app *Application) updateTabBar() {
-	tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw"}
+	tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw", "Security"}
 	var tabText strings.Builder
 	
 	for i, name := range tabNames {
@@ -270,6 +327,8 @@ func (app *Application) updateWaterfallView() {
 		waterfallEntries = app.harData.Log.Entries
 	}
 	if len(waterfallEntries) > 0 {
+		app.waterfallView.SetPages(app.getPages(), app.filterState.ActivePage)
+		app.waterfallView.SetShowSources(len(app.getSources()) > 0)
 		app.waterfallView.Update(waterfallEntries, app.filteredEntries)
 		
 		// Synchronize waterfall selection with requests list selection
@@ -300,7 +359,11 @@ func (app *Application) updateBottomBar() {
 		var totalEntries int
 		if app.isLoading {
 			totalEntries = app.streamingLoader.GetEntryCount()
-			if app.loadingProgress > 0 {
+			if app.recording != nil {
+				statusText.WriteString(fmt.Sprintf("[red]● Recording[white] on %s - %d requests (r to stop)", app.recording.address, totalEntries))
+			} else if app.following {
+				statusText.WriteString(fmt.Sprintf("[green]● Following[white] %s - %d entries (r to stop)", app.filename, totalEntries))
+			} else if app.loadingProgress > 0 {
 				statusText.WriteString(fmt.Sprintf("Loading... %d entries", app.loadingProgress))
 			} else {
 				statusText.WriteString("Starting load...")
@@ -315,6 +378,9 @@ func (app *Application) updateBottomBar() {
 		
 		if app.filterState.FilterText != "" {
 			statusText.WriteString(fmt.Sprintf(" | Filter: [cyan]%s[white]", app.filterState.FilterText))
+			if modes := app.filterState.SearchModeLabel(); modes != "" {
+				statusText.WriteString(fmt.Sprintf(" (%s)", modes))
+			}
 		}
 		if app.filterState.ShowErrorsOnly {
 			statusText.WriteString(" | [red]Errors Only[white]")
@@ -325,6 +391,21 @@ func (app *Application) updateBottomBar() {
 		if app.filterState.ActiveTypeFilter != "all" {
 			statusText.WriteString(fmt.Sprintf(" | [cyan]Type: %s[white]", app.filterState.ActiveTypeFilter))
 		}
+		if app.filterState.ActivePage != "" {
+			statusText.WriteString(fmt.Sprintf(" | [magenta]Page: %s[white]", app.filterState.ActivePage))
+		}
+		if app.filterState.ActiveSource != "" {
+			statusText.WriteString(fmt.Sprintf(" | [green]File: %s[white]", app.filterState.ActiveSource))
+		}
+		if app.environment != nil {
+			statusText.WriteString(fmt.Sprintf(" | [orange]Env: %s[white]", app.environment.Name))
+		}
+		if app.sanitizer != nil {
+			statusText.WriteString(" | [green]Sanitized[white]")
+		}
+		if app.mock != nil {
+			statusText.WriteString(fmt.Sprintf(" | [aqua]Mock: %s (%d hits)[white]", app.mock.address, app.mock.total))
+		}
 	}
 	
 	// Add contextual information on the right side
@@ -384,7 +465,11 @@ func (app *Application) updateBottomBarSafe() {
 		var totalEntries int
 		if app.isLoading {
 			totalEntries = app.streamingLoader.GetEntryCount()
-			if app.loadingProgress > 0 {
+			if app.recording != nil {
+				statusText.WriteString(fmt.Sprintf("[red]● Recording[white] on %s - %d requests (r to stop)", app.recording.address, totalEntries))
+			} else if app.following {
+				statusText.WriteString(fmt.Sprintf("[green]● Following[white] %s - %d entries (r to stop)", app.filename, totalEntries))
+			} else if app.loadingProgress > 0 {
 				statusText.WriteString(fmt.Sprintf("Loading... %d entries", app.loadingProgress))
 			} else {
 				statusText.WriteString("Starting load...")
@@ -399,6 +484,9 @@ func (app *Application) updateBottomBarSafe() {
 		
 		if app.filterState.FilterText != "" {
 			statusText.WriteString(fmt.Sprintf(" | Filter: [cyan]%s[white]", app.filterState.FilterText))
+			if modes := app.filterState.SearchModeLabel(); modes != "" {
+				statusText.WriteString(fmt.Sprintf(" (%s)", modes))
+			}
 		}
 		if app.filterState.ShowErrorsOnly {
 			statusText.WriteString(" | [red]Errors Only[white]")
@@ -409,6 +497,21 @@ func (app *Application) updateBottomBarSafe() {
 		if app.filterState.ActiveTypeFilter != "all" {
 			statusText.WriteString(fmt.Sprintf(" | [cyan]Type: %s[white]", app.filterState.ActiveTypeFilter))
 		}
+		if app.filterState.ActivePage != "" {
+			statusText.WriteString(fmt.Sprintf(" | [magenta]Page: %s[white]", app.filterState.ActivePage))
+		}
+		if app.filterState.ActiveSource != "" {
+			statusText.WriteString(fmt.Sprintf(" | [green]File: %s[white]", app.filterState.ActiveSource))
+		}
+		if app.environment != nil {
+			statusText.WriteString(fmt.Sprintf(" | [orange]Env: %s[white]", app.environment.Name))
+		}
+		if app.sanitizer != nil {
+			statusText.WriteString(" | [green]Sanitized[white]")
+		}
+		if app.mock != nil {
+			statusText.WriteString(fmt.Sprintf(" | [aqua]Mock: %s (%d hits)[white]", app.mock.address, app.mock.total))
+		}
 	}
 	
 	// Add contextual information on the right side
@@ -493,6 +596,8 @@ func (app *Application) getContentContext() string {
 		return app.getTimingsContext(entry)
 	case 5: // Raw tab
 		return app.getRawContext(entry)
+	case 6: // Security tab
+		return app.getSecurityContext(entryIdx)
 	default:
 		return ""
 	}
@@ -1416,11 +1521,7 @@ func (app *Application) walkJSONForPaths(result gjson.Result, currentPath string
 			pathInfo.lineToPath[lineNum] = currentPath
 			
 			result.ForEach(func(key, value gjson.Result) bool {
-				keyPath := currentPath
-				if keyPath != "" {
-					keyPath += "."
-				}
-				keyPath += key.String()
+				keyPath := util.JSONKeyPath(currentPath, key.String())
 				
 				// Find the line where this key appears in the formatted content
 				keyLine := app.findKeyInLines(lines, key.String(), lineNum)
@@ -1439,7 +1540,7 @@ func (app *Application) walkJSONForPaths(result gjson.Result, currentPath string
 			pathInfo.lineToPath[lineNum] = currentPath
 			
 			result.ForEach(func(key, value gjson.Result) bool {
-				indexPath := fmt.Sprintf("%s[%s]", currentPath, key.String())
+				indexPath := util.JSONIndexPath(currentPath, int(key.Int()))
 				
 				// Find the line where this array element appears
 				elemLine := app.findArrayElementInLines(lines, key.String(), lineNum)
@@ -1697,9 +1798,9 @@ func (app *Application) updateFocusStyles() {
 		}
 		
 		// Bottom panel focused - add blinking arrow to current tab
-		tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw"}
-		views := []tview.Primitive{app.requestView, app.responseView, app.bodyView, app.cookiesView, app.timingsView, app.rawView}
-		colors := []tcell.Color{tcell.ColorDarkCyan, tcell.ColorDarkGreen, tcell.ColorDarkBlue, tcell.ColorDarkMagenta, tcell.ColorDarkRed, tcell.ColorYellow}
+		tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw", "Security"}
+		views := []tview.Primitive{app.requestView, app.responseView, app.bodyView, app.cookiesView, app.timingsView, app.rawView, app.securityView}
+		colors := []tcell.Color{tcell.ColorDarkCyan, tcell.ColorDarkGreen, tcell.ColorDarkBlue, tcell.ColorDarkMagenta, tcell.ColorDarkRed, tcell.ColorYellow, tcell.ColorOrange}
 		
 		for i, view := range views {
 			// Handle Body tab specially when it's in side-by-side mode
@@ -1716,10 +1817,10 @@ func (app *Application) updateFocusStyles() {
 				if i == app.currentTab {
 					textView.SetBorderColor(tcell.ColorWhite)
 					textView.SetTitle(fmt.Sprintf(" [yellow]%s[white] %s %s ", 
-						arrow, []string{"📋", "📨", "📄", "🍪", "⏱️", "🔍"}[i], tabNames[i]))
+						arrow, []string{"📋", "📨", "📄", "🍪", "⏱️", "🔍", "🛡️"}[i], tabNames[i]))
 				} else {
 					textView.SetBorderColor(colors[i])
-					textView.SetTitle(" " + []string{"📋", "📨", "📄", "🍪", "⏱️", "🔍"}[i] + " " + tabNames[i] + " ")
+					textView.SetTitle(" " + []string{"📋", "📨", "📄", "🍪", "⏱️", "🔍", "🛡️"}[i] + " " + tabNames[i] + " ")
 				}
 			}
 		}
@@ -1738,9 +1839,9 @@ func (app *Application) updateFocusStyles() {
 		}
 		
 		// Bottom panel unfocused - no arrows
-		tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw"}
-		views := []tview.Primitive{app.requestView, app.responseView, app.bodyView, app.cookiesView, app.timingsView, app.rawView}
-		colors := []tcell.Color{tcell.ColorDarkCyan, tcell.ColorDarkGreen, tcell.ColorDarkBlue, tcell.ColorDarkMagenta, tcell.ColorDarkRed, tcell.ColorYellow}
+		tabNames := []string{"Request", "Response", "Body", "Cookies", "Timings", "Raw", "Security"}
+		views := []tview.Primitive{app.requestView, app.responseView, app.bodyView, app.cookiesView, app.timingsView, app.rawView, app.securityView}
+		colors := []tcell.Color{tcell.ColorDarkCyan, tcell.ColorDarkGreen, tcell.ColorDarkBlue, tcell.ColorDarkMagenta, tcell.ColorDarkRed, tcell.ColorYellow, tcell.ColorOrange}
 		
 		for i, view := range views {
 			// Handle Body tab specially when it's in side-by-side mode
@@ -1749,7 +1850,7 @@ func (app *Application) updateFocusStyles() {
 				app.bodyFlexContainer.SetTitle(" 📄 Body ")
 			} else if textView, ok := view.(*tview.TextView); ok {
 				textView.SetBorderColor(colors[i])
-				textView.SetTitle(" " + []string{"📋", "📨", "📄", "🍪", "⏱️", "🔍"}[i] + " " + tabNames[i] + " ")
+				textView.SetTitle(" " + []string{"📋", "📨", "📄", "🍪", "⏱️", "🔍", "🛡️"}[i] + " " + tabNames[i] + " ")
 			}
 		}
 	}
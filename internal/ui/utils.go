@@ -24,13 +24,53 @@ func (app *Application) getCurrentView() *tview.TextView {
 	}
 	
 	// Default behavior for normal tabs
-	views := []*tview.TextView{app.requestView, app.responseView, app.bodyView, app.cookiesView, app.timingsView, app.rawView}
+	views := []*tview.TextView{app.requestView, app.responseView, app.bodyView, app.cookiesView, app.timingsView, app.rawView, app.securityView}
 	if app.currentTab >= 0 && app.currentTab < len(views) {
 		return views[app.currentTab]
 	}
 	return app.requestView
 }
 
+// getPages returns the pages of the loaded HAR log
+func (app *Application) getPages() []har.HARPage {
+	if app.isLoading {
+		return app.streamingLoader.GetPages()
+	} else if app.harData != nil {
+		return app.harData.Log.Pages
+	}
+	return nil
+}
+
+// getPageLabel returns a short display label for a page
+func (app *Application) getPageLabel(page har.HARPage) string {
+	label := page.Title
+	if label == "" {
+		label = page.ID
+	}
+	if u, err := url.Parse(label); err == nil && u.Host != "" {
+		label = u.Host + u.Path
+	}
+	return truncateString(label, maxPathDisplayLength)
+}
+
+// getSources returns the source files of the loaded entries, empty for a single file
+func (app *Application) getSources() []string {
+	if sources := app.streamingLoader.GetIndex().Sources(); len(sources) > 0 {
+		return sources
+	}
+	var sources []string
+	if app.harData != nil {
+		seen := make(map[string]bool)
+		for _, entry := range app.harData.Log.Entries {
+			if entry.Source != "" && !seen[entry.Source] {
+				seen[entry.Source] = true
+				sources = append(sources, entry.Source)
+			}
+		}
+	}
+	return sources
+}
+
 // getBlinkingArrows returns blinking arrow characters
 func (app *Application) getBlinkingArrows() string {
 	if app.animationFrame%animationCycleFrames < pulseCycleFrames {
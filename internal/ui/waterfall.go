@@ -20,6 +20,7 @@ const (
 	requestInfoColumnsWidth = 70  // method + status + host + path + separator
 	minChartWidth = 20
 	stickyHeaderHeight = 2
+	barStartColumn = 72 // method(4) + status(3) + host(25) + path(35) + spacing and " │"
 	
 	// Timing thresholds
 	smallTimespanMs = 100    // < 100ms considered small timespan
@@ -47,6 +48,9 @@ type WaterfallView struct {
 	showDetails bool
 	manuallyResized bool  // Flag to prevent auto-sizing from overriding manual zoom
 	onSelectionChanged func(int)
+	pages       []har.HARPage // Pages from the log, drawn as markers on the time scale
+	activePage  string        // Only this page's markers are drawn when set
+	showSources bool          // Prefix each row with its source file
 }
 
 func NewWaterfallView() *WaterfallView {
@@ -86,6 +90,25 @@ func NewWaterfallView() *WaterfallView {
 	return wv
 }
 
+// SetPages sets the pages whose boundaries and load events are marked on the time scale
+func (wv *WaterfallView) SetPages(pages []har.HARPage, activePage string) {
+	wv.pages = pages
+	wv.activePage = activePage
+}
+
+// SetShowSources toggles the source file column used when several HARs are open
+func (wv *WaterfallView) SetShowSources(show bool) {
+	wv.showSources = show
+}
+
+// sourceColumnWidth returns the width of the source column, 0 when hidden
+func (wv *WaterfallView) sourceColumnWidth() int {
+	if !wv.showSources {
+		return 0
+	}
+	return maxSourceDisplayLength + 1
+}
+
 func (wv *WaterfallView) SetSelectionChangedFunc(handler func(int)) {
 	wv.onSelectionChanged = handler
 }
@@ -248,14 +271,14 @@ func (wv *WaterfallView) renderWaterfall() {
 	_, _, viewWidth, _ := wv.GetInnerRect()
 	if viewWidth > 0 && !wv.manuallyResized {
 		// Reserve space for request info columns
-		availableWidth := viewWidth - requestInfoColumnsWidth
+		availableWidth := viewWidth - requestInfoColumnsWidth - wv.sourceColumnWidth()
 		if availableWidth > minChartWidth { // Remove upper bound to use full terminal width
 			wv.chartWidth = availableWidth
 		}
 	}
 	
 	// Update sticky header
-	headerText := wv.renderTimeScale() + "\n" + strings.Repeat("─", viewWidth)
+	headerText := wv.renderTimeScale(viewWidth)
 	wv.headerView.SetText(headerText)
 	
 	// Clear the list and rebuild content (no header items)
@@ -303,11 +326,13 @@ func (wv *WaterfallView) renderWaterfall() {
 	}
 }
 
-func (wv *WaterfallView) renderTimeScale() string {
+// renderTimeScale renders the time scale line and, below it, a separator
+// carrying page start, DOMContentLoaded and onLoad markers
+func (wv *WaterfallView) renderTimeScale(viewWidth int) string {
 	var scale strings.Builder
 	
 	// Fixed-width header to align with request info columns
-	headerWidth := requestInfoColumnsWidth // method + status + host + path + separator
+	headerWidth := requestInfoColumnsWidth + wv.sourceColumnWidth() // source + method + status + host + path + separator
 	scale.WriteString(fmt.Sprintf("%-*s", headerWidth, "[white]Time Scale (log):"))
 	
 	// Calculate tick marks for the logarithmic time scale
@@ -347,9 +372,120 @@ func (wv *WaterfallView) renderTimeScale() string {
 		scale.WriteString(label)
 	}
 	
+	scale.WriteString("\n")
+	scale.WriteString(wv.renderPageMarkers(viewWidth))
+	
 	return scale.String()
 }
 
+// pageMarker is a page event positioned on the waterfall chart
+type pageMarker struct {
+	offsetMs float64 // relative to the waterfall start time
+	char     rune
+	color    string
+	priority int // higher wins when markers share a column
+}
+
+// getPageMarkers returns the page start, DOMContentLoaded and onLoad events to draw
+func (wv *WaterfallView) getPageMarkers() []pageMarker {
+	var markers []pageMarker
+	if wv.startTime.IsZero() {
+		return markers
+	}
+	
+	for _, page := range wv.pages {
+		if wv.activePage != "" && page.ID != wv.activePage {
+			continue
+		}
+		pageStart, err := har.ParseHARDateTime(page.StartedDateTime)
+		if err != nil {
+			continue
+		}
+		
+		startOffset := pageStart.Sub(wv.startTime).Seconds() * 1000
+		markers = append(markers, pageMarker{startOffset, '▼', "magenta", 0})
+		
+		// Spec uses -1 (or omits the field) when a timing isn't available
+		if page.PageTimings.OnContentLoad > 0 {
+			markers = append(markers, pageMarker{startOffset + page.PageTimings.OnContentLoad, '┃', "blue", 1})
+		}
+		if page.PageTimings.OnLoad > 0 {
+			markers = append(markers, pageMarker{startOffset + page.PageTimings.OnLoad, '┃', "red", 2})
+		}
+	}
+	
+	return markers
+}
+
+// renderPageMarkers renders the header separator line with page markers overlaid
+func (wv *WaterfallView) renderPageMarkers(viewWidth int) string {
+	markers := wv.getPageMarkers()
+	
+	// Tiny timespans draw every bar at the left edge, so there is nothing to line up with
+	if len(markers) == 0 || wv.maxDuration <= smallTimespanMs {
+		return strings.Repeat("─", viewWidth)
+	}
+	
+	barStart := barStartColumn + wv.sourceColumnWidth()
+	width := viewWidth
+	if width < barStart+wv.chartWidth+1 {
+		width = barStart + wv.chartWidth + 1
+	}
+	cells := []rune(strings.Repeat("─", width))
+	colors := make([]string, width)
+	priorities := make([]int, width)
+	
+	// Legend in the request info columns
+	legend := []struct {
+		text  string
+		color string
+	}{
+		{"▼ page", "magenta"},
+		{"┃ DOMContentLoaded", "blue"},
+		{"┃ load", "red"},
+	}
+	column := 1
+	for _, item := range legend {
+		for _, r := range " " + item.text + " " {
+			if column < barStart {
+				cells[column] = r
+				colors[column] = item.color
+			}
+			column++
+		}
+	}
+	
+	drawn := 0
+	for _, marker := range markers {
+		if marker.offsetMs < 0 || marker.offsetMs > wv.maxDuration {
+			continue // Outside the visible timespan
+		}
+		pos := barStart + int(float64(wv.chartWidth)*marker.offsetMs/wv.maxDuration)
+		if pos >= width {
+			pos = width - 1
+		}
+		if colors[pos] == "" || marker.priority >= priorities[pos] {
+			cells[pos] = marker.char
+			colors[pos] = marker.color
+			priorities[pos] = marker.priority
+		}
+		drawn++
+	}
+	if drawn == 0 {
+		return strings.Repeat("─", viewWidth)
+	}
+	
+	var line strings.Builder
+	for i, r := range cells {
+		if colors[i] != "" {
+			line.WriteString(fmt.Sprintf("[%s]%c[-]", colors[i], r))
+		} else {
+			line.WriteRune(r)
+		}
+	}
+	return line.String()
+}
+
 // stripColorTags removes tview color tags to get actual display length
 func stripColorTags(text string) string {
 	// Simple regex replacement would be better, but this works for our case
@@ -492,6 +628,14 @@ func (wv *WaterfallView) renderRequestBar(entry har.HAREntry, rowIndex int, isSe
 		}
 	}
 	
+	if wv.showSources {
+		source := fmt.Sprintf("%-*s", maxSourceDisplayLength, truncateString(entry.Source, maxSourceDisplayLength))
+		if isSelected {
+			bar.WriteString(fmt.Sprintf("[yellow:black]%s[-] ", tview.Escape(source)))
+		} else {
+			bar.WriteString(fmt.Sprintf("[green]%s[-] ", tview.Escape(source)))
+		}
+	}
 	bar.WriteString(fmt.Sprintf("%s %s %s %s%s │", methodCol, statusCol, hostCol, pathCol, corsIndicator))
 	
 	// Add spacing to align bars
@@ -1,7 +1,11 @@
 package ui
 
 import (
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/cnharrison/har-tui/internal/har"
 )
 
 // DateTime parsing tests are now centralized in internal/har/datetime_test.go
@@ -56,4 +60,54 @@ func TestWaterfallViewSetSelectedEntry(t *testing.T) {
 	if currentItem := wv.listView.GetCurrentItem(); currentItem != originalItem {
 		t.Errorf("Selection should not change for non-existent entry")
 	}
-}
\ No newline at end of file
+}
+func TestWaterfallViewPageMarkers(t *testing.T) {
+	wv := NewWaterfallView()
+	wv.chartWidth = 100
+	wv.startTime = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	wv.maxDuration = 2000
+	
+	wv.SetPages([]har.HARPage{
+		{
+			ID:              "page_1",
+			StartedDateTime: "2024-05-01T10:00:00.000Z",
+			PageTimings:     har.HARPageTimings{OnContentLoad: 500, OnLoad: 1000},
+		},
+		{
+			ID:              "page_2",
+			StartedDateTime: "2024-05-01T10:00:01.500Z",
+			PageTimings:     har.HARPageTimings{OnContentLoad: -1, OnLoad: -1},
+		},
+	}, "")
+	
+	markers := wv.getPageMarkers()
+	if len(markers) != 4 {
+		t.Fatalf("Expected 4 markers (2 page starts, DCL, load), got %d", len(markers))
+	}
+	if markers[2].offsetMs != 1000 || markers[2].color != "red" {
+		t.Errorf("Expected onLoad marker at 1000ms, got %+v", markers[2])
+	}
+	if markers[3].offsetMs != 1500 {
+		t.Errorf("Expected second page start at 1500ms, got %+v", markers[3])
+	}
+	
+	line := stripColorTags(wv.renderPageMarkers(200))
+	line = strings.NewReplacer("[magenta]", "", "[blue]", "", "[red]", "", "[-]", "").Replace(line)
+	cells := []rune(line)
+	for pos, want := range map[int]rune{
+		barStartColumn:      '▼', // page_1 start
+		barStartColumn + 25: '┃', // DCL at 500ms
+		barStartColumn + 50: '┃', // load at 1000ms
+		barStartColumn + 75: '▼', // page_2 start
+	} {
+		if cells[pos] != want {
+			t.Errorf("Expected %q at column %d, got %q", want, pos, cells[pos])
+		}
+	}
+	
+	// Only the active page's markers are drawn
+	wv.SetPages(wv.pages, "page_2")
+	if markers := wv.getPageMarkers(); len(markers) != 1 {
+		t.Errorf("Expected only page_2 start marker, got %+v", markers)
+	}
+}
@@ -0,0 +1,46 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+// JSONKeyPath appends an object key to a JSON path, e.g. "icons[0]" + "src" = "icons[0].src"
+func JSONKeyPath(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// JSONIndexPath appends an array index to a JSON path, e.g. "icons" + 0 = "icons[0]"
+func JSONIndexPath(parent string, index int) string {
+	return fmt.Sprintf("%s[%d]", parent, index)
+}
+
+// GJSONPath converts a JSON path in the notation above, e.g. "icons[0].src",
+// to gjson syntax ("icons.0.src"), escaping gjson's wildcard and query characters
+func GJSONPath(path string) string {
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		switch c := path[i]; c {
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				b.WriteString(path[i:])
+				return b.String()
+			}
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(path[i+1 : i+end])
+			i += end
+		case '*', '?', '|', '#', '@', '!', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,26 @@
+package util
+
+import "testing"
+
+func TestJSONPaths(t *testing.T) {
+	if got := JSONKeyPath("", "icons"); got != "icons" {
+		t.Errorf("JSONKeyPath at root = %q", got)
+	}
+	if got := JSONKeyPath(JSONIndexPath("icons", 0), "src"); got != "icons[0].src" {
+		t.Errorf("nested path = %q", got)
+	}
+}
+
+func TestGJSONPath(t *testing.T) {
+	tests := map[string]string{
+		"icons[0].src": "icons.0.src",
+		"[2][1]":       "2.1",
+		"a.b":          "a.b",
+		"what?":        `what\?`,
+	}
+	for path, want := range tests {
+		if got := GJSONPath(path); got != want {
+			t.Errorf("GJSONPath(%q) = %q, want %q", path, got, want)
+		}
+	}
+}